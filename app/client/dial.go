@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/paths"
+)
+
+var dialLog = logging.Logger("api-client")
+
+// The environment variables configuring the tls of the clients of the api, for a node using
+// a certificate not trusted by the system or requiring client certificates.
+const (
+	// EnvAPICA is a pem file of the authorities trusted for the certificate of the api, on top
+	// of the ones of the system.
+	EnvAPICA = "VENUS_API_CA"
+	// EnvAPICert and EnvAPIKey are the pem files of the client certificate and key presented
+	// to a node using mutual tls.
+	EnvAPICert = "VENUS_API_CERT"
+	EnvAPIKey  = "VENUS_API_KEY"
+)
+
+// NewTLSConfig returns the tls config of a client trusting the authorities of `caPath` on top
+// of the ones of the system, and presenting the certificate `certPath` with key `keyPath`.
+// Each path may be empty.
+func NewTLSConfig(caPath, certPath, keyPath string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caPath != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := ioutil.ReadFile(caPath)
+		if err != nil {
+			return nil, xerrors.Errorf("reading api ca: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, xerrors.Errorf("no certificate found in %s", caPath)
+		}
+		cfg.RootCAs = pool
+	}
+	if certPath != "" || keyPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, xerrors.Errorf("loading api client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// TLSConfigFromEnv returns the tls config given by the VENUS_API_CA, VENUS_API_CERT and
+// VENUS_API_KEY environment variables, nil when none is set.
+func TLSConfigFromEnv() (*tls.Config, error) {
+	ca, cert, key := os.Getenv(EnvAPICA), os.Getenv(EnvAPICert), os.Getenv(EnvAPIKey)
+	if ca == "" && cert == "" && key == "" {
+		return nil, nil
+	}
+	return NewTLSConfig(ca, cert, key)
+}
+
+// relay is a loopback listener relaying its connections to the endpoint of the api. jsonrpc
+// dials its websockets with the global dialer of the websocket package, which can neither
+// reach a unix socket endpoint nor use the tls config of the environment, so it is given the
+// address of a relay dialing the endpoint instead. The global dialer, used by the other
+// websocket clients of the process, is left alone.
+type relay struct {
+	ln   net.Listener
+	dial func(ctx context.Context) (net.Conn, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// relayAddr returns the address and headers jsonrpc dials for the websocket url `addr`, and
+// the function stopping the relay started for it. The urls of the tcp endpoints served
+// without tls, or over tls with no tls config in the environment, are dialed directly.
+func relayAddr(addr string, headers http.Header) (string, http.Header, func(), error) {
+	noop := func() {}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", nil, nil, xerrors.Errorf("parsing api url %s: %w", addr, err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return addr, headers, noop, nil
+	}
+	tlsCfg, err := TLSConfigFromEnv()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	host := u.Host
+	unix := paths.IsUnixAddr(host)
+	if !unix && (u.Scheme == "ws" || tlsCfg == nil) {
+		return addr, headers, noop, nil
+	}
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return paths.DialContext(ctx, "tcp", host)
+	}
+	if u.Scheme == "wss" {
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		if tlsCfg.ServerName == "" && !unix {
+			tlsCfg = tlsCfg.Clone()
+			tlsCfg.ServerName = u.Hostname()
+		}
+		dialTCP := dial
+		dial = func(ctx context.Context) (net.Conn, error) {
+			conn, err := dialTCP(ctx)
+			if err != nil {
+				return nil, err
+			}
+			tlsConn := tls.Client(conn, tlsCfg)
+			if err := tlsConn.Handshake(); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+			return tlsConn, nil
+		}
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, nil, xerrors.Errorf("listening for the api relay: %w", err)
+	}
+	r := &relay{ln: ln, dial: dial}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go r.serve()
+
+	// the endpoint still sees the host of the url
+	relayed := http.Header{}
+	for k, v := range headers {
+		relayed[k] = v
+	}
+	relayed.Set("Host", host)
+	u.Scheme, u.Host = "ws", ln.Addr().String()
+	return u.String(), relayed, r.close, nil
+}
+
+func (r *relay) serve() {
+	defer r.wg.Done()
+	for {
+		conn, err := r.ln.Accept()
+		if err != nil {
+			return
+		}
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.pipe(conn)
+		}()
+	}
+}
+
+func (r *relay) pipe(conn net.Conn) {
+	defer conn.Close() // nolint: errcheck
+	upstream, err := r.dial(r.ctx)
+	if err != nil {
+		dialLog.Warnw("dialing the api", "err", err)
+		return
+	}
+	defer upstream.Close() // nolint: errcheck
+
+	// stop both copies once either side is done or the relay is closed
+	ctx, cancel := context.WithCancel(r.ctx)
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+		_ = upstream.Close()
+	}()
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		cancel()
+	}()
+	_, _ = io.Copy(conn, upstream)
+	cancel()
+}
+
+func (r *relay) close() {
+	r.cancel()
+	_ = r.ln.Close()
+	r.wg.Wait()
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/paths"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+type versionAPI struct{}
+
+func (versionAPI) Version(context.Context) (apitypes.Version, error) {
+	return apitypes.Version{Version: "test"}, nil
+}
+
+func TestNewFullNodeRPCOverTLS(t *testing.T) {
+	testflags.UnitTest(t)
+
+	rpc := jsonrpc.NewServer()
+	rpc.Register("Filecoin", versionAPI{})
+	srv := httptest.NewTLSServer(rpc)
+	defer srv.Close()
+
+	// the certificate of the server is trusted through the environment
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, ioutil.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+	require.NoError(t, os.Setenv(EnvAPICA, ca))
+	defer os.Unsetenv(EnvAPICA) // nolint: errcheck
+
+	node, closer, err := NewFullNodeRPC(context.Background(), "wss://"+srv.Listener.Addr().String()+"/rpc/v1", nil)
+	require.NoError(t, err)
+	defer closer()
+	v, err := node.Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "test", v.Version)
+
+	// the other websocket clients of the process still dial as before
+	assert.Nil(t, websocket.DefaultDialer.TLSClientConfig)
+	assert.Nil(t, websocket.DefaultDialer.NetDialContext)
+}
+
+func TestNewFullNodeRPCOverUnixSocket(t *testing.T) {
+	testflags.UnitTest(t)
+
+	rpc := jsonrpc.NewServer()
+	rpc.Register("Filecoin", versionAPI{})
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: rpc}
+	go srv.Serve(ln)  // nolint: errcheck
+	defer srv.Close() // nolint: errcheck
+
+	node, closer, err := NewFullNodeRPC(context.Background(), paths.APIEndpoint{UnixPath: sock}.URL("ws", "/rpc/v1"), nil)
+	require.NoError(t, err)
+	defer closer()
+	v, err := node.Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "test", v.Version)
+}
@@ -1,4 +1,4 @@
-// Code generated by github.com/filecoin-project/tools/gen/api. DO NOT EDIT.
+// Code generated by github.com/filecoin-project/venus/tools/gen/api. DO NOT EDIT.
 
 package client
 
@@ -20,6 +20,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/chain"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/messagepool"
 	"github.com/filecoin-project/venus/pkg/net"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
@@ -45,6 +46,7 @@ type FullNodeStruct struct {
 	IMultiSigStruct
 	INetworkStruct
 	IPaychanStruct
+	IRPCStruct
 	ISyncerStruct
 	IWalletStruct
 	IJwtAuthAPIStruct
@@ -87,6 +89,7 @@ type IChainStruct struct {
 
 type IChainInfoStruct struct {
 	BlockTime                     func(p0 context.Context) time.Duration                                                                                             `perm:"read"`
+	ChainGasStats                 func(p0 context.Context, p1 abi.ChainEpoch, p2 abi.ChainEpoch) (*apitypes.GasStats, error)                                         `perm:"read"`
 	ChainGetBlock                 func(p0 context.Context, p1 cid.Cid) (*types.BlockHeader, error)                                                                   `perm:"read"`
 	ChainGetBlockMessages         func(p0 context.Context, p1 cid.Cid) (*apitypes.BlockMessages, error)                                                              `perm:"read"`
 	ChainGetMessage               func(p0 context.Context, p1 cid.Cid) (*types.UnsignedMessage, error)                                                               `perm:"read"`
@@ -102,6 +105,9 @@ type IChainInfoStruct struct {
 	ChainList                     func(p0 context.Context, p1 types.TipSetKey, p2 int) ([]types.TipSetKey, error)                                                    `perm:"read"`
 	ChainNotify                   func(p0 context.Context) <-chan []*chain.HeadChange                                                                                `perm:"read"`
 	ChainSetHead                  func(p0 context.Context, p1 types.TipSetKey) error                                                                                 `perm:"read"`
+	ChainWatchActor               func(p0 context.Context, p1 address.Address, p2 int) (<-chan apitypes.ActorEvent, error)                                           `perm:"read"`
+	ChainWatchHeight              func(p0 context.Context, p1 abi.ChainEpoch, p2 int) (<-chan apitypes.HeightEvent, error)                                           `perm:"read"`
+	ChainWatchMessages            func(p0 context.Context, p1 apitypes.MessageMatch, p2 int) (<-chan apitypes.MessageEvent, error)                                   `perm:"read"`
 	GetActor                      func(p0 context.Context, p1 address.Address) (*types.Actor, error)                                                                 `perm:"read"`
 	GetEntry                      func(p0 context.Context, p1 abi.ChainEpoch, p2 uint64) (*types.BeaconEntry, error)                                                 `perm:"read"`
 	GetFullBlock                  func(p0 context.Context, p1 cid.Cid) (*types.FullBlock, error)                                                                     `perm:"read"`
@@ -125,8 +131,8 @@ type IDiscoveryStruct struct {
 }
 
 type IJwtAuthAPIStruct struct {
-	AuthNew func(p0 context.Context, p1 []auth.Permission) ([]byte, error)                                             `perm:"read"`
-	Verify  func(p0 context.Context, p1 string, p2 string, p3 string, p4 string, p5 string) ([]auth.Permission, error) `perm:"read"`
+	AuthNew func(p0 context.Context, p1 []auth.Permission) ([]byte, error)            `perm:"read"`
+	Verify  func(p0 context.Context, p1 string, p2 string) ([]auth.Permission, error) `perm:"read"`
 }
 
 type IMarketStruct struct {
@@ -138,7 +144,9 @@ type IMessagePoolStruct struct {
 	GasEstimateFeeCap          func(p0 context.Context, p1 *types.UnsignedMessage, p2 int64, p3 types.TipSetKey) (big.Int, error)                                 `perm:"read"`
 	GasEstimateGasLimit        func(p0 context.Context, p1 *types.UnsignedMessage, p2 types.TipSetKey) (int64, error)                                             `perm:"read"`
 	GasEstimateGasPremium      func(p0 context.Context, p1 uint64, p2 address.Address, p3 int64, p4 types.TipSetKey) (big.Int, error)                             `perm:"read"`
+	GasEstimateInclusion       func(p0 context.Context, p1 int64, p2 []uint64, p3 types.TipSetKey) ([]messagepool.InclusionEstimate, error)                       `perm:"read"`
 	GasEstimateMessageGas      func(p0 context.Context, p1 *types.UnsignedMessage, p2 *types.MessageSendSpec, p3 types.TipSetKey) (*types.UnsignedMessage, error) `perm:"read"`
+	GasFeeHistory              func(p0 context.Context, p1 int, p2 []float64, p3 types.TipSetKey) (*messagepool.FeeHistory, error)                                `perm:"read"`
 	MpoolBatchPush             func(p0 context.Context, p1 []*types.SignedMessage) ([]cid.Cid, error)                                                             `perm:"read"`
 	MpoolBatchPushMessage      func(p0 context.Context, p1 []*types.UnsignedMessage, p2 *types.MessageSendSpec) ([]*types.SignedMessage, error)                   `perm:"read"`
 	MpoolBatchPushUntrusted    func(p0 context.Context, p1 []*types.SignedMessage) ([]cid.Cid, error)                                                             `perm:"read"`
@@ -162,34 +170,39 @@ type IMessagePoolStruct struct {
 }
 
 type IMinerStateStruct struct {
-	StateCirculatingSupply             func(p0 context.Context, p1 types.TipSetKey) (abi.TokenAmount, error)                                                           `perm:"read"`
-	StateListActors                    func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error)                                                         `perm:"read"`
-	StateListMiners                    func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error)                                                         `perm:"read"`
-	StateLookupID                      func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (address.Address, error)                                       `perm:"read"`
-	StateMarketBalance                 func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (apitypes.MarketBalance, error)                                `perm:"read"`
-	StateMarketDeals                   func(p0 context.Context, p1 types.TipSetKey) (map[string]pstate.MarketDeal, error)                                              `perm:"read"`
-	StateMarketStorageDeal             func(p0 context.Context, p1 abi.DealID, p2 types.TipSetKey) (*apitypes.MarketDeal, error)                                       `perm:"read"`
-	StateMinerActiveSectors            func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) ([]*miner.SectorOnChainInfo, error)                            `perm:"read"`
-	StateMinerAvailableBalance         func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (big.Int, error)                                               `perm:"read"`
-	StateMinerDeadlines                func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) ([]apitypes.Deadline, error)                                   `perm:"read"`
-	StateMinerFaults                   func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error)                                     `perm:"read"`
-	StateMinerInfo                     func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (miner.MinerInfo, error)                                       `perm:"read"`
-	StateMinerInitialPledgeCollateral  func(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 types.TipSetKey) (big.Int, error)                 `perm:"read"`
-	StateMinerPartitions               func(p0 context.Context, p1 address.Address, p2 uint64, p3 types.TipSetKey) ([]apitypes.Partition, error)                       `perm:"read"`
-	StateMinerPower                    func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*apitypes.MinerPower, error)                                  `perm:"read"`
-	StateMinerPreCommitDepositForPower func(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 types.TipSetKey) (big.Int, error)                 `perm:"read"`
-	StateMinerProvingDeadline          func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*dline.Info, error)                                           `perm:"read"`
-	StateMinerRecoveries               func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error)                                     `perm:"read"`
-	StateMinerSectorAllocated          func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (bool, error)                             `perm:"read"`
-	StateMinerSectorCount              func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (apitypes.MinerSectors, error)                                 `perm:"read"`
-	StateMinerSectorSize               func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (abi.SectorSize, error)                                        `perm:"read"`
-	StateMinerSectors                  func(p0 context.Context, p1 address.Address, p2 *bitfield.BitField, p3 types.TipSetKey) ([]*miner.SectorOnChainInfo, error)     `perm:"read"`
-	StateMinerWorkerAddress            func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (address.Address, error)                                       `perm:"read"`
-	StateSectorExpiration              func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorExpiration, error)          `perm:"read"`
-	StateSectorGetInfo                 func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorOnChainInfo, error)         `perm:"read"`
-	StateSectorPartition               func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorLocation, error)            `perm:"read"`
-	StateSectorPreCommitInfo           func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (miner.SectorPreCommitOnChainInfo, error) `perm:"read"`
-	StateVMCirculatingSupplyInternal   func(p0 context.Context, p1 types.TipSetKey) (chain.CirculatingSupply, error)                                                   `perm:"read"`
+	StateCirculatingSupply             func(p0 context.Context, p1 types.TipSetKey) (abi.TokenAmount, error)                                                            `perm:"read"`
+	StateListActors                    func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error)                                                          `perm:"read"`
+	StateListMiners                    func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error)                                                          `perm:"read"`
+	StateLookupID                      func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (address.Address, error)                                        `perm:"read"`
+	StateMarketBalance                 func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (apitypes.MarketBalance, error)                                 `perm:"read"`
+	StateMarketDeals                   func(p0 context.Context, p1 types.TipSetKey) (map[string]pstate.MarketDeal, error)                                               `perm:"read"`
+	StateMarketDealsQuery              func(p0 context.Context, p1 types.TipSetKey, p2 pstate.MarketDealFilter, p3 abi.DealID, p4 int) (*pstate.MarketDealsPage, error) `perm:"read"`
+	StateMarketStorageDeal             func(p0 context.Context, p1 abi.DealID, p2 types.TipSetKey) (*apitypes.MarketDeal, error)                                        `perm:"read"`
+	StateMinerActiveSectors            func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) ([]*miner.SectorOnChainInfo, error)                             `perm:"read"`
+	StateMinerAvailableBalance         func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (big.Int, error)                                                `perm:"read"`
+	StateMinerDeadlines                func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) ([]apitypes.Deadline, error)                                    `perm:"read"`
+	StateMinerFaults                   func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error)                                      `perm:"read"`
+	StateMinerHealth                   func(p0 context.Context, p1 address.Address, p2 abi.ChainEpoch, p3 types.TipSetKey) (*apitypes.MinerHealth, error)               `perm:"read"`
+	StateMinerInfo                     func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (miner.MinerInfo, error)                                        `perm:"read"`
+	StateMinerInitialPledgeCollateral  func(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 types.TipSetKey) (big.Int, error)                  `perm:"read"`
+	StateMinerPartitions               func(p0 context.Context, p1 address.Address, p2 uint64, p3 types.TipSetKey) ([]apitypes.Partition, error)                        `perm:"read"`
+	StateMinerPower                    func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*apitypes.MinerPower, error)                                   `perm:"read"`
+	StateMinerPowerAll                 func(p0 context.Context, p1 types.TipSetKey) (*apitypes.MinerPowerAll, error)                                                    `perm:"read"`
+	StateMinerPreCommitDepositForPower func(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 types.TipSetKey) (big.Int, error)                  `perm:"read"`
+	StateMinerProvingDeadline          func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*dline.Info, error)                                            `perm:"read"`
+	StateMinerRecoveries               func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error)                                      `perm:"read"`
+	StateMinerSectorAllocated          func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (bool, error)                              `perm:"read"`
+	StateMinerSectorCount              func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (apitypes.MinerSectors, error)                                  `perm:"read"`
+	StateMinerSectorSize               func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (abi.SectorSize, error)                                         `perm:"read"`
+	StateMinerSectors                  func(p0 context.Context, p1 address.Address, p2 *bitfield.BitField, p3 types.TipSetKey) ([]*miner.SectorOnChainInfo, error)      `perm:"read"`
+	StateMinerWorkerAddress            func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (address.Address, error)                                        `perm:"read"`
+	StateMinersSummary                 func(p0 context.Context, p1 types.TipSetKey) ([]*apitypes.MinerSummary, error)                                                   `perm:"read"`
+	StateSectorExpiration              func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorExpiration, error)           `perm:"read"`
+	StateSectorGetInfo                 func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorOnChainInfo, error)          `perm:"read"`
+	StateSectorPartition               func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorLocation, error)             `perm:"read"`
+	StateSectorPreCommitInfo           func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (miner.SectorPreCommitOnChainInfo, error)  `perm:"read"`
+	StateTokenomics                    func(p0 context.Context, p1 types.TipSetKey) (*apitypes.Tokenomics, error)                                                       `perm:"read"`
+	StateVMCirculatingSupplyInternal   func(p0 context.Context, p1 types.TipSetKey) (chain.CirculatingSupply, error)                                                    `perm:"read"`
 }
 
 type IMiningStruct struct {
@@ -245,10 +258,16 @@ type IPaychanStruct struct {
 	PaychVoucherSubmit          func(p0 context.Context, p1 address.Address, p2 *paych.SignedVoucher, p3 []byte, p4 []byte) (cid.Cid, error)               `perm:"read"`
 }
 
+type IRPCStruct struct {
+	Discover func(p0 context.Context) (apitypes.OpenRPCDocument, error) `perm:"read"`
+}
+
 type ISyncerStruct struct {
+	ChainCheck               func(p0 context.Context, p1 types.TipSetKey, p2 chain.CheckOptions) (*chain.CheckReport, error)        `perm:"admin"`
 	ChainSyncHandleNewTipSet func(p0 context.Context, p1 *types.ChainInfo) error                                                    `perm:"read"`
 	ChainTipSetWeight        func(p0 context.Context, p1 types.TipSetKey) (big.Int, error)                                          `perm:"read"`
 	Concurrent               func(p0 context.Context) int64                                                                         `perm:"read"`
+	JournalQuery             func(p0 context.Context, p1 string, p2 string, p3 time.Time) ([]*journal.Event, error)                 `perm:"read"`
 	SetConcurrent            func(p0 context.Context, p1 int64) error                                                               `perm:"read"`
 	StateCall                func(p0 context.Context, p1 *types.UnsignedMessage, p2 types.TipSetKey) (*apitypes.InvocResult, error) `perm:"read"`
 	SyncState                func(p0 context.Context) (*apitypes.SyncState, error)                                                  `perm:"read"`
@@ -257,19 +276,26 @@ type ISyncerStruct struct {
 }
 
 type IWalletStruct struct {
-	HasPassword          func(p0 context.Context) bool                                                                         `perm:"admin"`
-	LockWallet           func(p0 context.Context) error                                                                        `perm:"admin"`
-	SetPassword          func(p0 context.Context, p1 []byte) error                                                             `perm:"admin"`
-	UnLockWallet         func(p0 context.Context, p1 []byte) error                                                             `perm:"admin"`
-	WalletAddresses      func(p0 context.Context) []address.Address                                                            `perm:"admin"`
-	WalletBalance        func(p0 context.Context, p1 address.Address) (abi.TokenAmount, error)                                 `perm:"read"`
-	WalletDefaultAddress func(p0 context.Context) (address.Address, error)                                                     `perm:"write"`
-	WalletExport         func(p0 address.Address, p1 string) (*crypto.KeyInfo, error)                                          `perm:"admin"`
-	WalletHas            func(p0 context.Context, p1 address.Address) (bool, error)                                            `perm:"write"`
-	WalletImport         func(p0 *crypto.KeyInfo) (address.Address, error)                                                     `perm:"admin"`
-	WalletNewAddress     func(p0 address.Protocol) (address.Address, error)                                                    `perm:"write"`
-	WalletSetDefault     func(p0 context.Context, p1 address.Address) error                                                    `perm:"admin"`
-	WalletSign           func(p0 context.Context, p1 address.Address, p2 []byte, p3 wallet.MsgMeta) (*crypto.Signature, error) `perm:"sign"`
-	WalletSignMessage    func(p0 context.Context, p1 address.Address, p2 *types.UnsignedMessage) (*types.SignedMessage, error) `perm:"sign"`
-	WalletState          func(p0 context.Context) int                                                                          `perm:"admin"`
+	HasPassword           func(p0 context.Context) bool                                                                         `perm:"admin"`
+	LockWallet            func(p0 context.Context) error                                                                        `perm:"admin"`
+	SetPassword           func(p0 context.Context, p1 []byte) error                                                             `perm:"admin"`
+	UnLockWallet          func(p0 context.Context, p1 []byte) error                                                             `perm:"admin"`
+	WalletAddresses       func(p0 context.Context) []address.Address                                                            `perm:"admin"`
+	WalletBalance         func(p0 context.Context, p1 address.Address) (abi.TokenAmount, error)                                 `perm:"read"`
+	WalletChangePassword  func(p0 context.Context, p1 []byte, p2 []byte) error                                                  `perm:"admin"`
+	WalletDefaultAddress  func(p0 context.Context) (address.Address, error)                                                     `perm:"write"`
+	WalletDelete          func(p0 context.Context, p1 address.Address) error                                                    `perm:"admin"`
+	WalletDeriveAddress   func(p0 context.Context, p1 address.Protocol, p2 uint32) (*wallet.HDAddress, error)                   `perm:"admin"`
+	WalletExport          func(p0 address.Address, p1 string) (*crypto.KeyInfo, error)                                          `perm:"admin"`
+	WalletHas             func(p0 context.Context, p1 address.Address) (bool, error)                                            `perm:"write"`
+	WalletImport          func(p0 *crypto.KeyInfo) (address.Address, error)                                                     `perm:"admin"`
+	WalletNewAddress      func(p0 address.Protocol) (address.Address, error)                                                    `perm:"write"`
+	WalletNewHDAddress    func(p0 context.Context, p1 address.Protocol) (*wallet.HDAddress, error)                              `perm:"admin"`
+	WalletReencrypt       func(p0 context.Context, p1 []byte, p2 int, p3 int) error                                             `perm:"admin"`
+	WalletRestoreMnemonic func(p0 context.Context, p1 string) error                                                             `perm:"admin"`
+	WalletSetDefault      func(p0 context.Context, p1 address.Address) error                                                    `perm:"admin"`
+	WalletSign            func(p0 context.Context, p1 address.Address, p2 []byte, p3 wallet.MsgMeta) (*crypto.Signature, error) `perm:"sign"`
+	WalletSignMessage     func(p0 context.Context, p1 address.Address, p2 *types.UnsignedMessage) (*types.SignedMessage, error) `perm:"sign"`
+	WalletState           func(p0 context.Context) int                                                                          `perm:"admin"`
+	WalletUndelete        func(p0 context.Context, p1 address.Address) error                                                    `perm:"admin"`
 }
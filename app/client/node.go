@@ -7,8 +7,6 @@ import (
 	"path"
 
 	"github.com/filecoin-project/go-jsonrpc"
-	"github.com/multiformats/go-multiaddr"
-	manet "github.com/multiformats/go-multiaddr/net"
 
 	"github.com/filecoin-project/venus/app/paths"
 )
@@ -33,18 +31,12 @@ func getVenusClientInfo(version string) (string, http.Header, error) {
 
 	headers := http.Header{}
 	headers.Add("Authorization", "Bearer "+string(tokenBytes))
-	apima, err := multiaddr.NewMultiaddr(string(rpcBytes))
+	ep, err := paths.ParseAPIEndpoint(string(rpcBytes))
 	if err != nil {
 		return "", nil, err
 	}
 
-	_, addr, err := manet.DialArgs(apima)
-	if err != nil {
-		return "", nil, err
-	}
-
-	addr = "ws://" + addr + "/rpc/" + version
-	return addr, headers, nil
+	return ep.URL("ws", "/rpc/"+version), headers, nil
 }
 
 //NewFullNode It is used to construct a full node access client.
@@ -56,11 +48,27 @@ func GetFullNodeAPI(ctx context.Context, version string) (FullNodeStruct, jsonrp
 		return FullNodeStruct{}, nil, err
 	}
 
+	return NewFullNodeRPC(ctx, addr, headers)
+}
+
+// NewFullNodeRPC creates a client of the full node api served at `addr`, a websocket url
+// like ws://127.0.0.1:3453/rpc/v1 or wss://node:3453/rpc/v1, or the url of a unix socket
+// endpoint, see paths.APIEndpoint.
+func NewFullNodeRPC(ctx context.Context, addr string, headers http.Header) (FullNodeStruct, jsonrpc.ClientCloser, error) {
+	addr, headers, closeRelay, err := relayAddr(addr, headers)
+	if err != nil {
+		return FullNodeStruct{}, nil, err
+	}
+
 	node := FullNodeStruct{}
-	closer, err := jsonrpc.NewClient(ctx, addr, "Filecoin", &node, headers)
+	closer, err := jsonrpc.NewMergeClient(ctx, addr, "Filecoin", []interface{}{&node}, headers)
 	if err != nil {
+		closeRelay()
 		return FullNodeStruct{}, nil, err
 	}
 
-	return node, closer, nil
+	return node, func() {
+		closer()
+		closeRelay()
+	}, nil
 }
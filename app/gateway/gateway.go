@@ -0,0 +1,629 @@
+// Package gateway serves a read-only subset of the full node api, forwarded to upstream
+// venus nodes, so that the chain can be exposed publicly without exposing a full node.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/network"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/client"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var log = logging.Logger("gateway")
+
+// Config configures a gateway.
+type Config struct {
+	// Upstreams are the websocket urls of the v1 api of the upstream nodes, like
+	// ws://127.0.0.1:3453/rpc/v1.
+	Upstreams []string
+	// Token authenticates the gateway to the upstream nodes, it needs the write permission
+	// to push messages.
+	Token string
+
+	// LookbackCap is how old the tipsets, blocks and messages of the requests may be, a
+	// message is as old as the tipset executing it.
+	LookbackCap time.Duration
+	// SearchLookbackLimit caps the number of epochs StateSearchMsg and StateWaitMsg look back.
+	SearchLookbackLimit abi.ChainEpoch
+	// MaxWaitConfidence caps the confidence StateWaitMsg waits for, which holds the wait open
+	// on the upstream node.
+	MaxWaitConfidence uint64
+
+	// RateLimit is the number of calls per second allowed for each client, known by its ip,
+	// and method, zero disables the limit. MethodRateLimits overrides it for some methods.
+	RateLimit        float64
+	MethodRateLimits map[string]float64
+
+	// CacheSize is the number of immutable responses cached.
+	CacheSize int
+}
+
+// DefaultConfig returns the default configuration of a gateway, without upstream.
+func DefaultConfig() Config {
+	return Config{
+		LookbackCap:         24 * time.Hour,
+		SearchLookbackLimit: 2880,
+		MaxWaitConfidence:   20,
+		RateLimit:           100,
+		MethodRateLimits: map[string]float64{
+			"MpoolPush":    10,
+			"StateWaitMsg": 10,
+		},
+		CacheSize: 10000,
+	}
+}
+
+var (
+	errLookbackTooLong = xerrors.New("tipsets older than the lookback cap of the gateway are not served")
+	errNoUpstream      = xerrors.New("no healthy upstream node")
+	errMessageNotFound = xerrors.New("messages not executed within the search lookback limit of the gateway are not served")
+)
+
+// Gateway implements the methods of the full node api it serves. All its exported methods
+// are served, see NewHandler.
+type Gateway struct {
+	cfg       Config
+	upstreams *upstreams
+	limiter   *methodLimiter
+	cache     *lru.ARCCache
+
+	now func() time.Time
+}
+
+// New dials the upstream nodes of `cfg` and starts checking their health. The closer
+// closes the connections to the upstream nodes.
+func New(ctx context.Context, cfg Config) (*Gateway, jsonrpc.ClientCloser, error) {
+	if len(cfg.Upstreams) == 0 {
+		return nil, nil, xerrors.New("no upstream node configured")
+	}
+
+	headers := http.Header{}
+	if cfg.Token != "" {
+		headers.Add("Authorization", "Bearer "+cfg.Token)
+	}
+
+	var nodes []*upstream
+	closeAll := func() {
+		for _, n := range nodes {
+			n.closer()
+		}
+	}
+	for _, addr := range cfg.Upstreams {
+		api, closer, err := client.NewFullNodeRPC(ctx, addr, headers)
+		if err != nil {
+			closeAll()
+			return nil, nil, xerrors.Errorf("dialing upstream %s: %w", addr, err)
+		}
+		nodes = append(nodes, newUpstream(addr, &api, closer))
+	}
+
+	gw, err := newGateway(cfg, nodes)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go gw.upstreams.checkLoop(ctx)
+
+	return gw, func() {
+		cancel()
+		closeAll()
+	}, nil
+}
+
+func newGateway(cfg Config, nodes []*upstream) (*Gateway, error) {
+	cache, err := lru.NewARC(cfg.CacheSize)
+	if err != nil {
+		return nil, xerrors.Errorf("creating cache: %w", err)
+	}
+	return &Gateway{
+		cfg:       cfg,
+		upstreams: &upstreams{nodes: nodes},
+		limiter:   newMethodLimiter(cfg.RateLimit, cfg.MethodRateLimits),
+		cache:     cache,
+		now:       time.Now,
+	}, nil
+}
+
+// NewHandler serves the v0 and v1 api of the gateway at /rpc/v0 and /rpc/v1.
+func NewHandler(gw *Gateway) http.Handler {
+	v0 := jsonrpc.NewServer()
+	v0.Register("Filecoin", &V0{Gateway: gw})
+	v1 := jsonrpc.NewServer()
+	v1.Register("Filecoin", gw)
+
+	mux := http.NewServeMux()
+	mux.Handle("/rpc/v0", v0)
+	mux.Handle("/rpc/v1", v1)
+	return withClient(mux)
+}
+
+// upstream returns the upstream node serving a call of `method`, once the call passed the
+// rate limit of its client.
+func (gw *Gateway) upstream(ctx context.Context, method string) (*client.FullNodeStruct, error) {
+	if !gw.limiter.allow(clientFromCtx(ctx), method) {
+		return nil, xerrors.Errorf("rate limit of %s exceeded", method)
+	}
+	n := gw.upstreams.pick()
+	if n == nil {
+		return nil, errNoUpstream
+	}
+	return n.api, nil
+}
+
+// cached returns the response cached under `key`, or loads and caches it. Only immutable
+// responses may be cached.
+func (gw *Gateway) cached(key string, load func() (interface{}, error)) (interface{}, error) {
+	if v, ok := gw.cache.Get(key); ok {
+		return v, nil
+	}
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+	gw.cache.Add(key, v)
+	return v, nil
+}
+
+func (gw *Gateway) checkTimestamp(ts uint64) error {
+	if time.Unix(int64(ts), 0).Before(gw.now().Add(-gw.cfg.LookbackCap)) {
+		return errLookbackTooLong
+	}
+	return nil
+}
+
+func (gw *Gateway) checkTipSet(ts *types.TipSet) error {
+	return gw.checkTimestamp(ts.MinTimestamp())
+}
+
+// checkTipSetKey checks the lookback of the tipset `tsk`, the head when it is empty.
+func (gw *Gateway) checkTipSetKey(ctx context.Context, api *client.FullNodeStruct, tsk types.TipSetKey) error {
+	if tsk.IsEmpty() {
+		return nil
+	}
+	ts, err := gw.getTipSet(ctx, api, tsk)
+	if err != nil {
+		return err
+	}
+	return gw.checkTipSet(ts)
+}
+
+func (gw *Gateway) getTipSet(ctx context.Context, api *client.FullNodeStruct, tsk types.TipSetKey) (*types.TipSet, error) {
+	v, err := gw.cached("tipset:"+tsk.String(), func() (interface{}, error) {
+		return api.ChainGetTipSet(ctx, tsk)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.TipSet), nil
+}
+
+func (gw *Gateway) getBlock(ctx context.Context, api *client.FullNodeStruct, c cid.Cid) (*types.BlockHeader, error) {
+	v, err := gw.cached(cidKey("block", c), func() (interface{}, error) {
+		return api.ChainGetBlock(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.BlockHeader), nil
+}
+
+// checkBlock checks the lookback of the block `c`.
+func (gw *Gateway) checkBlock(ctx context.Context, api *client.FullNodeStruct, c cid.Cid) error {
+	blk, err := gw.getBlock(ctx, api, c)
+	if err != nil {
+		return err
+	}
+	return gw.checkTimestamp(blk.Timestamp)
+}
+
+// checkMessage checks the lookback of the tipset executing the message `c`, which must be
+// found within the search lookback limit. The execution is only cached once it is final, a
+// reorg may move the message to another tipset or out of the chain before.
+func (gw *Gateway) checkMessage(ctx context.Context, api *client.FullNodeStruct, c cid.Cid) error {
+	key := cidKey("executed", c)
+	if v, ok := gw.cache.Get(key); ok {
+		return gw.checkTimestamp(v.(uint64))
+	}
+	lookup, err := api.StateSearchMsg(ctx, types.EmptyTSK, c, gw.cfg.SearchLookbackLimit, true)
+	if err != nil {
+		return err
+	}
+	if lookup == nil {
+		return errMessageNotFound
+	}
+	ts, err := gw.getTipSet(ctx, api, lookup.TipSet)
+	if err != nil {
+		return err
+	}
+	head, err := api.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+	if head.Height()-ts.Height() >= constants.Finality {
+		gw.cache.Add(key, ts.MinTimestamp())
+	}
+	return gw.checkTimestamp(ts.MinTimestamp())
+}
+
+// checkObject checks the lookback of the blocks and messages read as raw objects. The other
+// objects, like those of the states, carry no time and are served.
+func (gw *Gateway) checkObject(ctx context.Context, api *client.FullNodeStruct, c cid.Cid, raw []byte) error {
+	var blk types.BlockHeader
+	if err := blk.UnmarshalCBOR(bytes.NewReader(raw)); err == nil {
+		return gw.checkTimestamp(blk.Timestamp)
+	}
+	var smsg types.SignedMessage
+	if err := smsg.UnmarshalCBOR(bytes.NewReader(raw)); err == nil {
+		return gw.checkMessage(ctx, api, c)
+	}
+	var msg types.UnsignedMessage
+	if err := msg.UnmarshalCBOR(bytes.NewReader(raw)); err == nil {
+		return gw.checkMessage(ctx, api, c)
+	}
+	return nil
+}
+
+func (gw *Gateway) searchLimit(limit abi.ChainEpoch) abi.ChainEpoch {
+	if limit == constants.LookbackNoLimit || limit > gw.cfg.SearchLookbackLimit {
+		return gw.cfg.SearchLookbackLimit
+	}
+	return limit
+}
+
+func cidKey(prefix string, c cid.Cid) string {
+	return fmt.Sprintf("%s:%s", prefix, c)
+}
+
+// ChainHead returns the head of the upstream node.
+func (gw *Gateway) ChainHead(ctx context.Context) (*types.TipSet, error) {
+	api, err := gw.upstream(ctx, "ChainHead")
+	if err != nil {
+		return nil, err
+	}
+	return api.ChainHead(ctx)
+}
+
+// ChainNotify forwards the head changes of the upstream node, the channel is closed right
+// away when no upstream node can serve it.
+func (gw *Gateway) ChainNotify(ctx context.Context) <-chan []*chain.HeadChange {
+	api, err := gw.upstream(ctx, "ChainNotify")
+	if err != nil {
+		log.Warnf("serving ChainNotify: %v", err)
+		out := make(chan []*chain.HeadChange)
+		close(out)
+		return out
+	}
+	return api.ChainNotify(ctx)
+}
+
+func (gw *Gateway) ChainGetTipSet(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	api, err := gw.upstream(ctx, "ChainGetTipSet")
+	if err != nil {
+		return nil, err
+	}
+	if tsk.IsEmpty() {
+		return api.ChainHead(ctx)
+	}
+	ts, err := gw.getTipSet(ctx, api, tsk)
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSet(ts); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (gw *Gateway) ChainGetTipSetByHeight(ctx context.Context, h abi.ChainEpoch, tsk types.TipSetKey) (*types.TipSet, error) {
+	api, err := gw.upstream(ctx, "ChainGetTipSetByHeight")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return nil, err
+	}
+	ts, err := api.ChainGetTipSetByHeight(ctx, h, tsk)
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSet(ts); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (gw *Gateway) ChainGetBlock(ctx context.Context, c cid.Cid) (*types.BlockHeader, error) {
+	api, err := gw.upstream(ctx, "ChainGetBlock")
+	if err != nil {
+		return nil, err
+	}
+	blk, err := gw.getBlock(ctx, api, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTimestamp(blk.Timestamp); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+func (gw *Gateway) ChainGetMessage(ctx context.Context, c cid.Cid) (*types.UnsignedMessage, error) {
+	api, err := gw.upstream(ctx, "ChainGetMessage")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkMessage(ctx, api, c); err != nil {
+		return nil, err
+	}
+	v, err := gw.cached(cidKey("message", c), func() (interface{}, error) {
+		return api.ChainGetMessage(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.UnsignedMessage), nil
+}
+
+func (gw *Gateway) ChainGetBlockMessages(ctx context.Context, c cid.Cid) (*apitypes.BlockMessages, error) {
+	api, err := gw.upstream(ctx, "ChainGetBlockMessages")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkBlock(ctx, api, c); err != nil {
+		return nil, err
+	}
+	v, err := gw.cached(cidKey("blockmessages", c), func() (interface{}, error) {
+		return api.ChainGetBlockMessages(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*apitypes.BlockMessages), nil
+}
+
+func (gw *Gateway) ChainGetParentMessages(ctx context.Context, c cid.Cid) ([]apitypes.Message, error) {
+	api, err := gw.upstream(ctx, "ChainGetParentMessages")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkBlock(ctx, api, c); err != nil {
+		return nil, err
+	}
+	v, err := gw.cached(cidKey("parentmessages", c), func() (interface{}, error) {
+		return api.ChainGetParentMessages(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]apitypes.Message), nil
+}
+
+func (gw *Gateway) ChainGetParentReceipts(ctx context.Context, c cid.Cid) ([]*types.MessageReceipt, error) {
+	api, err := gw.upstream(ctx, "ChainGetParentReceipts")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkBlock(ctx, api, c); err != nil {
+		return nil, err
+	}
+	v, err := gw.cached(cidKey("parentreceipts", c), func() (interface{}, error) {
+		return api.ChainGetParentReceipts(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*types.MessageReceipt), nil
+}
+
+func (gw *Gateway) ChainReadObj(ctx context.Context, c cid.Cid) ([]byte, error) {
+	api, err := gw.upstream(ctx, "ChainReadObj")
+	if err != nil {
+		return nil, err
+	}
+	v, err := gw.cached(cidKey("object", c), func() (interface{}, error) {
+		return api.ChainReadObj(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	raw := v.([]byte)
+	if err := gw.checkObject(ctx, api, c, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (gw *Gateway) ChainHasObj(ctx context.Context, c cid.Cid) (bool, error) {
+	api, err := gw.upstream(ctx, "ChainHasObj")
+	if err != nil {
+		return false, err
+	}
+	if gw.cache.Contains(cidKey("object", c)) {
+		return true, nil
+	}
+	return api.ChainHasObj(ctx, c)
+}
+
+func (gw *Gateway) StateGetActor(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*types.Actor, error) {
+	api, err := gw.upstream(ctx, "StateGetActor")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return nil, err
+	}
+	return api.StateGetActor(ctx, addr, tsk)
+}
+
+func (gw *Gateway) StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error) {
+	api, err := gw.upstream(ctx, "StateLookupID")
+	if err != nil {
+		return address.Undef, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return address.Undef, err
+	}
+	return api.StateLookupID(ctx, addr, tsk)
+}
+
+func (gw *Gateway) StateAccountKey(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error) {
+	api, err := gw.upstream(ctx, "StateAccountKey")
+	if err != nil {
+		return address.Undef, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return address.Undef, err
+	}
+	return api.StateAccountKey(ctx, addr, tsk)
+}
+
+func (gw *Gateway) StateNetworkVersion(ctx context.Context, tsk types.TipSetKey) (network.Version, error) {
+	api, err := gw.upstream(ctx, "StateNetworkVersion")
+	if err != nil {
+		return network.VersionMax, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return network.VersionMax, err
+	}
+	return api.StateNetworkVersion(ctx, tsk)
+}
+
+func (gw *Gateway) StateNetworkName(ctx context.Context) (apitypes.NetworkName, error) {
+	api, err := gw.upstream(ctx, "StateNetworkName")
+	if err != nil {
+		return "", err
+	}
+	v, err := gw.cached("networkname", func() (interface{}, error) {
+		return api.StateNetworkName(ctx)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(apitypes.NetworkName), nil
+}
+
+// StateSearchMsg searches the message `msg` back from `from`, up to the search lookback
+// limit of the gateway.
+func (gw *Gateway) StateSearchMsg(ctx context.Context, from types.TipSetKey, msg cid.Cid, limit abi.ChainEpoch, allowReplaced bool) (*apitypes.MsgLookup, error) {
+	api, err := gw.upstream(ctx, "StateSearchMsg")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, from); err != nil {
+		return nil, err
+	}
+	return api.StateSearchMsg(ctx, from, msg, gw.searchLimit(limit), allowReplaced)
+}
+
+// StateWaitMsg waits for the message `msg`, looking back up to the search lookback limit
+// of the gateway, with a confidence capped at the maximum of the gateway.
+func (gw *Gateway) StateWaitMsg(ctx context.Context, msg cid.Cid, confidence uint64, limit abi.ChainEpoch, allowReplaced bool) (*apitypes.MsgLookup, error) {
+	api, err := gw.upstream(ctx, "StateWaitMsg")
+	if err != nil {
+		return nil, err
+	}
+	if confidence > gw.cfg.MaxWaitConfidence {
+		confidence = gw.cfg.MaxWaitConfidence
+	}
+	return api.StateWaitMsg(ctx, msg, confidence, gw.searchLimit(limit), allowReplaced)
+}
+
+func (gw *Gateway) GasEstimateMessageGas(ctx context.Context, msg *types.UnsignedMessage, spec *types.MessageSendSpec, tsk types.TipSetKey) (*types.UnsignedMessage, error) {
+	api, err := gw.upstream(ctx, "GasEstimateMessageGas")
+	if err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return nil, err
+	}
+	return api.GasEstimateMessageGas(ctx, msg, spec, tsk)
+}
+
+func (gw *Gateway) GasEstimateFeeCap(ctx context.Context, msg *types.UnsignedMessage, maxqueueblks int64, tsk types.TipSetKey) (big.Int, error) {
+	api, err := gw.upstream(ctx, "GasEstimateFeeCap")
+	if err != nil {
+		return big.Int{}, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return big.Int{}, err
+	}
+	return api.GasEstimateFeeCap(ctx, msg, maxqueueblks, tsk)
+}
+
+func (gw *Gateway) GasEstimateGasPremium(ctx context.Context, nblocksincl uint64, sender address.Address, gaslimit int64, tsk types.TipSetKey) (big.Int, error) {
+	api, err := gw.upstream(ctx, "GasEstimateGasPremium")
+	if err != nil {
+		return big.Int{}, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return big.Int{}, err
+	}
+	return api.GasEstimateGasPremium(ctx, nblocksincl, sender, gaslimit, tsk)
+}
+
+func (gw *Gateway) GasEstimateGasLimit(ctx context.Context, msg *types.UnsignedMessage, tsk types.TipSetKey) (int64, error) {
+	api, err := gw.upstream(ctx, "GasEstimateGasLimit")
+	if err != nil {
+		return -1, err
+	}
+	if err := gw.checkTipSetKey(ctx, api, tsk); err != nil {
+		return -1, err
+	}
+	return api.GasEstimateGasLimit(ctx, msg, tsk)
+}
+
+func (gw *Gateway) MpoolGetNonce(ctx context.Context, addr address.Address) (uint64, error) {
+	api, err := gw.upstream(ctx, "MpoolGetNonce")
+	if err != nil {
+		return 0, err
+	}
+	return api.MpoolGetNonce(ctx, addr)
+}
+
+func (gw *Gateway) MpoolPush(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error) {
+	api, err := gw.upstream(ctx, "MpoolPush")
+	if err != nil {
+		return cid.Undef, err
+	}
+	return api.MpoolPush(ctx, smsg)
+}
+
+func (gw *Gateway) Version(ctx context.Context) (apitypes.Version, error) {
+	api, err := gw.upstream(ctx, "Version")
+	if err != nil {
+		return apitypes.Version{}, err
+	}
+	return api.Version(ctx)
+}
+
+// V0 serves the v0 api of the gateway, which searches messages without lookback limit.
+type V0 struct {
+	*Gateway
+}
+
+func (v *V0) StateSearchMsg(ctx context.Context, msg cid.Cid) (*apitypes.MsgLookup, error) {
+	return v.Gateway.StateSearchMsg(ctx, types.EmptyTSK, msg, constants.LookbackNoLimit, true)
+}
+
+func (v *V0) StateWaitMsg(ctx context.Context, msg cid.Cid, confidence uint64) (*apitypes.MsgLookup, error) {
+	return v.Gateway.StateWaitMsg(ctx, msg, confidence, constants.LookbackNoLimit, true)
+}
@@ -0,0 +1,293 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/client"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/constants"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func newTestGateway(t *testing.T, apis ...*client.FullNodeStruct) *Gateway {
+	var nodes []*upstream
+	for _, api := range apis {
+		nodes = append(nodes, newUpstream("test", api, func() {}))
+	}
+	cfg := DefaultConfig()
+	cfg.RateLimit = 0
+	cfg.MethodRateLimits = nil
+	gw, err := newGateway(cfg, nodes)
+	require.NoError(t, err)
+	return gw
+}
+
+func TestGatewayCachesImmutableResponses(t *testing.T) {
+	tf.UnitTest(t)
+
+	var calls, searches int
+	executed := newTestTipSet(t, time.Now())
+	api := &client.FullNodeStruct{}
+	api.ChainGetMessage = func(ctx context.Context, c cid.Cid) (*types.UnsignedMessage, error) {
+		calls++
+		return &types.UnsignedMessage{Nonce: 42}, nil
+	}
+	api.StateSearchMsg = func(ctx context.Context, from types.TipSetKey, msg cid.Cid, limit abi.ChainEpoch, allowReplaced bool) (*apitypes.MsgLookup, error) {
+		searches++
+		return &apitypes.MsgLookup{Message: msg, TipSet: executed.Key()}, nil
+	}
+	api.ChainGetTipSet = func(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+		return executed, nil
+	}
+	head := newTestTipSetAt(t, time.Now(), constants.Finality-1)
+	api.ChainHead = func(ctx context.Context) (*types.TipSet, error) {
+		return head, nil
+	}
+	gw := newTestGateway(t, api)
+
+	// the execution of the message is searched again until it is final
+	c := types.CidFromString(t, "message")
+	for i := 0; i < 3; i++ {
+		msg, err := gw.ChainGetMessage(context.Background(), c)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), msg.Nonce)
+	}
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 3, searches)
+
+	head = newTestTipSetAt(t, time.Now(), constants.Finality)
+	for i := 0; i < 3; i++ {
+		_, err := gw.ChainGetMessage(context.Background(), c)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 4, searches)
+}
+
+func TestGatewayCapsWaitConfidence(t *testing.T) {
+	tf.UnitTest(t)
+
+	var waited []uint64
+	api := &client.FullNodeStruct{}
+	api.StateWaitMsg = func(ctx context.Context, msg cid.Cid, confidence uint64, limit abi.ChainEpoch, allowReplaced bool) (*apitypes.MsgLookup, error) {
+		waited = append(waited, confidence)
+		return &apitypes.MsgLookup{Message: msg}, nil
+	}
+	gw := newTestGateway(t, api)
+
+	c := types.CidFromString(t, "message")
+	for _, confidence := range []uint64{5, gw.cfg.MaxWaitConfidence + 1, 1 << 40} {
+		_, err := gw.StateWaitMsg(context.Background(), c, confidence, constants.LookbackNoLimit, true)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []uint64{5, gw.cfg.MaxWaitConfidence, gw.cfg.MaxWaitConfidence}, waited)
+}
+
+func TestGatewayLookbackCap(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(1_000_000_000, 0)
+	blocks := map[cid.Cid]*types.BlockHeader{}
+	api := &client.FullNodeStruct{}
+	api.ChainGetBlock = func(ctx context.Context, c cid.Cid) (*types.BlockHeader, error) {
+		return blocks[c], nil
+	}
+	gw := newTestGateway(t, api)
+	gw.now = func() time.Time { return now }
+
+	recent := types.CidFromString(t, "recent")
+	blocks[recent] = &types.BlockHeader{Timestamp: uint64(now.Add(-time.Hour).Unix())}
+	old := types.CidFromString(t, "old")
+	blocks[old] = &types.BlockHeader{Timestamp: uint64(now.Add(-gw.cfg.LookbackCap - time.Second).Unix())}
+
+	_, err := gw.ChainGetBlock(context.Background(), recent)
+	assert.NoError(t, err)
+	_, err = gw.ChainGetBlock(context.Background(), old)
+	assert.Equal(t, errLookbackTooLong, err)
+}
+
+func TestGatewayLookbackCapOfMessagesAndObjects(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(1_000_000_000, 0)
+	recentTS := newTestTipSet(t, now.Add(-time.Hour))
+	oldTS := newTestTipSet(t, now.Add(-DefaultConfig().LookbackCap-time.Second))
+	recentMsg := &types.UnsignedMessage{To: types.RequireIDAddress(t, 100), From: types.RequireIDAddress(t, 101), Nonce: 1, Value: big.Zero(), GasFeeCap: big.Zero(), GasPremium: big.Zero()}
+	oldMsg := &types.UnsignedMessage{To: types.RequireIDAddress(t, 100), From: types.RequireIDAddress(t, 101), Nonce: 2, Value: big.Zero(), GasFeeCap: big.Zero(), GasPremium: big.Zero()}
+	lostMsg := &types.UnsignedMessage{To: types.RequireIDAddress(t, 100), From: types.RequireIDAddress(t, 101), Nonce: 3, Value: big.Zero(), GasFeeCap: big.Zero(), GasPremium: big.Zero()}
+
+	// the messages are executed in the tipsets, the lost one is not found
+	executed := map[cid.Cid]*types.TipSet{recentMsg.Cid(): recentTS, oldMsg.Cid(): oldTS}
+	objects := map[cid.Cid][]byte{}
+	for _, o := range []interface {
+		Cid() cid.Cid
+		ToStorageBlock() (blocks.Block, error)
+	}{recentTS.At(0), oldTS.At(0), recentMsg, oldMsg, lostMsg} {
+		b, err := o.ToStorageBlock()
+		require.NoError(t, err)
+		objects[o.Cid()] = b.RawData()
+	}
+	state := types.CidFromString(t, "state")
+	objects[state] = []byte{0x80}
+
+	api := &client.FullNodeStruct{}
+	api.StateSearchMsg = func(ctx context.Context, from types.TipSetKey, msg cid.Cid, limit abi.ChainEpoch, allowReplaced bool) (*apitypes.MsgLookup, error) {
+		ts, ok := executed[msg]
+		if !ok {
+			return nil, nil
+		}
+		return &apitypes.MsgLookup{Message: msg, TipSet: ts.Key()}, nil
+	}
+	api.ChainGetTipSet = func(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+		for _, ts := range []*types.TipSet{recentTS, oldTS} {
+			if ts.Key().Equals(tsk) {
+				return ts, nil
+			}
+		}
+		return nil, xerrors.New("not found")
+	}
+	api.ChainGetBlock = func(ctx context.Context, c cid.Cid) (*types.BlockHeader, error) {
+		for _, ts := range []*types.TipSet{recentTS, oldTS} {
+			if ts.At(0).Cid() == c {
+				return ts.At(0), nil
+			}
+		}
+		return nil, xerrors.New("not found")
+	}
+	api.ChainGetMessage = func(ctx context.Context, c cid.Cid) (*types.UnsignedMessage, error) {
+		for _, msg := range []*types.UnsignedMessage{recentMsg, oldMsg, lostMsg} {
+			if msg.Cid() == c {
+				return msg, nil
+			}
+		}
+		return nil, xerrors.New("not found")
+	}
+	api.ChainGetBlockMessages = func(ctx context.Context, c cid.Cid) (*apitypes.BlockMessages, error) {
+		return &apitypes.BlockMessages{}, nil
+	}
+	api.ChainReadObj = func(ctx context.Context, c cid.Cid) ([]byte, error) {
+		return objects[c], nil
+	}
+	api.ChainHead = func(ctx context.Context) (*types.TipSet, error) {
+		return recentTS, nil
+	}
+	gw := newTestGateway(t, api)
+	gw.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := gw.ChainGetMessage(ctx, recentMsg.Cid())
+	assert.NoError(t, err)
+	_, err = gw.ChainGetMessage(ctx, oldMsg.Cid())
+	assert.Equal(t, errLookbackTooLong, err)
+	_, err = gw.ChainGetMessage(ctx, lostMsg.Cid())
+	assert.Equal(t, errMessageNotFound, err)
+
+	_, err = gw.ChainGetBlockMessages(ctx, recentTS.At(0).Cid())
+	assert.NoError(t, err)
+	_, err = gw.ChainGetBlockMessages(ctx, oldTS.At(0).Cid())
+	assert.Equal(t, errLookbackTooLong, err)
+
+	for c, expected := range map[cid.Cid]error{
+		recentTS.At(0).Cid(): nil,
+		oldTS.At(0).Cid():    errLookbackTooLong,
+		recentMsg.Cid():      nil,
+		oldMsg.Cid():         errLookbackTooLong,
+		lostMsg.Cid():        errMessageNotFound,
+		state:                nil,
+	} {
+		_, err := gw.ChainReadObj(ctx, c)
+		assert.Equal(t, expected, err, c.String())
+	}
+}
+
+func TestGatewaySkipsUnhealthyUpstreams(t *testing.T) {
+	tf.UnitTest(t)
+
+	a, b := &client.FullNodeStruct{}, &client.FullNodeStruct{}
+	gw := newTestGateway(t, a, b)
+	gw.upstreams.nodes[0].healthy = 0
+
+	for i := 0; i < 3; i++ {
+		api, err := gw.upstream(context.Background(), "ChainHead")
+		require.NoError(t, err)
+		assert.True(t, api == b)
+	}
+
+	gw.upstreams.nodes[1].healthy = 0
+	_, err := gw.upstream(context.Background(), "ChainHead")
+	assert.Equal(t, errNoUpstream, err)
+}
+
+func TestMethodLimiter(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	l := newMethodLimiter(2, map[string]float64{"MpoolPush": 1, "ChainHead": 0})
+	l.now = func() time.Time { return now }
+
+	assert.True(t, l.allow("a", "ChainGetBlock"))
+	assert.True(t, l.allow("a", "ChainGetBlock"))
+	assert.False(t, l.allow("a", "ChainGetBlock"))
+
+	// the budgets are per client
+	assert.True(t, l.allow("b", "ChainGetBlock"))
+
+	assert.True(t, l.allow("a", "MpoolPush"))
+	assert.False(t, l.allow("a", "MpoolPush"))
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.allow("a", "ChainHead"))
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.allow("a", "ChainGetBlock"))
+	assert.False(t, l.allow("a", "MpoolPush"))
+}
+
+func TestWithClient(t *testing.T) {
+	tf.UnitTest(t)
+
+	var client string
+	h := withClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client = clientFromCtx(r.Context())
+	}))
+	r := httptest.NewRequest("POST", "/rpc/v1", nil)
+	r.RemoteAddr = "10.0.0.1:4321"
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "10.0.0.1", client)
+
+	assert.Equal(t, "unknown", clientFromCtx(context.Background()))
+}
+
+// newTestTipSet returns a tipset of a single block mined at `ts`.
+func newTestTipSet(t *testing.T, ts time.Time) *types.TipSet {
+	return newTestTipSetAt(t, ts, 0)
+}
+
+// newTestTipSetAt returns a tipset of a single block mined at `ts` and height `h`.
+func newTestTipSetAt(t *testing.T, ts time.Time, h abi.ChainEpoch) *types.TipSet {
+	return types.RequireNewTipSet(t, &types.BlockHeader{
+		Height:                h,
+		Miner:                 types.RequireIDAddress(t, 1000),
+		Ticket:                types.Ticket{VRFProof: []byte(ts.String())},
+		ParentWeight:          big.Zero(),
+		ParentStateRoot:       types.CidFromString(t, "state"),
+		ParentMessageReceipts: types.CidFromString(t, "receipts"),
+		Messages:              types.CidFromString(t, "messages"),
+		ParentBaseFee:         big.Zero(),
+		Timestamp:             uint64(ts.Unix()),
+	})
+}
@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/filecoin-project/venus/pkg/util/tokenbucket"
+)
+
+// methodLimiter is a token-bucket rate limiter keyed by client and method. Each client gets
+// a budget of one second of calls of each method, which refills continuously.
+type methodLimiter struct {
+	defaultRate float64
+	rates       map[string]float64
+	buckets     *tokenbucket.Buckets
+
+	now func() time.Time
+}
+
+// newMethodLimiter creates a limiter allowing `rate` calls per second of each method,
+// `rates` overrides the rate of some methods. A non positive rate disables the limit.
+func newMethodLimiter(rate float64, rates map[string]float64) *methodLimiter {
+	return &methodLimiter{
+		defaultRate: rate,
+		rates:       rates,
+		buckets:     tokenbucket.New(),
+		now:         time.Now,
+	}
+}
+
+// allow consumes a call of `method` by `client` and returns whether it may be served.
+func (l *methodLimiter) allow(client, method string) bool {
+	rate, ok := l.rates[method]
+	if !ok {
+		rate = l.defaultRate
+	}
+	_, ok = l.buckets.Take(l.now(), 1, tokenbucket.Budget{
+		Key:      client + "/" + method,
+		Rate:     rate,
+		Capacity: math.Max(rate, 1),
+	})
+	return ok
+}
+
+type clientKey struct{}
+
+// withClient records the client of the requests in their context. The clients are known by
+// their ip: the gateway does not check tokens, so a client could get new budgets by sending
+// new tokens.
+func withClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.RemoteAddr
+		if ip, _, err := net.SplitHostPort(host); err == nil {
+			host = ip
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, host)))
+	})
+}
+
+// clientFromCtx returns the client recorded by withClient.
+func clientFromCtx(ctx context.Context) string {
+	if client, ok := ctx.Value(clientKey{}).(string); ok {
+		return client
+	}
+	return "unknown"
+}
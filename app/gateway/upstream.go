@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc"
+
+	"github.com/filecoin-project/venus/app/client"
+)
+
+const (
+	// healthCheckPeriod is how often the upstream nodes are checked.
+	healthCheckPeriod = 10 * time.Second
+	// maxHeadAge is how old the head of a healthy upstream node may be.
+	maxHeadAge = 5 * time.Minute
+)
+
+type upstream struct {
+	addr   string
+	api    *client.FullNodeStruct
+	closer jsonrpc.ClientCloser
+
+	healthy int32
+}
+
+func newUpstream(addr string, api *client.FullNodeStruct, closer jsonrpc.ClientCloser) *upstream {
+	return &upstream{addr: addr, api: api, closer: closer, healthy: 1}
+}
+
+func (u *upstream) isHealthy() bool {
+	return atomic.LoadInt32(&u.healthy) == 1
+}
+
+// check marks the node unhealthy when it does not answer or when its head is stale, that
+// is when it is not in sync.
+func (u *upstream) check(ctx context.Context, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckPeriod)
+	defer cancel()
+
+	healthy := int32(1)
+	head, err := u.api.ChainHead(ctx)
+	switch {
+	case err != nil:
+		log.Warnf("upstream %s is unhealthy: %v", u.addr, err)
+		healthy = 0
+	case time.Unix(int64(head.MinTimestamp()), 0).Before(now.Add(-maxHeadAge)):
+		log.Warnf("upstream %s is unhealthy: head %d is stale", u.addr, head.Height())
+		healthy = 0
+	}
+	if atomic.SwapInt32(&u.healthy, healthy) != healthy && healthy == 1 {
+		log.Infof("upstream %s is healthy again", u.addr)
+	}
+}
+
+// upstreams balances the calls over the healthy upstream nodes.
+type upstreams struct {
+	nodes []*upstream
+	next  uint32
+}
+
+// pick returns the next healthy node, or nil when no node is healthy.
+func (us *upstreams) pick() *upstream {
+	n := uint32(len(us.nodes))
+	start := atomic.AddUint32(&us.next, 1)
+	for i := uint32(0); i < n; i++ {
+		if u := us.nodes[(start+i)%n]; u.isHealthy() {
+			return u
+		}
+	}
+	return nil
+}
+
+func (us *upstreams) checkLoop(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, u := range us.nodes {
+				u.check(ctx, now)
+			}
+		}
+	}
+}
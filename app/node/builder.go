@@ -22,17 +22,21 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/storagenetworking"
 	"github.com/filecoin-project/venus/app/submodule/syncer"
 	"github.com/filecoin-project/venus/app/submodule/wallet"
+	"github.com/filecoin-project/venus/pkg/beacon"
+	chainpkg "github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/paychmgr"
+	"github.com/filecoin-project/venus/pkg/ratelimit"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/specactors/policy"
 	"github.com/filecoin-project/venus/pkg/statemanger"
 	"github.com/filecoin-project/venus/pkg/util/ffiwrapper"
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/pkg/errors"
 )
 
@@ -50,6 +54,8 @@ type Builder struct {
 	genCid         cid.Cid
 	walletPassword []byte
 	authURL        string
+	libp2pHost     host.Host
+	beaconSchedule beacon.Schedule
 }
 
 // BuilderOpt is an option for building a filecoin node.
@@ -115,6 +121,24 @@ func Libp2pOptions(opts ...libp2p.Option) BuilderOpt {
 	}
 }
 
+// Libp2pHostOption returns a builder option that makes the node use the given libp2p
+// host, eg. one of a mocknet, rather than building its own. The libp2p options are then ignored.
+func Libp2pHostOption(h host.Host) BuilderOpt {
+	return func(b *Builder) error {
+		b.libp2pHost = h
+		return nil
+	}
+}
+
+// BeaconScheduleOption returns a builder option that sets the beacon schedule used by
+// the node in place of the drand schedule of the network parameters.
+func BeaconScheduleOption(schedule beacon.Schedule) BuilderOpt {
+	return func(b *Builder) error {
+		b.beaconSchedule = schedule
+		return nil
+	}
+}
+
 // VerifierConfigOption returns a function that sets the verifier to use in the node consensus
 func VerifierConfigOption(verifier ffiwrapper.Verifier) BuilderOpt {
 	return func(c *Builder) error {
@@ -211,11 +235,11 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 
 	var err error
 	if b.journal == nil {
-		b.journal = journal.NewNoopJournal()
+		b.journal = journal.NilJournal()
 	}
 
 	// fetch genesis block id
-	b.genCid, err = readGenesisCid(b.repo.ChainDatastore())
+	b.genCid, err = chainpkg.ReadGenesisCid(b.repo.ChainDatastore())
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +247,7 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 	nd := &Node{
 		offlineMode: b.offlineMode,
 		repo:        b.repo,
+		journal:     b.journal,
 	}
 
 	nd.configModule = config2.NewConfigModule(b.repo)
@@ -301,6 +326,10 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 
 	apiBuilder := NewBuilder()
 	apiBuilder.NameSpace("Filecoin")
+	if rlCfg := b.repo.Config().RateLimitCfg; rlCfg != nil && rlCfg.InProcess {
+		nd.apiLimiter = ratelimit.NewAPILimiter(rlCfg)
+		apiBuilder.WithLimiter(nd.apiLimiter)
+	}
 
 	err = apiBuilder.AddServices(nd.configModule,
 		nd.blockstore,
@@ -314,7 +343,8 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		nd.mining,
 		nd.mpool,
 		nd.paychan,
-		nd.market)
+		nd.market,
+		newDiscoverService())
 
 	if err != nil {
 		return nil, errors.Wrap(err, "add service failed ")
@@ -368,6 +398,16 @@ func (b builder) Libp2pOpts() []libp2p.Option {
 	return b.libp2pOpts
 }
 
+// Libp2pHost get the libp2p host given to the node, nil when the node builds its own
+func (b builder) Libp2pHost() host.Host {
+	return b.libp2pHost
+}
+
+// BeaconSchedule get the beacon schedule given to the node, nil when it follows the network parameters
+func (b builder) BeaconSchedule() beacon.Schedule {
+	return b.beaconSchedule
+}
+
 // OfflineMode get the p2p network mode
 func (b builder) OfflineMode() bool {
 	return b.offlineMode
@@ -0,0 +1,50 @@
+package node
+
+import (
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/venus/app/client"
+	"github.com/filecoin-project/venus/app/client/v0api"
+	"github.com/filecoin-project/venus/app/openrpc"
+	"github.com/filecoin-project/venus/app/submodule/apiface"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+)
+
+// discoverService serves the OpenRPC documents of the api, they are built on the first call.
+type discoverService struct {
+	v0, v1 *discoverAPI
+}
+
+func newDiscoverService() *discoverService {
+	return &discoverService{
+		v0: &discoverAPI{api: &v0api.FullNodeStruct{}, version: "v0"},
+		v1: &discoverAPI{api: &client.FullNodeStruct{}, version: "v1"},
+	}
+}
+
+func (s *discoverService) API() apiface.IRPC {
+	return s.v1
+}
+
+func (s *discoverService) V0API() apiface.IRPC {
+	return s.v0
+}
+
+var _ apiface.IRPC = &discoverAPI{}
+
+type discoverAPI struct {
+	api     interface{}
+	version string
+
+	once sync.Once
+	doc  apitypes.OpenRPCDocument
+}
+
+// Discover returns the OpenRPC document of the api
+func (a *discoverAPI) Discover(ctx context.Context) (apitypes.OpenRPCDocument, error) {
+	a.once.Do(func() {
+		a.doc = openrpc.NewDocument(a.api, a.version)
+	})
+	return a.doc, nil
+}
@@ -14,6 +14,7 @@ import (
 type Env struct {
 	ctx                  context.Context
 	InspectorAPI         IInspector
+	RepoAPI              IRepo
 	BlockServiceAPI      apiface.IBlockService
 	BlockStoreAPI        apiface.IBlockStore
 	ChainAPI             apiface.IChain
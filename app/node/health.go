@@ -0,0 +1,151 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/ipfs/go-datastore"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// drandCheckTimeout bounds the time /readyz waits for a drand round.
+const drandCheckTimeout = 5 * time.Second
+
+// healthCheck is the result of one of the checks of /healthz and /readyz.
+type healthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthReport is the json body of /healthz and /readyz.
+type healthReport struct {
+	OK     bool          `json:"ok"`
+	Checks []healthCheck `json:"checks"`
+}
+
+// healthHandler serves /healthz, the node is healthy while its process runs and its datastores
+// are open.
+func (node *Node) healthHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, node.datastoreChecks())
+}
+
+// readyHandler serves /readyz, the node is ready once it is healthy, its head is close to the
+// current epoch, it has enough peers and drand answers.
+func (node *Node) readyHandler(w http.ResponseWriter, r *http.Request) {
+	checks := node.datastoreChecks()
+	checks = append(checks, node.headCheck(), node.peersCheck(), node.drandCheck(r.Context()))
+	writeHealthReport(w, checks)
+}
+
+func writeHealthReport(w http.ResponseWriter, checks []healthCheck) {
+	report := healthReport{OK: true, Checks: checks}
+	for _, c := range checks {
+		report.OK = report.OK && c.OK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.OK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Warnf("writing health report: %v", err)
+	}
+}
+
+func (node *Node) datastoreChecks() []healthCheck {
+	stores := []struct {
+		name string
+		ds   repo.Datastore
+	}{
+		{"chain datastore", node.repo.ChainDatastore()},
+		{"meta datastore", node.repo.MetaDatastore()},
+		{"wallet datastore", node.repo.WalletDatastore()},
+		{"paych datastore", node.repo.PaychDatastore()},
+	}
+
+	key := datastore.NewKey("/healthz")
+	checks := make([]healthCheck, 0, len(stores))
+	for _, s := range stores {
+		c := healthCheck{Name: s.name, OK: true}
+		if _, err := s.ds.Has(key); err != nil {
+			c.OK, c.Detail = false, err.Error()
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func (node *Node) headCheck() healthCheck {
+	maxLag := node.repo.Config().API.ReadyMaxHeadLag
+	head := node.chain.ChainReader.GetHead()
+	expected := node.chainClock.EpochAtTime(node.chainClock.Now())
+	lag := int64(expected - head.Height())
+	return healthCheck{
+		Name:   "head",
+		OK:     lag <= maxLag,
+		Detail: fmt.Sprintf("head %d, current epoch %d, %d epochs behind, at most %d allowed", head.Height(), expected, lag, maxLag),
+	}
+}
+
+func (node *Node) peersCheck() healthCheck {
+	if node.offlineMode {
+		return healthCheck{Name: "peers", OK: true, Detail: "offline"}
+	}
+	minPeers := node.repo.Config().Bootstrap.MinPeerThreshold
+	peers := len(node.network.Host.Network().Peers())
+	return healthCheck{
+		Name:   "peers",
+		OK:     peers >= minPeers,
+		Detail: fmt.Sprintf("%d peers, at least %d required", peers, minPeers),
+	}
+}
+
+func (node *Node) drandCheck(ctx context.Context) healthCheck {
+	ctx, cancel := context.WithTimeout(ctx, drandCheckTimeout)
+	defer cancel()
+
+	epoch := node.chainClock.EpochAtTime(node.chainClock.Now())
+	beacon := node.chain.Drand.BeaconForEpoch(epoch)
+	round := beacon.MaxBeaconRoundForEpoch(epoch)
+	select {
+	case resp := <-beacon.Entry(ctx, round):
+		if resp.Err != nil {
+			return healthCheck{Name: "drand", Detail: fmt.Sprintf("getting round %d: %v", round, resp.Err)}
+		}
+		return healthCheck{Name: "drand", OK: true, Detail: fmt.Sprintf("round %d", round)}
+	case <-ctx.Done():
+		return healthCheck{Name: "drand", Detail: fmt.Sprintf("getting round %d: %v", round, ctx.Err())}
+	}
+}
+
+// withProbes serves the probes without auth, by path whatever the query of the request, and the
+// other requests with `next`. The trusted handlers of the auth mux match the whole request uri.
+func withProbes(next http.Handler, probes map[string]http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if probe, ok := probes[r.URL.Path]; ok {
+			probe(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminOnly serves the requests authenticated with an admin token, the permissions are set in
+// the context of the request by the auth mux.
+func adminOnly(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.HasPerm(r.Context(), nil, "admin") {
+			http.Error(w, "missing permission (need 'admin')", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
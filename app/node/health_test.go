@@ -0,0 +1,73 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestWriteHealthReport(t *testing.T) {
+	tf.UnitTest(t)
+
+	rec := httptest.NewRecorder()
+	writeHealthReport(rec, []healthCheck{{Name: "a", OK: true}, {Name: "b", OK: true}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	writeHealthReport(rec, []healthCheck{{Name: "a", OK: true}, {Name: "b", Detail: "behind"}})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var report healthReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.False(t, report.OK)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "behind", report.Checks[1].Detail)
+}
+
+func TestAdminOnly(t *testing.T) {
+	tf.UnitTest(t)
+
+	h := adminOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for perm, code := range map[auth.Permission]int{
+		"read":  http.StatusForbidden,
+		"sign":  http.StatusForbidden,
+		"admin": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		req = req.WithContext(auth.WithPerm(req.Context(), []auth.Permission{perm}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, code, rec.Code, perm)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestWithProbes(t *testing.T) {
+	tf.UnitTest(t)
+
+	h := withProbes(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}), map[string]http.HandlerFunc{
+		"/healthz": func(w http.ResponseWriter, r *http.Request) {},
+	})
+	for target, code := range map[string]int{
+		"/healthz":           http.StatusOK,
+		"/healthz?verbose=1": http.StatusOK,
+		"/readyz":            http.StatusUnauthorized,
+		"/rpc/v1":            http.StatusUnauthorized,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, code, rec.Code, target)
+	}
+}
@@ -1,27 +0,0 @@
-package node
-
-import (
-	"encoding/json"
-
-	"github.com/ipfs/go-cid"
-	"github.com/ipfs/go-datastore"
-	"github.com/pkg/errors"
-
-	"github.com/filecoin-project/venus/pkg/chain"
-)
-
-// readGenesisCid is a helper function that queries the provided datastore for
-// an entry with the genesisKey cid, returning if found.
-func readGenesisCid(ds datastore.Datastore) (cid.Cid, error) {
-	bb, err := ds.Get(chain.GenesisKey)
-	if err != nil {
-		return cid.Undef, errors.Wrap(err, "failed to read genesisKey")
-	}
-
-	var c cid.Cid
-	err = json.Unmarshal(bb, &c)
-	if err != nil {
-		return cid.Undef, errors.Wrap(err, "failed to cast genesisCid")
-	}
-	return c, nil
-}
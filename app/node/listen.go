@@ -0,0 +1,79 @@
+package node
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+)
+
+// apiTLSConfig returns the tls config of the api server, nil when the api is served over
+// plain http. The clients must present a certificate signed by one of the authorities of
+// TLSClientCAPath when it is set.
+func apiTLSConfig(cfg *config.APIConfig) (*tls.Config, error) {
+	if cfg.TLSCertPath == "" && cfg.TLSKeyPath == "" {
+		if cfg.TLSClientCAPath != "" {
+			return nil, xerrors.New("tlsClientCAPath needs tlsCertPath and tlsKeyPath")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertPath, cfg.TLSKeyPath)
+	if err != nil {
+		return nil, xerrors.Errorf("loading api certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if cfg.TLSClientCAPath != "" {
+		pem, err := ioutil.ReadFile(cfg.TLSClientCAPath)
+		if err != nil {
+			return nil, xerrors.Errorf("reading api client ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, xerrors.Errorf("no certificate found in %s", cfg.TLSClientCAPath)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsCfg, nil
+}
+
+// listenUnixSocket listens on the unix socket at `path` with the octal file `mode`, a socket
+// left behind by a node which did not stop cleanly is replaced.
+func listenUnixSocket(path, mode string) (net.Listener, error) {
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing unix socket mode %s: %w", mode, err)
+	}
+
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, xerrors.Errorf("%s exists and is not a unix socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, xerrors.Errorf("removing stale unix socket: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	lst, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, xerrors.Errorf("listening on unix socket %s: %w", path, err)
+	}
+	if err := os.Chmod(path, os.FileMode(perm)); err != nil {
+		_ = lst.Close()
+		return nil, xerrors.Errorf("setting the mode of unix socket %s: %w", path, err)
+	}
+	return lst, nil
+}
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -42,8 +43,10 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/wallet"
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/jwtauth"
 	"github.com/filecoin-project/venus/pkg/metrics"
+	"github.com/filecoin-project/venus/pkg/ratelimit"
 	"github.com/filecoin-project/venus/pkg/repo"
 
 	_ "github.com/filecoin-project/venus/pkg/crypto/bls"  // enable bls signatures
@@ -72,6 +75,9 @@ type Node struct {
 	// It contains all persistent artifacts of the filecoin node.
 	repo repo.Repo
 
+	// journal records the events of the node subsystems.
+	journal journal.Journal
+
 	//
 	// Core services
 	//
@@ -104,6 +110,7 @@ type Node struct {
 	// Jsonrpc
 	//
 	jsonRPCService, jsonRPCServiceV1 *jsonrpc.RPCServer
+	apiLimiter                       *ratelimit.APILimiter
 
 	jaegerExporter *jaeger.Exporter
 }
@@ -112,6 +119,14 @@ func (node *Node) Chain() *chain2.ChainSubmodule {
 	return node.chain
 }
 
+func (node *Node) Syncer() *syncer2.SyncerSubmodule {
+	return node.syncer
+}
+
+func (node *Node) Mining() *mining.MiningModule {
+	return node.mining
+}
+
 func (node *Node) StorageNetworking() *storagenetworking.StorageNetworkingSubmodule {
 	return node.storageNetworking
 }
@@ -228,6 +243,10 @@ func (node *Node) Stop(ctx context.Context) {
 	// Stop market submodule
 	// node.market.Stop()
 
+	if err := node.journal.Close(); err != nil {
+		fmt.Printf("error closing journal: %s\n", err)
+	}
+
 	if err := node.repo.Close(); err != nil {
 		fmt.Printf("error closing repo: %s\n", err)
 	}
@@ -257,6 +276,13 @@ func (node *Node) RunRPCAndWait(ctx context.Context, rootCmdDaemon *cmds.Command
 	}
 
 	netListener := manet.NetListener(apiListener) // nolint
+	tlsCfg, err := apiTLSConfig(cfg.API)
+	if err != nil {
+		return err
+	}
+	if tlsCfg != nil {
+		netListener = tls.NewListener(netListener, tlsCfg)
+	}
 	mux := http.NewServeMux()
 	err = node.runRestfulAPI(ctx, mux, rootCmdDaemon) // nolint
 	if err != nil {
@@ -267,6 +293,8 @@ func (node *Node) RunRPCAndWait(ctx context.Context, rootCmdDaemon *cmds.Command
 	if err != nil {
 		return err
 	}
+	// pprof is served by the default mux, see the imports of net/http/pprof
+	mux.Handle("/debug/pprof/", adminOnly(http.DefaultServeMux))
 
 	localVerifer, err := jwtauth.NewJwtAuth(node.repo)
 	if err != nil {
@@ -293,13 +321,16 @@ func (node *Node) RunRPCAndWait(ctx context.Context, rootCmdDaemon *cmds.Command
 
 	authMux := jwtclient.NewAuthMux(localVerifer,
 		remoteVerifer, handler, logging.Logger("venus-auth"))
-	authMux.TrustHandle("/debug/pprof/", http.DefaultServeMux)
+	probes := withProbes(authMux, map[string]http.HandlerFunc{
+		"/healthz": node.healthHandler,
+		"/readyz":  node.readyHandler,
+	})
 
 	// todo:
 	apikey, _ := tag.NewKey("api")
 
 	apiserv := &http.Server{
-		Handler: authMux,
+		Handler: probes,
 		BaseContext: func(listener net.Listener) context.Context {
 			ctx, _ := tag.New(context.Background(),
 				tag.Upsert(apikey, "venus"))
@@ -314,9 +345,27 @@ func (node *Node) RunRPCAndWait(ctx context.Context, rootCmdDaemon *cmds.Command
 		}
 	}()
 
-	// Write the resolved API address to the repo
+	if cfg.API.UnixSocketPath != "" {
+		unixListener, err := listenUnixSocket(cfg.API.UnixSocketPath, cfg.API.UnixSocketMode)
+		if err != nil {
+			return err
+		}
+		go func() {
+			err := apiserv.Serve(unixListener)
+			if err != nil && err != http.ErrServerClosed {
+				log.Errorf("serving api on unix socket: %v", err)
+			}
+		}()
+	}
+
+	// Write the resolved API address to the repo, the clients learn from the api file whether
+	// to use tls, the address of the config stays a plain listen address
 	cfg.API.APIAddress = apiListener.Multiaddr().String()
-	if err := node.repo.SetAPIAddr(cfg.API.APIAddress); err != nil {
+	apiAddr := cfg.API.APIAddress
+	if tlsCfg != nil {
+		apiAddr += "/https"
+	}
+	if err := node.repo.SetAPIAddr(apiAddr); err != nil {
 		log.Error("Could not save API address to repo")
 		return err
 	}
@@ -352,8 +401,12 @@ func (node *Node) runRestfulAPI(ctx context.Context, handler *http.ServeMux, roo
 }
 
 func (node *Node) runJsonrpcAPI(ctx context.Context, handler *http.ServeMux) error { // nolint
-	handler.Handle("/rpc/v0", node.jsonRPCService)
-	handler.Handle("/rpc/v1", node.jsonRPCServiceV1)
+	var v0, v1 http.Handler = node.jsonRPCService, node.jsonRPCServiceV1
+	if node.apiLimiter != nil {
+		v0, v1 = node.apiLimiter.Handler(v0), node.apiLimiter.Handler(v1)
+	}
+	handler.Handle("/rpc/v0", v0)
+	handler.Handle("/rpc/v1", v1)
 	return nil
 }
 
@@ -362,6 +415,7 @@ func (node *Node) createServerEnv(ctx context.Context) *Env {
 	env := Env{
 		ctx:                  ctx,
 		InspectorAPI:         NewInspectorAPI(node.repo),
+		RepoAPI:              NewRepoAPI(node.repo),
 		BlockServiceAPI:      node.blockservice.API(),
 		BlockStoreAPI:        node.blockstore.API(),
 		ChainAPI:             node.chain.API(),
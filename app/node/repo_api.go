@@ -0,0 +1,56 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+// IRepo gives the commands access to the repo of the node.
+type IRepo interface {
+	// Backup writes a plain backup of the repo, which can run while the node is using it.
+	Backup(ctx context.Context, w io.Writer) (*repo.BackupResult, error)
+	// Convert copies the data of the repo to the datastore backend `to` while the node is
+	// using it, the repo switches to it when the node is restarted.
+	Convert(ctx context.Context, to, path string) (*repo.ConvertResult, error)
+}
+
+var _ IRepo = &repoAPI{}
+
+// NewRepoAPI returns the `IRepo` of the repo `r`.
+func NewRepoAPI(r repo.Repo) IRepo {
+	return &repoAPI{repo: r}
+}
+
+type repoAPI struct {
+	repo repo.Repo
+}
+
+// Backup writes a backup of the config, keystore and non-blockstore datastores of the repo,
+// with the genesis the chain is synced again from once restored.
+func (a *repoAPI) Backup(ctx context.Context, w io.Writer) (*repo.BackupResult, error) {
+	genCid, err := chain.ReadGenesisCid(a.repo.ChainDatastore())
+	if err != nil {
+		return nil, err
+	}
+	return repo.Backup(ctx, a.repo, w, nil, []cid.Cid{genCid})
+}
+
+// onlineConverter is a repo which can be converted while it is open.
+type onlineConverter interface {
+	ConvertOnline(ctx context.Context, to, path string) (*repo.ConvertResult, error)
+}
+
+// Convert copies the data of the repo to the backend `to`, with the blockstore at `path`.
+func (a *repoAPI) Convert(ctx context.Context, to, path string) (*repo.ConvertResult, error) {
+	r, ok := a.repo.(onlineConverter)
+	if !ok {
+		return nil, fmt.Errorf("a repo of type %T cannot be converted", a.repo)
+	}
+	return r.ConvertOnline(ctx, to, path)
+}
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/filecoin-project/venus/app/client/v0api"
@@ -20,6 +21,13 @@ type RPCBuilder struct {
 	namespace   []string
 	v0APIStruct []interface{}
 	v1APIStruct []interface{}
+	limiter     CallLimiter
+}
+
+// CallLimiter decides whether an api call may be served, the calls it returns an error for are
+// rejected with that error.
+type CallLimiter interface {
+	Allow(ctx context.Context, method string) error
 }
 
 func NewBuilder() *RPCBuilder {
@@ -30,6 +38,13 @@ func (builder *RPCBuilder) NameSpace(nameSpaece string) *RPCBuilder {
 	builder.namespace = append(builder.namespace, nameSpaece)
 	return builder
 }
+
+// WithLimiter makes every api call go through `limiter` before being served.
+func (builder *RPCBuilder) WithLimiter(limiter CallLimiter) *RPCBuilder {
+	builder.limiter = limiter
+	return builder
+}
+
 func (builder *RPCBuilder) AddServices(services ...RPCService) error {
 	for _, service := range services {
 		err := builder.AddService(service)
@@ -106,6 +121,9 @@ func (builder *RPCBuilder) Build(version string) *jsonrpc.RPCServer {
 		for _, apiStruct := range builder.v0APIStruct {
 			funcrule.PermissionProxy(apiStruct, &fullNodeV0)
 		}
+		if builder.limiter != nil {
+			limitProxy(&fullNodeV0, builder.limiter)
+		}
 		for _, nameSpace := range builder.namespace {
 			server.Register(nameSpace, &fullNodeV0)
 		}
@@ -113,12 +131,80 @@ func (builder *RPCBuilder) Build(version string) *jsonrpc.RPCServer {
 		for _, apiStruct := range builder.v1APIStruct {
 			funcrule.PermissionProxy(apiStruct, &fullNode)
 		}
+		if builder.limiter != nil {
+			limitProxy(&fullNode, builder.limiter)
+		}
 		for _, nameSpace := range builder.namespace {
 			server.Register(nameSpace, &fullNode)
 		}
 	default:
 		panic("invalid version: " + version)
 	}
+	for _, nameSpace := range builder.namespace {
+		server.AliasMethod("rpc.discover", nameSpace+".Discover")
+	}
 
 	return server
 }
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// limitProxy wraps the methods set in the api struct `out`, and in the structs it embeds, so
+// that the calls rejected by `limiter` return its error instead of being served. The methods
+// without an error result are not limited, they could not tell the rejection from a result.
+func limitProxy(out interface{}, limiter CallLimiter) {
+	rv := reflect.ValueOf(out).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		field, ft := rv.Field(i), rv.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			limitProxy(field.Addr().Interface(), limiter)
+			continue
+		}
+		if field.Kind() != reflect.Func || field.IsNil() || ft.Type.NumIn() == 0 || ft.Type.In(0) != contextType {
+			continue
+		}
+		if !returnsError(ft.Type) {
+			continue
+		}
+
+		method := ft.Name
+		fn := reflect.ValueOf(field.Interface())
+		field.Set(reflect.MakeFunc(ft.Type, func(args []reflect.Value) []reflect.Value {
+			if err := limiter.Allow(args[0].Interface().(context.Context), method); err != nil {
+				return rejectedResults(ft.Type, err)
+			}
+			return fn.Call(args)
+		}))
+	}
+}
+
+func returnsError(t reflect.Type) bool {
+	for i := 0; i < t.NumOut(); i++ {
+		if t.Out(i) == errorType {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectedResults returns the results of a rejected call of a func of type `t`: `err` for the
+// error, a closed channel for a channel, such as a subscription which ends at once, and the
+// zero value for anything else.
+func rejectedResults(t reflect.Type, err error) []reflect.Value {
+	out := make([]reflect.Value, t.NumOut())
+	for i := range out {
+		ot := t.Out(i)
+		switch {
+		case ot == errorType:
+			out[i] = reflect.ValueOf(&err).Elem()
+		case ot.Kind() == reflect.Chan:
+			ch := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, ot.Elem()), 0)
+			ch.Close()
+			out[i] = ch.Convert(ot)
+		default:
+			out[i] = reflect.Zero(ot)
+		}
+	}
+	return out
+}
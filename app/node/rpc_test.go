@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/filecoin-project/venus/app/client/funcrule"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/filecoin-project/go-jsonrpc"
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
@@ -81,6 +83,39 @@ func TestJsonrpc(t *testing.T) {
 	assert.Equal(t, res.Result, "test")
 }
 
+type rejectAll struct{}
+
+func (rejectAll) Allow(context.Context, string) error {
+	return errors.New("rate limited")
+}
+
+func TestLimitProxy(t *testing.T) {
+	tf.UnitTest(t)
+
+	var api struct {
+		Adapter1
+		Notify    func(ctx context.Context) (<-chan string, error)
+		Check     func(ctx context.Context, height int64) error
+		BlockTime func(ctx context.Context) time.Duration
+	}
+	api.Test1 = func(ctx context.Context) (string, error) { return "test", nil }
+	api.Notify = func(ctx context.Context) (<-chan string, error) { return make(chan string), nil }
+	api.Check = func(ctx context.Context, height int64) error { return nil }
+	api.BlockTime = func(ctx context.Context) time.Duration { return time.Second }
+	limitProxy(&api, rejectAll{})
+
+	_, err := api.Test1(context.Background())
+	require.EqualError(t, err, "rate limited")
+	require.EqualError(t, api.Check(context.Background(), 1), "rate limited")
+	// a rejected subscription ends at once
+	ch, err := api.Notify(context.Background())
+	require.EqualError(t, err, "rate limited")
+	_, ok := <-ch
+	require.False(t, ok)
+	// a method which can not report an error is not limited
+	require.Equal(t, time.Second, api.BlockTime(context.Background()))
+}
+
 type tmodule1 struct {
 }
 
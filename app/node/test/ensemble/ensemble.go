@@ -0,0 +1,312 @@
+package ensemble
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/node/test"
+	"github.com/filecoin-project/venus/pkg/beacon"
+	"github.com/filecoin-project/venus/pkg/clock"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/types"
+	gengen "github.com/filecoin-project/venus/tools/gengen/util"
+)
+
+// genesisAge is how far in the past the genesis is set. Blocks are validated against the
+// wall clock, so at most genesisAge / block delay epochs can be mined.
+const genesisAge = 24 * time.Hour
+
+// maxNullRounds is the number of rounds in a row without a winner after which MineNext fails.
+const maxNullRounds = 50
+
+// Ensemble is a set of in-process venus nodes connected over a libp2p mocknet and sharing
+// a fake clock. The genesis holds pre-sealed mock miners, each run by its own node, and
+// the chain only moves forward when the test calls MineNext, so that the tests of sync,
+// reorgs or message propagation are deterministic and run without a network.
+//
+// The proofs are checked by a verifier accepting them all and the beacon is a mock one.
+type Ensemble struct {
+	t *testing.T
+
+	numMiners   int
+	numNodes    int
+	numAccounts int
+	numSectors  int
+
+	configMutations []node.ConfigOpt
+
+	mn         mocknet.Mocknet
+	clk        clock.Fake
+	seed       *test.ChainSeed
+	genTime    uint64
+	blockDelay uint64
+
+	nodes  []*node.Node
+	miners []*Miner
+}
+
+// NewEnsemble creates an ensemble of one miner, and no other node or account.
+func NewEnsemble(t *testing.T) *Ensemble {
+	return &Ensemble{
+		t:          t,
+		numMiners:  1,
+		numSectors: 2,
+	}
+}
+
+// WithMiners sets the number of genesis miners, each is run by its own node.
+func (ens *Ensemble) WithMiners(n int) *Ensemble {
+	ens.numMiners = n
+	return ens
+}
+
+// WithNodes sets the number of nodes which do not mine.
+func (ens *Ensemble) WithNodes(n int) *Ensemble {
+	ens.numNodes = n
+	return ens
+}
+
+// WithAccounts sets the number of funded accounts in the genesis, see Account.
+func (ens *Ensemble) WithAccounts(n int) *Ensemble {
+	ens.numAccounts = n
+	return ens
+}
+
+// WithSectors sets the number of pre-sealed sectors of each miner.
+func (ens *Ensemble) WithSectors(n int) *Ensemble {
+	ens.numSectors = n
+	return ens
+}
+
+// WithConfig adds a configuration mutation applied to the repo of every node.
+func (ens *Ensemble) WithConfig(cm node.ConfigOpt) *Ensemble {
+	ens.configMutations = append(ens.configMutations, cm)
+	return ens
+}
+
+// Start generates the genesis, builds and starts the nodes, the miners first, and
+// connects them all together. The nodes are stopped when the test ends.
+func (ens *Ensemble) Start(ctx context.Context) *Ensemble {
+	ens.t.Helper()
+	require.True(ens.t, ens.numMiners > 0, "an ensemble needs at least one miner")
+
+	ens.blockDelay = config.NewDefaultConfig().NetworkParams.BlockDelay
+	ens.genTime = uint64(time.Now().Add(-genesisAge).Unix())
+	ens.clk = clock.NewFake(time.Unix(int64(ens.genTime), 0))
+	ens.seed = test.MakeChainSeed(ens.t, ens.genesisConfig())
+	ens.mn = mocknet.New(ctx)
+
+	for i := 0; i < ens.numMiners; i++ {
+		nd := ens.buildNode(ctx)
+		m := &Miner{Node: nd}
+		m.Owner = ens.seed.GiveKey(ens.t, nd, i)
+		m.Address, _ = ens.seed.GiveMiner(ens.t, nd, i)
+		require.NoError(ens.t, nd.ConfigModule().API().ConfigSet(ctx, "walletModule.defaultAddress", m.Owner.String()))
+		ens.miners = append(ens.miners, m)
+	}
+	for i := 0; i < ens.numNodes; i++ {
+		ens.buildNode(ctx)
+	}
+
+	test.StartNodes(ens.t, ens.nodes)
+	ens.t.Cleanup(func() {
+		test.StopNodes(ens.nodes)
+	})
+
+	require.NoError(ens.t, ens.mn.LinkAll())
+	require.NoError(ens.t, ens.mn.ConnectAllButSelf())
+	return ens
+}
+
+func (ens *Ensemble) genesisConfig() *gengen.GenesisCfg {
+	cfg := &gengen.GenesisCfg{
+		KeysToGen: ens.numMiners + ens.numAccounts,
+		Network:   "gfctest",
+		Time:      ens.genTime,
+	}
+	for i := 0; i < cfg.KeysToGen; i++ {
+		cfg.PreallocatedFunds = append(cfg.PreallocatedFunds, "10000")
+	}
+	for i := 0; i < ens.numMiners; i++ {
+		commCfgs, err := gengen.MakeCommitCfgs(ens.numSectors)
+		require.NoError(ens.t, err)
+		cfg.Miners = append(cfg.Miners, &gengen.CreateStorageMinerConfig{
+			Owner:            i,
+			CommittedSectors: commCfgs,
+			SealProofType:    constants.DevSealProofType,
+			MarketBalance:    abi.NewTokenAmount(0),
+		})
+	}
+	return cfg
+}
+
+func (ens *Ensemble) buildNode(ctx context.Context) *node.Node {
+	h, err := ens.mn.GenPeer()
+	require.NoError(ens.t, err)
+
+	// the mock miners only hold 2KiB sectors
+	params := config.NewDefaultConfig().NetworkParams
+	params.ConsensusMinerMinPower = 2048
+	params.ReplaceProofTypes = []abi.RegisteredSealProof{constants.DevRegisteredSealProof}
+
+	builder := test.NewNodeBuilder(ens.t).
+		WithGenesisInit(ens.seed.GenesisInitFunc).
+		WithConfig(func(c *config.Config) {
+			c.NetworkParams.ConsensusMinerMinPower = params.ConsensusMinerMinPower
+			c.NetworkParams.ReplaceProofTypes = params.ReplaceProofTypes
+		}).
+		WithBuilderOpt(
+			node.VerifierConfigOption(&mockProofVerifier{}),
+			node.ChainClockConfigOption(clock.NewChainClockFromClock(ens.genTime, time.Duration(ens.blockDelay)*time.Second, ens.clk)),
+			node.BeaconScheduleOption(beacon.NewMockSchedule(time.Duration(ens.blockDelay)*time.Second)),
+			node.Libp2pHostOption(h),
+			node.MonkeyPatchNetworkParamsOption(params),
+		)
+	for _, cm := range ens.configMutations {
+		builder.WithConfig(cm)
+	}
+
+	nd := builder.Build(ctx)
+	ens.nodes = append(ens.nodes, nd)
+	return nd
+}
+
+// Nodes returns all the nodes of the ensemble, the nodes of the miners first.
+func (ens *Ensemble) Nodes() []*node.Node {
+	return ens.nodes
+}
+
+// Miners returns the genesis miners.
+func (ens *Ensemble) Miners() []*Miner {
+	return ens.miners
+}
+
+// Clock returns the fake clock shared by the nodes, it is advanced by MineNext.
+func (ens *Ensemble) Clock() clock.Fake {
+	return ens.clk
+}
+
+// Account returns the address of the i-th funded account of the genesis.
+func (ens *Ensemble) Account(i int) address.Address {
+	return ens.seed.Addr(ens.t, ens.numMiners+i)
+}
+
+// GiveAccount imports the key of the i-th funded account of the genesis into the wallet of `nd`.
+func (ens *Ensemble) GiveAccount(nd *node.Node, i int) address.Address {
+	return ens.seed.GiveKey(ens.t, nd, ens.numMiners+i)
+}
+
+// Connect links and connects two nodes of the ensemble.
+func (ens *Ensemble) Connect(a, b *node.Node) {
+	ens.t.Helper()
+	pa, pb := a.Network().Host.ID(), b.Network().Host.ID()
+	if len(ens.mn.LinksBetweenPeers(pa, pb)) == 0 {
+		_, err := ens.mn.LinkPeers(pa, pb)
+		require.NoError(ens.t, err)
+	}
+	_, err := ens.mn.ConnectPeers(pa, pb)
+	require.NoError(ens.t, err)
+}
+
+// Disconnect disconnects and unlinks two nodes of the ensemble, so that they can no longer
+// reach each other directly.
+func (ens *Ensemble) Disconnect(a, b *node.Node) {
+	ens.t.Helper()
+	pa, pb := a.Network().Host.ID(), b.Network().Host.ID()
+	require.NoError(ens.t, ens.mn.DisconnectPeers(pa, pb))
+	require.NoError(ens.t, ens.mn.UnlinkPeers(pa, pb))
+}
+
+// Partition splits the nodes in two groups which can not reach each other.
+func (ens *Ensemble) Partition(left, right []*node.Node) {
+	ens.t.Helper()
+	for _, a := range left {
+		for _, b := range right {
+			ens.Disconnect(a, b)
+		}
+	}
+}
+
+// MineNext mines the next non null round: each miner tries to mine on the head of its own
+// node, from the round after the highest of these heads, and the round is skipped when no
+// miner wins it. The clock is advanced to the time of the blocks, which are submitted
+// through the nodes of their miners, and returned.
+func (ens *Ensemble) MineNext(ctx context.Context) []*types.BlockMsg {
+	ens.t.Helper()
+
+	var height abi.ChainEpoch
+	for _, m := range ens.miners {
+		if h := m.Node.Chain().ChainReader.GetHead().Height(); h > height {
+			height = h
+		}
+	}
+
+	for round := height + 1; ; round++ {
+		require.True(ens.t, round-height <= maxNullRounds, "no miner won in %d rounds", maxNullRounds)
+
+		var blks []*types.BlockMsg
+		var timestamp uint64
+		for _, m := range ens.miners {
+			blk, err := m.Mine(ctx, round)
+			require.NoError(ens.t, err)
+			if blk != nil {
+				blks = append(blks, blk)
+				timestamp = blk.Header.Timestamp
+			}
+		}
+		if len(blks) == 0 {
+			continue
+		}
+
+		if d := time.Unix(int64(timestamp), 0).Sub(ens.clk.Now()); d > 0 {
+			ens.clk.Advance(d)
+		}
+		for _, m := range ens.miners {
+			for _, blk := range blks {
+				if blk.Header.Miner == m.Address {
+					require.NoError(ens.t, m.Node.Syncer().API().SyncSubmitBlock(ctx, blk))
+				}
+			}
+		}
+		return blks
+	}
+}
+
+// WaitSync waits until the given nodes, all the nodes of the ensemble by default, share
+// the same head and returns it.
+func (ens *Ensemble) WaitSync(ctx context.Context, nds ...*node.Node) *types.TipSet {
+	ens.t.Helper()
+	if len(nds) == 0 {
+		nds = ens.nodes
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		head := nds[0].Chain().ChainReader.GetHead()
+		synced := true
+		for _, nd := range nds[1:] {
+			if !nd.Chain().ChainReader.GetHead().Equals(head) {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			return head
+		}
+
+		select {
+		case <-ctx.Done():
+			ens.t.Fatalf("nodes did not sync: %s", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ensemble
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestEnsembleMineAndSync(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ens := NewEnsemble(t).WithNodes(1).Start(ctx)
+	genesis := ens.WaitSync(ctx)
+
+	for i := 0; i < 3; i++ {
+		blks := ens.MineNext(ctx)
+		require.Len(t, blks, 1)
+		assert.Equal(t, ens.Miners()[0].Address, blks[0].Header.Miner)
+	}
+
+	head := ens.WaitSync(ctx)
+	assert.True(t, head.Height() >= genesis.Height()+3)
+	assert.Equal(t, int64(head.MinTimestamp()), ens.Clock().Now().Unix())
+}
+
+func TestEnsemblePartitionReorg(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ens := NewEnsemble(t).WithMiners(2).Start(ctx)
+	left, right := ens.Miners()[0].Node, ens.Miners()[1].Node
+	base := ens.WaitSync(ctx)
+
+	// each side mines its own chain on top of the shared base
+	ens.Partition([]*node.Node{left}, []*node.Node{right})
+	for i := 0; i < 3; i++ {
+		ens.MineNext(ctx)
+	}
+	leftHead, rightHead := left.Chain().ChainReader.GetHead(), right.Chain().ChainReader.GetHead()
+	require.False(t, leftHead.Equals(rightHead), "the partition heads should differ")
+	assert.True(t, leftHead.Height() > base.Height() || rightHead.Height() > base.Height())
+
+	// once reconnected, both converge on the same tipset, built on the heaviest side
+	ens.Connect(left, right)
+	ens.MineNext(ctx)
+	head := ens.WaitSync(ctx, left, right)
+	assert.Equal(t, head.Key(), left.Chain().ChainReader.GetHead().Key())
+	assert.Equal(t, head.Key(), right.Chain().ChainReader.GetHead().Key())
+	assert.True(t, head.Height() > leftHead.Height() && head.Height() > rightHead.Height())
+
+	onChain := func(ts *types.TipSet) bool {
+		at, err := left.Chain().ChainReader.GetTipSetByHeight(ctx, head, ts.Height(), true)
+		require.NoError(t, err)
+		return at.Equals(ts)
+	}
+	onLeft, onRight := onChain(leftHead), onChain(rightHead)
+	assert.True(t, onLeft || onRight, "the converged chain should extend one of the partition heads")
+	if leftHead.Height() > base.Height() && rightHead.Height() > base.Height() {
+		assert.False(t, onLeft && onRight, "one of the sides should have reorged")
+	}
+}
@@ -0,0 +1,104 @@
+package ensemble
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	acrypto "github.com/filecoin-project/go-state-types/crypto"
+	proof2 "github.com/filecoin-project/specs-actors/v2/actors/runtime/proof"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// Miner is a genesis miner of an ensemble, whose owner is also the worker.
+type Miner struct {
+	Node    *node.Node
+	Address address.Address
+	Owner   address.Address
+}
+
+// Mine tries to mine a block at `round` on the head of the node of the miner. It returns
+// nil when the miner is not eligible, does not win the round, or its head is already at
+// or past `round`.
+func (m *Miner) Mine(ctx context.Context, round abi.ChainEpoch) (*types.BlockMsg, error) {
+	base := m.Node.Chain().ChainReader.GetHead()
+	if base.Height() >= round {
+		return nil, nil
+	}
+
+	mbi, err := m.Node.Mining().API().MinerGetBaseInfo(ctx, m.Address, round, base.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get mining base info: %w", err)
+	}
+	if mbi == nil || !mbi.EligibleForMining {
+		return nil, nil
+	}
+
+	signer := m.Node.Wallet().Signer
+	beaconBase := mbi.PrevBeaconEntry
+	if len(mbi.BeaconEntries) > 0 {
+		beaconBase = mbi.BeaconEntries[len(mbi.BeaconEntries)-1]
+	}
+
+	// election
+	buf := new(bytes.Buffer)
+	if err := m.Address.MarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	electionRand, err := chain.DrawRandomness(beaconBase.Data, acrypto.DomainSeparationTag_ElectionProofProduction, round, buf.Bytes())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to draw election randomness: %w", err)
+	}
+	vrf, err := signer.SignBytes(ctx, electionRand, mbi.WorkerKey)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to compute election vrf: %w", err)
+	}
+	eproof := &types.ElectionProof{VRFProof: vrf.Data}
+	eproof.WinCount = eproof.ComputeWinCount(mbi.MinerPower, mbi.NetworkPower)
+	if eproof.WinCount < 1 {
+		return nil, nil
+	}
+
+	// ticket
+	smokeHeight := m.Node.Repo().Config().NetworkParams.ForkUpgradeParam.UpgradeSmokeHeight
+	tm := consensus.NewTicketMachine(nil, m.Node.Chain().ChainReader)
+	ticket, err := tm.MakeTicket(ctx, base.Key(), round-constants.TicketRandomnessLookback, m.Address, &beaconBase, round > smokeHeight, mbi.WorkerKey, signer)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to make ticket: %w", err)
+	}
+
+	msgs, err := m.Node.Mpool().API().MpoolSelect(ctx, base.Key(), 1)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to select messages: %w", err)
+	}
+
+	beaconValues := make([]*types.BeaconEntry, len(mbi.BeaconEntries))
+	for i := range mbi.BeaconEntries {
+		beaconValues[i] = &mbi.BeaconEntries[i]
+	}
+
+	blockDelay := m.Node.Repo().Config().NetworkParams.BlockDelay
+	return m.Node.Mining().API().MinerCreateBlock(ctx, &apitypes.BlockTemplate{
+		Miner:        m.Address,
+		Parents:      base.Key(),
+		Ticket:       ticket,
+		Eproof:       eproof,
+		BeaconValues: beaconValues,
+		Messages:     msgs,
+		Epoch:        round,
+		Timestamp:    base.MinTimestamp() + blockDelay*uint64(round-base.Height()),
+		// the proof is accepted by mockProofVerifier
+		WinningPoStProof: []proof2.PoStProof{{
+			PoStProof:  abi.RegisteredPoStProof_StackedDrgWinning2KiBV1,
+			ProofBytes: []byte("valid proof"),
+		}},
+	})
+}
@@ -0,0 +1,40 @@
+package ensemble
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	proof5 "github.com/filecoin-project/specs-actors/v5/actors/runtime/proof"
+
+	"github.com/filecoin-project/venus/pkg/util/ffiwrapper"
+)
+
+// mockProofVerifier accepts every seal and post proof, and always challenges the first
+// proving sector for the winning post. It lets the miners of an ensemble, which only hold
+// mock sealed sectors, mine.
+type mockProofVerifier struct{}
+
+var _ ffiwrapper.Verifier = (*mockProofVerifier)(nil)
+
+func (mockProofVerifier) VerifySeal(proof5.SealVerifyInfo) (bool, error) {
+	return true, nil
+}
+
+func (mockProofVerifier) VerifyAggregateSeals(proof5.AggregateSealVerifyProofAndInfos) (bool, error) {
+	return true, nil
+}
+
+func (mockProofVerifier) VerifyWinningPoSt(context.Context, proof5.WinningPoStVerifyInfo) (bool, error) {
+	return true, nil
+}
+
+func (mockProofVerifier) VerifyWindowPoSt(context.Context, proof5.WindowPoStVerifyInfo) (bool, error) {
+	return true, nil
+}
+
+func (mockProofVerifier) GenerateWinningPoStSectorChallenge(_ context.Context, _ abi.RegisteredPoStProof, _ abi.ActorID, _ abi.PoStRandomness, eligibleSectorCount uint64) ([]uint64, error) {
+	if eligibleSectorCount == 0 {
+		return nil, nil
+	}
+	return []uint64{0}, nil
+}
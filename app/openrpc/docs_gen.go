@@ -0,0 +1,378 @@
+// Code generated by github.com/filecoin-project/venus/tools/gen/api. DO NOT EDIT.
+
+package openrpc
+
+var methodDocs = map[string]map[string]methodDoc{
+	"v0": {
+		"IAccount.StateAccountKey":                       {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IActor.ListActor":                               {Doc: "", Params: []string{"ctx"}},
+		"IActor.StateGetActor":                           {Doc: "", Params: []string{"ctx", "actor", "tsk"}},
+		"IBeacon.BeaconGetEntry":                         {Doc: "", Params: []string{"ctx", "epoch"}},
+		"IBlockService.DAGCat":                           {Doc: "", Params: []string{"ctx", "c"}},
+		"IBlockService.DAGGetFileSize":                   {Doc: "", Params: []string{"ctx", "c"}},
+		"IBlockService.DAGGetNode":                       {Doc: "", Params: []string{"ctx", "ref"}},
+		"IBlockService.DAGImportData":                    {Doc: "", Params: []string{"ctx", "data"}},
+		"IBlockStore.ChainDeleteObj":                     {Doc: "", Params: []string{"ctx", "obj"}},
+		"IBlockStore.ChainHasObj":                        {Doc: "", Params: []string{"ctx", "obj"}},
+		"IBlockStore.ChainReadObj":                       {Doc: "", Params: []string{"ctx", "ocid"}},
+		"IBlockStore.ChainStatObj":                       {Doc: "", Params: []string{"ctx", "obj", "base"}},
+		"IChainInfo.BlockTime":                           {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ChainGasStats":                       {Doc: "ChainGasStats aggregates the gas used and the fees paid by the messages of the tipsets from the height `from` to `to`, by type of receiver and method, and by sender", Params: []string{"ctx", "from", "to"}},
+		"IChainInfo.ChainGetBlock":                       {Doc: "", Params: []string{"ctx", "id"}},
+		"IChainInfo.ChainGetBlockMessages":               {Doc: "", Params: []string{"ctx", "bid"}},
+		"IChainInfo.ChainGetMessage":                     {Doc: "", Params: []string{"ctx", "msgID"}},
+		"IChainInfo.ChainGetParentMessages":              {Doc: "", Params: []string{"ctx", "bcid"}},
+		"IChainInfo.ChainGetParentReceipts":              {Doc: "", Params: []string{"ctx", "bcid"}},
+		"IChainInfo.ChainGetRandomnessFromBeacon":        {Doc: "", Params: []string{"ctx", "key", "personalization", "randEpoch", "entropy"}},
+		"IChainInfo.ChainGetRandomnessFromTickets":       {Doc: "", Params: []string{"ctx", "tsk", "personalization", "randEpoch", "entropy"}},
+		"IChainInfo.ChainGetReceipts":                    {Doc: "", Params: []string{"ctx", "id"}},
+		"IChainInfo.ChainGetTipSet":                      {Doc: "", Params: []string{"ctx", "key"}},
+		"IChainInfo.ChainGetTipSetByHeight":              {Doc: "", Params: []string{"ctx", "height", "tsk"}},
+		"IChainInfo.ChainHead":                           {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ChainList":                           {Doc: "", Params: []string{"ctx", "tsKey", "count"}},
+		"IChainInfo.ChainNotify":                         {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ChainSetHead":                        {Doc: "", Params: []string{"ctx", "key"}},
+		"IChainInfo.ChainWatchActor":                     {Doc: "ChainWatchActor notifies the changes of the actor `addr` once they are `confidence` epochs deep in the chain, and their reverts", Params: []string{"ctx", "addr", "confidence"}},
+		"IChainInfo.ChainWatchHeight":                    {Doc: "ChainWatchHeight notifies when the chain reaches the height `h` by `confidence` epochs, and when it is rolled back under it", Params: []string{"ctx", "h", "confidence"}},
+		"IChainInfo.ChainWatchMessages":                  {Doc: "ChainWatchMessages notifies the messages matching `match` once they are `confidence` epochs deep in the chain, and their reverts", Params: []string{"ctx", "match", "confidence"}},
+		"IChainInfo.GetActor":                            {Doc: "", Params: []string{"ctx", "addr"}},
+		"IChainInfo.GetEntry":                            {Doc: "", Params: []string{"ctx", "height", "round"}},
+		"IChainInfo.GetFullBlock":                        {Doc: "", Params: []string{"ctx", "id"}},
+		"IChainInfo.GetParentStateRootActor":             {Doc: "", Params: []string{"ctx", "ts", "addr"}},
+		"IChainInfo.MessageWait":                         {Doc: "", Params: []string{"ctx", "msgCid", "confidence", "lookback"}},
+		"IChainInfo.ProtocolParameters":                  {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ResolveToKeyAddr":                    {Doc: "", Params: []string{"ctx", "addr", "ts"}},
+		"IChainInfo.StateGetReceipt":                     {Doc: "", Params: []string{"ctx", "msg", "from"}},
+		"IChainInfo.StateNetworkName":                    {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.StateNetworkVersion":                 {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IChainInfo.StateSearchMsg":                      {Doc: "", Params: []string{"ctx", "msg"}},
+		"IChainInfo.StateSearchMsgLimited":               {Doc: "", Params: []string{"ctx", "cid", "limit"}},
+		"IChainInfo.StateWaitMsg":                        {Doc: "", Params: []string{"ctx", "cid", "confidence"}},
+		"IChainInfo.StateWaitMsgLimited":                 {Doc: "", Params: []string{"ctx", "cid", "confidence", "limit"}},
+		"IChainInfo.VerifyEntry":                         {Doc: "", Params: []string{"parent", "child", "height"}},
+		"IConfig.ConfigGet":                              {Doc: "", Params: []string{"ctx", "dottedPath"}},
+		"IConfig.ConfigSet":                              {Doc: "", Params: []string{"ctx", "dottedPath", "paramJSON"}},
+		"IJwtAuthAPI.AuthNew":                            {Doc: "", Params: []string{"ctx", "perms"}},
+		"IJwtAuthAPI.Verify":                             {Doc: "", Params: []string{"ctx", "host", "token"}},
+		"IMarket.StateMarketParticipants":                {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMessagePool.GasBatchEstimateMessageGas":        {Doc: "", Params: []string{"ctx", "estimateMessages", "fromNonce", "tsk"}},
+		"IMessagePool.GasEstimateFeeCap":                 {Doc: "", Params: []string{"ctx", "msg", "maxqueueblks", "tsk"}},
+		"IMessagePool.GasEstimateGasLimit":               {Doc: "", Params: []string{"ctx", "msgIn", "tsk"}},
+		"IMessagePool.GasEstimateGasPremium":             {Doc: "", Params: []string{"ctx", "nblocksincl", "sender", "gaslimit", "tsk"}},
+		"IMessagePool.GasEstimateInclusion":              {Doc: "GasEstimateInclusion returns the gas premiums and fee caps with which a message should be included within each number of epochs of `targets`", Params: []string{"ctx", "gaslimit", "targets", "tsk"}},
+		"IMessagePool.GasEstimateMessageGas":             {Doc: "", Params: []string{"ctx", "msg", "spec", "tsk"}},
+		"IMessagePool.GasFeeHistory":                     {Doc: "GasFeeHistory returns the base fees and the gas premium percentiles of the last `blocks` tipsets", Params: []string{"ctx", "blocks", "percentiles", "tsk"}},
+		"IMessagePool.MpoolBatchPush":                    {Doc: "", Params: []string{"ctx", "smsgs"}},
+		"IMessagePool.MpoolBatchPushMessage":             {Doc: "", Params: []string{"ctx", "msgs", "spec"}},
+		"IMessagePool.MpoolBatchPushUntrusted":           {Doc: "", Params: []string{"ctx", "smsgs"}},
+		"IMessagePool.MpoolCheckMessages":                {Doc: "MpoolCheckMessages performs logical checks on a batch of messages", Params: []string{"ctx", "protos"}},
+		"IMessagePool.MpoolCheckPendingMessages":         {Doc: "MpoolCheckPendingMessages performs logical checks for all pending messages from a given address", Params: []string{"ctx", "addr"}},
+		"IMessagePool.MpoolCheckReplaceMessages":         {Doc: "MpoolCheckReplaceMessages performs logical checks on pending messages with replacement", Params: []string{"ctx", "msg"}},
+		"IMessagePool.MpoolClear":                        {Doc: "", Params: []string{"ctx", "local"}},
+		"IMessagePool.MpoolDeleteByAdress":               {Doc: "", Params: []string{"ctx", "addr"}},
+		"IMessagePool.MpoolGetConfig":                    {Doc: "", Params: []string{"p0"}},
+		"IMessagePool.MpoolGetNonce":                     {Doc: "", Params: []string{"ctx", "addr"}},
+		"IMessagePool.MpoolPending":                      {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMessagePool.MpoolPublishByAddr":                {Doc: "", Params: []string{"p0", "p1"}},
+		"IMessagePool.MpoolPublishMessage":               {Doc: "", Params: []string{"ctx", "smsg"}},
+		"IMessagePool.MpoolPush":                         {Doc: "", Params: []string{"ctx", "smsg"}},
+		"IMessagePool.MpoolPushMessage":                  {Doc: "", Params: []string{"ctx", "msg", "spec"}},
+		"IMessagePool.MpoolPushUntrusted":                {Doc: "", Params: []string{"ctx", "smsg"}},
+		"IMessagePool.MpoolSelect":                       {Doc: "", Params: []string{"p0", "p1", "p2"}},
+		"IMessagePool.MpoolSelects":                      {Doc: "", Params: []string{"p0", "p1", "p2"}},
+		"IMessagePool.MpoolSetConfig":                    {Doc: "", Params: []string{"ctx", "cfg"}},
+		"IMessagePool.MpoolSub":                          {Doc: "", Params: []string{"ctx"}},
+		"IMinerState.StateCirculatingSupply":             {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateListActors":                    {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateListMiners":                    {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateLookupID":                      {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMarketBalance":                 {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMarketDeals":                   {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateMarketDealsQuery":              {Doc: "", Params: []string{"ctx", "tsk", "filter", "cursor", "limit"}},
+		"IMinerState.StateMarketStorageDeal":             {Doc: "", Params: []string{"ctx", "dealID", "tsk"}},
+		"IMinerState.StateMinerActiveSectors":            {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerAvailableBalance":         {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerDeadlines":                {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerFaults":                   {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerHealth":                   {Doc: "StateMinerHealth reports the state of the deadlines and sectors of a miner and warns about the risks to its power.", Params: []string{"ctx", "maddr", "expiryWindow", "tsk"}},
+		"IMinerState.StateMinerInfo":                     {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerInitialPledgeCollateral":  {Doc: "", Params: []string{"ctx", "maddr", "pci", "tsk"}},
+		"IMinerState.StateMinerPartitions":               {Doc: "", Params: []string{"ctx", "maddr", "dlIdx", "tsk"}},
+		"IMinerState.StateMinerPower":                    {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMinerPowerAll":                 {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateMinerPreCommitDepositForPower": {Doc: "", Params: []string{"ctx", "maddr", "pci", "tsk"}},
+		"IMinerState.StateMinerProvingDeadline":          {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerRecoveries":               {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerSectorAllocated":          {Doc: "", Params: []string{"ctx", "maddr", "s", "tsk"}},
+		"IMinerState.StateMinerSectorCount":              {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMinerSectorSize":               {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerSectors":                  {Doc: "", Params: []string{"ctx", "maddr", "sectorNos", "tsk"}},
+		"IMinerState.StateMinerWorkerAddress":            {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinersSummary":                 {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateSectorExpiration":              {Doc: "", Params: []string{"ctx", "maddr", "sectorNumber", "tsk"}},
+		"IMinerState.StateSectorGetInfo":                 {Doc: "", Params: []string{"ctx", "maddr", "n", "tsk"}},
+		"IMinerState.StateSectorPartition":               {Doc: "", Params: []string{"ctx", "maddr", "sectorNumber", "tsk"}},
+		"IMinerState.StateSectorPreCommitInfo":           {Doc: "", Params: []string{"ctx", "maddr", "n", "tsk"}},
+		"IMinerState.StateTokenomics":                    {Doc: "StateTokenomics breaks down the supply of FIL and the funds locked, owed and burnt at a tipset.", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateVMCirculatingSupplyInternal":   {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMining.MinerCreateBlock":                       {Doc: "", Params: []string{"ctx", "bt"}},
+		"IMining.MinerGetBaseInfo":                       {Doc: "", Params: []string{"ctx", "maddr", "round", "tsk"}},
+		"IMultiSig.MsigAddApprove":                       {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "proposer", "newAdd", "inc"}},
+		"IMultiSig.MsigAddCancel":                        {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "newAdd", "inc"}},
+		"IMultiSig.MsigAddPropose":                       {Doc: "", Params: []string{"ctx", "msig", "src", "newAdd", "inc"}},
+		"IMultiSig.MsigApprove":                          {Doc: "", Params: []string{"ctx", "msig", "txID", "src"}},
+		"IMultiSig.MsigApproveTxnHash":                   {Doc: "", Params: []string{"ctx", "msig", "txID", "proposer", "to", "amt", "src", "method", "params"}},
+		"IMultiSig.MsigCancel":                           {Doc: "", Params: []string{"ctx", "msig", "txID", "to", "amt", "src", "method", "params"}},
+		"IMultiSig.MsigCreate":                           {Doc: "MsigCreate creates a multisig wallet It takes the following params: <required number of senders>, <approving addresses>, <unlock duration> <initial balance>, <sender address of the create msg>, <gas price>", Params: []string{"p0", "p1", "p2", "p3", "p4", "p5", "p6"}},
+		"IMultiSig.MsigGetVested":                        {Doc: "", Params: []string{"ctx", "addr", "start", "end"}},
+		"IMultiSig.MsigPropose":                          {Doc: "", Params: []string{"ctx", "msig", "to", "amt", "src", "method", "params"}},
+		"IMultiSig.MsigRemoveSigner":                     {Doc: "", Params: []string{"ctx", "msig", "proposer", "toRemove", "decrease"}},
+		"IMultiSig.MsigSwapApprove":                      {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "proposer", "oldAdd", "newAdd"}},
+		"IMultiSig.MsigSwapCancel":                       {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "oldAdd", "newAdd"}},
+		"IMultiSig.MsigSwapPropose":                      {Doc: "", Params: []string{"ctx", "msig", "src", "oldAdd", "newAdd"}},
+		"INetwork.NetAddrsListen":                        {Doc: "", Params: []string{"p0"}},
+		"INetwork.NetworkConnect":                        {Doc: "", Params: []string{"ctx", "addrs"}},
+		"INetwork.NetworkFindPeer":                       {Doc: "", Params: []string{"ctx", "peerID"}},
+		"INetwork.NetworkFindProvidersAsync":             {Doc: "", Params: []string{"ctx", "key", "count"}},
+		"INetwork.NetworkGetBandwidthStats":              {Doc: "", Params: []string{"ctx"}},
+		"INetwork.NetworkGetClosestPeers":                {Doc: "", Params: []string{"ctx", "key"}},
+		"INetwork.NetworkGetPeerAddresses":               {Doc: "", Params: []string{"ctx"}},
+		"INetwork.NetworkGetPeerID":                      {Doc: "", Params: []string{"ctx"}},
+		"INetwork.NetworkPeers":                          {Doc: "", Params: []string{"ctx", "verbose", "latency", "streams"}},
+		"INetwork.Version":                               {Doc: "", Params: []string{"p0"}},
+		"IPaychan.PaychAllocateLane":                     {Doc: "PaychAllocateLane Allocate late creates a lane within a payment channel so that calls to CreatePaymentVoucher will automatically make vouchers only for the difference in total", Params: []string{"ctx", "ch"}},
+		"IPaychan.PaychAvailableFunds":                   {Doc: "PaychAvailableFunds get the status of an outbound payment channel @pch: payment channel address", Params: []string{"ctx", "ch"}},
+		"IPaychan.PaychAvailableFundsByFromTo":           {Doc: "PaychAvailableFundsByFromTo  get the status of an outbound payment channel @from: the payment channel sender @to: he payment channel recipient", Params: []string{"ctx", "from", "to"}},
+		"IPaychan.PaychCollect":                          {Doc: "PaychCollect update payment channel status to collect Collect sends the value of submitted vouchers to the channel recipient (the provider), and refunds the remaining channel balance to the channel creator (the client). @pch: payment channel address", Params: []string{"ctx", "addr"}},
+		"IPaychan.PaychGet":                              {Doc: "PaychGet creates a payment channel to a provider with a amount of FIL @from: the payment channel sender @to: the payment channel recipient @amt: the deposits funds in the payment channel", Params: []string{"ctx", "from", "to", "amt"}},
+		"IPaychan.PaychGetWaitReady":                     {Doc: "PaychGetWaitReady waits until the create channel / add funds message with the sentinel @sentinel: given message CID arrives. @ch: the returned channel address can safely be used against the Manager methods.", Params: []string{"ctx", "sentinel"}},
+		"IPaychan.PaychList":                             {Doc: "PaychList list the addresses of all channels that have been created", Params: []string{"ctx"}},
+		"IPaychan.PaychNewPayment":                       {Doc: "PaychNewPayment aggregate vouchers into a new lane @from: the payment channel sender @to: the payment channel recipient @vouchers: the outstanding (non-redeemed) vouchers", Params: []string{"ctx", "from", "to", "vouchers"}},
+		"IPaychan.PaychSettle":                           {Doc: "PaychSettle update payment channel status to settle After a settlement period (currently 12 hours) either party to the payment channel can call collect on chain @pch: payment channel address", Params: []string{"ctx", "addr"}},
+		"IPaychan.PaychStatus":                           {Doc: "PaychStatus get the payment channel status @pch: payment channel address", Params: []string{"ctx", "pch"}},
+		"IPaychan.PaychVoucherAdd":                       {Doc: "PaychVoucherAdd adds a voucher for an inbound channel. If the channel is not in the store, fetches the channel from state (and checks that the channel To address is owned by the wallet).", Params: []string{"ctx", "ch", "sv", "proof", "minDelta"}},
+		"IPaychan.PaychVoucherCheckSpendable":            {Doc: "PaychVoucherCheckSpendable checks if the given voucher is currently spendable @pch: payment channel address @sv: voucher", Params: []string{"ctx", "ch", "sv", "secret", "proof"}},
+		"IPaychan.PaychVoucherCheckValid":                {Doc: "PaychVoucherCheckValid checks if the given voucher is valid (is or could become spendable at some point). If the channel is not in the store, fetches the channel from state (and checks that the channel To address is owned by the wallet). @pch: payment channel address @sv: voucher", Params: []string{"ctx", "ch", "sv"}},
+		"IPaychan.PaychVoucherCreate":                    {Doc: "PaychVoucherCreate creates a new signed voucher on the given payment channel with the given lane and amount.  The value passed in is exactly the value that will be used to create the voucher, so if previous vouchers exist, the actual additional value of this voucher will only be the difference between the two. If there are insufficient funds in the channel to create the voucher, returns a nil voucher and the shortfall.", Params: []string{"ctx", "pch", "amt", "lane"}},
+		"IPaychan.PaychVoucherList":                      {Doc: "PaychVoucherList list vouchers in payment channel @pch: payment channel address", Params: []string{"ctx", "pch"}},
+		"IPaychan.PaychVoucherSubmit":                    {Doc: "PaychVoucherSubmit Submit voucher to chain to update payment channel state @pch: payment channel address @sv: voucher in payment channel", Params: []string{"ctx", "ch", "sv", "secret", "proof"}},
+		"IRPC.Discover":                                  {Doc: "Discover returns the OpenRPC document of the api, it is also served as rpc.discover", Params: []string{"ctx"}},
+		"ISyncer.ChainCheck":                             {Doc: "", Params: []string{"ctx", "tsk", "opts"}},
+		"ISyncer.ChainSyncHandleNewTipSet":               {Doc: "", Params: []string{"ctx", "ci"}},
+		"ISyncer.ChainTipSetWeight":                      {Doc: "", Params: []string{"ctx", "tsk"}},
+		"ISyncer.Concurrent":                             {Doc: "", Params: []string{"ctx"}},
+		"ISyncer.JournalQuery":                           {Doc: "", Params: []string{"ctx", "system", "event", "since"}},
+		"ISyncer.SetConcurrent":                          {Doc: "", Params: []string{"ctx", "concurrent"}},
+		"ISyncer.StateCall":                              {Doc: "", Params: []string{"ctx", "msg", "tsk"}},
+		"ISyncer.SyncState":                              {Doc: "", Params: []string{"ctx"}},
+		"ISyncer.SyncSubmitBlock":                        {Doc: "", Params: []string{"ctx", "blk"}},
+		"ISyncer.SyncerTracker":                          {Doc: "", Params: []string{"ctx"}},
+		"IWallet.HasPassword":                            {Doc: "", Params: []string{"Context"}},
+		"IWallet.LockWallet":                             {Doc: "", Params: []string{"ctx"}},
+		"IWallet.SetPassword":                            {Doc: "", Params: []string{"Context", "password"}},
+		"IWallet.UnLockWallet":                           {Doc: "", Params: []string{"ctx", "password"}},
+		"IWallet.WalletAddresses":                        {Doc: "", Params: []string{"ctx"}},
+		"IWallet.WalletBalance":                          {Doc: "not exists in remote", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletChangePassword":                   {Doc: "", Params: []string{"ctx", "oldPassword", "newPassword"}},
+		"IWallet.WalletDefaultAddress":                   {Doc: "not exists in remote", Params: []string{"ctx"}},
+		"IWallet.WalletDelete":                           {Doc: "", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletDeriveAddress":                    {Doc: "", Params: []string{"ctx", "protocol", "index"}},
+		"IWallet.WalletExport":                           {Doc: "", Params: []string{"addr", "password"}},
+		"IWallet.WalletHas":                              {Doc: "", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletImport":                           {Doc: "", Params: []string{"key"}},
+		"IWallet.WalletNewAddress":                       {Doc: "", Params: []string{"protocol"}},
+		"IWallet.WalletNewHDAddress":                     {Doc: "", Params: []string{"ctx", "protocol"}},
+		"IWallet.WalletReencrypt":                        {Doc: "", Params: []string{"ctx", "password", "scryptN", "scryptP"}},
+		"IWallet.WalletRestoreMnemonic":                  {Doc: "", Params: []string{"ctx", "mnemonic"}},
+		"IWallet.WalletSetDefault":                       {Doc: "not exists in remote", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletSign":                             {Doc: "", Params: []string{"ctx", "k", "msg", "meta"}},
+		"IWallet.WalletSignMessage":                      {Doc: "", Params: []string{"ctx", "k", "msg"}},
+		"IWallet.WalletState":                            {Doc: "", Params: []string{"Context"}},
+		"IWallet.WalletUndelete":                         {Doc: "", Params: []string{"ctx", "addr"}},
+	},
+	"v1": {
+		"IAccount.StateAccountKey":                       {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IActor.ListActor":                               {Doc: "", Params: []string{"ctx"}},
+		"IActor.StateGetActor":                           {Doc: "", Params: []string{"ctx", "actor", "tsk"}},
+		"IBeacon.BeaconGetEntry":                         {Doc: "", Params: []string{"ctx", "epoch"}},
+		"IBlockService.DAGCat":                           {Doc: "", Params: []string{"ctx", "c"}},
+		"IBlockService.DAGGetFileSize":                   {Doc: "", Params: []string{"ctx", "c"}},
+		"IBlockService.DAGGetNode":                       {Doc: "", Params: []string{"ctx", "ref"}},
+		"IBlockService.DAGImportData":                    {Doc: "", Params: []string{"ctx", "data"}},
+		"IBlockStore.ChainDeleteObj":                     {Doc: "", Params: []string{"ctx", "obj"}},
+		"IBlockStore.ChainHasObj":                        {Doc: "", Params: []string{"ctx", "obj"}},
+		"IBlockStore.ChainReadObj":                       {Doc: "", Params: []string{"ctx", "ocid"}},
+		"IBlockStore.ChainStatObj":                       {Doc: "", Params: []string{"ctx", "obj", "base"}},
+		"IChainInfo.BlockTime":                           {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ChainGasStats":                       {Doc: "ChainGasStats aggregates the gas used and the fees paid by the messages of the tipsets from the height `from` to `to`, by type of receiver and method, and by sender", Params: []string{"ctx", "from", "to"}},
+		"IChainInfo.ChainGetBlock":                       {Doc: "", Params: []string{"ctx", "id"}},
+		"IChainInfo.ChainGetBlockMessages":               {Doc: "", Params: []string{"ctx", "bid"}},
+		"IChainInfo.ChainGetMessage":                     {Doc: "", Params: []string{"ctx", "msgID"}},
+		"IChainInfo.ChainGetMessagesInTipset":            {Doc: "", Params: []string{"ctx", "key"}},
+		"IChainInfo.ChainGetParentMessages":              {Doc: "", Params: []string{"ctx", "bcid"}},
+		"IChainInfo.ChainGetParentReceipts":              {Doc: "", Params: []string{"ctx", "bcid"}},
+		"IChainInfo.ChainGetRandomnessFromBeacon":        {Doc: "", Params: []string{"ctx", "key", "personalization", "randEpoch", "entropy"}},
+		"IChainInfo.ChainGetRandomnessFromTickets":       {Doc: "", Params: []string{"ctx", "tsk", "personalization", "randEpoch", "entropy"}},
+		"IChainInfo.ChainGetReceipts":                    {Doc: "", Params: []string{"ctx", "id"}},
+		"IChainInfo.ChainGetTipSet":                      {Doc: "", Params: []string{"ctx", "key"}},
+		"IChainInfo.ChainGetTipSetByHeight":              {Doc: "", Params: []string{"ctx", "height", "tsk"}},
+		"IChainInfo.ChainHead":                           {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ChainList":                           {Doc: "", Params: []string{"ctx", "tsKey", "count"}},
+		"IChainInfo.ChainNotify":                         {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ChainSetHead":                        {Doc: "", Params: []string{"ctx", "key"}},
+		"IChainInfo.ChainWatchActor":                     {Doc: "ChainWatchActor notifies the changes of the actor `addr` once they are `confidence` epochs deep in the chain, and their reverts", Params: []string{"ctx", "addr", "confidence"}},
+		"IChainInfo.ChainWatchHeight":                    {Doc: "ChainWatchHeight notifies when the chain reaches the height `h` by `confidence` epochs, and when it is rolled back under it", Params: []string{"ctx", "h", "confidence"}},
+		"IChainInfo.ChainWatchMessages":                  {Doc: "ChainWatchMessages notifies the messages matching `match` once they are `confidence` epochs deep in the chain, and their reverts", Params: []string{"ctx", "match", "confidence"}},
+		"IChainInfo.GetActor":                            {Doc: "", Params: []string{"ctx", "addr"}},
+		"IChainInfo.GetEntry":                            {Doc: "", Params: []string{"ctx", "height", "round"}},
+		"IChainInfo.GetFullBlock":                        {Doc: "", Params: []string{"ctx", "id"}},
+		"IChainInfo.GetParentStateRootActor":             {Doc: "", Params: []string{"ctx", "ts", "addr"}},
+		"IChainInfo.MessageWait":                         {Doc: "", Params: []string{"ctx", "msgCid", "confidence", "lookback"}},
+		"IChainInfo.ProtocolParameters":                  {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.ResolveToKeyAddr":                    {Doc: "", Params: []string{"ctx", "addr", "ts"}},
+		"IChainInfo.StateNetworkName":                    {Doc: "", Params: []string{"ctx"}},
+		"IChainInfo.StateNetworkVersion":                 {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IChainInfo.StateSearchMsg":                      {Doc: "StateSearchMsg looks back up to limit epochs in the chain for a message, and returns its receipt and the tipset where it was executed NOTE: If a replacing message is found on chain, this method will return a MsgLookup for the replacing message - the MsgLookup.Message will be a different CID than the one provided in the 'cid' param, MsgLookup.Receipt will contain the result of the execution of the replacing message. If the caller wants to ensure that exactly the requested message was executed, they must check that MsgLookup.Message is equal to the provided 'cid', or set the `allowReplaced` parameter to false. Without this check, and with `allowReplaced` set to true, both the requested and original message may appear as successfully executed on-chain, which may look like a double-spend. A replacing message is a message with a different CID, any of Gas values, and different signature, but with all other parameters matching (source/destination, nonce, params, etc.)", Params: []string{"ctx", "from", "msg", "limit", "allowReplaced"}},
+		"IChainInfo.StateWaitMsg":                        {Doc: "StateWaitMsg looks back up to limit epochs in the chain for a message. If not found, it blocks until the message arrives on chain, and gets to the indicated confidence depth. NOTE: If a replacing message is found on chain, this method will return a MsgLookup for the replacing message - the MsgLookup.Message will be a different CID than the one provided in the 'cid' param, MsgLookup.Receipt will contain the result of the execution of the replacing message. If the caller wants to ensure that exactly the requested message was executed, they must check that MsgLookup.Message is equal to the provided 'cid', or set the `allowReplaced` parameter to false. Without this check, and with `allowReplaced` set to true, both the requested and original message may appear as successfully executed on-chain, which may look like a double-spend. A replacing message is a message with a different CID, any of Gas values, and different signature, but with all other parameters matching (source/destination, nonce, params, etc.)", Params: []string{"ctx", "cid", "confidence", "limit", "allowReplaced"}},
+		"IChainInfo.VerifyEntry":                         {Doc: "", Params: []string{"parent", "child", "height"}},
+		"IConfig.ConfigGet":                              {Doc: "", Params: []string{"ctx", "dottedPath"}},
+		"IConfig.ConfigSet":                              {Doc: "", Params: []string{"ctx", "dottedPath", "paramJSON"}},
+		"IJwtAuthAPI.AuthNew":                            {Doc: "", Params: []string{"ctx", "perms"}},
+		"IJwtAuthAPI.Verify":                             {Doc: "", Params: []string{"ctx", "host", "token"}},
+		"IMarket.StateMarketParticipants":                {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMessagePool.GasBatchEstimateMessageGas":        {Doc: "", Params: []string{"ctx", "estimateMessages", "fromNonce", "tsk"}},
+		"IMessagePool.GasEstimateFeeCap":                 {Doc: "", Params: []string{"ctx", "msg", "maxqueueblks", "tsk"}},
+		"IMessagePool.GasEstimateGasLimit":               {Doc: "", Params: []string{"ctx", "msgIn", "tsk"}},
+		"IMessagePool.GasEstimateGasPremium":             {Doc: "", Params: []string{"ctx", "nblocksincl", "sender", "gaslimit", "tsk"}},
+		"IMessagePool.GasEstimateInclusion":              {Doc: "GasEstimateInclusion returns the gas premiums and fee caps with which a message should be included within each number of epochs of `targets`", Params: []string{"ctx", "gaslimit", "targets", "tsk"}},
+		"IMessagePool.GasEstimateMessageGas":             {Doc: "", Params: []string{"ctx", "msg", "spec", "tsk"}},
+		"IMessagePool.GasFeeHistory":                     {Doc: "GasFeeHistory returns the base fees and the gas premium percentiles of the last `blocks` tipsets", Params: []string{"ctx", "blocks", "percentiles", "tsk"}},
+		"IMessagePool.MpoolBatchPush":                    {Doc: "", Params: []string{"ctx", "smsgs"}},
+		"IMessagePool.MpoolBatchPushMessage":             {Doc: "", Params: []string{"ctx", "msgs", "spec"}},
+		"IMessagePool.MpoolBatchPushUntrusted":           {Doc: "", Params: []string{"ctx", "smsgs"}},
+		"IMessagePool.MpoolCheckMessages":                {Doc: "MpoolCheckMessages performs logical checks on a batch of messages", Params: []string{"ctx", "protos"}},
+		"IMessagePool.MpoolCheckPendingMessages":         {Doc: "MpoolCheckPendingMessages performs logical checks for all pending messages from a given address", Params: []string{"ctx", "addr"}},
+		"IMessagePool.MpoolCheckReplaceMessages":         {Doc: "MpoolCheckReplaceMessages performs logical checks on pending messages with replacement", Params: []string{"ctx", "msg"}},
+		"IMessagePool.MpoolClear":                        {Doc: "", Params: []string{"ctx", "local"}},
+		"IMessagePool.MpoolDeleteByAdress":               {Doc: "", Params: []string{"ctx", "addr"}},
+		"IMessagePool.MpoolGetConfig":                    {Doc: "", Params: []string{"p0"}},
+		"IMessagePool.MpoolGetNonce":                     {Doc: "", Params: []string{"ctx", "addr"}},
+		"IMessagePool.MpoolPending":                      {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMessagePool.MpoolPublishByAddr":                {Doc: "", Params: []string{"p0", "p1"}},
+		"IMessagePool.MpoolPublishMessage":               {Doc: "", Params: []string{"ctx", "smsg"}},
+		"IMessagePool.MpoolPush":                         {Doc: "", Params: []string{"ctx", "smsg"}},
+		"IMessagePool.MpoolPushMessage":                  {Doc: "", Params: []string{"ctx", "msg", "spec"}},
+		"IMessagePool.MpoolPushUntrusted":                {Doc: "", Params: []string{"ctx", "smsg"}},
+		"IMessagePool.MpoolSelect":                       {Doc: "", Params: []string{"p0", "p1", "p2"}},
+		"IMessagePool.MpoolSelects":                      {Doc: "", Params: []string{"p0", "p1", "p2"}},
+		"IMessagePool.MpoolSetConfig":                    {Doc: "", Params: []string{"ctx", "cfg"}},
+		"IMessagePool.MpoolSub":                          {Doc: "", Params: []string{"ctx"}},
+		"IMinerState.StateCirculatingSupply":             {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateListActors":                    {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateListMiners":                    {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateLookupID":                      {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMarketBalance":                 {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMarketDeals":                   {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateMarketDealsQuery":              {Doc: "", Params: []string{"ctx", "tsk", "filter", "cursor", "limit"}},
+		"IMinerState.StateMarketStorageDeal":             {Doc: "", Params: []string{"ctx", "dealID", "tsk"}},
+		"IMinerState.StateMinerActiveSectors":            {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerAvailableBalance":         {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerDeadlines":                {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerFaults":                   {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerHealth":                   {Doc: "StateMinerHealth reports the state of the deadlines and sectors of a miner and warns about the risks to its power.", Params: []string{"ctx", "maddr", "expiryWindow", "tsk"}},
+		"IMinerState.StateMinerInfo":                     {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerInitialPledgeCollateral":  {Doc: "", Params: []string{"ctx", "maddr", "pci", "tsk"}},
+		"IMinerState.StateMinerPartitions":               {Doc: "", Params: []string{"ctx", "maddr", "dlIdx", "tsk"}},
+		"IMinerState.StateMinerPower":                    {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMinerPowerAll":                 {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateMinerPreCommitDepositForPower": {Doc: "", Params: []string{"ctx", "maddr", "pci", "tsk"}},
+		"IMinerState.StateMinerProvingDeadline":          {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerRecoveries":               {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerSectorAllocated":          {Doc: "", Params: []string{"ctx", "maddr", "s", "tsk"}},
+		"IMinerState.StateMinerSectorCount":              {Doc: "", Params: []string{"ctx", "addr", "tsk"}},
+		"IMinerState.StateMinerSectorSize":               {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinerSectors":                  {Doc: "", Params: []string{"ctx", "maddr", "sectorNos", "tsk"}},
+		"IMinerState.StateMinerWorkerAddress":            {Doc: "", Params: []string{"ctx", "maddr", "tsk"}},
+		"IMinerState.StateMinersSummary":                 {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateSectorExpiration":              {Doc: "", Params: []string{"ctx", "maddr", "sectorNumber", "tsk"}},
+		"IMinerState.StateSectorGetInfo":                 {Doc: "", Params: []string{"ctx", "maddr", "n", "tsk"}},
+		"IMinerState.StateSectorPartition":               {Doc: "", Params: []string{"ctx", "maddr", "sectorNumber", "tsk"}},
+		"IMinerState.StateSectorPreCommitInfo":           {Doc: "", Params: []string{"ctx", "maddr", "n", "tsk"}},
+		"IMinerState.StateTokenomics":                    {Doc: "StateTokenomics breaks down the supply of FIL and the funds locked, owed and burnt at a tipset.", Params: []string{"ctx", "tsk"}},
+		"IMinerState.StateVMCirculatingSupplyInternal":   {Doc: "", Params: []string{"ctx", "tsk"}},
+		"IMining.MinerCreateBlock":                       {Doc: "", Params: []string{"ctx", "bt"}},
+		"IMining.MinerGetBaseInfo":                       {Doc: "", Params: []string{"ctx", "maddr", "round", "tsk"}},
+		"IMultiSig.MsigAddApprove":                       {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "proposer", "newAdd", "inc"}},
+		"IMultiSig.MsigAddCancel":                        {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "newAdd", "inc"}},
+		"IMultiSig.MsigAddPropose":                       {Doc: "", Params: []string{"ctx", "msig", "src", "newAdd", "inc"}},
+		"IMultiSig.MsigApprove":                          {Doc: "", Params: []string{"ctx", "msig", "txID", "src"}},
+		"IMultiSig.MsigApproveTxnHash":                   {Doc: "", Params: []string{"ctx", "msig", "txID", "proposer", "to", "amt", "src", "method", "params"}},
+		"IMultiSig.MsigCancel":                           {Doc: "", Params: []string{"ctx", "msig", "txID", "to", "amt", "src", "method", "params"}},
+		"IMultiSig.MsigCreate":                           {Doc: "", Params: []string{"ctx", "req", "addrs", "duration", "val", "src", "gp"}},
+		"IMultiSig.MsigGetVested":                        {Doc: "", Params: []string{"ctx", "addr", "start", "end"}},
+		"IMultiSig.MsigPropose":                          {Doc: "", Params: []string{"ctx", "msig", "to", "amt", "src", "method", "params"}},
+		"IMultiSig.MsigRemoveSigner":                     {Doc: "", Params: []string{"ctx", "msig", "proposer", "toRemove", "decrease"}},
+		"IMultiSig.MsigSwapApprove":                      {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "proposer", "oldAdd", "newAdd"}},
+		"IMultiSig.MsigSwapCancel":                       {Doc: "", Params: []string{"ctx", "msig", "src", "txID", "oldAdd", "newAdd"}},
+		"IMultiSig.MsigSwapPropose":                      {Doc: "", Params: []string{"ctx", "msig", "src", "oldAdd", "newAdd"}},
+		"INetwork.NetAddrsListen":                        {Doc: "", Params: []string{"p0"}},
+		"INetwork.NetworkConnect":                        {Doc: "", Params: []string{"ctx", "addrs"}},
+		"INetwork.NetworkFindPeer":                       {Doc: "", Params: []string{"ctx", "peerID"}},
+		"INetwork.NetworkFindProvidersAsync":             {Doc: "", Params: []string{"ctx", "key", "count"}},
+		"INetwork.NetworkGetBandwidthStats":              {Doc: "", Params: []string{"ctx"}},
+		"INetwork.NetworkGetClosestPeers":                {Doc: "", Params: []string{"ctx", "key"}},
+		"INetwork.NetworkGetPeerAddresses":               {Doc: "", Params: []string{"ctx"}},
+		"INetwork.NetworkGetPeerID":                      {Doc: "", Params: []string{"ctx"}},
+		"INetwork.NetworkPeers":                          {Doc: "", Params: []string{"ctx", "verbose", "latency", "streams"}},
+		"INetwork.Version":                               {Doc: "", Params: []string{"p0"}},
+		"IPaychan.PaychAllocateLane":                     {Doc: "PaychAllocateLane Allocate late creates a lane within a payment channel so that calls to CreatePaymentVoucher will automatically make vouchers only for the difference in total", Params: []string{"ctx", "ch"}},
+		"IPaychan.PaychAvailableFunds":                   {Doc: "PaychAvailableFunds get the status of an outbound payment channel @pch: payment channel address", Params: []string{"ctx", "ch"}},
+		"IPaychan.PaychAvailableFundsByFromTo":           {Doc: "PaychAvailableFundsByFromTo  get the status of an outbound payment channel @from: the payment channel sender @to: he payment channel recipient", Params: []string{"ctx", "from", "to"}},
+		"IPaychan.PaychCollect":                          {Doc: "PaychCollect update payment channel status to collect Collect sends the value of submitted vouchers to the channel recipient (the provider), and refunds the remaining channel balance to the channel creator (the client). @pch: payment channel address", Params: []string{"ctx", "addr"}},
+		"IPaychan.PaychGet":                              {Doc: "PaychGet creates a payment channel to a provider with a amount of FIL @from: the payment channel sender @to: the payment channel recipient @amt: the deposits funds in the payment channel", Params: []string{"ctx", "from", "to", "amt"}},
+		"IPaychan.PaychGetWaitReady":                     {Doc: "PaychGetWaitReady waits until the create channel / add funds message with the sentinel @sentinel: given message CID arrives. @ch: the returned channel address can safely be used against the Manager methods.", Params: []string{"ctx", "sentinel"}},
+		"IPaychan.PaychList":                             {Doc: "PaychList list the addresses of all channels that have been created", Params: []string{"ctx"}},
+		"IPaychan.PaychNewPayment":                       {Doc: "PaychNewPayment aggregate vouchers into a new lane @from: the payment channel sender @to: the payment channel recipient @vouchers: the outstanding (non-redeemed) vouchers", Params: []string{"ctx", "from", "to", "vouchers"}},
+		"IPaychan.PaychSettle":                           {Doc: "PaychSettle update payment channel status to settle After a settlement period (currently 12 hours) either party to the payment channel can call collect on chain @pch: payment channel address", Params: []string{"ctx", "addr"}},
+		"IPaychan.PaychStatus":                           {Doc: "PaychStatus get the payment channel status @pch: payment channel address", Params: []string{"ctx", "pch"}},
+		"IPaychan.PaychVoucherAdd":                       {Doc: "PaychVoucherAdd adds a voucher for an inbound channel. If the channel is not in the store, fetches the channel from state (and checks that the channel To address is owned by the wallet).", Params: []string{"ctx", "ch", "sv", "proof", "minDelta"}},
+		"IPaychan.PaychVoucherCheckSpendable":            {Doc: "PaychVoucherCheckSpendable checks if the given voucher is currently spendable @pch: payment channel address @sv: voucher", Params: []string{"ctx", "ch", "sv", "secret", "proof"}},
+		"IPaychan.PaychVoucherCheckValid":                {Doc: "PaychVoucherCheckValid checks if the given voucher is valid (is or could become spendable at some point). If the channel is not in the store, fetches the channel from state (and checks that the channel To address is owned by the wallet). @pch: payment channel address @sv: voucher", Params: []string{"ctx", "ch", "sv"}},
+		"IPaychan.PaychVoucherCreate":                    {Doc: "PaychVoucherCreate creates a new signed voucher on the given payment channel with the given lane and amount.  The value passed in is exactly the value that will be used to create the voucher, so if previous vouchers exist, the actual additional value of this voucher will only be the difference between the two. If there are insufficient funds in the channel to create the voucher, returns a nil voucher and the shortfall.", Params: []string{"ctx", "pch", "amt", "lane"}},
+		"IPaychan.PaychVoucherList":                      {Doc: "PaychVoucherList list vouchers in payment channel @pch: payment channel address", Params: []string{"ctx", "pch"}},
+		"IPaychan.PaychVoucherSubmit":                    {Doc: "PaychVoucherSubmit Submit voucher to chain to update payment channel state @pch: payment channel address @sv: voucher in payment channel", Params: []string{"ctx", "ch", "sv", "secret", "proof"}},
+		"IRPC.Discover":                                  {Doc: "Discover returns the OpenRPC document of the api, it is also served as rpc.discover", Params: []string{"ctx"}},
+		"ISyncer.ChainCheck":                             {Doc: "", Params: []string{"ctx", "tsk", "opts"}},
+		"ISyncer.ChainSyncHandleNewTipSet":               {Doc: "", Params: []string{"ctx", "ci"}},
+		"ISyncer.ChainTipSetWeight":                      {Doc: "", Params: []string{"ctx", "tsk"}},
+		"ISyncer.Concurrent":                             {Doc: "", Params: []string{"ctx"}},
+		"ISyncer.JournalQuery":                           {Doc: "", Params: []string{"ctx", "system", "event", "since"}},
+		"ISyncer.SetConcurrent":                          {Doc: "", Params: []string{"ctx", "concurrent"}},
+		"ISyncer.StateCall":                              {Doc: "", Params: []string{"ctx", "msg", "tsk"}},
+		"ISyncer.SyncState":                              {Doc: "", Params: []string{"ctx"}},
+		"ISyncer.SyncSubmitBlock":                        {Doc: "", Params: []string{"ctx", "blk"}},
+		"ISyncer.SyncerTracker":                          {Doc: "", Params: []string{"ctx"}},
+		"IWallet.HasPassword":                            {Doc: "", Params: []string{"Context"}},
+		"IWallet.LockWallet":                             {Doc: "", Params: []string{"ctx"}},
+		"IWallet.SetPassword":                            {Doc: "", Params: []string{"Context", "password"}},
+		"IWallet.UnLockWallet":                           {Doc: "", Params: []string{"ctx", "password"}},
+		"IWallet.WalletAddresses":                        {Doc: "", Params: []string{"ctx"}},
+		"IWallet.WalletBalance":                          {Doc: "not exists in remote", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletChangePassword":                   {Doc: "", Params: []string{"ctx", "oldPassword", "newPassword"}},
+		"IWallet.WalletDefaultAddress":                   {Doc: "not exists in remote", Params: []string{"ctx"}},
+		"IWallet.WalletDelete":                           {Doc: "", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletDeriveAddress":                    {Doc: "", Params: []string{"ctx", "protocol", "index"}},
+		"IWallet.WalletExport":                           {Doc: "", Params: []string{"addr", "password"}},
+		"IWallet.WalletHas":                              {Doc: "", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletImport":                           {Doc: "", Params: []string{"key"}},
+		"IWallet.WalletNewAddress":                       {Doc: "", Params: []string{"protocol"}},
+		"IWallet.WalletNewHDAddress":                     {Doc: "", Params: []string{"ctx", "protocol"}},
+		"IWallet.WalletReencrypt":                        {Doc: "", Params: []string{"ctx", "password", "scryptN", "scryptP"}},
+		"IWallet.WalletRestoreMnemonic":                  {Doc: "", Params: []string{"ctx", "mnemonic"}},
+		"IWallet.WalletSetDefault":                       {Doc: "not exists in remote", Params: []string{"ctx", "addr"}},
+		"IWallet.WalletSign":                             {Doc: "", Params: []string{"ctx", "k", "msg", "meta"}},
+		"IWallet.WalletSignMessage":                      {Doc: "", Params: []string{"ctx", "k", "msg"}},
+		"IWallet.WalletState":                            {Doc: "", Params: []string{"Context"}},
+		"IWallet.WalletUndelete":                         {Doc: "", Params: []string{"ctx", "addr"}},
+	},
+}
@@ -0,0 +1,114 @@
+// Package openrpc builds the OpenRPC documents of the api, see https://spec.open-rpc.org.
+package openrpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/constants"
+)
+
+// openRPCVersion is the version of the OpenRPC specification the documents follow.
+const openRPCVersion = "1.2.6"
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// methodDoc is the documentation of a method of the api, taken from the api interfaces by
+// tools/gen/api. The docs are keyed by version, then by interface and method.
+type methodDoc struct {
+	Doc    string
+	Params []string
+}
+
+// NewDocument builds the OpenRPC document of the api described by `api`, a pointer to the
+// FullNodeStruct of a version of the client, such as &client.FullNodeStruct{}.
+func NewDocument(api interface{}, version string) apitypes.OpenRPCDocument {
+	b := newSchemaBuilder()
+
+	var methods []schema
+	addMethods(reflect.TypeOf(api).Elem(), methodDocs[version], b, &methods)
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i]["name"].(string) < methods[j]["name"].(string)
+	})
+
+	return apitypes.OpenRPCDocument{
+		"openrpc": openRPCVersion,
+		"info": schema{
+			"title":   "Venus RPC API",
+			"version": version + "-" + constants.UserVersion(),
+		},
+		"methods": methods,
+		"components": schema{
+			"schemas": b.components,
+		},
+	}
+}
+
+// addMethods adds the methods of the api struct `t`, and of the structs it embeds. The
+// struct of the interface IChainInfo is IChainInfoStruct.
+func addMethods(t reflect.Type, docs map[string]methodDoc, b *schemaBuilder, methods *[]schema) {
+	iface := strings.TrimSuffix(t.Name(), "Struct")
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			addMethods(f.Type, docs, b, methods)
+		case reflect.Func:
+			*methods = append(*methods, methodOf(f, docs[iface+"."+f.Name], b))
+		}
+	}
+}
+
+func methodOf(f reflect.StructField, doc methodDoc, b *schemaBuilder) schema {
+	ft := f.Type
+
+	// the context is not a parameter of the call
+	first := 0
+	if ft.NumIn() > 0 && ft.In(0) == contextType {
+		first = 1
+	}
+	params := []schema{}
+	for i := first; i < ft.NumIn(); i++ {
+		name := fmt.Sprintf("p%d", i)
+		if i < len(doc.Params) {
+			name = doc.Params[i]
+		}
+		params = append(params, schema{
+			"name":     name,
+			"required": ft.In(i).Kind() != reflect.Ptr,
+			"schema":   b.schemaOf(ft.In(i)),
+		})
+	}
+
+	result := schema{"name": f.Name + "Result", "schema": schema{"type": "null"}}
+	for i := 0; i < ft.NumOut(); i++ {
+		out := ft.Out(i)
+		if out == errorType {
+			continue
+		}
+		result["schema"] = b.schemaOf(out)
+		if out.Kind() == reflect.Chan {
+			result["x-subscription"] = true
+		}
+		break
+	}
+
+	m := schema{
+		"name":           "Filecoin." + f.Name,
+		"params":         params,
+		"result":         result,
+		"paramStructure": "by-position",
+		"x-perm":         f.Tag.Get("perm"),
+	}
+	if doc.Doc != "" {
+		m["summary"] = doc.Doc
+	}
+	return m
+}
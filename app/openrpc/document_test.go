@@ -0,0 +1,122 @@
+package openrpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+type testNode struct {
+	Next *testNode `json:",omitempty"`
+	Head cid.Cid
+	Name string `json:"name"`
+}
+
+type testAPIStruct struct {
+	testChainStruct
+	WalletBalance func(p0 context.Context, p1 address.Address) (string, error) `perm:"read"`
+}
+
+type testChainStruct struct {
+	ChainNotify   func(p0 context.Context) (<-chan []*testNode, error)       `perm:"read"`
+	ChainSetHead  func(p0 context.Context, p1 []cid.Cid, p2 *testNode) error `perm:"admin"`
+	ChainGetBytes func(p0 context.Context, p1 []byte) ([]byte, error)        `perm:"read"`
+}
+
+func TestNewDocument(t *testing.T) {
+	tf.UnitTest(t)
+
+	doc := NewDocument(&testAPIStruct{}, "v1")
+	// the document is plain json
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, openRPCVersion, decoded["openrpc"])
+
+	methods := doc["methods"].([]schema)
+	require.Len(t, methods, 4)
+	// sorted by name, with the methods of the embedded structs
+	assert.Equal(t, "Filecoin.ChainGetBytes", methods[0]["name"])
+	assert.Equal(t, "Filecoin.WalletBalance", methods[3]["name"])
+
+	notify := methods[1]
+	assert.Equal(t, "read", notify["x-perm"])
+	result := notify["result"].(schema)
+	assert.Equal(t, true, result["x-subscription"])
+	assert.Equal(t, "#/components/schemas/openrpc.testNode", result["schema"].(schema)["items"].(schema)["$ref"])
+
+	setHead := methods[2]
+	assert.Equal(t, "admin", setHead["x-perm"])
+	assert.Equal(t, schema{"type": "null"}, setHead["result"].(schema)["schema"])
+	params := setHead["params"].([]schema)
+	require.Len(t, params, 2)
+	assert.Equal(t, true, params[0]["required"])
+	assert.Equal(t, false, params[1]["required"])
+
+	bytesParam := methods[0]["params"].([]schema)[0]["schema"].(schema)
+	assert.Equal(t, "base64", bytesParam["contentEncoding"])
+
+	node := doc["components"].(schema)["schemas"].(map[string]schema)["openrpc.testNode"]
+	props := node["properties"].(schema)
+	assert.Contains(t, props, "Next")
+	assert.Contains(t, props, "Head")
+	assert.Contains(t, props, "name")
+	assert.Equal(t, []string{"Head", "name"}, node["required"])
+}
+
+type testV0ChainStruct struct {
+	ChainNotify func(p0 context.Context) <-chan []*testNode          `perm:"read"`
+	VerifyEntry func(p0 *testNode, p1 *testNode, p2 int64) bool      `perm:"read"`
+	ChainHead   func(p0 context.Context) (*testNode, error)          `perm:"read"`
+	ChainList   func(p0 context.Context, p1 []cid.Cid, p2 int) error `perm:"read"`
+}
+
+func TestNewDocumentMethodsOfVersion(t *testing.T) {
+	tf.UnitTest(t)
+
+	methodDocs["test-v0"] = map[string]methodDoc{
+		"testV0Chain.ChainHead":   {Doc: "ChainHead of v0", Params: []string{"ctx"}},
+		"testV0Chain.VerifyEntry": {Params: []string{"parent", "child", "height"}},
+	}
+	methodDocs["test-v1"] = map[string]methodDoc{
+		"testV0Chain.ChainHead": {Doc: "ChainHead of v1", Params: []string{"ctx"}},
+	}
+	defer delete(methodDocs, "test-v0")
+	defer delete(methodDocs, "test-v1")
+
+	byName := func(doc map[string]interface{}) map[string]schema {
+		methods := map[string]schema{}
+		for _, m := range doc["methods"].([]schema) {
+			methods[m["name"].(string)] = m
+		}
+		return methods
+	}
+	v0 := byName(NewDocument(&testV0ChainStruct{}, "test-v0"))
+	v1 := byName(NewDocument(&testV0ChainStruct{}, "test-v1"))
+
+	// the docs of a version do not leak into the other
+	assert.Equal(t, "ChainHead of v0", v0["Filecoin.ChainHead"]["summary"])
+	assert.Equal(t, "ChainHead of v1", v1["Filecoin.ChainHead"]["summary"])
+
+	// a method without a context takes all its parameters
+	params := v0["Filecoin.VerifyEntry"]["params"].([]schema)
+	require.Len(t, params, 3)
+	assert.Equal(t, "parent", params[0]["name"])
+	assert.Equal(t, "height", params[2]["name"])
+	assert.Equal(t, schema{"type": "boolean"}, v0["Filecoin.VerifyEntry"]["result"].(schema)["schema"])
+
+	// a subscription without an error is still one
+	result := v0["Filecoin.ChainNotify"]["result"].(schema)
+	assert.Equal(t, true, result["x-subscription"])
+	assert.Equal(t, "array", result["schema"].(schema)["type"])
+
+	assert.Equal(t, schema{"type": "null"}, v0["Filecoin.ChainList"]["result"].(schema)["schema"])
+}
@@ -0,0 +1,174 @@
+package openrpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+type schema = map[string]interface{}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// knownSchemas are the schemas of the types with a json encoding of their own.
+var knownSchemas = map[reflect.Type]schema{
+	reflect.TypeOf(cid.Cid{}): {
+		"type":       "object",
+		"properties": schema{"/": schema{"type": "string"}},
+		"required":   []string{"/"},
+	},
+	reflect.TypeOf(address.Address{}): {"type": "string", "description": "filecoin address"},
+	reflect.TypeOf(big.Int{}):         {"type": "string", "description": "big integer"},
+	reflect.TypeOf(types.TipSetKey{}): {
+		"type": "array",
+		"items": schema{
+			"type":       "object",
+			"properties": schema{"/": schema{"type": "string"}},
+		},
+	},
+	reflect.TypeOf(peer.ID("")):                 {"type": "string", "description": "peer id"},
+	reflect.TypeOf(time.Time{}):                 {"type": "string", "format": "date-time"},
+	reflect.TypeOf(bitfield.BitField{}):         {"type": "array", "items": schema{"type": "integer"}, "description": "run-length encoded bitfield"},
+	reflect.TypeOf((*ma.Multiaddr)(nil)).Elem(): {"type": "string", "description": "multiaddr"},
+}
+
+// schemaBuilder builds the json schemas of the types of the api, the structs are put in
+// components and referred to, so that recursive types terminate.
+type schemaBuilder struct {
+	components map[string]schema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]schema{}}
+}
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) schema {
+	if s, ok := knownSchemas[t]; ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schemaOf(t.Elem())
+	case reflect.Interface:
+		return schema{}
+	}
+
+	if t.Kind() != reflect.Struct && (t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)) {
+		return schema{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return schema{"type": "array", "items": b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": b.schemaOf(t.Elem())}
+	case reflect.Chan:
+		return b.schemaOf(t.Elem())
+	case reflect.Struct:
+		return b.structSchema(t)
+	}
+	return schema{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) schema {
+	if t.Name() == "" {
+		return b.objectSchema(t)
+	}
+
+	name := strings.ReplaceAll(t.String(), " ", "")
+	ref := schema{"$ref": "#/components/schemas/" + name}
+	if _, ok := b.components[name]; ok {
+		return ref
+	}
+	// a placeholder, for the recursive types
+	b.components[name] = schema{}
+
+	var s schema
+	switch {
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		s = schema{"description": "custom json encoding of " + name}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		s = schema{"type": "string"}
+	default:
+		s = b.objectSchema(t)
+	}
+	b.components[name] = s
+	return ref
+}
+
+func (b *schemaBuilder) objectSchema(t reflect.Type) schema {
+	props := schema{}
+	var required []string
+	b.addFields(t, props, &required)
+
+	s := schema{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// addFields adds the fields of the struct `t` as encoding/json encodes them, with the
+// fields of the embedded structs flattened.
+func (b *schemaBuilder) addFields(t reflect.Type, props schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if _, ok := knownSchemas[ft]; !ok && !ft.Implements(jsonMarshalerType) && !reflect.PtrTo(ft).Implements(jsonMarshalerType) {
+				b.addFields(ft, props, required)
+				continue
+			}
+		}
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		props[name] = b.schemaOf(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package paths
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"golang.org/x/xerrors"
+)
+
+// unixHostSuffix marks the placeholder hosts standing for a unix socket in the urls of an
+// endpoint, DialContext dials the socket instead.
+const unixHostSuffix = ".unix"
+
+// APIEndpoint is where the api of a node is served, either a tcp address or a unix socket.
+type APIEndpoint struct {
+	// Host is the host:port of a tcp address.
+	Host string
+	// UnixPath is the path of a unix socket.
+	UnixPath string
+	// TLS is set when the api is served over tls, that is when the multiaddr ends with
+	// /https or /wss.
+	TLS bool
+}
+
+// ParseAPIEndpoint parses the multiaddr of an api, such as /ip4/127.0.0.1/tcp/3453,
+// /dns4/node/tcp/3453/https or /unix/home/venus/api.sock.
+func ParseAPIEndpoint(addr string) (APIEndpoint, error) {
+	maddr, err := ma.NewMultiaddr(strings.TrimSpace(addr))
+	if err != nil {
+		return APIEndpoint{}, xerrors.Errorf("parsing api address %s: %w", addr, err)
+	}
+	network, host, err := manet.DialArgs(maddr)
+	if err != nil {
+		return APIEndpoint{}, xerrors.Errorf("parsing api address %s: %w", addr, err)
+	}
+	if network == "unix" {
+		return APIEndpoint{UnixPath: host}, nil
+	}
+
+	ep := APIEndpoint{Host: host}
+	ma.ForEach(maddr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_HTTPS, ma.P_WSS:
+			ep.TLS = true
+		}
+		return true
+	})
+	return ep, nil
+}
+
+// Addr returns the host:port of the endpoint, for a unix socket it is a placeholder which
+// must be dialed with DialContext.
+func (ep APIEndpoint) Addr() string {
+	if ep.UnixPath != "" {
+		return hex.EncodeToString([]byte(ep.UnixPath)) + unixHostSuffix + ":80"
+	}
+	return ep.Host
+}
+
+// URL returns the url of `path` on the endpoint, `scheme` is http or ws and is turned into
+// https or wss for a tls endpoint.
+func (ep APIEndpoint) URL(scheme, path string) string {
+	if ep.TLS {
+		scheme += "s"
+	}
+	return scheme + "://" + ep.Addr() + path
+}
+
+// IsUnixAddr returns whether the host:port `addr` is the placeholder of a unix socket endpoint.
+func IsUnixAddr(addr string) bool {
+	_, ok := unixPath(addr)
+	return ok
+}
+
+// DialContext dials `addr` like a net.Dialer, except that the placeholder hosts of the urls
+// of unix socket endpoints are dialed as the unix socket they stand for.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var d net.Dialer
+	if path, ok := unixPath(addr); ok {
+		return d.DialContext(ctx, "unix", path)
+	}
+	return d.DialContext(ctx, network, addr)
+}
+
+func unixPath(addr string) (string, bool) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || !strings.HasSuffix(host, unixHostSuffix) {
+		return "", false
+	}
+	path, err := hex.DecodeString(strings.TrimSuffix(host, unixHostSuffix))
+	if err != nil {
+		return "", false
+	}
+	return string(path), true
+}
@@ -0,0 +1,69 @@
+package paths
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestParseAPIEndpoint(t *testing.T) {
+	tf.UnitTest(t)
+
+	ep, err := ParseAPIEndpoint("/ip4/127.0.0.1/tcp/3453")
+	require.NoError(t, err)
+	assert.Equal(t, "ws://127.0.0.1:3453/rpc/v1", ep.URL("ws", "/rpc/v1"))
+
+	ep, err = ParseAPIEndpoint("/dns4/node/tcp/3453/https")
+	require.NoError(t, err)
+	assert.True(t, ep.TLS)
+	assert.Equal(t, "wss://node:3453/rpc/v1", ep.URL("ws", "/rpc/v1"))
+	assert.Equal(t, "https://node:3453", ep.URL("http", ""))
+
+	ep, err = ParseAPIEndpoint("/unix/tmp/venus.sock")
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/venus.sock", ep.UnixPath)
+	assert.False(t, ep.TLS)
+
+	_, err = ParseAPIEndpoint("not a multiaddr")
+	assert.Error(t, err)
+}
+
+func TestDialContextUnixSocket(t *testing.T) {
+	tf.UnitTest(t)
+
+	dir, err := ioutil.TempDir("", "venus-endpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	sock := filepath.Join(dir, "api.sock")
+	lst, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})}
+	go srv.Serve(lst) // nolint: errcheck
+	defer srv.Close() // nolint: errcheck
+
+	ep, err := ParseAPIEndpoint("/unix" + sock)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{DialContext: DialContext}}
+	resp, err := client.Get(ep.URL("http", "/healthz"))
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+
+	// the other addresses are dialed as usual
+	_, err = DialContext(context.Background(), "tcp", "127.0.0.1:0")
+	assert.Error(t, err)
+}
@@ -76,8 +76,20 @@ type IChainInfo interface {
 	ChainGetParentMessages(ctx context.Context, bcid cid.Cid) ([]apitypes.Message, error)
 	// Rule[perm:read]
 	ChainGetParentReceipts(ctx context.Context, bcid cid.Cid) ([]*types.MessageReceipt, error)
+	// ChainGasStats aggregates the gas used and the fees paid by the messages of the tipsets from the height `from` to `to`, by type of receiver and method, and by sender
+	// Rule[perm:read]
+	ChainGasStats(ctx context.Context, from, to abi.ChainEpoch) (*apitypes.GasStats, error)
 	// Rule[perm:read]
 	ChainNotify(ctx context.Context) chan []*chain.HeadChange
+	// ChainWatchMessages notifies the messages matching `match` once they are `confidence` epochs deep in the chain, and their reverts
+	// Rule[perm:read]
+	ChainWatchMessages(ctx context.Context, match apitypes.MessageMatch, confidence int) (<-chan apitypes.MessageEvent, error)
+	// ChainWatchActor notifies the changes of the actor `addr` once they are `confidence` epochs deep in the chain, and their reverts
+	// Rule[perm:read]
+	ChainWatchActor(ctx context.Context, addr address.Address, confidence int) (<-chan apitypes.ActorEvent, error)
+	// ChainWatchHeight notifies when the chain reaches the height `h` by `confidence` epochs, and when it is rolled back under it
+	// Rule[perm:read]
+	ChainWatchHeight(ctx context.Context, h abi.ChainEpoch, confidence int) (<-chan apitypes.HeightEvent, error)
 	// Rule[perm:read]
 	GetFullBlock(ctx context.Context, id cid.Cid) (*types.FullBlock, error)
 	// Rule[perm:read]
@@ -175,9 +187,14 @@ type IMinerState interface {
 	StateVMCirculatingSupplyInternal(ctx context.Context, tsk types.TipSetKey) (chain.CirculatingSupply, error)
 	// Rule[perm:read]
 	StateCirculatingSupply(ctx context.Context, tsk types.TipSetKey) (abi.TokenAmount, error)
+	// StateTokenomics breaks down the supply of FIL and the funds locked, owed and burnt at a tipset.
+	// Rule[perm:read]
+	StateTokenomics(ctx context.Context, tsk types.TipSetKey) (*apitypes.Tokenomics, error)
 	// Rule[perm:read]
 	StateMarketDeals(ctx context.Context, tsk types.TipSetKey) (map[string]pstate.MarketDeal, error)
 	// Rule[perm:read]
+	StateMarketDealsQuery(ctx context.Context, tsk types.TipSetKey, filter pstate.MarketDealFilter, cursor abi.DealID, limit int) (*pstate.MarketDealsPage, error)
+	// Rule[perm:read]
 	StateMinerActiveSectors(ctx context.Context, maddr address.Address, tsk types.TipSetKey) ([]*miner.SectorOnChainInfo, error)
 	// Rule[perm:read]
 	StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error)
@@ -188,11 +205,18 @@ type IMinerState interface {
 	// Rule[perm:read]
 	StateMinerPower(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*apitypes.MinerPower, error)
 	// Rule[perm:read]
+	StateMinerPowerAll(ctx context.Context, tsk types.TipSetKey) (*apitypes.MinerPowerAll, error)
+	// Rule[perm:read]
+	StateMinersSummary(ctx context.Context, tsk types.TipSetKey) ([]*apitypes.MinerSummary, error)
+	// Rule[perm:read]
 	StateMinerAvailableBalance(ctx context.Context, maddr address.Address, tsk types.TipSetKey) (big.Int, error)
 	// Rule[perm:read]
 	StateSectorExpiration(ctx context.Context, maddr address.Address, sectorNumber abi.SectorNumber, tsk types.TipSetKey) (*miner.SectorExpiration, error)
 	// Rule[perm:read]
 	StateMinerSectorCount(ctx context.Context, addr address.Address, tsk types.TipSetKey) (apitypes.MinerSectors, error)
+	// StateMinerHealth reports the state of the deadlines and sectors of a miner and warns about the risks to its power.
+	// Rule[perm:read]
+	StateMinerHealth(ctx context.Context, maddr address.Address, expiryWindow abi.ChainEpoch, tsk types.TipSetKey) (*apitypes.MinerHealth, error)
 	// Rule[perm:read]
 	StateMarketBalance(ctx context.Context, addr address.Address, tsk types.TipSetKey) (apitypes.MarketBalance, error)
 }
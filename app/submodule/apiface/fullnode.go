@@ -25,6 +25,7 @@ type FullNode interface {
 	IMultiSig
 	INetwork
 	IPaychan
+	IRPC
 	ISyncer
 	IWallet
 	IJwtAuthAPI
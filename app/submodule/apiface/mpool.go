@@ -57,6 +57,12 @@ type IMessagePool interface {
 	GasEstimateGasPremium(ctx context.Context, nblocksincl uint64, sender address.Address, gaslimit int64, tsk types.TipSetKey) (big.Int, error)
 	// Rule[perm:read]
 	GasEstimateGasLimit(ctx context.Context, msgIn *types.UnsignedMessage, tsk types.TipSetKey) (int64, error)
+	// GasFeeHistory returns the base fees and the gas premium percentiles of the last `blocks` tipsets
+	// Rule[perm:read]
+	GasFeeHistory(ctx context.Context, blocks int, percentiles []float64, tsk types.TipSetKey) (*messagepool.FeeHistory, error)
+	// GasEstimateInclusion returns the gas premiums and fee caps with which a message should be included within each number of epochs of `targets`
+	// Rule[perm:read]
+	GasEstimateInclusion(ctx context.Context, gaslimit int64, targets []uint64, tsk types.TipSetKey) ([]messagepool.InclusionEstimate, error)
 	// MpoolCheckMessages performs logical checks on a batch of messages
 	// Rule[perm:read]
 	MpoolCheckMessages(ctx context.Context, protos []*apitypes.MessagePrototype) ([][]apitypes.MessageCheckStatus, error)
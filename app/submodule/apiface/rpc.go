@@ -0,0 +1,13 @@
+package apiface
+
+import (
+	"context"
+
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+)
+
+type IRPC interface {
+	// Discover returns the OpenRPC document of the api, it is also served as rpc.discover
+	// Rule[perm:read]
+	Discover(ctx context.Context) (apitypes.OpenRPCDocument, error)
+}
@@ -2,9 +2,13 @@ package apiface
 
 import (
 	"context"
+	"time"
+
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/chain"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/types"
 )
 
@@ -25,4 +29,8 @@ type ISyncer interface {
 	StateCall(ctx context.Context, msg *types.UnsignedMessage, tsk types.TipSetKey) (*apitypes.InvocResult, error)
 	// Rule[perm:read]
 	SyncState(ctx context.Context) (*apitypes.SyncState, error)
+	// Rule[perm:admin]
+	ChainCheck(ctx context.Context, tsk types.TipSetKey, opts chain.CheckOptions) (*chain.CheckReport, error)
+	// Rule[perm:read]
+	JournalQuery(ctx context.Context, system, event string, since time.Time) ([]*journal.Event, error)
 }
@@ -48,8 +48,20 @@ type IChainInfo interface {
 	ChainGetParentMessages(ctx context.Context, bcid cid.Cid) ([]apitypes.Message, error)
 	// Rule[perm:read]
 	ChainGetParentReceipts(ctx context.Context, bcid cid.Cid) ([]*types.MessageReceipt, error)
+	// ChainGasStats aggregates the gas used and the fees paid by the messages of the tipsets from the height `from` to `to`, by type of receiver and method, and by sender
+	// Rule[perm:read]
+	ChainGasStats(ctx context.Context, from, to abi.ChainEpoch) (*apitypes.GasStats, error)
 	// Rule[perm:read]
 	ChainNotify(ctx context.Context) chan []*chain.HeadChange
+	// ChainWatchMessages notifies the messages matching `match` once they are `confidence` epochs deep in the chain, and their reverts
+	// Rule[perm:read]
+	ChainWatchMessages(ctx context.Context, match apitypes.MessageMatch, confidence int) (<-chan apitypes.MessageEvent, error)
+	// ChainWatchActor notifies the changes of the actor `addr` once they are `confidence` epochs deep in the chain, and their reverts
+	// Rule[perm:read]
+	ChainWatchActor(ctx context.Context, addr address.Address, confidence int) (<-chan apitypes.ActorEvent, error)
+	// ChainWatchHeight notifies when the chain reaches the height `h` by `confidence` epochs, and when it is rolled back under it
+	// Rule[perm:read]
+	ChainWatchHeight(ctx context.Context, h abi.ChainEpoch, confidence int) (<-chan apitypes.HeightEvent, error)
 	// Rule[perm:read]
 	GetFullBlock(ctx context.Context, id cid.Cid) (*types.FullBlock, error)
 	// Rule[perm:read]
@@ -41,4 +41,18 @@ type IWallet interface {
 	HasPassword(Context context.Context) bool
 	// Rule[perm:admin]
 	WalletState(Context context.Context) int
+	// Rule[perm:admin]
+	WalletNewHDAddress(ctx context.Context, protocol address.Protocol) (*wallet.HDAddress, error)
+	// Rule[perm:admin]
+	WalletRestoreMnemonic(ctx context.Context, mnemonic string) error
+	// Rule[perm:admin]
+	WalletDeriveAddress(ctx context.Context, protocol address.Protocol, index uint32) (*wallet.HDAddress, error)
+	// Rule[perm:admin]
+	WalletDelete(ctx context.Context, addr address.Address) error
+	// Rule[perm:admin]
+	WalletUndelete(ctx context.Context, addr address.Address) error
+	// Rule[perm:admin]
+	WalletChangePassword(ctx context.Context, oldPassword, newPassword []byte) error
+	// Rule[perm:admin]
+	WalletReencrypt(ctx context.Context, password []byte, scryptN, scryptP int) error
 }
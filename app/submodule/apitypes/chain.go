@@ -3,13 +3,17 @@ package apitypes
 import (
 	"time"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/dline"
 	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
 
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
 	"github.com/filecoin-project/venus/pkg/types"
 )
@@ -82,4 +86,157 @@ type MinerPower struct {
 	HasMinPower bool
 }
 
+// MinerPowerAll holds the claims of every miner in the power actor, keyed by miner address.
+type MinerPowerAll struct {
+	Miners     map[string]power.Claim
+	TotalPower power.Claim
+}
+
+// MinerSummary is an overview of a single miner with power claimed in the power actor.
+type MinerSummary struct {
+	Address     address.Address
+	PeerID      *peer.ID
+	SectorSize  abi.SectorSize
+	Power       power.Claim
+	HasMinPower bool
+	Sectors     MinerSectors
+	LockedFunds miner.LockedFunds
+	Balance     abi.TokenAmount
+}
+
+// LessAddress orders ID addresses by their ID, and any other address after them by its string.
+// Miner summaries are listed in this order.
+func LessAddress(a, b address.Address) bool {
+	aid, aerr := address.IDFromAddress(a)
+	bid, berr := address.IDFromAddress(b)
+	switch {
+	case aerr == nil && berr == nil:
+		return aid < bid
+	case aerr == nil:
+		return true
+	case berr == nil:
+		return false
+	default:
+		return a.String() < b.String()
+	}
+}
+
+// MinerHealth is a report of the proving state of a miner, see DeadlineHealth.
+type MinerHealth struct {
+	Miner        address.Address
+	CurrentEpoch abi.ChainEpoch
+	// Deadline is the current deadline, or the next one when the current one elapsed.
+	Deadline  *dline.Info
+	Deadlines []DeadlineHealth
+
+	FeeDebt          abi.TokenAmount
+	AvailableBalance abi.TokenAmount
+	Worker           address.Address
+	WorkerBalance    abi.TokenAmount
+	// PoStCost is an estimate of the gas fees of the PoSts of a proving period at the
+	// current base fee, which the worker pays.
+	PoStCost abi.TokenAmount
+
+	// Warnings lists the problems which put the power of the miner at risk.
+	Warnings []string
+}
+
+// DeadlineHealth is the state of the sectors of a deadline of a miner.
+type DeadlineHealth struct {
+	Index uint64
+	// Open and Close are the epochs of the next challenge window of the deadline, or of
+	// the current one when the deadline is open.
+	Open  abi.ChainEpoch
+	Close abi.ChainEpoch
+
+	Partitions       uint64
+	ProvenPartitions uint64
+
+	Live       uint64
+	Active     uint64
+	Faulty     uint64
+	Recovering uint64
+	// Expiring is the number of live sectors scheduled to expire, on time or early for a long
+	// fault, before the end of the window asked for.
+	Expiring uint64
+	// AtRisk is the number of sectors of the partitions which were not proven yet while the
+	// deadline is open, the non faulty sectors along with the recovering ones, whose recovery
+	// fails if the partition is not proven.
+	AtRisk uint64
+}
+
+// Tokenomics breaks down the supply of FIL in the parent state of a tipset, see
+// chain.CirculatingSupply.
+type Tokenomics struct {
+	Height abi.ChainEpoch
+
+	FilVested           abi.TokenAmount
+	FilMined            abi.TokenAmount
+	FilBurnt            abi.TokenAmount
+	FilLocked           abi.TokenAmount
+	FilCirculating      abi.TokenAmount
+	FilReserveDisbursed abi.TokenAmount
+
+	// TotalPledge and MarketLocked are the parts of FilLocked locked in the power actor and
+	// in the market actor.
+	TotalPledge  abi.TokenAmount
+	MarketLocked abi.TokenAmount
+	MinerFeeDebt abi.TokenAmount
+
+	// BaseFee is the base fee paid by the messages of the parent tipset, which burnt
+	// BaseFeeBurn, and OverEstimationBurn for their over estimated gas limits.
+	BaseFee            abi.TokenAmount
+	BaseFeeBurn        abi.TokenAmount
+	OverEstimationBurn abi.TokenAmount
+}
+
 type MsgLookup = chain.MsgLookup
+
+// GasStats are the gas statistics of a range of tipsets, see chain.CollectGasStats.
+type GasStats = chain.GasStats
+
+// WatchEventType tells whether the event of a watch was applied or reverted.
+type WatchEventType string
+
+const (
+	WatchApply  WatchEventType = "apply"
+	WatchRevert WatchEventType = "revert"
+)
+
+// MessageMatch selects the messages of ChainWatchMessages. An undefined address or a nil
+// method matches any message, the addresses are compared as they appear in the messages.
+type MessageMatch struct {
+	From   address.Address
+	To     address.Address
+	Method *abi.MethodNum
+}
+
+// MessageEvent is sent when a watched message is included `confidence` epochs deep in the
+// chain, or when the tipset including it is reverted.
+type MessageEvent struct {
+	Type    WatchEventType
+	TipSet  types.TipSetKey
+	Height  abi.ChainEpoch
+	Cid     cid.Cid
+	Message *types.UnsignedMessage
+	// Receipt is nil on revert
+	Receipt *types.MessageReceipt
+}
+
+// ActorEvent is sent when a change of a watched actor is `confidence` epochs deep in the
+// chain, or when the tipset of the change is reverted. Actor is the state after the change
+// when applied and the state before the change when reverted, nil if the actor did not exist.
+type ActorEvent struct {
+	Type   WatchEventType
+	TipSet types.TipSetKey
+	Height abi.ChainEpoch
+	Actor  *types.Actor
+}
+
+// HeightEvent is sent when the chain reaches a watched height by `confidence` epochs, or
+// when it is rolled back under that height.
+type HeightEvent struct {
+	Type   WatchEventType
+	TipSet types.TipSetKey
+	Height abi.ChainEpoch
+}
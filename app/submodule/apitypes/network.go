@@ -12,3 +12,6 @@ type Version struct {
 	// See APIVersion in build/version.go
 	APIVersion constants.Version
 }
+
+// OpenRPCDocument is the OpenRPC document describing the api, see https://spec.open-rpc.org.
+type OpenRPCDocument map[string]interface{}
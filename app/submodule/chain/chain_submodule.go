@@ -17,7 +17,9 @@ import (
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/consensusfault"
+	"github.com/filecoin-project/venus/pkg/events"
 	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/util/ffiwrapper"
@@ -40,6 +42,13 @@ type ChainSubmodule struct { //nolint
 
 	// Wait for confirm message
 	Waiter *chain.Waiter
+
+	// DealIndex is nil unless enabled in the api config
+	DealIndex *DealIndex
+
+	// Events is shared by the watch apis, it is created on start
+	Events       *events.Events
+	eventsCancel context.CancelFunc
 }
 
 // xxx go back to using an interface here
@@ -52,6 +61,8 @@ type chainConfig interface {
 	GenesisCid() cid.Cid
 	BlockTime() time.Duration
 	Repo() repo.Repo
+	Journal() journal.Journal
+	BeaconSchedule() beacon.Schedule
 }
 
 // NewChainSubmodule creates a new chain submodule.
@@ -62,16 +73,19 @@ func NewChainSubmodule(ctx context.Context,
 	verifier ffiwrapper.Verifier,
 ) (*ChainSubmodule, error) {
 	// initialize chain store
-	chainStore := chain.NewStore(repo.ChainDatastore(), blockstore.CborStore, blockstore.Blockstore, repo.Config().NetworkParams.ForkUpgradeParam, config.GenesisCid())
+	chainStore := chain.NewStore(repo.ChainDatastore(), blockstore.CborStore, blockstore.Blockstore, repo.Config().NetworkParams.ForkUpgradeParam, config.GenesisCid(), config.Journal())
 	//drand
 	genBlk, err := chainStore.GetGenesisBlock(context.TODO())
 	if err != nil {
 		return nil, err
 	}
 
-	drand, err := beacon.DrandConfigSchedule(genBlk.Timestamp, repo.Config().NetworkParams.BlockDelay, repo.Config().NetworkParams.DrandSchedule)
-	if err != nil {
-		return nil, err
+	drand := config.BeaconSchedule()
+	if drand == nil {
+		drand, err = beacon.DrandConfigSchedule(genBlk.Timestamp, repo.Config().NetworkParams.BlockDelay, repo.Config().NetworkParams.DrandSchedule)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	messageStore := chain.NewMessageStore(blockstore.Blockstore, repo.Config().NetworkParams.ForkUpgradeParam)
@@ -96,6 +110,9 @@ func NewChainSubmodule(ctx context.Context,
 		Waiter:       waiter,
 		CheckPoint:   chainStore.GetCheckPoint(),
 	}
+	if repo.Config().API.EnableDealIndex {
+		store.DealIndex = NewDealIndex(chainStore)
+	}
 	err = store.ChainReader.Load(context.TODO())
 	if err != nil {
 		return nil, err
@@ -105,11 +122,25 @@ func NewChainSubmodule(ctx context.Context,
 
 // Start loads the chain from disk.
 func (chain *ChainSubmodule) Start(ctx context.Context) error {
+	if chain.DealIndex != nil {
+		chain.DealIndex.Start(ctx)
+	}
+
+	var evtCtx context.Context
+	evtCtx, chain.eventsCancel = context.WithCancel(ctx)
+	chain.Events = events.NewEvents(evtCtx, &eventAPI{chain.API()})
+
 	return chain.Fork.Start(ctx)
 }
 
 //Stop stop the chain head event
 func (chain *ChainSubmodule) Stop(ctx context.Context) {
+	if chain.DealIndex != nil {
+		chain.DealIndex.Stop()
+	}
+	if chain.eventsCancel != nil {
+		chain.eventsCancel()
+	}
 	chain.ChainReader.Stop()
 }
 
@@ -295,6 +295,13 @@ func (cia *chainInfoAPI) ChainGetParentReceipts(ctx context.Context, bcid cid.Ci
 	return out, nil
 }
 
+// ChainGasStats aggregates the gas used and the fees paid by the messages of the tipsets from
+// the height `from` to `to` of the current chain.
+func (cia *chainInfoAPI) ChainGasStats(ctx context.Context, from, to abi.ChainEpoch) (*apitypes.GasStats, error) {
+	head := cia.chain.ChainReader.GetHead()
+	return chain.CollectGasStats(ctx, cia.chain.ChainReader, cia.chain.MessageStore, head, from, to)
+}
+
 // ResolveToKeyAddr resolve user address to t0 address
 func (cia *chainInfoAPI) ResolveToKeyAddr(ctx context.Context, addr address.Address, ts *types.TipSet) (address.Address, error) {
 	if ts == nil {
@@ -0,0 +1,199 @@
+package chain
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	pstate "github.com/filecoin-project/venus/pkg/state"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var dealIndexLog = logging.Logger("deal-index")
+
+type dealSet map[abi.DealID]struct{}
+
+// DealIndex indexes the deal proposals of the storage market by client and provider at the
+// parent state of the chain head. The index is built once and then kept up to date on head
+// changes by diffing the proposals of the new head against the indexed ones.
+type DealIndex struct {
+	store *chain.Store
+
+	lk         sync.RWMutex
+	tsk        types.TipSetKey
+	proposals  market.DealProposals
+	byClient   map[address.Address]dealSet
+	byProvider map[address.Address]dealSet
+
+	cancel context.CancelFunc
+}
+
+// NewDealIndex creates an empty index, it is filled once Start is called.
+func NewDealIndex(store *chain.Store) *DealIndex {
+	return &DealIndex{
+		store:      store,
+		byClient:   make(map[address.Address]dealSet),
+		byProvider: make(map[address.Address]dealSet),
+	}
+}
+
+// Start builds the index and follows head changes in the background.
+func (idx *DealIndex) Start(ctx context.Context) {
+	ctx, idx.cancel = context.WithCancel(ctx)
+	go func() {
+		for range idx.store.SubHeadChanges(ctx) {
+			if err := idx.update(ctx, idx.store.GetHead()); err != nil {
+				dealIndexLog.Errorf("updating deal index: %s", err)
+			}
+		}
+	}()
+}
+
+// Stop stops following head changes.
+func (idx *DealIndex) Stop() {
+	if idx.cancel != nil {
+		idx.cancel()
+	}
+}
+
+// Candidates returns the sorted ids of the deals which may match the client and provider of the
+// filter at the parent state of `ts`. It returns false if the index can not answer, that is when
+// it is not built for `ts` or the filter selects neither a client nor a provider.
+func (idx *DealIndex) Candidates(ts *types.TipSet, filter *pstate.MarketDealFilter) ([]abi.DealID, bool) {
+	if filter == nil || (filter.Client == nil && filter.Provider == nil) {
+		return nil, false
+	}
+
+	idx.lk.RLock()
+	defer idx.lk.RUnlock()
+
+	if idx.proposals == nil || !idx.tsk.Equals(ts.Key()) {
+		return nil, false
+	}
+
+	var sets []dealSet
+	if filter.Client != nil {
+		sets = append(sets, idx.byClient[*filter.Client])
+	}
+	if filter.Provider != nil {
+		sets = append(sets, idx.byProvider[*filter.Provider])
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return len(sets[i]) < len(sets[j])
+	})
+
+	ids := make([]abi.DealID, 0, len(sets[0]))
+	for id := range sets[0] {
+		if len(sets) > 1 {
+			if _, ok := sets[1][id]; !ok {
+				continue
+			}
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids, true
+}
+
+func (idx *DealIndex) update(ctx context.Context, ts *types.TipSet) error {
+	view, err := idx.store.ParentStateView(ts)
+	if err != nil {
+		return err
+	}
+	marketState, err := view.LoadMarketState(ctx)
+	if err != nil {
+		return err
+	}
+	proposals, err := marketState.Proposals()
+	if err != nil {
+		return err
+	}
+	return idx.apply(ts, proposals)
+}
+
+// apply indexes the proposals of the parent state of `ts`, diffing them against the indexed ones.
+func (idx *DealIndex) apply(ts *types.TipSet, proposals market.DealProposals) error {
+	idx.lk.RLock()
+	prev := idx.proposals
+	idx.lk.RUnlock()
+
+	if prev == nil {
+		return idx.rebuild(ts, proposals)
+	}
+
+	changes, err := market.DiffDealProposals(prev, proposals)
+	if err != nil {
+		return err
+	}
+
+	idx.lk.Lock()
+	defer idx.lk.Unlock()
+
+	for _, removed := range changes.Removed {
+		idx.remove(removed.ID, &removed.Proposal)
+	}
+	for _, added := range changes.Added {
+		idx.add(added.ID, &added.Proposal)
+	}
+	idx.tsk, idx.proposals = ts.Key(), proposals
+	return nil
+}
+
+func (idx *DealIndex) rebuild(ts *types.TipSet, proposals market.DealProposals) error {
+	byClient := make(map[address.Address]dealSet)
+	byProvider := make(map[address.Address]dealSet)
+	err := proposals.ForEach(func(id abi.DealID, proposal market.DealProposal) error {
+		addToSet(byClient, proposal.Client, id)
+		addToSet(byProvider, proposal.Provider, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	idx.lk.Lock()
+	defer idx.lk.Unlock()
+
+	idx.byClient, idx.byProvider = byClient, byProvider
+	idx.tsk, idx.proposals = ts.Key(), proposals
+	dealIndexLog.Infof("deal index built at %d with %d clients and %d providers", ts.Height(), len(byClient), len(byProvider))
+	return nil
+}
+
+func (idx *DealIndex) add(id abi.DealID, proposal *market.DealProposal) {
+	addToSet(idx.byClient, proposal.Client, id)
+	addToSet(idx.byProvider, proposal.Provider, id)
+}
+
+func (idx *DealIndex) remove(id abi.DealID, proposal *market.DealProposal) {
+	removeFromSet(idx.byClient, proposal.Client, id)
+	removeFromSet(idx.byProvider, proposal.Provider, id)
+}
+
+func addToSet(sets map[address.Address]dealSet, key address.Address, id abi.DealID) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(dealSet)
+		sets[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func removeFromSet(sets map[address.Address]dealSet, key address.Address, id abi.DealID) {
+	set, ok := sets[key]
+	if !ok {
+		return
+	}
+	delete(set, id)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
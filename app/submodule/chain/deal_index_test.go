@@ -0,0 +1,125 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
+	adt0 "github.com/filecoin-project/specs-actors/actors/util/adt"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	pstate "github.com/filecoin-project/venus/pkg/state"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestDealIndex(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	ts1 := builder.AppendOn(builder.Genesis(), 1)
+	ts2 := builder.AppendOn(ts1, 1)
+	store := adt.WrapStore(ctx, cbor.NewMemCborStore())
+
+	client1, client2 := types.RequireIDAddress(t, 100), types.RequireIDAddress(t, 101)
+	provider1, provider2 := types.RequireIDAddress(t, 1000), types.RequireIDAddress(t, 1001)
+	proposal := func(client, provider address.Address) market0.DealProposal {
+		return market0.DealProposal{
+			PieceCID:             types.CidFromString(t, "piece"),
+			PieceSize:            abi.PaddedPieceSize(2048),
+			Client:               client,
+			Provider:             provider,
+			StoragePricePerEpoch: big.Zero(),
+			ProviderCollateral:   big.Zero(),
+			ClientCollateral:     big.Zero(),
+		}
+	}
+	filter := func(client, provider *address.Address) *pstate.MarketDealFilter {
+		return &pstate.MarketDealFilter{Client: client, Provider: provider}
+	}
+
+	idx := NewDealIndex(nil)
+	_, ok := idx.Candidates(ts1, filter(&client1, nil))
+	assert.False(t, ok)
+
+	require.NoError(t, idx.apply(ts1, requireDealProposals(ctx, t, store, map[abi.DealID]market0.DealProposal{
+		0: proposal(client1, provider1),
+		1: proposal(client2, provider1),
+		2: proposal(client1, provider2),
+	})))
+
+	ids, ok := idx.Candidates(ts1, filter(&client1, nil))
+	assert.True(t, ok)
+	assert.Equal(t, []abi.DealID{0, 2}, ids)
+	ids, _ = idx.Candidates(ts1, filter(nil, &provider1))
+	assert.Equal(t, []abi.DealID{0, 1}, ids)
+	ids, _ = idx.Candidates(ts1, filter(&client1, &provider1))
+	assert.Equal(t, []abi.DealID{0}, ids)
+	ids, _ = idx.Candidates(ts1, filter(&client2, &provider2))
+	assert.Empty(t, ids)
+
+	// the index can only answer queries on a client or a provider, at the indexed tipset
+	_, ok = idx.Candidates(ts1, filter(nil, nil))
+	assert.False(t, ok)
+	_, ok = idx.Candidates(ts2, filter(&client1, nil))
+	assert.False(t, ok)
+
+	// deal 0 expired and deal 3 was published, the index is updated from the diff
+	require.NoError(t, idx.apply(ts2, requireDealProposals(ctx, t, store, map[abi.DealID]market0.DealProposal{
+		1: proposal(client2, provider1),
+		2: proposal(client1, provider2),
+		3: proposal(client1, provider1),
+	})))
+
+	ids, ok = idx.Candidates(ts2, filter(&client1, nil))
+	assert.True(t, ok)
+	assert.Equal(t, []abi.DealID{2, 3}, ids)
+	ids, _ = idx.Candidates(ts2, filter(nil, &provider1))
+	assert.Equal(t, []abi.DealID{1, 3}, ids)
+	_, ok = idx.Candidates(ts1, filter(&client1, nil))
+	assert.False(t, ok)
+
+	// a provider without deals left is dropped from the index
+	require.NoError(t, idx.apply(ts1, requireDealProposals(ctx, t, store, map[abi.DealID]market0.DealProposal{
+		1: proposal(client2, provider1),
+		3: proposal(client1, provider1),
+	})))
+	ids, _ = idx.Candidates(ts1, filter(nil, &provider2))
+	assert.Empty(t, ids)
+	assert.NotContains(t, idx.byProvider, provider2)
+}
+
+func requireDealProposals(ctx context.Context, t *testing.T, store adt.Store, proposals map[abi.DealID]market0.DealProposal) market.DealProposals {
+	emptyArray, err := adt0.MakeEmptyArray(store).Root()
+	require.NoError(t, err)
+	emptyMap, err := adt0.MakeEmptyMap(store).Root()
+	require.NoError(t, err)
+	st := market0.ConstructState(emptyArray, emptyMap, emptyMap)
+
+	proposalArray, err := adt0.AsArray(store, st.Proposals)
+	require.NoError(t, err)
+	for id := range proposals {
+		proposal := proposals[id]
+		require.NoError(t, proposalArray.Set(uint64(id), &proposal))
+	}
+	st.Proposals, err = proposalArray.Root()
+	require.NoError(t, err)
+
+	head, err := store.Put(ctx, st)
+	require.NoError(t, err)
+	marketState, err := market.Load(store, &types.Actor{Code: builtin0.StorageMarketActorCodeID, Head: head})
+	require.NoError(t, err)
+	out, err := marketState.Proposals()
+	require.NoError(t, err)
+	return out
+}
@@ -2,6 +2,8 @@ package chain
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
@@ -21,6 +23,7 @@ import (
 	pstate "github.com/filecoin-project/venus/pkg/state"
 	"github.com/filecoin-project/venus/pkg/state/tree"
 	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
 )
 
 var _ apiface.IMinerState = &minerStateAPI{}
@@ -547,6 +550,84 @@ func (msa *minerStateAPI) StateCirculatingSupply(ctx context.Context, tsk types.
 	return msa.ChainReader.StateCirculatingSupply(ctx, tsk)
 }
 
+// StateTokenomics breaks down the supply of FIL in the parent state of the given tipset, along with the
+// funds burnt by the messages of its parent tipset. Summing the fee debts walks every miner, so it is slow.
+func (msa *minerStateAPI) StateTokenomics(ctx context.Context, tsk types.TipSetKey) (*apitypes.Tokenomics, error) {
+	ts, err := msa.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+	view, err := msa.ChainReader.ParentStateView(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading view %s: %v", tsk, err)
+	}
+	sTree, err := tree.LoadState(ctx, msa.ChainReader.Store(ctx), ts.Blocks()[0].ParentStateRoot)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state tree: %v", err)
+	}
+
+	cs, err := msa.ChainReader.GetCirculatingSupplyDetailed(ctx, ts.Height(), sTree)
+	if err != nil {
+		return nil, xerrors.Errorf("getting circulating supply: %v", err)
+	}
+	out := &apitypes.Tokenomics{
+		Height:              ts.Height(),
+		FilVested:           cs.FilVested,
+		FilMined:            cs.FilMined,
+		FilBurnt:            cs.FilBurnt,
+		FilLocked:           cs.FilLocked,
+		FilCirculating:      cs.FilCirculating,
+		FilReserveDisbursed: cs.FilReserveDisbursed,
+		BaseFee:             ts.Blocks()[0].ParentBaseFee,
+		BaseFeeBurn:         big.Zero(),
+		OverEstimationBurn:  big.Zero(),
+	}
+
+	if out.TotalPledge, out.MarketLocked, err = view.StateLockedFunds(ctx); err != nil {
+		return nil, err
+	}
+	if out.MinerFeeDebt, err = view.StateTotalFeeDebt(ctx); err != nil {
+		return nil, err
+	}
+
+	if ts.Height() == 0 {
+		return out, nil
+	}
+	if err := msa.parentBurns(ctx, ts, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// parentBurns sets the base fee of the messages of the parent tipset of `ts` and the funds
+// they burnt.
+func (msa *minerStateAPI) parentBurns(ctx context.Context, ts *types.TipSet, out *apitypes.Tokenomics) error {
+	pts, err := msa.ChainReader.GetTipSet(ts.Parents())
+	if err != nil {
+		return xerrors.Errorf("loading parent tipset: %v", err)
+	}
+	// the messages of a tipset are executed with the base fee in its own headers
+	out.BaseFee = pts.Blocks()[0].ParentBaseFee
+	msgs, err := msa.MessageStore.MessagesForTipset(pts)
+	if err != nil {
+		return xerrors.Errorf("loading parent messages: %v", err)
+	}
+	receipts, err := msa.MessageStore.LoadReceipts(ctx, ts.Blocks()[0].ParentMessageReceipts)
+	if err != nil {
+		return xerrors.Errorf("loading parent receipts: %v", err)
+	}
+	if len(msgs) != len(receipts) {
+		return xerrors.Errorf("got %d parent messages but %d receipts", len(msgs), len(receipts))
+	}
+	for i, m := range msgs {
+		msg := m.VMMessage()
+		outputs := gas.ComputeGasOutputs(receipts[i].GasUsed, msg.GasLimit, out.BaseFee, msg.GasFeeCap, msg.GasPremium, true)
+		out.BaseFeeBurn = big.Add(out.BaseFeeBurn, outputs.BaseFeeBurn)
+		out.OverEstimationBurn = big.Add(out.OverEstimationBurn, outputs.OverEstimationBurn)
+	}
+	return nil
+}
+
 // StateMarketDeals returns information about every deal in the Storage Market
 func (msa *minerStateAPI) StateMarketDeals(ctx context.Context, tsk types.TipSetKey) (map[string]pstate.MarketDeal, error) {
 	ts, err := msa.ChainReader.GetTipSet(tsk)
@@ -561,6 +642,41 @@ func (msa *minerStateAPI) StateMarketDeals(ctx context.Context, tsk types.TipSet
 	return view.StateMarketDeals(ctx, tsk)
 }
 
+// StateMarketDealsQuery returns, in ascending id order, up to `limit` published deals matching the filter
+// whose id is not lower than `cursor`. When the returned page has More set, the next page starts at its Next cursor.
+func (msa *minerStateAPI) StateMarketDealsQuery(ctx context.Context, tsk types.TipSetKey, filter pstate.MarketDealFilter, cursor abi.DealID, limit int) (*pstate.MarketDealsPage, error) {
+	ts, err := msa.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get tipset %v", err)
+	}
+	view, err := msa.ChainReader.ParentStateView(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading view %s: %v", tsk, err)
+	}
+
+	// deal proposals reference clients and providers by id address
+	if filter.Client != nil {
+		client, err := view.InitResolveAddress(ctx, *filter.Client)
+		if err != nil {
+			return nil, xerrors.Errorf("resolving client %s: %v", filter.Client, err)
+		}
+		filter.Client = &client
+	}
+	if filter.Provider != nil {
+		provider, err := view.InitResolveAddress(ctx, *filter.Provider)
+		if err != nil {
+			return nil, xerrors.Errorf("resolving provider %s: %v", filter.Provider, err)
+		}
+		filter.Provider = &provider
+	}
+
+	var candidates []abi.DealID
+	if msa.DealIndex != nil {
+		candidates, _ = msa.DealIndex.Candidates(ts, &filter)
+	}
+	return view.MarketDealsQuery(ctx, &filter, candidates, cursor, limit)
+}
+
 // StateMinerActiveSectors returns info about sectors that a given miner is actively proving.
 func (msa *minerStateAPI) StateMinerActiveSectors(ctx context.Context, maddr address.Address, tsk types.TipSetKey) ([]*miner.SectorOnChainInfo, error) { // TODO: only used in cli
 	ts, err := msa.ChainReader.GetTipSet(tsk)
@@ -697,6 +813,121 @@ func (msa *minerStateAPI) StateMinerSectorCount(ctx context.Context, addr addres
 	if err != nil {
 		return apitypes.MinerSectors{}, err
 	}
+	return countMinerSectors(mas)
+}
+
+// StateMinerPowerAll returns the power claimed by every miner in the power actor and the network total
+func (msa *minerStateAPI) StateMinerPowerAll(ctx context.Context, tsk types.TipSetKey) (*apitypes.MinerPowerAll, error) {
+	ts, err := msa.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+
+	view, err := msa.ChainReader.ParentStateView(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading view %s: %v", tsk, err)
+	}
+
+	claims, tpow, err := view.StateMinerPowerAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &apitypes.MinerPowerAll{
+		Miners:     make(map[string]power.Claim, len(claims)),
+		TotalPower: tpow,
+	}
+	for maddr, claim := range claims {
+		out.Miners[maddr.String()] = claim
+	}
+	return out, nil
+}
+
+// StateMinersSummary returns power, sectors, locked funds and peer id of every miner that has claimed power in the Power Actor
+func (msa *minerStateAPI) StateMinersSummary(ctx context.Context, tsk types.TipSetKey) ([]*apitypes.MinerSummary, error) {
+	ts, err := msa.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+
+	view, err := msa.ChainReader.ParentStateView(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading view %s: %v", tsk, err)
+	}
+
+	claims, _, err := view.StateMinerPowerAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the miners are listed by ID, not in the random order of the map
+	addrs := make([]address.Address, 0, len(claims))
+	for maddr := range claims {
+		addrs = append(addrs, maddr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return apitypes.LessAddress(addrs[i], addrs[j])
+	})
+
+	out := make([]*apitypes.MinerSummary, 0, len(claims))
+	for _, maddr := range addrs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		summary, err := msa.minerSummary(ctx, view, maddr, claims[maddr])
+		if err != nil {
+			return nil, xerrors.Errorf("summarizing miner %s: %v", maddr, err)
+		}
+		out = append(out, summary)
+	}
+	return out, nil
+}
+
+func (msa *minerStateAPI) minerSummary(ctx context.Context, view *pstate.View, maddr address.Address, claim power.Claim) (*apitypes.MinerSummary, error) {
+	act, err := view.LoadActor(ctx, maddr)
+	if err != nil {
+		return nil, err
+	}
+
+	mas, err := view.LoadMinerState(ctx, maddr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load miner actor state: %v", err)
+	}
+
+	info, err := mas.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := mas.LockedFunds()
+	if err != nil {
+		return nil, err
+	}
+
+	sectors, err := countMinerSectors(mas)
+	if err != nil {
+		return nil, err
+	}
+
+	hmp, err := view.MinerNominalPowerMeetsConsensusMinimum(ctx, maddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitypes.MinerSummary{
+		Address:     maddr,
+		PeerID:      info.PeerId,
+		SectorSize:  info.SectorSize,
+		Power:       claim,
+		HasMinPower: hmp,
+		Sectors:     sectors,
+		LockedFunds: locked,
+		Balance:     act.Balance,
+	}, nil
+}
+
+func countMinerSectors(mas miner.State) (apitypes.MinerSectors, error) {
 	var activeCount, liveCount, faultyCount uint64
 	if err := mas.ForEachDeadline(func(_ uint64, dl miner.Deadline) error {
 		return dl.ForEachPartition(func(_ uint64, part miner.Partition) error {
@@ -773,3 +1004,184 @@ func (msa *minerStateAPI) StateMarketBalance(ctx context.Context, addr address.A
 	return out, nil
 
 }
+
+// postGasEstimate is a generous estimate of the gas used by a SubmitWindowedPoSt message.
+const postGasEstimate = 100_000_000
+
+// StateMinerHealth reports, for each deadline of a miner, the next challenge window and the
+// state of its sectors, counting the live sectors expiring within `expiryWindow` epochs. It
+// warns about faults, fee debt, a worker short of funds for the next PoSts and partitions
+// of the open deadline still not proven late in the challenge window.
+func (msa *minerStateAPI) StateMinerHealth(ctx context.Context, maddr address.Address, expiryWindow abi.ChainEpoch, tsk types.TipSetKey) (*apitypes.MinerHealth, error) {
+	ts, err := msa.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+	view, err := msa.ChainReader.ParentStateView(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading view %s: %v", tsk, err)
+	}
+
+	mas, err := view.LoadMinerState(ctx, maddr)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load miner actor state: %v", err)
+	}
+	info, err := mas.Info()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get miner info: %v", err)
+	}
+
+	cur := ts.Height()
+	di, err := mas.DeadlineInfo(cur)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get deadline info: %v", err)
+	}
+	di = di.NextNotElapsed()
+
+	out := &apitypes.MinerHealth{
+		Miner:        maddr,
+		CurrentEpoch: cur,
+		Deadline:     di,
+		Worker:       info.Worker,
+	}
+
+	if out.FeeDebt, err = mas.FeeDebt(); err != nil {
+		return nil, xerrors.Errorf("getting fee debt: %v", err)
+	}
+	if out.AvailableBalance, err = view.StateMinerAvailableBalance(ctx, maddr, ts); err != nil {
+		return nil, xerrors.Errorf("getting available balance: %v", err)
+	}
+	worker, err := view.LoadActor(ctx, info.Worker)
+	if err != nil {
+		return nil, xerrors.Errorf("loading worker %s: %v", info.Worker, err)
+	}
+	out.WorkerBalance = worker.Balance
+
+	var provingDeadlines int64
+	if err := mas.ForEachDeadline(func(idx uint64, dl miner.Deadline) error {
+		next := dline.NewInfo(di.PeriodStart, idx, cur, di.WPoStPeriodDeadlines, di.WPoStProvingPeriod, di.WPoStChallengeWindow, di.WPoStChallengeLookback, di.FaultDeclarationCutoff).NextNotElapsed()
+		dh, err := deadlineHealth(dl, next, cur+expiryWindow)
+		if err != nil {
+			return xerrors.Errorf("deadline %d: %v", idx, err)
+		}
+		if dh.Live > 0 {
+			provingDeadlines++
+		}
+		out.Deadlines = append(out.Deadlines, *dh)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	baseFee := ts.Blocks()[0].ParentBaseFee
+	out.PoStCost = big.Mul(baseFee, big.NewInt(postGasEstimate*provingDeadlines))
+
+	out.Warnings = healthWarnings(out)
+	return out, nil
+}
+
+func deadlineHealth(dl miner.Deadline, di *dline.Info, expiresBefore abi.ChainEpoch) (*apitypes.DeadlineHealth, error) {
+	posted, err := dl.PartitionsPoSted()
+	if err != nil {
+		return nil, xerrors.Errorf("getting posted partitions: %v", err)
+	}
+
+	dh := &apitypes.DeadlineHealth{
+		Index: di.Index,
+		Open:  di.Open,
+		Close: di.Close,
+	}
+	err = dl.ForEachPartition(func(idx uint64, part miner.Partition) error {
+		live, err := part.LiveSectors()
+		if err != nil {
+			return xerrors.Errorf("getting LiveSectors: %v", err)
+		}
+		active, err := part.ActiveSectors()
+		if err != nil {
+			return xerrors.Errorf("getting ActiveSectors: %v", err)
+		}
+		faulty, err := part.FaultySectors()
+		if err != nil {
+			return xerrors.Errorf("getting FaultySectors: %v", err)
+		}
+		recovering, err := part.RecoveringSectors()
+		if err != nil {
+			return xerrors.Errorf("getting RecoveringSectors: %v", err)
+		}
+
+		counts := make([]uint64, 4)
+		for i, bf := range []bitfield.BitField{live, active, faulty, recovering} {
+			if counts[i], err = bf.Count(); err != nil {
+				return err
+			}
+		}
+		dh.Partitions++
+		dh.Live += counts[0]
+		dh.Active += counts[1]
+		dh.Faulty += counts[2]
+		dh.Recovering += counts[3]
+
+		proven, err := posted.IsSet(idx)
+		if err != nil {
+			return err
+		}
+		if proven {
+			dh.ProvenPartitions++
+		} else if di.IsOpen() {
+			// the non faulty sectors of the partition become faulty if it is not proven in time, and
+			// the recovering ones, which are counted in the faulty ones, stay faulty
+			dh.AtRisk += counts[0] - counts[2] + counts[3]
+		}
+
+		// the expiration queue holds the epochs of the live sectors, no sector is loaded
+		expiring, err := part.ExpiringSectors(expiresBefore)
+		if err != nil {
+			return xerrors.Errorf("getting ExpiringSectors: %v", err)
+		}
+		n, err := expiring.Count()
+		if err != nil {
+			return err
+		}
+		dh.Expiring += n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dh, nil
+}
+
+func healthWarnings(h *apitypes.MinerHealth) []string {
+	var warnings []string
+	if h.FeeDebt.GreaterThan(big.Zero()) {
+		warnings = append(warnings, fmt.Sprintf("miner has a fee debt of %s", types.FIL(h.FeeDebt)))
+	}
+	if h.WorkerBalance.LessThan(h.PoStCost) {
+		warnings = append(warnings, fmt.Sprintf("worker balance %s is lower than the estimated cost of the PoSts of a proving period %s",
+			types.FIL(h.WorkerBalance), types.FIL(h.PoStCost)))
+	}
+
+	var faulty, recovering, expiring uint64
+	for _, dh := range h.Deadlines {
+		faulty += dh.Faulty
+		recovering += dh.Recovering
+		expiring += dh.Expiring
+
+		if dh.AtRisk == 0 {
+			continue
+		}
+		// a PoSt is usually submitted early in the challenge window
+		left := dh.Close - h.CurrentEpoch
+		if left < h.Deadline.WPoStChallengeWindow/2 {
+			warnings = append(warnings, fmt.Sprintf("PoSt overdue: deadline %d closes in %d epochs with %d of %d partitions not proven, %d sectors at risk",
+				dh.Index, left, dh.Partitions-dh.ProvenPartitions, dh.Partitions, dh.AtRisk))
+		}
+	}
+	if faulty > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d faulty sectors, %d of them declared recovering", faulty, recovering))
+	}
+	if expiring > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d sectors expire soon", expiring))
+	}
+	return warnings
+}
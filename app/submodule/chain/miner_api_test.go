@@ -0,0 +1,216 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+	miner0 "github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	adt0 "github.com/filecoin-project/specs-actors/actors/util/adt"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/state/tree"
+	emptycid "github.com/filecoin-project/venus/pkg/testhelpers/empty_cid"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
+)
+
+func TestStateMinerHealth(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	store := adt.WrapStore(ctx, builder.Cstore())
+	maddr, worker := types.RequireIDAddress(t, 1000), types.RequireIDAddress(t, 100)
+
+	// deadline 0 is open at the height of the tipset, its sectors expire at the end of
+	// its challenge window in the proving period of their expiration
+	minerHead := requireMinerState(ctx, t, store, worker, []*miner0.SectorOnChainInfo{
+		requireSector(t, 1, 1000),  // 2939
+		requireSector(t, 2, 10000), // 11579
+	})
+	stateTree, err := tree.NewState(builder.Cstore(), tree.StateTreeVersion0)
+	require.NoError(t, err)
+	require.NoError(t, stateTree.SetActor(ctx, maddr, &types.Actor{Code: builtin0.StorageMinerActorCodeID, Head: minerHead, Balance: types.NewAttoFILFromFIL(10)}))
+	require.NoError(t, stateTree.SetActor(ctx, worker, &types.Actor{Code: builtin0.AccountActorCodeID, Head: minerHead, Balance: big.Zero()}))
+	root, err := stateTree.Flush(ctx)
+	require.NoError(t, err)
+
+	baseFee := abi.NewTokenAmount(100)
+	blk := newTestBlock(t, builder.Genesis(), 10)
+	blk.ParentStateRoot = root
+	blk.ParentBaseFee = baseFee
+	ts := requireTipSet(ctx, t, builder, blk)
+	api := &minerStateAPI{ChainSubmodule: &ChainSubmodule{ChainReader: builder.Store()}}
+
+	h, err := api.StateMinerHealth(ctx, maddr, 3000, ts.Key())
+	require.NoError(t, err)
+	assert.Equal(t, abi.ChainEpoch(10), h.CurrentEpoch)
+	assert.Equal(t, uint64(0), h.Deadline.Index)
+	assert.Equal(t, worker, h.Worker)
+	assert.Equal(t, types.NewAttoFILFromFIL(10), h.AvailableBalance)
+	assert.Equal(t, big.Mul(baseFee, big.NewInt(postGasEstimate)), h.PoStCost)
+
+	require.Len(t, h.Deadlines, int(miner0.WPoStPeriodDeadlines))
+	assert.Equal(t, apitypes.DeadlineHealth{
+		Index:      0,
+		Open:       0,
+		Close:      miner0.WPoStChallengeWindow,
+		Partitions: 1,
+		Live:       2,
+		Active:     2,
+		Expiring:   1,
+		AtRisk:     2,
+	}, h.Deadlines[0])
+	for _, dh := range h.Deadlines[1:] {
+		assert.Zero(t, dh.Partitions)
+	}
+	assert.Equal(t, []string{
+		"worker balance 0 FIL is lower than the estimated cost of the PoSts of a proving period 0.00000001 FIL",
+		"1 sectors expire soon",
+	}, h.Warnings)
+
+	// both sectors expire in a longer window, none in a shorter one
+	h, err = api.StateMinerHealth(ctx, maddr, 20000, ts.Key())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), h.Deadlines[0].Expiring)
+	h, err = api.StateMinerHealth(ctx, maddr, 100, ts.Key())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), h.Deadlines[0].Expiring)
+}
+
+func requireSector(t *testing.T, num abi.SectorNumber, expiration abi.ChainEpoch) *miner0.SectorOnChainInfo {
+	return &miner0.SectorOnChainInfo{
+		SectorNumber:          num,
+		SealProof:             abi.RegisteredSealProof_StackedDrg2KiBV1,
+		SealedCID:             types.CidFromString(t, "sealed"),
+		Expiration:            expiration,
+		DealWeight:            big.Zero(),
+		VerifiedDealWeight:    big.Zero(),
+		InitialPledge:         big.Zero(),
+		ExpectedDayReward:     big.Zero(),
+		ExpectedStoragePledge: big.Zero(),
+	}
+}
+
+// requireMinerState stores the state of a miner with a proving period starting at 0, and the
+// sectors in deadline 0.
+func requireMinerState(ctx context.Context, t *testing.T, store adt.Store, worker address.Address, sectors []*miner0.SectorOnChainInfo) cid.Cid {
+	emptyArray, err := adt0.MakeEmptyArray(store).Root()
+	require.NoError(t, err)
+	emptyMap, err := adt0.MakeEmptyMap(store).Root()
+	require.NoError(t, err)
+	emptyBitfield, err := store.Put(ctx, bitfield.NewFromSet(nil))
+	require.NoError(t, err)
+	emptyDeadline, err := store.Put(ctx, miner0.ConstructDeadline(emptyArray))
+	require.NoError(t, err)
+	emptyDeadlines, err := store.Put(ctx, miner0.ConstructDeadlines(emptyDeadline))
+	require.NoError(t, err)
+	emptyVestingFunds, err := store.Put(ctx, miner0.ConstructVestingFunds())
+	require.NoError(t, err)
+
+	info, err := miner0.ConstructMinerInfo(worker, worker, nil, nil, nil, abi.RegisteredSealProof_StackedDrg2KiBV1)
+	require.NoError(t, err)
+	infoCid, err := store.Put(ctx, info)
+	require.NoError(t, err)
+	st, err := miner0.ConstructState(infoCid, 0, emptyBitfield, emptyArray, emptyMap, emptyDeadlines, emptyVestingFunds)
+	require.NoError(t, err)
+
+	require.NoError(t, st.PutSectors(store, sectors...))
+	deadlines, err := st.LoadDeadlines(store)
+	require.NoError(t, err)
+	dl, err := deadlines.LoadDeadline(store, 0)
+	require.NoError(t, err)
+	_, err = dl.AddSectors(store, info.WindowPoStPartitionSectors, sectors, info.SectorSize, st.QuantSpecForDeadline(0))
+	require.NoError(t, err)
+	require.NoError(t, deadlines.UpdateDeadline(store, 0, dl))
+	require.NoError(t, st.SaveDeadlines(store, deadlines))
+
+	head, err := store.Put(ctx, st)
+	require.NoError(t, err)
+	return head
+}
+
+// newTestBlock returns a block on `parent` at height `h` without messages.
+func newTestBlock(t *testing.T, parent *types.TipSet, h abi.ChainEpoch) *types.BlockHeader {
+	return &types.BlockHeader{
+		Miner:                 types.RequireIDAddress(t, 1000),
+		Ticket:                types.Ticket{VRFProof: []byte{byte(h)}},
+		ElectionProof:         &types.ElectionProof{VRFProof: []byte{byte(h)}},
+		Parents:               parent.Key(),
+		ParentWeight:          big.Zero(),
+		Height:                h,
+		ParentStateRoot:       parent.At(0).ParentStateRoot,
+		ParentMessageReceipts: emptycid.EmptyReceiptsCID,
+		Messages:              emptycid.EmptyTxMetaCID,
+		ParentBaseFee:         big.Zero(),
+		BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+		BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+	}
+}
+
+// requireTipSet stores the block in the chain store of `builder`.
+func requireTipSet(ctx context.Context, t *testing.T, builder *chain.Builder, blk *types.BlockHeader) *types.TipSet {
+	_, err := builder.Cstore().Put(ctx, blk)
+	require.NoError(t, err)
+	return types.RequireNewTipSet(t, blk)
+}
+
+func TestStateTokenomicsParentBurns(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	emptyTree, err := tree.NewState(builder.Cstore(), tree.StateTreeVersion0)
+	require.NoError(t, err)
+	root, err := emptyTree.Flush(ctx)
+	require.NoError(t, err)
+
+	from, to := types.RequireIDAddress(t, 100), types.RequireIDAddress(t, 101)
+	msgs := []*types.UnsignedMessage{
+		{From: from, To: to, Nonce: 0, Value: big.Zero(), GasLimit: 2000, GasFeeCap: abi.NewTokenAmount(1000), GasPremium: abi.NewTokenAmount(1)},
+		{From: from, To: to, Nonce: 1, Value: big.Zero(), GasLimit: 10000, GasFeeCap: abi.NewTokenAmount(1000), GasPremium: abi.NewTokenAmount(1)},
+	}
+	receipts := []types.MessageReceipt{{GasUsed: 1000}, {GasUsed: 1000}}
+
+	// the messages of the parent are executed with the base fee of the parent, which
+	// differs from the one of the child
+	parentBlk := newTestBlock(t, builder.Genesis(), 1)
+	parentBlk.ParentStateRoot = root
+	parentBlk.ParentBaseFee = abi.NewTokenAmount(100)
+	parentBlk.Messages, err = builder.Mstore().StoreMessages(ctx, nil, msgs)
+	require.NoError(t, err)
+	parent := requireTipSet(ctx, t, builder, parentBlk)
+
+	blk := newTestBlock(t, parent, 2)
+	blk.ParentBaseFee = abi.NewTokenAmount(200)
+	blk.ParentMessageReceipts, err = builder.Mstore().StoreReceipts(ctx, receipts)
+	require.NoError(t, err)
+	ts := requireTipSet(ctx, t, builder, blk)
+
+	api := &minerStateAPI{ChainSubmodule: &ChainSubmodule{ChainReader: builder.Store(), MessageStore: builder.Mstore()}}
+	out := &apitypes.Tokenomics{BaseFeeBurn: big.Zero(), OverEstimationBurn: big.Zero()}
+	require.NoError(t, api.parentBurns(ctx, ts, out))
+
+	baseFee := abi.NewTokenAmount(100)
+	assert.Equal(t, baseFee, out.BaseFee)
+	assert.Equal(t, big.Mul(baseFee, big.NewInt(2000)), out.BaseFeeBurn)
+	overEstimation := big.Zero()
+	for i, msg := range msgs {
+		outputs := gas.ComputeGasOutputs(receipts[i].GasUsed, msg.GasLimit, baseFee, msg.GasFeeCap, msg.GasPremium, true)
+		overEstimation = big.Add(overEstimation, outputs.OverEstimationBurn)
+	}
+	assert.True(t, overEstimation.GreaterThan(big.Zero()))
+	assert.Equal(t, overEstimation, out.OverEstimationBurn)
+}
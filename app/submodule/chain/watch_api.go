@@ -0,0 +1,399 @@
+package chain
+
+import (
+	"context"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	logging "github.com/ipfs/go-log/v2"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/app/submodule/apiface"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/events"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// watchBuffer is the size of the channels of the watches, events are queued behind it
+// so that a slow client does not hold up the shared events.
+const watchBuffer = 16
+
+// watchQueueLimit is the number of events queued for a client, the watch is closed when
+// the client falls further behind.
+const watchQueueLimit = 1024
+
+var watchLog = logging.Logger("chain-watch")
+
+var errEventsNotStarted = xerrors.New("chain events are not started")
+
+// eventAPI adapts the chain api to the api of the events.
+type eventAPI struct {
+	apiface.IChain
+}
+
+var _ events.IEvent = &eventAPI{}
+
+func (e *eventAPI) ChainNotify(ctx context.Context) (<-chan []*chain.HeadChange, error) {
+	return e.IChain.ChainNotify(ctx), nil
+}
+
+// ChainWatchMessages sends an apply event for every message matching `match` once it is
+// `confidence` epochs deep in the chain, and a revert event when the tipset including it is
+// reverted. The watch ends, closing the channel, when the context is canceled or when the
+// client falls watchQueueLimit events behind.
+func (cia *chainInfoAPI) ChainWatchMessages(ctx context.Context, match apitypes.MessageMatch, confidence int) (<-chan apitypes.MessageEvent, error) {
+	evts := cia.chain.Events
+	if evts == nil {
+		return nil, errEventsNotStarted
+	}
+
+	// canceled when the client goes away or falls behind, which removes the watch
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan apitypes.MessageEvent, watchBuffer)
+	sink := newWatchSink(ctx, cancel, func(evt interface{}) bool {
+		select {
+		case out <- evt.(apitypes.MessageEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+
+	// the messages applied by tipset, to tell which ones a revert drops
+	applied := newWatchedTipSets()
+
+	check := func(ts *types.TipSet) (bool, bool, error) {
+		return false, ctx.Err() == nil, nil
+	}
+	hnd := func(msg *types.UnsignedMessage, rec *types.MessageReceipt, ts *types.TipSet, curH abi.ChainEpoch) (bool, error) {
+		if msg == nil {
+			// timeout
+			return ctx.Err() == nil, nil
+		}
+		applied.add(ts, curH, msg)
+		return sink.push(apitypes.MessageEvent{
+			Type:    apitypes.WatchApply,
+			TipSet:  ts.Key(),
+			Height:  ts.Height(),
+			Cid:     msg.Cid(),
+			Message: msg,
+			Receipt: rec,
+		}), nil
+	}
+	rev := func(_ context.Context, ts *types.TipSet) error {
+		for _, v := range applied.remove(ts) {
+			msg := v.(*types.UnsignedMessage)
+			sink.push(apitypes.MessageEvent{
+				Type:    apitypes.WatchRevert,
+				TipSet:  ts.Key(),
+				Height:  ts.Height(),
+				Cid:     msg.Cid(),
+				Message: msg,
+			})
+		}
+		return nil
+	}
+	mf := func(msg *types.UnsignedMessage) (bool, error) {
+		return matchMessage(match, msg), nil
+	}
+
+	if err := evts.CalledUntil(ctx, check, hnd, rev, confidence, events.NoTimeout, mf); err != nil {
+		cancel()
+		return nil, xerrors.Errorf("watching messages: %v", err)
+	}
+	return out, nil
+}
+
+func matchMessage(match apitypes.MessageMatch, msg *types.UnsignedMessage) bool {
+	if match.From != address.Undef && match.From != msg.From {
+		return false
+	}
+	if match.To != address.Undef && match.To != msg.To {
+		return false
+	}
+	if match.Method != nil && *match.Method != msg.Method {
+		return false
+	}
+	return true
+}
+
+// ChainWatchActor sends an apply event for every change of the actor `addr` once it is
+// `confidence` epochs deep in the chain, and a revert event when the tipset of the change is
+// reverted. The watch ends, closing the channel, when the context is canceled or when the
+// client falls watchQueueLimit events behind.
+func (cia *chainInfoAPI) ChainWatchActor(ctx context.Context, addr address.Address, confidence int) (<-chan apitypes.ActorEvent, error) {
+	evts := cia.chain.Events
+	if evts == nil {
+		return nil, errEventsNotStarted
+	}
+
+	// canceled when the client goes away or falls behind, which removes the watch
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan apitypes.ActorEvent, watchBuffer)
+	sink := newWatchSink(ctx, cancel, func(evt interface{}) bool {
+		select {
+		case out <- evt.(apitypes.ActorEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+
+	// the state of the actor before each applied change, sent back on revert
+	before := newWatchedTipSets()
+
+	check := func(ts *types.TipSet) (bool, bool, error) {
+		return false, ctx.Err() == nil, nil
+	}
+	hnd := func(oldTs, newTs *types.TipSet, states events.StateChange, curH abi.ChainEpoch) (bool, error) {
+		change, ok := states.(*actorChange)
+		if !ok {
+			// timeout
+			return ctx.Err() == nil, nil
+		}
+		before.add(newTs, curH, change.old)
+		return sink.push(apitypes.ActorEvent{
+			Type:   apitypes.WatchApply,
+			TipSet: newTs.Key(),
+			Height: newTs.Height(),
+			Actor:  change.new,
+		}), nil
+	}
+	rev := func(_ context.Context, ts *types.TipSet) error {
+		// the actor changes once by tipset
+		for _, v := range before.remove(ts) {
+			sink.push(apitypes.ActorEvent{
+				Type:   apitypes.WatchRevert,
+				TipSet: ts.Key(),
+				Height: ts.Height(),
+				Actor:  v.(*types.Actor),
+			})
+		}
+		return nil
+	}
+	mf := func(oldTs, newTs *types.TipSet) (bool, events.StateChange, error) {
+		if ctx.Err() != nil {
+			return false, nil, nil
+		}
+		oldAct, err := cia.loadActorOrNil(ctx, oldTs, addr)
+		if err != nil {
+			return false, nil, err
+		}
+		newAct, err := cia.loadActorOrNil(ctx, newTs, addr)
+		if err != nil {
+			return false, nil, err
+		}
+		if actorEqual(oldAct, newAct) {
+			return false, nil, nil
+		}
+		return true, &actorChange{old: oldAct, new: newAct}, nil
+	}
+
+	if err := evts.StateChangedUntil(ctx, check, hnd, rev, confidence, events.NoTimeout, mf); err != nil {
+		cancel()
+		return nil, xerrors.Errorf("watching actor %s: %v", addr, err)
+	}
+	return out, nil
+}
+
+type actorChange struct {
+	old, new *types.Actor
+}
+
+// loadActorOrNil loads the actor in the parent state of `ts`, it returns nil if the actor does not exist.
+func (cia *chainInfoAPI) loadActorOrNil(ctx context.Context, ts *types.TipSet, addr address.Address) (*types.Actor, error) {
+	view, err := cia.chain.ChainReader.ParentStateView(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state of tipset %s: %v", ts.Key(), err)
+	}
+	act, err := view.LoadActor(ctx, addr)
+	if xerrors.Is(err, types.ErrActorNotFound) {
+		return nil, nil
+	}
+	return act, err
+}
+
+func actorEqual(a, b *types.Actor) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Code.Equals(b.Code) && a.Head.Equals(b.Head) && a.Nonce == b.Nonce && a.Balance.Equals(b.Balance)
+}
+
+// ChainWatchHeight sends an apply event when the chain reaches the height `h` by
+// `confidence` epochs, and a revert event when the chain is rolled back under `h`, after
+// which it is sent an apply event again once the height is reached. The watch ends, closing
+// the channel, when the context is canceled, when the client falls watchQueueLimit events
+// behind, or after the apply event when the height is final already.
+func (cia *chainInfoAPI) ChainWatchHeight(ctx context.Context, h abi.ChainEpoch, confidence int) (<-chan apitypes.HeightEvent, error) {
+	evts := cia.chain.Events
+	if evts == nil {
+		return nil, errEventsNotStarted
+	}
+
+	// canceled when the client goes away or falls behind, which removes the watch
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan apitypes.HeightEvent, watchBuffer)
+	sink := newWatchSink(ctx, cancel, func(evt interface{}) bool {
+		select {
+		case out <- evt.(apitypes.HeightEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+
+	event := func(typ apitypes.WatchEventType, ts *types.TipSet) apitypes.HeightEvent {
+		evt := apitypes.HeightEvent{Type: typ, Height: h}
+		if ts != nil {
+			evt.TipSet = ts.Key()
+			evt.Height = ts.Height()
+		}
+		return evt
+	}
+	hnd := func(_ context.Context, ts *types.TipSet, curH abi.ChainEpoch) error {
+		sink.push(event(apitypes.WatchApply, ts))
+		return nil
+	}
+	rev := func(_ context.Context, ts *types.TipSet) error {
+		sink.push(event(apitypes.WatchRevert, ts))
+		return nil
+	}
+
+	watching, err := evts.ChainAtUntil(ctx, hnd, rev, confidence, h)
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("watching height %d: %v", h, err)
+	}
+	if !watching {
+		// the height is final, it is not reverted anymore
+		sink.finish()
+	}
+	return out, nil
+}
+
+// watchedTipSets keeps what a watch applied by tipset, to send it back when the tipset is
+// reverted. The tipsets deeper than events.GCConfidence are dropped as the chain grows, they
+// are not reverted anymore.
+type watchedTipSets struct {
+	lk      sync.Mutex
+	heights map[types.TipSetKey]abi.ChainEpoch
+	applied map[types.TipSetKey][]interface{}
+}
+
+func newWatchedTipSets() *watchedTipSets {
+	return &watchedTipSets{
+		heights: map[types.TipSetKey]abi.ChainEpoch{},
+		applied: map[types.TipSetKey][]interface{}{},
+	}
+}
+
+// add records `v` as applied at `ts` when the chain is at the height `curH`.
+func (w *watchedTipSets) add(ts *types.TipSet, curH abi.ChainEpoch, v interface{}) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	for key, h := range w.heights {
+		if h+events.GCConfidence < curH {
+			delete(w.heights, key)
+			delete(w.applied, key)
+		}
+	}
+	w.heights[ts.Key()] = ts.Height()
+	w.applied[ts.Key()] = append(w.applied[ts.Key()], v)
+}
+
+// remove returns what was applied at `ts` and forgets it.
+func (w *watchedTipSets) remove(ts *types.TipSet) []interface{} {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	applied := w.applied[ts.Key()]
+	delete(w.heights, ts.Key())
+	delete(w.applied, ts.Key())
+	return applied
+}
+
+// watchSink queues the events of a watch and sends them to the client until its context
+// is done, the handlers of the events must not block. The watch is canceled when more
+// than watchQueueLimit events are waiting.
+type watchSink struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lk     sync.Mutex
+	queue  []interface{}
+	notify chan struct{}
+	// finished is set once no more events are pushed, the watch ends when the queue is sent
+	finished bool
+}
+
+func newWatchSink(ctx context.Context, cancel context.CancelFunc, send func(evt interface{}) bool, done func()) *watchSink {
+	s := &watchSink{
+		ctx:    ctx,
+		cancel: cancel,
+		notify: make(chan struct{}, 1),
+	}
+
+	go func() {
+		defer done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.notify:
+			}
+
+			s.lk.Lock()
+			queue, finished := s.queue, s.finished
+			s.queue = nil
+			s.lk.Unlock()
+
+			for _, evt := range queue {
+				if !send(evt) {
+					return
+				}
+			}
+			if finished {
+				s.cancel()
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// finish ends the watch once the events queued are sent.
+func (s *watchSink) finish() {
+	s.lk.Lock()
+	s.finished = true
+	s.lk.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// push queues an event, it returns false once the watch ended.
+func (s *watchSink) push(evt interface{}) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	s.lk.Lock()
+	if len(s.queue) >= watchQueueLimit {
+		s.lk.Unlock()
+		watchLog.Warnf("closing a watch, %d events are waiting for the client", watchQueueLimit)
+		s.cancel()
+		return false
+	}
+	s.queue = append(s.queue, evt)
+	s.lk.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return true
+}
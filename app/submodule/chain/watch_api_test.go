@@ -0,0 +1,106 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/events"
+	"github.com/filecoin-project/venus/pkg/testhelpers"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func newTestSink(ctx context.Context) (*watchSink, context.Context, <-chan int) {
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan int)
+	sink := newWatchSink(ctx, cancel, func(evt interface{}) bool {
+		select {
+		case out <- evt.(int):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(out) })
+	return sink, ctx, out
+}
+
+func TestWatchSink(t *testing.T) {
+	tf.UnitTest(t)
+
+	parent, cancel := context.WithCancel(context.Background())
+	sink, ctx, out := newTestSink(parent)
+
+	for i := 0; i < 3; i++ {
+		require.True(t, sink.push(i))
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, <-out)
+	}
+
+	cancel()
+	_, ok := <-out
+	assert.False(t, ok)
+	assert.Error(t, ctx.Err())
+	assert.False(t, sink.push(3))
+}
+
+func TestWatchSinkFinish(t *testing.T) {
+	tf.UnitTest(t)
+
+	sink, ctx, out := newTestSink(context.Background())
+
+	require.True(t, sink.push(0))
+	require.True(t, sink.push(1))
+	sink.finish()
+
+	// the events queued are sent before the channel is closed
+	var got []int
+	for evt := range out {
+		got = append(got, evt)
+	}
+	assert.Equal(t, []int{0, 1}, got)
+	assert.Error(t, ctx.Err())
+}
+
+func TestWatchSinkClosesWhenClientFallsBehind(t *testing.T) {
+	tf.UnitTest(t)
+
+	sink, ctx, out := newTestSink(context.Background())
+
+	// nothing is read from the channel, the queue fills up
+	pushed := 0
+	for sink.push(pushed) {
+		pushed++
+		require.LessOrEqual(t, pushed, watchQueueLimit+1)
+	}
+	assert.Error(t, ctx.Err())
+	assert.False(t, sink.push(pushed))
+
+	for range out {
+	}
+}
+
+func TestWatchedTipSetsDropsDeepTipSets(t *testing.T) {
+	tf.UnitTest(t)
+
+	w := newWatchedTipSets()
+	old := testhelpers.RequireTipsetWithHeight(t, 10)
+	recent := testhelpers.RequireTipsetWithHeight(t, 20)
+
+	w.add(old, old.Height(), "old")
+	w.add(recent, recent.Height(), "recent")
+	w.add(recent, recent.Height(), "recent again")
+	assert.Equal(t, []interface{}{"old"}, w.remove(old))
+	assert.Nil(t, w.remove(old))
+
+	w.add(old, old.Height(), "old")
+	// the chain grows past the gc confidence of the old tipset
+	head := testhelpers.RequireTipsetWithHeight(t, old.Height()+events.GCConfidence+1)
+	w.add(head, head.Height(), "head")
+	assert.Nil(t, w.remove(old))
+	assert.Equal(t, []interface{}{"recent", "recent again"}, w.remove(recent))
+	assert.Len(t, w.applied, 1)
+	assert.Len(t, w.heights, 1)
+}
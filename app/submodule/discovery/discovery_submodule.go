@@ -6,6 +6,7 @@ import (
 	"github.com/filecoin-project/venus/app/submodule/network"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -67,6 +68,22 @@ func NewDiscoverySubmodule(ctx context.Context,
 
 	minPeerThreshold := config.Bootstrap.MinPeerThreshold
 
+	limitCfg := config.PeerRateLimit
+	whitelist := make([]peer.ID, 0, len(limitCfg.Whitelist))
+	for _, s := range limitCfg.Whitelist {
+		p, err := peer.Decode(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse whitelisted peer %s", s)
+		}
+		whitelist = append(whitelist, p)
+	}
+	// a request of the maximum length must fit in the budgets of the exchange, or it would never be served
+	for name, perMinute := range map[string]int64{"headers": limitCfg.HeadersPerMinute, "messages": limitCfg.MessagesPerMinute} {
+		if perMinute > 0 && perMinute < int64(exchange.MaxRequestLength) {
+			return nil, errors.Errorf("%s per minute of the peer rate limit must be at least %d, the maximum length of a request", name, exchange.MaxRequestLength)
+		}
+	}
+
 	exchangeClient := exchange.NewClient(network.Host, network.PeerMgr)
 	// create a bootstrapper
 	bootstrapper := discovery.NewBootstrapper(bpi, network.Host, network.Host.Network(), network.Router, minPeerThreshold, period)
@@ -76,14 +93,20 @@ func NewDiscoverySubmodule(ctx context.Context,
 
 	bootStrapReady := moresync.NewLatch(uint(minPeerThreshold))
 
+	helloHandler := discovery.NewHelloProtocolHandler(network.Host, network.PeerMgr, exchangeClient, chainStore, messageStore, genesiGetter.GenesisCid(), time.Duration(config.NetworkParams.BlockDelay)*time.Second,
+		net.NewPeerLimiter(limitCfg.HelloPerMinute, whitelist))
+	exchangeServer := exchange.NewServer(chainStore, messageStore, network.Host,
+		net.NewPeerLimiter(limitCfg.HeadersPerMinute, whitelist),
+		net.NewPeerLimiter(limitCfg.MessagesPerMinute, whitelist))
+
 	return &DiscoverySubmodule{
 		host:            network.Host,
 		Bootstrapper:    bootstrapper,
 		BootstrapReady:  bootStrapReady,
 		PeerTracker:     peerTracker,
 		ExchangeClient:  exchangeClient,
-		HelloHandler:    discovery.NewHelloProtocolHandler(network.Host, network.PeerMgr, exchangeClient, chainStore, messageStore, genesiGetter.GenesisCid(), time.Duration(config.NetworkParams.BlockDelay)*time.Second),
-		ExchangeHandler: exchange.NewServer(chainStore, messageStore, network.Host),
+		HelloHandler:    helloHandler,
+		ExchangeHandler: exchangeServer,
 		PeerDiscoveryCallbacks: []discovery.PeerDiscoveredCallback{func(msg *types.ChainInfo) {
 			bootStrapReady.Done()
 		}},
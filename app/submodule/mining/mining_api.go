@@ -266,7 +266,8 @@ func (miningAPI *MiningAPI) minerCreateBlock(ctx context.Context, bt *apitypes.B
 	if bHas {
 		nosigbytes := next.SignatureData()
 		sig, err := miningAPI.Ming.Wallet.API().WalletSign(ctx, worker, nosigbytes, wallet.MsgMeta{
-			Type: wallet.MTBlock,
+			Type:  wallet.MTBlock,
+			Extra: nosigbytes,
 		})
 		if err != nil {
 			return nil, xerrors.Errorf("failed to sign new block: %v", err)
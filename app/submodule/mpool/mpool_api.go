@@ -302,6 +302,18 @@ func (a *MessagePoolAPI) GasEstimateGasPremium(ctx context.Context, nblocksincl
 	return a.mp.MPool.GasEstimateGasPremium(ctx, nblocksincl, sender, gaslimit, tsk, a.mp.MPool.PriceCache)
 }
 
+// GasFeeHistory returns the base fees and the gas premium percentiles of the
+// last `blocks` tipsets ending at `tsk`.
+func (a *MessagePoolAPI) GasFeeHistory(ctx context.Context, blocks int, percentiles []float64, tsk types.TipSetKey) (*messagepool.FeeHistory, error) {
+	return a.mp.MPool.GasFeeHistory(ctx, blocks, percentiles, tsk)
+}
+
+// GasEstimateInclusion estimates the gas premiums and fee caps with which a message
+// of `gaslimit` gas should be included within each number of epochs of `targets`.
+func (a *MessagePoolAPI) GasEstimateInclusion(ctx context.Context, gaslimit int64, targets []uint64, tsk types.TipSetKey) ([]messagepool.InclusionEstimate, error) {
+	return a.mp.MPool.GasEstimateInclusion(ctx, gaslimit, targets, tsk)
+}
+
 func (a *MessagePoolAPI) MpoolCheckMessages(ctx context.Context, protos []*apitypes.MessagePrototype) ([][]apitypes.MessageCheckStatus, error) {
 	return a.mp.MPool.CheckMessages(ctx, protos)
 }
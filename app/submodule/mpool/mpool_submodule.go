@@ -25,8 +25,8 @@ import (
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/consensus"
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/messagepool"
-	"github.com/filecoin-project/venus/pkg/messagepool/journal"
 	"github.com/filecoin-project/venus/pkg/net/msgsub"
 	"github.com/filecoin-project/venus/pkg/net/pubsub"
 	"github.com/filecoin-project/venus/pkg/repo"
@@ -50,6 +50,7 @@ var log = logging.Logger("mpool")
 
 type messagepoolConfig interface {
 	Repo() repo.Repo
+	Journal() journal.Journal
 }
 
 // MessagingSubmodule enhances the `Node` with internal message capabilities.
@@ -66,15 +67,6 @@ type MessagePoolSubmodule struct { //nolint
 	networkCfg *config.NetworkParamsConfig
 }
 
-func OpenFilesystemJournal(lr repo.Repo) (journal.Journal, error) {
-	jrnl, err := journal.OpenFSJournal(lr, journal.EnvDisabledEvents())
-	if err != nil {
-		return nil, err
-	}
-
-	return jrnl, err
-}
-
 func NewMpoolSubmodule(cfg messagepoolConfig,
 	network *network.NetworkSubmodule,
 	chain *chain.ChainSubmodule,
@@ -84,12 +76,8 @@ func NewMpoolSubmodule(cfg messagepoolConfig,
 ) (*MessagePoolSubmodule, error) {
 	mpp := messagepool.NewProvider(chain.ChainReader, chain.MessageStore, cfg.Repo().Config().NetworkParams, network.Pubsub)
 
-	j, err := OpenFilesystemJournal(cfg.Repo())
-	if err != nil {
-		return nil, err
-	}
 	mp, err := messagepool.New(mpp, cfg.Repo().MetaDatastore(), cfg.Repo().Config().NetworkParams.ForkUpgradeParam, cfg.Repo().Config().Mpool,
-		network.NetworkName, syncer.Consensus, chain.ChainReader, j)
+		network.NetworkName, syncer.Consensus, chain.ChainReader, cfg.Journal())
 	if err != nil {
 		return nil, xerrors.Errorf("constructing mpool: %s", err)
 	}
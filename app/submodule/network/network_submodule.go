@@ -101,6 +101,7 @@ type networkConfig interface {
 	OfflineMode() bool
 	IsRelay() bool
 	Libp2pOpts() []libp2p.Option
+	Libp2pHost() host.Host
 }
 
 type networkRepo interface {
@@ -158,9 +159,17 @@ func NewNetworkSubmodule(ctx context.Context, config networkConfig, repo network
 		return r, err
 	}
 
-	peerHost, err = buildHost(ctx, config, libP2pOpts, repo, makeDHT)
-	if err != nil {
-		return nil, err
+	if h := config.Libp2pHost(); h != nil {
+		// the host is given, eg. by a mocknet, only the routing is set up on it
+		if _, err := makeDHT(h); err != nil {
+			return nil, err
+		}
+		peerHost = h
+	} else {
+		peerHost, err = buildHost(ctx, config, libP2pOpts, repo, makeDHT)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// require message signing in online mode when we have priv key
 	pubsubMessageSigning = true
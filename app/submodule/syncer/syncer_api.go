@@ -6,10 +6,15 @@ import (
 
 	"github.com/filecoin-project/venus/app/submodule/apiface"
 	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/chain"
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 	xerrors "github.com/pkg/errors"
 )
@@ -22,6 +27,26 @@ type syncerAPI struct { //nolint
 	syncer *SyncerSubmodule
 }
 
+// SubmitBlockEvt is the journal event of a block submitted through SyncSubmitBlock.
+type SubmitBlockEvt struct {
+	Block  cid.Cid
+	Miner  address.Address
+	Height abi.ChainEpoch
+}
+
+// SlashFilterEvt is the journal event of a submitted block rejected by the slash filter.
+type SlashFilterEvt struct {
+	Block  cid.Cid
+	Miner  address.Address
+	Height abi.ChainEpoch
+	Error  string
+}
+
+// JournalQuery returns the journal events recorded since `since`, filtered by system and event when not empty.
+func (sa *syncerAPI) JournalQuery(ctx context.Context, system, event string, since time.Time) ([]*journal.Event, error) {
+	return sa.syncer.Journal.Query(system, event, since)
+}
+
 // SyncerStatus returns the current status of the active or last active chain sync operation.
 func (sa *syncerAPI) SyncerTracker(ctx context.Context) *syncTypes.TargetTracker {
 	return sa.syncer.ChainSyncManager.BlockProposer().SyncTracker()
@@ -64,6 +89,14 @@ func (sa *syncerAPI) SyncSubmitBlock(ctx context.Context, blk *types.BlockMsg) e
 
 	if err := sa.syncer.SlashFilter.MinedBlock(blk.Header, parent.Height); err != nil {
 		log.Errorf("<!!> SLASH FILTER ERROR: %s", err)
+		sa.syncer.Journal.RecordEvent(sa.syncer.slashFilterEvtType, func() interface{} {
+			return SlashFilterEvt{
+				Block:  blk.Cid(),
+				Miner:  blk.Header.Miner,
+				Height: blk.Header.Height,
+				Error:  err.Error(),
+			}
+		})
 		return xerrors.Errorf("<!!> SLASH FILTER ERROR: %v", err)
 	}
 
@@ -100,6 +133,13 @@ func (sa *syncerAPI) SyncSubmitBlock(ctx context.Context, blk *types.BlockMsg) e
 	if err := sa.syncer.SyncProvider.HandleNewTipSet(ci); err != nil {
 		return xerrors.Errorf("sync to submitted block failed: %v", err)
 	}
+	sa.syncer.Journal.RecordEvent(sa.syncer.submitBlockEvtType, func() interface{} {
+		return SubmitBlockEvt{
+			Block:  blk.Cid(),
+			Miner:  blk.Header.Miner,
+			Height: blk.Header.Height,
+		}
+	})
 
 	b, err := blk.Serialize()
 	if err != nil {
@@ -114,6 +154,19 @@ func (sa *syncerAPI) SyncSubmitBlock(ctx context.Context, blk *types.BlockMsg) e
 	return nil
 }
 
+// ChainCheck walks the local chain back from the specified tipset down to `opts.Height`
+// and reports missing or inconsistent blocks, messages, receipts and state.
+func (sa *syncerAPI) ChainCheck(ctx context.Context, tsk types.TipSetKey, opts chain.CheckOptions) (*chain.CheckReport, error) {
+	chainModule := sa.syncer.ChainModule
+	ts, err := chainModule.ChainReader.GetTipSet(tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+	}
+
+	checker := chain.NewChecker(chainModule.ChainReader, chainModule.MessageStore, sa.syncer.Consensus, sa.syncer.DiscoverySubmodule.ExchangeClient)
+	return checker.Check(ctx, ts, opts)
+}
+
 // MethodGroup: State
 // The State methods are used to query, inspect, and interact with chain state.
 // Most methods take a TipSetKey as a parameter. The state looked up is the parent state of the tipset.
@@ -25,6 +25,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/chainsync/slashfilter"
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/consensus"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/net/blocksub"
 	"github.com/filecoin-project/venus/pkg/net/pubsub"
 	"github.com/filecoin-project/venus/pkg/repo"
@@ -51,8 +52,12 @@ type SyncerSubmodule struct { //nolint
 	SyncProvider     ChainSyncProvider
 	SlashFilter      slashfilter.ISlashFilter
 	BlockValidator   *consensus.BlockValidator
+	Journal          journal.Journal
 	// cancelChainSync cancels the context for chain sync subscriptions and handlers.
 	CancelChainSync context.CancelFunc
+
+	submitBlockEvtType journal.EventType
+	slashFilterEvtType journal.EventType
 }
 
 type syncerConfig interface {
@@ -60,6 +65,7 @@ type syncerConfig interface {
 	BlockTime() time.Duration
 	ChainClock() clock.ChainEpochClock
 	Repo() repo.Repo
+	Journal() journal.Journal
 }
 
 type nodeChainSelector interface {
@@ -122,7 +128,7 @@ func NewSyncerSubmodule(ctx context.Context,
 		chn.SystemCall,
 	)
 
-	chainSyncManager, err := chainsync.NewManager(nodeConsensus, blkValid, nodeChainSelector, chn.ChainReader, chn.MessageStore, blockstore.Blockstore, discovery.ExchangeClient, config.ChainClock(), chn.Fork)
+	chainSyncManager, err := chainsync.NewManager(nodeConsensus, blkValid, nodeChainSelector, chn.ChainReader, chn.MessageStore, blockstore.Blockstore, discovery.ExchangeClient, config.ChainClock(), chn.Fork, config.Journal())
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +165,9 @@ func NewSyncerSubmodule(ctx context.Context,
 		Drand:              chn.Drand,
 		SyncProvider:       *NewChainSyncProvider(&chainSyncManager),
 		BlockValidator:     blkValid,
+		Journal:            config.Journal(),
+		submitBlockEvtType: config.Journal().RegisterEventType("sync", "submit_block"),
+		slashFilterEvtType: config.Journal().RegisterEventType("sync", "slash_filter"),
 	}, nil
 }
 
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus/app/submodule/apiface"
+	pconfig "github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/crypto"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/wallet"
@@ -124,7 +126,10 @@ func (walletAPI *WalletAPI) WalletSignMessage(ctx context.Context, k address.Add
 		return nil, xerrors.Errorf("serializing message: %w", err)
 	}
 
-	sign, err := walletAPI.WalletSign(ctx, k, mb.Cid().Bytes(), wallet.MsgMeta{Type: wallet.MTChainMsg})
+	sign, err := walletAPI.WalletSign(ctx, k, mb.Cid().Bytes(), wallet.MsgMeta{
+		Type:  wallet.MTChainMsg,
+		Extra: mb.RawData(),
+	})
 	if err != nil {
 		return nil, xerrors.Errorf("failed to sign message: %w", err)
 	}
@@ -159,3 +164,57 @@ func (walletAPI *WalletAPI) HasPassword(Context context.Context) bool {
 func (walletAPI *WalletAPI) WalletState(Context context.Context) int {
 	return walletAPI.walletModule.Wallet.WalletState()
 }
+
+//WalletNewHDAddress derives a new address from the hd seed of the wallet, the mnemonic is
+//returned along when the seed is created by this call
+func (walletAPI *WalletAPI) WalletNewHDAddress(ctx context.Context, protocol address.Protocol) (*wallet.HDAddress, error) {
+	return walletAPI.walletModule.Wallet.NewHDAddress(protocol)
+}
+
+//WalletRestoreMnemonic restore the hd seed of the wallet from a mnemonic
+func (walletAPI *WalletAPI) WalletRestoreMnemonic(ctx context.Context, mnemonic string) error {
+	return walletAPI.walletModule.Wallet.RestoreHDSeed(mnemonic)
+}
+
+//WalletDeriveAddress derives the address at index from the hd seed of the wallet
+func (walletAPI *WalletAPI) WalletDeriveAddress(ctx context.Context, protocol address.Protocol, index uint32) (*wallet.HDAddress, error) {
+	return walletAPI.walletModule.Wallet.DeriveAddress(protocol, index)
+}
+
+//WalletDelete delete an address from the wallet, its key is kept in the trash of the wallet
+//and can be recovered with WalletUndelete
+func (walletAPI *WalletAPI) WalletDelete(ctx context.Context, addr address.Address) error {
+	def, err := walletAPI.WalletDefaultAddress(ctx)
+	if err != nil {
+		return err
+	}
+	if def == addr {
+		return errors.New("can not delete the default address, set another default address first")
+	}
+	return walletAPI.walletModule.Wallet.DeleteAddress(addr)
+}
+
+//WalletUndelete recover a deleted address from the trash of the wallet
+func (walletAPI *WalletAPI) WalletUndelete(ctx context.Context, addr address.Address) error {
+	return walletAPI.walletModule.Wallet.UndeleteAddress(addr)
+}
+
+//WalletChangePassword re-encrypt all the keys of the wallet with a new password
+func (walletAPI *WalletAPI) WalletChangePassword(ctx context.Context, oldPassword, newPassword []byte) error {
+	return walletAPI.walletModule.Wallet.ChangePassword(oldPassword, newPassword)
+}
+
+//WalletReencrypt re-encrypt all the keys of the wallet with new scrypt parameters, which are
+//saved in the config to encrypt the following keys
+func (walletAPI *WalletAPI) WalletReencrypt(ctx context.Context, password []byte, scryptN, scryptP int) error {
+	conf := pconfig.PassphraseConfig{ScryptN: scryptN, ScryptP: scryptP}
+	if err := walletAPI.walletModule.Wallet.Reencrypt(password, conf); err != nil {
+		return err
+	}
+
+	confJSON, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	return walletAPI.walletModule.Config.Set("walletModule.passphraseConfig", string(confJSON))
+}
@@ -48,6 +48,14 @@ func NewWalletSubmodule(ctx context.Context,
 		return nil, errors.Wrap(err, "failed to set up walletModule backend")
 	}
 	fcWallet := wallet.New(backend)
+	if policyCfg := repo.Config().Wallet.SignPolicy; policyCfg != nil {
+		policy, err := wallet.NewSignPolicy(policyCfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set up sign policy")
+		}
+		fcWallet.SetSignPolicy(policy)
+		log.Info("wallet sign policy set up")
+	}
 	headSigner := state.NewHeadSignView(chain.ChainReader)
 
 	var adapter wallet.WalletIntersection
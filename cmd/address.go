@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/cmd/tablewriter"
 	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/types"
 	"github.com/filecoin-project/venus/pkg/wallet"
 )
@@ -31,16 +33,23 @@ var walletCmd = &cmds.Command{
 		Tagline: "Manage your filecoin wallets",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"balance":      balanceCmd,
-		"import":       walletImportCmd,
-		"export":       walletExportCmd,
-		"ls":           addrsLsCmd,
-		"new":          addrsNewCmd,
-		"default":      defaultAddressCmd,
-		"set-default":  setDefaultAddressCmd,
-		"lock":         lockedCmd,
-		"unlock":       unlockedCmd,
-		"set-password": setWalletPassword,
+		"balance":         balanceCmd,
+		"import":          walletImportCmd,
+		"export":          walletExportCmd,
+		"ls":              addrsLsCmd,
+		"new":             addrsNewCmd,
+		"default":         defaultAddressCmd,
+		"set-default":     setDefaultAddressCmd,
+		"lock":            lockedCmd,
+		"unlock":          unlockedCmd,
+		"set-password":    setWalletPassword,
+		"restore":         walletRestoreCmd,
+		"derive":          walletDeriveCmd,
+		"delete":          walletDeleteCmd,
+		"undelete":        walletUndeleteCmd,
+		"change-password": walletChangePasswordCmd,
+		"reencrypt":       walletReencryptCmd,
+		"sign-file":       walletSignFileCmd,
 	},
 }
 
@@ -56,17 +65,12 @@ type AddressLsResult struct {
 var addrsNewCmd = &cmds.Command{
 	Options: []cmds.Option{
 		cmds.StringOption("type", "The type of address to create: bls (default) or secp256k1").WithDefault("bls"),
+		cmds.BoolOption("hd", "Derive the address from the hd seed of the wallet, the seed is created if the wallet has none"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		protocolName := req.Options["type"].(string)
-		var protocol address.Protocol
-		switch protocolName {
-		case "secp256k1":
-			protocol = address.SECP256K1
-		case "bls":
-			protocol = address.BLS
-		default:
-			return fmt.Errorf("unrecognized address protocol %s", protocolName)
+		protocol, err := parseProtocol(req.Options["type"].(string))
+		if err != nil {
+			return err
 		}
 
 		if !env.(*node.Env).WalletAPI.HasPassword(req.Context) {
@@ -76,6 +80,14 @@ var addrsNewCmd = &cmds.Command{
 			return errWalletLocked
 		}
 
+		if hd, _ := req.Options["hd"].(bool); hd {
+			hdAddr, err := env.(*node.Env).WalletAPI.WalletNewHDAddress(req.Context, protocol)
+			if err != nil {
+				return err
+			}
+			return emitHDAddress(re, hdAddr)
+		}
+
 		addr, err := env.(*node.Env).WalletAPI.WalletNewAddress(protocol)
 		if err != nil {
 			return err
@@ -85,6 +97,135 @@ var addrsNewCmd = &cmds.Command{
 	},
 }
 
+var walletRestoreCmd = &cmds.Command{
+	Extra: AdminExtra,
+	Helptext: cmds.HelpText{
+		Tagline: "Restore the hd seed of the wallet from a mnemonic",
+		ShortDescription: `
+The mnemonic is prompted for, or read from --mnemonic-file. The addresses derived from the
+seed are not restored, use 'venus wallet derive' or 'venus wallet new --hd' to recreate them.
+`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("mnemonic-file", "file holding the BIP39 mnemonic to restore, instead of prompting for it"),
+	},
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		// the mnemonic is read by the cli and handed to the daemon in the body of the request,
+		// it is not taken from the command line where it would be kept in the shell history
+		var mnemonic []byte
+		if path, _ := req.Options["mnemonic-file"].(string); path != "" {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("reading the mnemonic file: %w", err)
+			}
+			mnemonic = data
+		} else {
+			data, err := gopass.GetPasswdPrompt("Mnemonic:", true, os.Stdin, os.Stdout)
+			if err != nil {
+				return err
+			}
+			mnemonic = data
+		}
+		req.Files = files.NewMapDirectory(map[string]files.Node{"mnemonic": files.NewBytesFile(mnemonic)})
+		return nil
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if !env.(*node.Env).WalletAPI.HasPassword(req.Context) {
+			return errMissPassword
+		}
+		if env.(*node.Env).WalletAPI.WalletState(req.Context) == wallet.Lock {
+			return errWalletLocked
+		}
+
+		if req.Files == nil {
+			return errors.New("a mnemonic is required")
+		}
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("a mnemonic is required: %v", iter.Err())
+		}
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("the mnemonic was not a files.File")
+		}
+		data, err := ioutil.ReadAll(fi)
+		if err != nil {
+			return err
+		}
+		mnemonic := string(data)
+		if len(strings.TrimSpace(mnemonic)) == 0 {
+			return errors.New("a mnemonic is required")
+		}
+
+		err = env.(*node.Env).WalletAPI.WalletRestoreMnemonic(req.Context, strings.Join(strings.Fields(mnemonic), " "))
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, "hd seed restored")
+	},
+}
+
+var walletDeriveCmd = &cmds.Command{
+	Extra: AdminExtra,
+	Helptext: cmds.HelpText{
+		Tagline: "Derive the address at an index from the hd seed of the wallet",
+	},
+	Options: []cmds.Option{
+		cmds.Uint64Option("index", "The index of the address to derive"),
+		cmds.StringOption("type", "The type of address to derive: bls (default) or secp256k1").WithDefault("bls"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		protocol, err := parseProtocol(req.Options["type"].(string))
+		if err != nil {
+			return err
+		}
+		index, ok := req.Options["index"].(uint64)
+		if !ok {
+			return errors.New("the index of the address is required")
+		}
+		if index >= uint64(crypto.HardenedKeyStart) {
+			return fmt.Errorf("index %d out of range", index)
+		}
+
+		if env.(*node.Env).WalletAPI.WalletState(req.Context) == wallet.Lock {
+			return errWalletLocked
+		}
+
+		hdAddr, err := env.(*node.Env).WalletAPI.WalletDeriveAddress(req.Context, protocol, uint32(index))
+		if err != nil {
+			return err
+		}
+
+		return emitHDAddress(re, hdAddr)
+	},
+}
+
+func emitHDAddress(re cmds.ResponseEmitter, hdAddr *wallet.HDAddress) error {
+	buf := new(bytes.Buffer)
+	writer := NewSilentWriter(buf)
+	writer.Printf("%s (%s)\n", hdAddr.Address, hdAddr.Path)
+	if len(hdAddr.Mnemonic) != 0 {
+		writer.Println()
+		writer.Println("A new hd seed was created, write down its mnemonic and keep it safe, it is the only way")
+		writer.Println("to recover the addresses derived from it and it will NOT be shown again:")
+		writer.Println()
+		writer.Println(hdAddr.Mnemonic)
+	}
+	return re.Emit(buf)
+}
+
+func parseProtocol(protocolName string) (address.Protocol, error) {
+	switch protocolName {
+	case "secp256k1":
+		return address.SECP256K1, nil
+	case "bls":
+		return address.BLS, nil
+	default:
+		return address.Unknown, fmt.Errorf("unrecognized address protocol %s", protocolName)
+	}
+}
+
 var addrsLsCmd = &cmds.Command{
 	Options: []cmds.Option{
 		cmds.BoolOption("addr-only", "Only print addresses"),
@@ -400,3 +541,274 @@ var unlockedCmd = &cmds.Command{
 		return re.Emit("unlocked success")
 	},
 }
+
+var walletDeleteCmd = &cmds.Command{
+	Extra: AdminExtra,
+	Helptext: cmds.HelpText{
+		Tagline: "Delete an address from the wallet",
+		ShortDescription: `
+The key of the address is kept encrypted in the trash of the wallet, it can be recovered
+with 'venus wallet undelete'.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "address to delete"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if env.(*node.Env).WalletAPI.WalletState(req.Context) == wallet.Lock {
+			return errWalletLocked
+		}
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		if err := env.(*node.Env).WalletAPI.WalletDelete(req.Context, addr); err != nil {
+			return err
+		}
+
+		return printOneString(re, fmt.Sprintf("%s deleted", addr))
+	},
+}
+
+var walletUndeleteCmd = &cmds.Command{
+	Extra: AdminExtra,
+	Helptext: cmds.HelpText{
+		Tagline: "Recover a deleted address from the trash of the wallet",
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "address to recover"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if env.(*node.Env).WalletAPI.WalletState(req.Context) == wallet.Lock {
+			return errWalletLocked
+		}
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		if err := env.(*node.Env).WalletAPI.WalletUndelete(req.Context, addr); err != nil {
+			return err
+		}
+
+		return printOneString(re, fmt.Sprintf("%s recovered", addr))
+	},
+}
+
+var walletChangePasswordCmd = &cmds.Command{
+	Extra: AdminExtra,
+	Helptext: cmds.HelpText{
+		Tagline: "Change the password of the wallet",
+		ShortDescription: `
+Every key of the wallet is re-encrypted with the new password in a single batch.
+`,
+	},
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		oldPw, err := gopass.GetPasswdPrompt("Old Password:", true, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		pw, err := gopass.GetPasswdPrompt("New Password:", true, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		pw2, err := gopass.GetPasswdPrompt("Enter New Password again:", true, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(pw, pw2) {
+			return errors.New("the input passwords are inconsistent")
+		}
+
+		req.Arguments = []string{string(oldPw), string(pw)}
+
+		return nil
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if len(req.Arguments) != 2 {
+			return re.Emit("Two parameter is required.")
+		}
+		if len(req.Arguments[1]) == 0 {
+			return re.Emit("Do not enter an empty string")
+		}
+		if env.(*node.Env).WalletAPI.WalletState(req.Context) == wallet.Lock {
+			return errWalletLocked
+		}
+
+		err := env.(*node.Env).WalletAPI.WalletChangePassword(req.Context, []byte(req.Arguments[0]), []byte(req.Arguments[1]))
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, "Password changed successfully \n"+
+			"You must REMEMBER your password! Without the password, it's impossible to decrypt the key!")
+	},
+}
+
+var walletReencryptCmd = &cmds.Command{
+	Extra: AdminExtra,
+	Helptext: cmds.HelpText{
+		Tagline: "Re-encrypt the keys of the wallet with new scrypt parameters",
+		ShortDescription: `
+Every key of the wallet is re-encrypted in a single batch, the parameters are saved in the
+config and used for the keys created afterwards.
+`,
+	},
+	Options: []cmds.Option{
+		cmds.IntOption("scrypt-n", "The scrypt CPU/memory cost parameter, a power of 2").WithDefault(1 << 21),
+		cmds.IntOption("scrypt-p", "The scrypt parallelization parameter").WithDefault(1),
+	},
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		pw, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		req.Arguments = []string{string(pw)}
+
+		return nil
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if len(req.Arguments) != 1 {
+			return re.Emit("A parameter is required.")
+		}
+		if env.(*node.Env).WalletAPI.WalletState(req.Context) == wallet.Lock {
+			return errWalletLocked
+		}
+
+		scryptN := req.Options["scrypt-n"].(int)
+		scryptP := req.Options["scrypt-p"].(int)
+		if scryptN <= 1 || scryptN&(scryptN-1) != 0 {
+			return fmt.Errorf("scrypt-n must be a power of 2 greater than 1")
+		}
+		if scryptP <= 0 {
+			return fmt.Errorf("scrypt-p must be positive")
+		}
+
+		err := env.(*node.Env).WalletAPI.WalletReencrypt(req.Context, []byte(req.Arguments[0]), scryptN, scryptP)
+		if err != nil {
+			return err
+		}
+
+		return printOneString(re, "wallet re-encrypted")
+	},
+}
+
+var walletSignFileCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Sign the unsigned messages of a file",
+		ShortDescription: `
+Signs the messages written by 'venus send --unsigned-out', a message or an array of
+messages in json, with the keys of the wallet of the repo, at --repodir or the default repo
+path. It runs without the daemon, which must be stopped, so that the keys can stay on an
+offline machine. The messages must be from the key addresses of the wallet, not from id
+addresses. The password of the wallet is prompted for, or read from --password-file when
+there is no terminal. The signed messages are submitted
+with 'venus mpool push-file'.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.FileArg("messages", true, false, "File containing the unsigned messages").EnableStdin(),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("out", "write the signed messages to this file instead of printing them"),
+		cmds.StringOption(passwordFileOption, "file holding the password of the wallet, instead of prompting for it"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var msgs []*types.UnsignedMessage
+		if err := readMessagesArg(req, &msgs); err != nil {
+			return err
+		}
+
+		r, err := openStoppedRepo(req)
+		if err != nil {
+			return err
+		}
+		defer r.Close() // nolint: errcheck
+
+		password, err := readPasswordFile(req)
+		if err != nil {
+			return err
+		}
+		if password == nil {
+			if password, err = gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout); err != nil {
+				return err
+			}
+		}
+		w, err := openRepoWallet(r, password)
+		if err != nil {
+			return err
+		}
+
+		signed := make([]*types.SignedMessage, 0, len(msgs))
+		for i, msg := range msgs {
+			sm, err := signMessage(w, msg)
+			if err != nil {
+				return fmt.Errorf("signing message %d from %s: %v", i, msg.From, err)
+			}
+			signed = append(signed, sm)
+		}
+
+		return re.Emit(signed)
+	},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			v, err := res.Next()
+			if err != nil {
+				return err
+			}
+			out, _ := res.Request().Options["out"].(string)
+			if out == "" {
+				return re.Emit(v)
+			}
+
+			var signed []*types.SignedMessage
+			if err := decodeEmitted(v, &signed); err != nil {
+				return err
+			}
+			if err := writeMessagesFile(out, signed); err != nil {
+				return err
+			}
+			return re.Emit(fmt.Sprintf("%d signed messages written to %s", len(signed), out))
+		},
+	},
+}
+
+// openRepoWallet opens the wallet of the repo, unlocked with `password`, with the sign policy
+// of the repo.
+func openRepoWallet(r repo.Repo, password []byte) (*wallet.Wallet, error) {
+	cfg := r.Config().Wallet
+	backend, err := wallet.NewDSBackend(r.WalletDatastore(), cfg.PassphraseConfig, password)
+	if err != nil {
+		return nil, fmt.Errorf("opening the wallet: %v", err)
+	}
+	w := wallet.New(backend)
+	if cfg.SignPolicy != nil {
+		policy, err := wallet.NewSignPolicy(cfg.SignPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("setting up the sign policy: %v", err)
+		}
+		w.SetSignPolicy(policy)
+	}
+	return w, nil
+}
+
+// signMessage signs `msg` with the wallet, as WalletSignMessage does. Without the chain, the
+// sender is not resolved to its key address and must be one.
+func signMessage(w *wallet.Wallet, msg *types.UnsignedMessage) (*types.SignedMessage, error) {
+	if msg.From.Protocol() == address.ID {
+		return nil, fmt.Errorf("%s is an id address, send the message from the key address", msg.From)
+	}
+	mb, err := msg.ToStorageBlock()
+	if err != nil {
+		return nil, fmt.Errorf("serializing message: %v", err)
+	}
+	sig, err := w.WalletSign(msg.From, mb.Cid().Bytes(), wallet.MsgMeta{
+		Type:  wallet.MTChainMsg,
+		Extra: mb.RawData(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.SignedMessage{Message: *msg, Signature: *sig}, nil
+}
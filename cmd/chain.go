@@ -3,20 +3,29 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"text/tabwriter"
 	"time"
 
 	"github.com/filecoin-project/venus/app/submodule/apitypes"
 
+	"github.com/docker/go-units"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	cmds "github.com/ipfs/go-ipfs-cmds"
+	cbor "github.com/ipfs/go-ipld-cbor"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 	"github.com/filecoin-project/venus/pkg/types"
 )
 
@@ -25,11 +34,14 @@ var chainCmd = &cmds.Command{
 		Tagline: "Inspect the filecoin blockchain",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"head":     chainHeadCmd,
-		"ls":       chainLsCmd,
-		"set-head": chainSetHeadCmd,
-		"getblock": chainGetBlockCmd,
-		"disputer": chainDisputeSetCmd,
+		"head":         chainHeadCmd,
+		"ls":           chainLsCmd,
+		"set-head":     chainSetHeadCmd,
+		"getblock":     chainGetBlockCmd,
+		"disputer":     chainDisputeSetCmd,
+		"check":        chainCheckCmd,
+		"migrate-test": chainMigrateTestCmd,
+		"gas-stats":    chainGasStatsCmd,
 	},
 }
 
@@ -152,6 +164,259 @@ var chainSetHeadCmd = &cmds.Command{
 	},
 }
 
+var chainCheckCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check the integrity of the local chain.",
+		ShortDescription: `Walks the chain back from the head, or from the given tipset, down to --height and
+verifies that block headers, messages, receipts, tipset metadata and state roots are present
+and consistent with each other. With --reexec every tipset is executed again and the computed
+state compared with the stored one, which is slow.
+
+With --repair missing headers and messages are fetched from peers and broken tipset metadata
+is rewritten from the state root and receipts in the header of the child tipset. A re-executed
+state which differs from the child header is only reported. To check a store without syncing, run it against a daemon started with --offline;
+repairs needing peers will fail in that case.`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("cids", false, true, "CID's of the blocks of the tipset to start from, defaults to the chain head."),
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("height", "Lowest height to check").WithDefault(int64(0)),
+		cmds.BoolOption("reexec", "Re-execute tipsets and compare the resulting state").WithDefault(false),
+		cmds.BoolOption("repair", "Refetch missing data from peers and rewrite broken metadata").WithDefault(false),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		var tsk types.TipSetKey
+		if len(req.Arguments) > 0 {
+			startCids, err := cidsFromSlice(req.Arguments)
+			if err != nil {
+				return err
+			}
+			tsk = types.NewTipSetKey(startCids...)
+		} else {
+			head, err := env.(*node.Env).ChainAPI.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			tsk = head.Key()
+		}
+
+		height, _ := req.Options["height"].(int64)
+		reexec, _ := req.Options["reexec"].(bool)
+		repair, _ := req.Options["repair"].(bool)
+		report, checkErr := env.(*node.Env).SyncerAPI.ChainCheck(ctx, tsk, chain.CheckOptions{
+			Height:    abi.ChainEpoch(height),
+			ReExecute: reexec,
+			Repair:    repair,
+		})
+		if report == nil {
+			return checkErr
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for _, p := range report.Problems {
+			repaired := ""
+			if p.Repaired {
+				repaired = " (repaired)"
+			}
+			writer.Printf("%d %s: %s: %s%s\n", p.Height, p.TipSet, p.Kind, p.Detail, repaired)
+		}
+		writer.Printf("checked %d tipsets from %d to %d, %d problems found\n", report.Checked, report.From, report.To, len(report.Problems))
+		if checkErr != nil {
+			writer.Printf("check aborted: %s\n", checkErr)
+		}
+
+		return re.Emit(buf)
+	},
+}
+
+var chainMigrateTestCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Rehearse the state migration of a network upgrade.",
+		ShortDescription: `Applies the state migration of the given upgrade to the parent state of a tipset, the
+head by default, and reports how long it took, the memory it used and the migrated state root.
+With --pre-migrate the pre-migrations of the upgrade fill the migration cache first, as they
+would before the upgrade epoch.
+
+The migration runs without the daemon, which must be stopped, on the repo at --repodir or the
+default repo path, so the parent state of the tipset must be in its blockstore. The migrated
+state is kept in memory and dropped afterwards, nothing is written to the repo and the head is
+left untouched.`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("upgrade", "Name of the upgrade, e.g. hyperdrive"),
+		cmds.StringOption("tipset", "Comma separated CIDs of the blocks of the tipset, defaults to the chain head"),
+		cmds.BoolOption("pre-migrate", "Run the pre-migrations before the migration").WithDefault(false),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		upgrade, _ := req.Options["upgrade"].(string)
+		if upgrade == "" {
+			return xerrors.New("an upgrade is required, use --upgrade")
+		}
+		tipset, _ := req.Options["tipset"].(string)
+		tsk, err := parseTipSetKey(tipset)
+		if err != nil {
+			return xerrors.Errorf("parsing tipset: %v", err)
+		}
+
+		r, err := openStoppedRepo(req)
+		if err != nil {
+			return err
+		}
+		defer r.Close() // nolint: errcheck
+
+		preMigrate, _ := req.Options["pre-migrate"].(bool)
+		res, err := rehearseMigration(req.Context, r, upgrade, tsk, preMigrate)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("upgrade:        %s (network version %d)\n", res.Upgrade, res.Network)
+		writer.Printf("height:         %d\n", res.Height)
+		writer.Printf("old root:       %s\n", res.OldRoot)
+		writer.Printf("new root:       %s\n", res.NewRoot)
+		if preMigrate {
+			writer.Printf("pre-migration:  %s\n", res.PreMigrationDuration)
+		}
+		writer.Printf("migration:      %s\n", res.Duration)
+		writer.Printf("allocated:      %s\n", units.BytesSize(float64(res.AllocatedBytes)))
+		writer.Printf("peak heap:      %s\n", units.BytesSize(float64(res.PeakHeapBytes)))
+
+		return re.Emit(buf)
+	},
+}
+
+// rehearseMigration runs the migration of `upgrade` on the parent state of the tipset `tsk` of
+// the chain of the stopped repo `r`, or of its head when `tsk` is empty.
+func rehearseMigration(ctx context.Context, r repo.Repo, upgrade string, tsk types.TipSetKey, preMigrate bool) (*fork.MigrationResult, error) {
+	genCid, err := chain.ReadGenesisCid(r.ChainDatastore())
+	if err != nil {
+		return nil, err
+	}
+	bs := r.Datastore()
+	networkParams := r.Config().NetworkParams
+	chainStore := chain.NewStore(r.ChainDatastore(), cbor.NewCborStore(bs), bs, networkParams.ForkUpgradeParam, genCid, nil)
+	defer chainStore.Stop()
+	if err := chainStore.Load(ctx); err != nil {
+		return nil, xerrors.Errorf("loading the chain: %v", err)
+	}
+
+	ts := chainStore.GetHead()
+	if !tsk.IsEmpty() {
+		if ts, err = chainStore.GetTipSet(tsk); err != nil {
+			return nil, xerrors.Errorf("loading tipset %s: %v", tsk, err)
+		}
+	}
+	return fork.RehearseMigration(ctx, chainStore, bs, networkParams, upgrade, ts, preMigrate)
+}
+
+var chainGasStatsCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Report the gas used and the fees paid over a range of epochs.",
+		ShortDescription: `Aggregates the messages executed from --from to --to, by default the last day of the
+chain, and prints the trend of the base fee, the fullness of the blocks, the fees burnt and
+tipped, then the gas used and the fees paid by type of receiver, by method and by sender.
+The messages of a tipset are executed by its child, so --to is below the head. The range
+cannot exceed a week of epochs.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("from", "First epoch, defaults to a day before --to").WithDefault(int64(-1)),
+		cmds.Int64Option("to", "Last epoch, defaults to the parent of the head").WithDefault(int64(-1)),
+		cmds.IntOption("top", "Number of rows of the method and sender tables").WithDefault(10),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		head, err := env.(*node.Env).ChainAPI.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+		from, _ := req.Options["from"].(int64)
+		to, _ := req.Options["to"].(int64)
+		top, _ := req.Options["top"].(int)
+		if to < 0 {
+			to = int64(head.Height()) - 1
+		}
+		if from < 0 {
+			from = to - int64(builtin.EpochsInDay) + 1
+			if from < 0 {
+				from = 0
+			}
+		}
+
+		stats, err := env.(*node.Env).ChainAPI.ChainGasStats(ctx, abi.ChainEpoch(from), abi.ChainEpoch(to))
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		if err := printGasStats(buf, stats, top); err != nil {
+			return err
+		}
+		return re.Emit(buf)
+	},
+}
+
+func printGasStats(w io.Writer, stats *apitypes.GasStats, top int) error {
+	writer := NewSilentWriter(w)
+	writer.Printf("Epochs:               %d to %d, %d tipsets, %d blocks\n", stats.From, stats.To, stats.Tipsets, stats.Blocks)
+	writer.Printf("Messages:             %d (%d failed)\n", stats.Total.Messages, stats.Total.Failed)
+	writer.Printf("Gas Used:             %d of %d limit (%s)\n", stats.Total.GasUsed, stats.Total.GasLimit, percent(stats.Total.GasUsed, stats.Total.GasLimit))
+	writer.Printf("Block Fullness:       %.2f%%\n", stats.BlockFullness*100)
+	if len(stats.BaseFee) > 0 {
+		minFee, maxFee, sum := stats.BaseFee[0].BaseFee, stats.BaseFee[0].BaseFee, big.Zero()
+		for _, s := range stats.BaseFee {
+			minFee, maxFee, sum = big.Min(minFee, s.BaseFee), big.Max(maxFee, s.BaseFee), big.Add(sum, s.BaseFee)
+		}
+		first, last := stats.BaseFee[0], stats.BaseFee[len(stats.BaseFee)-1]
+		writer.Printf("Base Fee:             %s at %d, %s at %d\n", types.FIL(first.BaseFee).Short(), first.Height, types.FIL(last.BaseFee).Short(), last.Height)
+		writer.Printf("                      min %s, avg %s, max %s\n", types.FIL(minFee).Short(),
+			types.FIL(big.Div(sum, big.NewInt(int64(len(stats.BaseFee))))).Short(), types.FIL(maxFee).Short())
+	}
+	writer.Printf("Total Fees:           %s\n", types.FIL(stats.Total.TotalFees()))
+	writer.Printf("  Base Fee Burn:      %s\n", types.FIL(stats.Total.BaseFeeBurn))
+	writer.Printf("  Over Estim. Burn:   %s\n", types.FIL(stats.Total.OverEstimationBurn))
+	writer.Printf("  Miner Tip:          %s\n", types.FIL(stats.Total.MinerTip))
+	writer.Printf("Miner Penalty:        %s\n", types.FIL(stats.MinerPenalty))
+
+	tw := tabwriter.NewWriter(w, 2, 4, 2, ' ', 0)
+	printUsage := func(name string, u *chain.GasUsage) {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", name, u.Messages, u.Failed, u.GasUsed, percent(u.GasUsed, stats.Total.GasUsed),
+			types.FIL(u.BaseFeeBurn).Short(), types.FIL(u.OverEstimationBurn).Short(), types.FIL(u.MinerTip).Short(), types.FIL(u.TotalFees()).Short())
+	}
+	const usageColumns = "messages\tfailed\tgas used\tshare\tbase fee burn\tover estim. burn\ttip\ttotal fees\n"
+
+	_, _ = fmt.Fprintf(tw, "\nactor\t"+usageColumns)
+	for i := range stats.ByActor {
+		printUsage(stats.ByActor[i].Actor, &stats.ByActor[i].GasUsage)
+	}
+	_, _ = fmt.Fprintf(tw, "\nmethod\t"+usageColumns)
+	for i := range stats.ByMethod {
+		if i == top {
+			break
+		}
+		printUsage(stats.ByMethod[i].Actor+"."+stats.ByMethod[i].Method, &stats.ByMethod[i].GasUsage)
+	}
+	_, _ = fmt.Fprintf(tw, "\nsender\t"+usageColumns)
+	for i := range stats.TopConsumers {
+		if i == top {
+			break
+		}
+		printUsage(stats.TopConsumers[i].Sender.String(), &stats.TopConsumers[i].GasUsage)
+	}
+	return tw.Flush()
+}
+
+func percent(n, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", float64(n)*100/float64(total))
+}
+
 var chainGetBlockCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Get a block and print its details.",
@@ -226,7 +226,7 @@ func daemonRun(req *cmds.Request, re cmds.ResponseEmitter) error {
 		opts = append(opts, node.SetWalletPassword([]byte(password)))
 	}
 
-	journal, err := journal.NewZapJournal(rep.JournalPath()) // nolint
+	journal, err := journal.Open(rep) // nolint
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/gateway"
+	"github.com/filecoin-project/venus/app/paths"
+)
+
+var gatewayCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Serve a read-only subset of the api, forwarded to upstream nodes",
+		ShortDescription: `
+Starts a gateway serving the chain reads, StateSearchMsg, StateWaitMsg, gas estimation,
+MpoolGetNonce and MpoolPush of the v0 and v1 api at /rpc/v0 and /rpc/v1, without repo,
+sync or wallet. The calls are balanced over the healthy upstream nodes, tipsets, blocks and
+messages older than the lookback cap are refused, a message being as old as the tipset
+executing it, the confidence StateWaitMsg waits for is capped, and immutable responses such
+as blocks and messages are cached.
+
+An upstream is the multiaddr or the websocket url of the v1 api of a venus node, the token
+needs the write permission for MpoolPush. The token is read from the file given with
+--upstream-token-file, or else from the VENUS_GATEWAY_UPSTREAM_TOKEN environment variable, it
+is not given on the command line where the other users could read it. The calls of each client, known by its ip, are
+rate limited by method, a method can be given its own limit, in calls per second, with
+--method-rate-limit MpoolPush=5.
+`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("listen", "multiaddr the gateway listens on").WithDefault("/ip4/127.0.0.1/tcp/2346"),
+		cmds.StringsOption("upstream", "multiaddr or websocket url of the v1 api of an upstream node"),
+		cmds.StringOption("upstream-token-file", "file holding the token authenticating the gateway to the upstream nodes"),
+		cmds.StringOption("lookback-cap", "how old the tipsets, blocks and messages served may be").WithDefault("24h"),
+		cmds.Int64Option("search-lookback", "maximum number of epochs searched for a message").WithDefault(int64(2880)),
+		cmds.Uint64Option("max-wait-confidence", "maximum confidence, in epochs, StateWaitMsg waits for").WithDefault(uint64(20)),
+		cmds.FloatOption("rate-limit", "calls per second allowed for each client and method, 0 disables the limit").WithDefault(float64(100)),
+		cmds.StringsOption("method-rate-limit", "calls per second allowed for a method, as Method=rate"),
+		cmds.IntOption("cache-size", "number of immutable responses cached").WithDefault(10000),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		cfg, err := gatewayConfig(req)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(req.Context)
+		defer cancel()
+		gw, closer, err := gateway.New(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		listen, _ := req.Options["listen"].(string)
+		laddr, err := ma.NewMultiaddr(listen)
+		if err != nil {
+			return xerrors.Errorf("parsing listen address: %v", err)
+		}
+		lst, err := manet.Listen(laddr)
+		if err != nil {
+			return xerrors.Errorf("listening on %s: %v", laddr, err)
+		}
+
+		srv := &http.Server{Handler: gateway.NewHandler(gw)}
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- srv.Serve(manet.NetListener(lst))
+		}()
+		log.Infof("gateway listening on %s", lst.Multiaddr())
+
+		terminate := make(chan os.Signal, 1)
+		signal.Notify(terminate, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(terminate)
+		select {
+		case err := <-errCh:
+			return err
+		case <-terminate:
+		case <-ctx.Done():
+		}
+		return srv.Shutdown(context.Background())
+	},
+}
+
+func gatewayConfig(req *cmds.Request) (gateway.Config, error) {
+	cfg := gateway.DefaultConfig()
+
+	upstreams, _ := req.Options["upstream"].([]string)
+	for _, u := range upstreams {
+		addr, err := upstreamURL(u)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Upstreams = append(cfg.Upstreams, addr)
+	}
+	if len(cfg.Upstreams) == 0 {
+		return cfg, xerrors.New("at least one --upstream is required")
+	}
+	var err error
+	if cfg.Token, err = upstreamToken(req); err != nil {
+		return cfg, err
+	}
+
+	lookback, _ := req.Options["lookback-cap"].(string)
+	if cfg.LookbackCap, err = time.ParseDuration(lookback); err != nil {
+		return cfg, xerrors.Errorf("parsing lookback cap: %v", err)
+	}
+	search, _ := req.Options["search-lookback"].(int64)
+	cfg.SearchLookbackLimit = abi.ChainEpoch(search)
+	cfg.MaxWaitConfidence, _ = req.Options["max-wait-confidence"].(uint64)
+
+	cfg.RateLimit, _ = req.Options["rate-limit"].(float64)
+	limits, _ := req.Options["method-rate-limit"].([]string)
+	for _, l := range limits {
+		kv := strings.SplitN(l, "=", 2)
+		if len(kv) != 2 {
+			return cfg, xerrors.Errorf("expected Method=rate, got %s", l)
+		}
+		r, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil {
+			return cfg, xerrors.Errorf("parsing rate limit of %s: %v", kv[0], err)
+		}
+		cfg.MethodRateLimits[kv[0]] = r
+	}
+	cfg.CacheSize, _ = req.Options["cache-size"].(int)
+
+	return cfg, nil
+}
+
+// envUpstreamToken is the environment variable the token of the upstream nodes is read from
+// when no token file is given.
+const envUpstreamToken = "VENUS_GATEWAY_UPSTREAM_TOKEN"
+
+func upstreamToken(req *cmds.Request) (string, error) {
+	path, _ := req.Options["upstream-token-file"].(string)
+	if path == "" {
+		return os.Getenv(envUpstreamToken), nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", xerrors.Errorf("reading upstream token: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// upstreamURL turns the multiaddr of an upstream node into the url of its v1 api, urls are
+// kept as is.
+func upstreamURL(s string) (string, error) {
+	if !strings.HasPrefix(s, "/") {
+		return s, nil
+	}
+	ep, err := paths.ParseAPIEndpoint(s)
+	if err != nil {
+		return "", xerrors.Errorf("parsing upstream %s: %v", s, err)
+	}
+	return ep.URL("ws", "/rpc/v1"), nil
+}
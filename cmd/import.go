@@ -67,7 +67,7 @@ func importChain(r repo.Repo, fname string) error {
 	bs := r.Datastore()
 	// setup a ipldCbor on top of the local store
 	ipldCborStore := cbor.NewCborStore(bs)
-	chainStore := chain.NewStore(r.ChainDatastore(), ipldCborStore, bs, config.DefaultForkUpgradeParam, cid.Undef)
+	chainStore := chain.NewStore(r.ChainDatastore(), ipldCborStore, bs, config.DefaultForkUpgradeParam, cid.Undef, nil)
 
 	bufr := bufio.NewReaderSize(rd, 1<<20)
 
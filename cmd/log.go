@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/cmd/tablewriter"
 )
 
 var logCmd = &cmds.Command{
@@ -21,6 +27,7 @@ output of a running daemon.
 		"set-level": logLevelCmd,
 		"list":      logLsCmd,
 		"tail":      logTailCmd,
+		"journal":   logJournalCmd,
 	},
 }
 
@@ -110,3 +117,74 @@ subsystems of a running daemon.
 	},
 	Type: []string{},
 }
+
+var logJournalCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Query the events recorded in the daemon journal.",
+		ShortDescription: `
+'venus log journal' lists the journal events of the running daemon, oldest
+first. Events can be filtered by system and event name, and only the events
+recorded within the --since duration are listed when it is set.
+
+   eg) log journal --system chain --event reorg --since 24h
+`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("system", "Only list the events of this system, eg. chain, sync, mpool"),
+		cmds.StringOption("event", "Only list the events with this name, eg. reorg"),
+		cmds.StringOption("since", "Only list the events recorded within this duration, eg. 1h30m"),
+		cmds.BoolOption("json", "Output the events as ndjson"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		system, _ := req.Options["system"].(string)
+		event, _ := req.Options["event"].(string)
+
+		var since time.Time
+		if s, _ := req.Options["since"].(string); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("invalid since duration: %w", err)
+			}
+			since = time.Now().Add(-d)
+		}
+
+		evts, err := env.(*node.Env).SyncerAPI.JournalQuery(req.Context, system, event, since)
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		if asJSON, _ := req.Options["json"].(bool); asJSON {
+			for _, evt := range evts {
+				b, err := json.Marshal(evt)
+				if err != nil {
+					return err
+				}
+				buf.Write(append(b, '\n'))
+			}
+			return re.Emit(buf)
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("System"),
+			tablewriter.Col("Event"),
+			tablewriter.NewLineCol("Data"))
+		for _, evt := range evts {
+			data, err := json.Marshal(evt.Data)
+			if err != nil {
+				return err
+			}
+			tw.Write(map[string]interface{}{
+				"Time":   evt.Timestamp.Format(time.RFC3339),
+				"System": evt.System,
+				"Event":  evt.Event,
+				"Data":   string(data),
+			})
+		}
+		if err := tw.Flush(buf); err != nil {
+			return err
+		}
+		return re.Emit(buf)
+	},
+}
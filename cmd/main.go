@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 
 	fbig "github.com/filecoin-project/go-state-types/big"
@@ -12,10 +13,9 @@ import (
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	"github.com/ipfs/go-ipfs-cmds/cli"
 	cmdhttp "github.com/ipfs/go-ipfs-cmds/http"
-	ma "github.com/multiformats/go-multiaddr"
-	manet "github.com/multiformats/go-multiaddr/net"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/venus/app/client"
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/app/paths"
 	"github.com/filecoin-project/venus/pkg/repo"
@@ -108,6 +108,7 @@ var RootCmd = &cmds.Command{
 START RUNNING FILECOIN
   venus config <key> [<value>] - Get and set filecoin config values
   venus daemon                 - Start a long-running daemon process
+  venus gateway                - Serve a read-only api forwarded to upstream nodes
   venus wallet                 - Manage your filecoin wallets
   venus msig                   - Interact with a multisig wallet
 
@@ -123,6 +124,7 @@ NETWORK COMMANDS
 
 MESSAGE COMMANDS
   venus send                   - Send message
+  venus send batch             - Send the messages of the rows of a csv file
   venus mpool                  - Manage the message pool
 
 State COMMANDS
@@ -151,6 +153,9 @@ TOOL COMMANDS
   venus version                - Show venus version information
   venus seed                   - Seal sectors for genesis miner
   venus fetch                  - Fetch proving parameters
+  venus repo convert           - Move the data of the repo to another datastore backend
+  venus repo backup <file>     - Back up the config, keys and metadata of the repo to a file
+  venus repo restore <file>    - Create a repo from a backup
 `,
 	},
 	Options: []cmds.Option{
@@ -176,6 +181,8 @@ var rootSubcmdsLocal = map[string]*cmds.Command{
 	"version": versionCmd,
 	"leb128":  leb128Cmd,
 	"seed":    seedCmd,
+	"gateway": gatewayCmd,
+	"repo":    repoCmd,
 }
 
 // all top level commands, available on daemon. set during init() to avoid configuration loops.
@@ -210,6 +217,7 @@ func init() {
 		RootCmd.Subcommands[k] = v
 		RootCmdDaemon.Subcommands[k] = v
 	}
+	RootCmdDaemon.Subcommands["repo"] = repoCmdDaemon
 }
 
 // Run processes the arguments and stdin
@@ -230,9 +238,10 @@ func buildEnv(ctx context.Context, _ *cmds.Request) (cmds.Environment, error) {
 }
 
 type executor struct {
-	api   string
-	token string
-	exec  cmds.Executor
+	api      string
+	token    string
+	endpoint paths.APIEndpoint
+	exec     cmds.Executor
 }
 
 func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -240,9 +249,41 @@ func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.
 		return e.exec.Execute(req, re, env)
 	}
 
-	client := cmdhttp.NewClient(e.api, e.token, cmdhttp.ClientWithAPIPrefix(node.APIPrefix))
+	opts := []cmdhttp.ClientOpt{cmdhttp.ClientWithAPIPrefix(node.APIPrefix)}
+	if e.endpoint.TLS || e.endpoint.UnixPath != "" {
+		tlsCfg, err := client.TLSConfigFromEnv()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, cmdhttp.ClientWithHTTPClient(&http.Client{
+			Transport: &apiTransport{
+				tls: e.endpoint.TLS,
+				rt: &http.Transport{
+					Proxy:           http.ProxyFromEnvironment,
+					DialContext:     paths.DialContext,
+					TLSClientConfig: tlsCfg,
+				},
+			},
+		}))
+	}
+	cmdClient := cmdhttp.NewClient(e.api, e.token, opts...)
+
+	return cmdClient.Execute(req, re, env)
+}
 
-	return client.Execute(req, re, env)
+// apiTransport sends the requests of the cmds client, which only speaks plain http, over tls
+// to a tls endpoint.
+type apiTransport struct {
+	tls bool
+	rt  http.RoundTripper
+}
+
+func (t *apiTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.tls && r.URL.Scheme == "http" {
+		r = r.Clone(r.Context())
+		r.URL.Scheme = "https"
+	}
+	return t.rt.RoundTrip(r)
 }
 
 func makeExecutor(req *cmds.Request, env interface{}) (cmds.Executor, error) {
@@ -266,15 +307,17 @@ func makeExecutor(req *cmds.Request, env interface{}) (cmds.Executor, error) {
 	}
 
 	return &executor{
-		api:   apiInfo.Addr,
-		token: apiInfo.Token,
-		exec:  cmds.NewExecutor(RootCmd),
+		api:      apiInfo.Addr,
+		token:    apiInfo.Token,
+		endpoint: apiInfo.Endpoint,
+		exec:     cmds.NewExecutor(RootCmd),
 	}, nil
 }
 
 type APIInfo struct {
-	Addr  string
-	Token string
+	Addr     string
+	Token    string
+	Endpoint paths.APIEndpoint
 }
 
 func getAPIInfo(req *cmds.Request) (*APIInfo, error) {
@@ -303,14 +346,9 @@ func getAPIInfo(req *cmds.Request) (*APIInfo, error) {
 		rawAddr = rpcAPI //NOTICE command only use api
 	}
 
-	maddr, err := ma.NewMultiaddr(rawAddr)
+	ep, err := paths.ParseAPIEndpoint(rawAddr)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("unable to convert API endpoint address %s to a multiaddr", rawAddr))
-	}
-
-	_, host, err := manet.DialArgs(maddr)
-	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("unable to dial API endpoint address %s", maddr))
+		return nil, errors.Wrap(err, fmt.Sprintf("unable to dial API endpoint address %s", rawAddr))
 	}
 
 	token := ""
@@ -329,8 +367,9 @@ func getAPIInfo(req *cmds.Request) (*APIInfo, error) {
 	}
 
 	return &APIInfo{
-		Addr:  host,
-		Token: token,
+		Addr:     ep.Addr(),
+		Token:    token,
+		Endpoint: ep,
 	}, nil
 }
 
@@ -362,20 +401,28 @@ func getAPIAddress(req *cmds.Request) (string, error) {
 		rawAddr = rpcAPI //NOTICE command only use api
 	}
 
-	maddr, err := ma.NewMultiaddr(rawAddr)
-	if err != nil {
-		return "", errors.Wrap(err, fmt.Sprintf("unable to convert API endpoint address %s to a multiaddr", rawAddr))
-	}
-
-	_, host, err := manet.DialArgs(maddr)
+	ep, err := paths.ParseAPIEndpoint(rawAddr)
 	if err != nil {
-		return "", errors.Wrap(err, fmt.Sprintf("unable to dial API endpoint address %s", maddr))
+		return "", errors.Wrap(err, fmt.Sprintf("unable to dial API endpoint address %s", rawAddr))
 	}
 
-	return host, nil
+	return ep.Addr(), nil
 }
 
 func requiresDaemon(req *cmds.Request) bool {
+	// a backup is taken, and a conversion made, by the running node, unless it is stopped
+	if len(req.Path) > 1 && req.Path[0] == "repo" && (req.Path[1] == "backup" || req.Path[1] == "convert") {
+		offline, _ := req.Options["offline"].(bool)
+		return !offline
+	}
+	// the migrations are rehearsed on the repo of a stopped node
+	if len(req.Path) > 1 && req.Path[0] == "chain" && req.Path[1] == "migrate-test" {
+		return false
+	}
+	// the messages are signed with the wallet of the repo of a stopped node
+	if len(req.Path) > 1 && req.Path[0] == "wallet" && req.Path[1] == "sign-file" {
+		return false
+	}
 	for cmd := range rootSubcmdsLocal {
 		if len(req.Path) > 0 && req.Path[0] == cmd {
 			return false
@@ -23,4 +23,24 @@ func TestRequiresDaemon(t *testing.T) {
 	reqSubcmdDaemon, err := cmds.NewRequest(context.Background(), []string{"leb128", "decode"}, nil, []string{"A=="}, nil, RootCmd)
 	assert.NoError(t, err)
 	assert.False(t, requiresDaemon(reqSubcmdDaemon))
+
+	reqBackup, err := cmds.NewRequest(context.Background(), []string{"repo", "backup"}, nil, []string{"backup"}, nil, RootCmd)
+	assert.NoError(t, err)
+	assert.True(t, requiresDaemon(reqBackup))
+
+	reqOfflineBackup, err := cmds.NewRequest(context.Background(), []string{"repo", "backup"}, map[string]interface{}{"offline": true}, []string{"backup"}, nil, RootCmd)
+	assert.NoError(t, err)
+	assert.False(t, requiresDaemon(reqOfflineBackup))
+
+	reqConvert, err := cmds.NewRequest(context.Background(), []string{"repo", "convert"}, nil, []string{}, nil, RootCmd)
+	assert.NoError(t, err)
+	assert.True(t, requiresDaemon(reqConvert))
+
+	reqOfflineConvert, err := cmds.NewRequest(context.Background(), []string{"repo", "convert"}, map[string]interface{}{"offline": true}, []string{}, nil, RootCmd)
+	assert.NoError(t, err)
+	assert.False(t, requiresDaemon(reqOfflineConvert))
+
+	reqSignFile, err := cmds.NewRequest(context.Background(), []string{"wallet", "sign-file"}, nil, []string{}, nil, RootCmd)
+	assert.NoError(t, err)
+	assert.False(t, requiresDaemon(reqSignFile))
 }
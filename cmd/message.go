@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"reflect"
 
 	"golang.org/x/xerrors"
@@ -14,6 +16,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/ipfs/go-cid"
 	cmds "github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipfs/go-ipfs-files"
 	"github.com/pkg/errors"
 	cbg "github.com/whyrusleeping/cbor-gen"
 
@@ -35,6 +38,9 @@ var msgSendCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "Send a message", // This feels too generic...
 	},
+	Subcommands: map[string]*cmds.Command{
+		"batch": msgSendBatchCmd,
+	},
 	Arguments: []cmds.Argument{
 		cmds.StringArg("target", true, false, "address of the actor to send the message to"),
 		cmds.StringArg("value", true, false, "amount of FIL"),
@@ -49,6 +55,8 @@ var msgSendCmd = &cmds.Command{
 		cmds.StringOption("params-json", "specify invocation parameters in json"),
 		cmds.StringOption("params-hex", "specify invocation parameters in hex"),
 		cmds.Uint64Option("method", "The method to invoke on the target actor"),
+		cmds.StringOption("unsigned-out", "write the unsigned message, with its nonce and gas, to this file instead of sending it, "+
+			"the messages of a batch are appended to the file"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		toAddr, err := address.NewFromString(req.Arguments[0])
@@ -115,14 +123,34 @@ var msgSendCmd = &cmds.Command{
 		}
 
 		nonceOption := req.Options["nonce"]
-		c := cid.Undef
 		if nonceOption != nil {
 			nonce, ok := nonceOption.(uint64)
 			if !ok {
 				return xerrors.Errorf("invalid nonce option: %v", nonceOption)
 			}
 			msg.Nonce = nonce
+		}
+
+		if out, _ := req.Options["unsigned-out"].(string); out != "" {
+			if nonceOption == nil {
+				if msg.Nonce, err = env.(*node.Env).MessagePoolAPI.MpoolGetNonce(req.Context, msg.From); err != nil {
+					return xerrors.Errorf("getting nonce: %v", err)
+				}
+			}
+			spec, err := estimateSpec(req.Context, env.(*node.Env))
+			if err != nil {
+				return err
+			}
+			msg, err = env.(*node.Env).MessagePoolAPI.GasEstimateMessageGas(req.Context, msg, spec, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("estimating gas: %v", err)
+			}
+			// written to the file by the cli, see PostRun
+			return re.Emit(msg)
+		}
 
+		c := cid.Undef
+		if nonceOption != nil {
 			sm, err := env.(*node.Env).WalletAPI.WalletSignMessage(req.Context, msg.From, msg)
 			if err != nil {
 				return err
@@ -143,6 +171,110 @@ var msgSendCmd = &cmds.Command{
 
 		return re.Emit(c.String())
 	},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			v, err := res.Next()
+			if err != nil {
+				return err
+			}
+			out, _ := res.Request().Options["unsigned-out"].(string)
+			if out == "" {
+				return re.Emit(v)
+			}
+
+			var msg types.UnsignedMessage
+			if err := decodeEmitted(v, &msg); err != nil {
+				return err
+			}
+			var msgs []*types.UnsignedMessage
+			if err := readMessagesFile(out, &msgs); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			// the node does not know about the messages already in the batch
+			if _, ok := res.Request().Options["nonce"]; !ok {
+				for _, m := range msgs {
+					if m.From == msg.From && m.Nonce >= msg.Nonce {
+						msg.Nonce = m.Nonce + 1
+					}
+				}
+			}
+			msgs = append(msgs, &msg)
+			if err := writeMessagesFile(out, msgs); err != nil {
+				return err
+			}
+
+			return re.Emit(fmt.Sprintf("unsigned message from %s with nonce %d written to %s, %d messages in the file", msg.From, msg.Nonce, out, len(msgs)))
+		},
+	},
+}
+
+// estimateSpec returns the spec of the gas estimates of the cli: the gas limit is over
+// estimated as configured in the mpool of the node, and the fee capped to the default max fee.
+func estimateSpec(ctx context.Context, fapi *node.Env) (*types.MessageSendSpec, error) {
+	cfg, err := fapi.MessagePoolAPI.MpoolGetConfig(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting mpool config: %v", err)
+	}
+	return &types.MessageSendSpec{
+		MaxFee:            types.DefaultMessageSendSpec.MaxFee,
+		GasOverEstimation: cfg.GasLimitOverestimation,
+	}, nil
+}
+
+// decodeEmitted decodes a value emitted by a command into `out`, the cli receives the
+// values of the commands run by the daemon as decoded json.
+func decodeEmitted(v interface{}, out interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// decodeMessages decodes the json of a message, or of an array of messages, into the slice
+// pointed to by `out`.
+func decodeMessages(data []byte, out interface{}) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '[' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return xerrors.Errorf("decoding messages: %v", err)
+	}
+	return nil
+}
+
+func readMessagesFile(path string, out interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return decodeMessages(data, out)
+}
+
+func writeMessagesFile(path string, msgs interface{}) error {
+	data, err := json.MarshalIndent(msgs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// readMessagesArg decodes the messages of the file argument of a command.
+func readMessagesArg(req *cmds.Request, out interface{}) error {
+	iter := req.Files.Entries()
+	if !iter.Next() {
+		return fmt.Errorf("no file given: %s", iter.Err())
+	}
+	fi, ok := iter.Node().(files.File)
+	if !ok {
+		return fmt.Errorf("given file was not a files.File")
+	}
+	data, err := ioutil.ReadAll(fi)
+	if err != nil {
+		return err
+	}
+	return decodeMessages(data, out)
 }
 
 func decodeTypedParams(ctx context.Context, fapi *node.Env, to address.Address, method abi.MethodNum, paramstr string) ([]byte, error) {
@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipfs/go-ipfs-files"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// BatchSendEntry is a row of the csv of a batch send, and what became of its message.
+type BatchSendEntry struct {
+	Row    int
+	To     address.Address
+	Value  abi.TokenAmount
+	Method abi.MethodNum
+	Nonce  uint64
+	Cid    cid.Cid
+	// Resumed is set when the message was pushed by a previous run, according to the
+	// resume file.
+	Resumed bool
+	// ExitCode and Height are set once the message landed on chain, with --wait.
+	ExitCode *exitcode.ExitCode `json:",omitempty"`
+	Height   abi.ChainEpoch     `json:",omitempty"`
+
+	params []byte
+}
+
+// BatchSendReport is the result of the send batch command.
+type BatchSendReport struct {
+	From    address.Address
+	Entries []BatchSendEntry
+}
+
+var msgSendBatchCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Send the messages of the rows of a csv file",
+		ShortDescription: `
+Each row of the csv file is recipient,value[,method,params], the value is in FIL and the
+params are either hex or, when they start with '{' or '[', the json of the params of the
+method. A first row whose recipient and value do not parse is taken as a header.
+
+All the rows are checked before anything is sent: their fields, that the balance of the
+sender covers the values and the gas of all the messages, and the mpool checks of every
+message. The messages are then pushed in the order of the rows, with consecutive nonces.
+
+With --resume, the rows pushed are recorded in the given file, and the rows already
+recorded there are skipped, so that a batch which failed half way can be run again.
+`,
+	},
+	Options: []cmds.Option{
+		// --from is the option of send
+		cmds.StringOption("csv", "csv file of the messages to send"),
+		cmds.StringOption("resume", "file recording the rows pushed, the rows in it are skipped"),
+		cmds.BoolOption("wait", "wait for the messages to land on chain and report their exit codes"),
+	},
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		// the files are read by the cli and handed to the daemon
+		path, _ := req.Options["csv"].(string)
+		if path == "" {
+			return xerrors.New("--csv is required")
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		entries := map[string]files.Node{"csv": files.NewBytesFile(data)}
+
+		if resume, _ := req.Options["resume"].(string); resume != "" {
+			data, err := ioutil.ReadFile(resume)
+			if err == nil {
+				entries["resume"] = files.NewBytesFile(data)
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+		}
+		req.Files = files.NewMapDirectory(entries)
+		return nil
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fapi := env.(*node.Env)
+		inputs, err := readBatchFiles(req)
+		if err != nil {
+			return err
+		}
+
+		from, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+		entries, err := parseBatchCSV(req, fapi, from, inputs["csv"])
+		if err != nil {
+			return err
+		}
+		if err := resumeBatch(entries, inputs["resume"]); err != nil {
+			return err
+		}
+
+		spec, err := estimateSpec(req.Context, fapi)
+		if err != nil {
+			return err
+		}
+		var pending []*types.UnsignedMessage
+		var pendingIdx []int
+		total := big.Zero()
+		for i, e := range entries {
+			if e.Resumed {
+				continue
+			}
+			msg, err := fapi.MessagePoolAPI.GasEstimateMessageGas(req.Context, &types.UnsignedMessage{
+				From:   from,
+				To:     e.To,
+				Value:  e.Value,
+				Method: e.Method,
+				Params: e.params,
+			}, spec, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("estimating gas of row %d: %v", e.Row, err)
+			}
+			total = big.Add(total, big.Add(msg.Value, big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit))))
+			pending = append(pending, msg)
+			pendingIdx = append(pendingIdx, i)
+		}
+
+		report := &BatchSendReport{From: from, Entries: entries}
+		if len(pending) > 0 {
+			if err := checkBatch(req, fapi, from, total, pending, entries, pendingIdx); err != nil {
+				return err
+			}
+
+			smsgs, err := fapi.MessagePoolAPI.MpoolBatchPushMessage(req.Context, pending, nil)
+			for i, sm := range smsgs {
+				if sm == nil {
+					continue
+				}
+				e := &entries[pendingIdx[i]]
+				e.Cid = sm.Cid()
+				e.Nonce = sm.Message.Nonce
+			}
+			if err != nil {
+				// report the messages pushed so far, so that they are recorded in the resume file
+				if err := re.Emit(report); err != nil {
+					return err
+				}
+				return xerrors.Errorf("pushing messages: %v", err)
+			}
+		}
+
+		if wait, _ := req.Options["wait"].(bool); wait {
+			for i := range entries {
+				e := &entries[i]
+				if !e.Cid.Defined() {
+					continue
+				}
+				lookup, err := fapi.ChainAPI.StateWaitMsg(req.Context, e.Cid, constants.MessageConfidence, constants.LookbackNoLimit, true)
+				if err != nil {
+					if err := re.Emit(report); err != nil {
+						return err
+					}
+					return xerrors.Errorf("waiting for message of row %d: %v", e.Row, err)
+				}
+				exit := lookup.Receipt.ExitCode
+				e.Cid = lookup.Message
+				e.ExitCode = &exit
+				e.Height = lookup.Height
+			}
+		}
+
+		return re.Emit(report)
+	},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			v, err := res.Next()
+			if err != nil {
+				return err
+			}
+			var report BatchSendReport
+			if err := decodeEmitted(v, &report); err != nil {
+				return err
+			}
+
+			if resume, _ := res.Request().Options["resume"].(string); resume != "" {
+				var sent []BatchSendEntry
+				for _, e := range report.Entries {
+					if e.Cid.Defined() {
+						sent = append(sent, e)
+					}
+				}
+				if err := writeMessagesFile(resume, sent); err != nil {
+					return err
+				}
+			}
+
+			buf := new(bytes.Buffer)
+			tw := tabwriter.NewWriter(buf, 2, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintf(tw, "Row\tTo\tValue\tNonce\tCid\tStatus\n")
+			for _, e := range report.Entries {
+				c := "-"
+				if e.Cid.Defined() {
+					c = e.Cid.String()
+				}
+				_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%s\n", e.Row, e.To, types.FIL(e.Value), e.Nonce, c, batchEntryStatus(e))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if err := re.Emit(buf); err != nil {
+				return err
+			}
+
+			// the error of a batch which failed half way follows its report
+			if _, err := res.Next(); err != nil && err != io.EOF {
+				return err
+			}
+			return nil
+		},
+	},
+}
+
+func batchEntryStatus(e BatchSendEntry) string {
+	switch {
+	case e.ExitCode != nil:
+		return fmt.Sprintf("exit %d at %d", *e.ExitCode, e.Height)
+	case e.Resumed:
+		return "resumed"
+	case e.Cid.Defined():
+		return "pushed"
+	default:
+		return "not sent"
+	}
+}
+
+// readBatchFiles returns the content of the files handed over by the PreRun of the send
+// batch command, by name.
+func readBatchFiles(req *cmds.Request) (map[string][]byte, error) {
+	out := map[string][]byte{}
+	if req.Files == nil {
+		return out, nil
+	}
+	iter := req.Files.Entries()
+	for iter.Next() {
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a file", iter.Name())
+		}
+		data, err := ioutil.ReadAll(fi)
+		if err != nil {
+			return nil, err
+		}
+		out[iter.Name()] = data
+	}
+	return out, iter.Err()
+}
+
+// parseBatchCSV parses and validates all the rows of the csv, the errors of all the rows are
+// returned together.
+func parseBatchCSV(req *cmds.Request, fapi *node.Env, from address.Address, data []byte) ([]BatchSendEntry, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, xerrors.Errorf("reading csv: %v", err)
+	}
+
+	var entries []BatchSendEntry
+	var errs []string
+	for i, rec := range records {
+		row := i + 1
+		if i == 0 && isBatchHeader(rec) {
+			continue
+		}
+		e, err := parseBatchRow(req, fapi, from, rec)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("row %d: %v", row, err))
+			continue
+		}
+		e.Row = row
+		entries = append(entries, e)
+	}
+	if len(errs) > 0 {
+		return nil, xerrors.Errorf("invalid rows:\n%s", strings.Join(errs, "\n"))
+	}
+	if len(entries) == 0 {
+		return nil, xerrors.New("no rows to send")
+	}
+	return entries, nil
+}
+
+// isBatchHeader tells whether the row is a header, that is neither its recipient nor its value
+// parse.
+func isBatchHeader(rec []string) bool {
+	if len(rec) < 2 {
+		return false
+	}
+	if _, err := address.NewFromString(strings.TrimSpace(rec[0])); err == nil {
+		return false
+	}
+	_, ok := types.NewAttoFILFromFILString(strings.TrimSpace(rec[1]))
+	return !ok
+}
+
+func parseBatchRow(req *cmds.Request, fapi *node.Env, from address.Address, rec []string) (BatchSendEntry, error) {
+	var e BatchSendEntry
+	if len(rec) < 2 || len(rec) > 4 {
+		return e, xerrors.Errorf("expected recipient,value[,method,params], got %d fields", len(rec))
+	}
+	for i := range rec {
+		rec[i] = strings.TrimSpace(rec[i])
+	}
+
+	var err error
+	if e.To, err = address.NewFromString(rec[0]); err != nil {
+		return e, xerrors.Errorf("parsing recipient: %v", err)
+	}
+	val, ok := types.NewAttoFILFromFILString(rec[1])
+	if !ok || val.Sign() < 0 {
+		return e, xerrors.Errorf("invalid value %s", rec[1])
+	}
+	e.Value = val
+
+	e.Method = builtin.MethodSend
+	if len(rec) > 2 && rec[2] != "" {
+		m, err := strconv.ParseUint(rec[2], 10, 64)
+		if err != nil {
+			return e, xerrors.Errorf("parsing method: %v", err)
+		}
+		e.Method = abi.MethodNum(m)
+	}
+	if e.Method == builtin.MethodSend && e.To == from {
+		return e, xerrors.New("self-transfer is not allowed")
+	}
+
+	if len(rec) > 3 && rec[3] != "" {
+		p := rec[3]
+		if strings.HasPrefix(p, "{") || strings.HasPrefix(p, "[") {
+			e.params, err = decodeTypedParams(req.Context, fapi, e.To, e.Method, p)
+		} else {
+			e.params, err = hex.DecodeString(strings.TrimPrefix(p, "0x"))
+		}
+		if err != nil {
+			return e, xerrors.Errorf("decoding params: %v", err)
+		}
+	}
+	return e, nil
+}
+
+// resumeBatch marks the entries recorded in the resume file as resumed, the rows of the
+// file must match the ones of the csv.
+func resumeBatch(entries []BatchSendEntry, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var sent []BatchSendEntry
+	if err := json.Unmarshal(data, &sent); err != nil {
+		return xerrors.Errorf("decoding resume file: %v", err)
+	}
+	rows := make(map[int]BatchSendEntry, len(sent))
+	for _, s := range sent {
+		rows[s.Row] = s
+	}
+	for i := range entries {
+		s, ok := rows[entries[i].Row]
+		if !ok {
+			continue
+		}
+		if s.To != entries[i].To || !s.Value.Equals(entries[i].Value) {
+			return xerrors.Errorf("row %d does not match the resume file, was it edited?", entries[i].Row)
+		}
+		entries[i].Cid = s.Cid
+		entries[i].Nonce = s.Nonce
+		entries[i].Resumed = true
+	}
+	return nil
+}
+
+// checkBatch makes sure the balance of the sender covers the whole batch and that the mpool
+// accepts each message, before anything is pushed.
+func checkBatch(req *cmds.Request, fapi *node.Env, from address.Address, total abi.TokenAmount, msgs []*types.UnsignedMessage, entries []BatchSendEntry, idx []int) error {
+	act, err := fapi.ChainAPI.StateGetActor(req.Context, from, types.EmptyTSK)
+	if err != nil {
+		return xerrors.Errorf("getting sender actor: %v", err)
+	}
+	if act.Balance.LessThan(total) {
+		return xerrors.Errorf("balance of %s is %s, the batch needs up to %s", from, types.FIL(act.Balance), types.FIL(total))
+	}
+
+	protos := make([]*apitypes.MessagePrototype, len(msgs))
+	for i, msg := range msgs {
+		protos[i] = &apitypes.MessagePrototype{Message: *msg}
+	}
+	checks, err := fapi.MessagePoolAPI.MpoolCheckMessages(req.Context, protos)
+	if err != nil {
+		return xerrors.Errorf("checking messages: %v", err)
+	}
+	var errs []string
+	for i, statuses := range checks {
+		for _, s := range statuses {
+			if !s.OK {
+				errs = append(errs, fmt.Sprintf("row %d: %s", entries[idx[i]].Row, s.Err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return xerrors.Errorf("messages failed the mpool checks:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
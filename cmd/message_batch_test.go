@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipfs/go-ipfs-files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/apiface"
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestParseBatchCSV(t *testing.T) {
+	tf.UnitTest(t)
+
+	from, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+
+	t.Run("parses rows and skips the header", func(t *testing.T) {
+		data := []byte("recipient,value,method,params\nt0101,1.5\nt0102, 2 ,3,0x0a0b\n")
+		entries, err := parseBatchCSV(nil, nil, from, data)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+
+		assert.Equal(t, 2, entries[0].Row)
+		assert.Equal(t, "t0101", entries[0].To.String())
+		assert.Equal(t, types.MustParseFIL("1.5").String(), types.FIL(entries[0].Value).String())
+		assert.Equal(t, abi.MethodNum(0), entries[0].Method)
+
+		assert.Equal(t, 3, entries[1].Row)
+		assert.Equal(t, abi.MethodNum(3), entries[1].Method)
+		assert.Equal(t, []byte{0x0a, 0x0b}, entries[1].params)
+	})
+
+	t.Run("reports all the invalid rows", func(t *testing.T) {
+		data := []byte("t0101,1\nnotanaddress,1\nt0102,-1\nt0100,1\n")
+		_, err := parseBatchCSV(nil, nil, from, data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "row 2")
+		assert.Contains(t, err.Error(), "row 3")
+		assert.Contains(t, err.Error(), "row 4")
+		assert.NotContains(t, err.Error(), "row 1")
+	})
+}
+
+func TestResumeBatch(t *testing.T) {
+	tf.UnitTest(t)
+
+	from, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+	entries, err := parseBatchCSV(nil, nil, from, []byte("t0101,1\nt0102,2\n"))
+	require.NoError(t, err)
+
+	sent := entries[0]
+	sent.Cid = types.CidFromString(t, "sent")
+	sent.Nonce = 7
+	data, err := json.Marshal([]BatchSendEntry{sent})
+	require.NoError(t, err)
+
+	require.NoError(t, resumeBatch(entries, data))
+	assert.True(t, entries[0].Resumed)
+	assert.Equal(t, sent.Cid, entries[0].Cid)
+	assert.Equal(t, uint64(7), entries[0].Nonce)
+	assert.False(t, entries[1].Resumed)
+
+	// a row edited since the previous run is refused
+	edited, err := parseBatchCSV(nil, nil, from, []byte("t0101,3\nt0102,2\n"))
+	require.NoError(t, err)
+	assert.Error(t, resumeBatch(edited, data))
+}
+
+// fakeBatchAPI accepts and pushes every message of a batch, with consecutive nonces.
+type fakeBatchAPI struct {
+	*fakeMpoolAPI
+	pushed []*types.UnsignedMessage
+}
+
+func (f *fakeBatchAPI) MpoolCheckMessages(ctx context.Context, protos []*apitypes.MessagePrototype) ([][]apitypes.MessageCheckStatus, error) {
+	out := make([][]apitypes.MessageCheckStatus, len(protos))
+	for i := range protos {
+		out[i] = []apitypes.MessageCheckStatus{{CheckStatus: apitypes.CheckStatus{OK: true}}}
+	}
+	return out, nil
+}
+
+func (f *fakeBatchAPI) MpoolBatchPushMessage(ctx context.Context, msgs []*types.UnsignedMessage, spec *types.MessageSendSpec) ([]*types.SignedMessage, error) {
+	var out []*types.SignedMessage
+	for _, msg := range msgs {
+		msg.Nonce = f.nonce
+		f.nonce++
+		f.pushed = append(f.pushed, msg)
+		out = append(out, &types.SignedMessage{Message: *msg})
+	}
+	return out, nil
+}
+
+type fakeActorAPI struct {
+	apiface.IChain
+	balance abi.TokenAmount
+}
+
+func (f *fakeActorAPI) StateGetActor(context.Context, address.Address, types.TipSetKey) (*types.Actor, error) {
+	return &types.Actor{Balance: f.balance}, nil
+}
+
+func TestSendBatch(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	mpool := &fakeBatchAPI{fakeMpoolAPI: &fakeMpoolAPI{nonce: 3, gasUsed: 1000}}
+	env := node.NewClientEnv(ctx)
+	env.MessagePoolAPI = mpool
+	env.ChainAPI = &fakeActorAPI{balance: types.NewAttoFILFromFIL(10)}
+
+	sendBatch := func(csv string) (interface{}, error) {
+		req, err := cmds.NewRequest(ctx, []string{"send", "batch"}, cmds.OptMap{"from": "t0100"}, nil,
+			files.NewMapDirectory(map[string]files.Node{"csv": files.NewBytesFile([]byte(csv))}), RootCmd)
+		require.NoError(t, err)
+		return callCmd(req, env)
+	}
+
+	v, err := sendBatch("t0101,1\nt0102,2\n")
+	require.NoError(t, err)
+	report, ok := v.(*BatchSendReport)
+	require.True(t, ok)
+	require.Len(t, report.Entries, 2)
+	require.Len(t, mpool.pushed, 2)
+	for i, e := range report.Entries {
+		msg := mpool.pushed[i]
+		assert.Equal(t, e.To, msg.To)
+		assert.Equal(t, uint64(3+i), e.Nonce)
+		assert.Equal(t, (&types.SignedMessage{Message: *msg}).Cid(), e.Cid)
+		// the gas limit is estimated with the over estimation of the mpool
+		assert.Equal(t, int64(1250), msg.GasLimit)
+	}
+
+	// the balance must cover the values and the gas of the whole batch
+	_, err = sendBatch("t0101,9\nt0102,1\n")
+	assert.Error(t, err)
+	assert.Len(t, mpool.pushed, 2)
+}
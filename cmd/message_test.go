@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipfs/go-ipfs-files"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/submodule/apiface"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/messagepool"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/wallet"
+)
+
+// fakeMpoolAPI estimates the gas of the messages as the mpool does, from a fixed gas used.
+type fakeMpoolAPI struct {
+	apiface.IMessagePool
+	nonce   uint64
+	gasUsed int64
+}
+
+func (f *fakeMpoolAPI) MpoolGetConfig(context.Context) (*messagepool.MpoolConfig, error) {
+	return &messagepool.MpoolConfig{GasLimitOverestimation: 1.25}, nil
+}
+
+func (f *fakeMpoolAPI) MpoolGetNonce(context.Context, address.Address) (uint64, error) {
+	return f.nonce, nil
+}
+
+func (f *fakeMpoolAPI) GasEstimateMessageGas(ctx context.Context, msg *types.UnsignedMessage, spec *types.MessageSendSpec, tsk types.TipSetKey) (*types.UnsignedMessage, error) {
+	out := *msg
+	out.GasLimit = int64(float64(f.gasUsed) * spec.GasOverEstimation)
+	out.GasFeeCap = abi.NewTokenAmount(100)
+	out.GasPremium = abi.NewTokenAmount(10)
+	return &out, nil
+}
+
+// callCmd runs the command of `req` and returns the first value it emitted.
+func callCmd(req *cmds.Request, env cmds.Environment) (interface{}, error) {
+	re, res := cmds.NewChanResponsePair(req)
+	go RootCmd.Call(req, re, env)
+	return res.Next()
+}
+
+func TestSendUnsignedOut(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	from, err := address.NewSecp256k1Address([]byte("from"))
+	require.NoError(t, err)
+	env := node.NewClientEnv(ctx)
+	env.MessagePoolAPI = &fakeMpoolAPI{nonce: 7, gasUsed: 1000}
+
+	req, err := cmds.NewRequest(ctx, []string{"send"}, cmds.OptMap{
+		"from":         from.String(),
+		"unsigned-out": filepath.Join(t.TempDir(), "msgs.json"),
+	}, []string{"t0101", "1"}, nil, RootCmd)
+	require.NoError(t, err)
+	v, err := callCmd(req, env)
+	require.NoError(t, err)
+
+	msg, ok := v.(*types.UnsignedMessage)
+	require.True(t, ok)
+	assert.Equal(t, from, msg.From)
+	assert.Equal(t, uint64(7), msg.Nonce)
+	assert.Equal(t, int64(1250), msg.GasLimit)
+	assert.Equal(t, abi.NewTokenAmount(100), msg.GasFeeCap)
+}
+
+func TestWalletSignFile(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	cfg := config.NewDefaultConfig()
+	cfg.Wallet.PassphraseConfig = config.TestPassphraseConfig()
+	require.NoError(t, repo.InitFSRepo(repoDir, repo.LatestVersion, cfg))
+	r, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+	require.NoError(t, err)
+	// the wallet wipes the password it is given
+	password := "password"
+	backend, err := wallet.NewDSBackend(r.WalletDatastore(), cfg.Wallet.PassphraseConfig, []byte(password))
+	require.NoError(t, err)
+	from, err := backend.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	msgs := []*types.UnsignedMessage{
+		{From: from, To: types.RequireIDAddress(t, 101), Nonce: 7, Value: abi.NewTokenAmount(1), GasLimit: 1000, GasFeeCap: abi.NewTokenAmount(100), GasPremium: abi.NewTokenAmount(10)},
+		{From: from, To: types.RequireIDAddress(t, 101), Nonce: 8, Value: abi.NewTokenAmount(1), GasLimit: 1000, GasFeeCap: abi.NewTokenAmount(100), GasPremium: abi.NewTokenAmount(10)},
+	}
+	signFile := func(msgs interface{}, password string) (interface{}, error) {
+		data, err := json.Marshal(msgs)
+		require.NoError(t, err)
+		passwordFile := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, ioutil.WriteFile(passwordFile, []byte(password+"\n"), 0600))
+		req, err := cmds.NewRequest(ctx, []string{"wallet", "sign-file"}, cmds.OptMap{
+			OptionRepoDir:      repoDir,
+			passwordFileOption: passwordFile,
+		}, nil, files.NewMapDirectory(map[string]files.Node{"messages": files.NewBytesFile(data)}), RootCmd)
+		require.NoError(t, err)
+		return callCmd(req, node.NewClientEnv(ctx))
+	}
+
+	// the wallet of the repo signs without any api
+	v, err := signFile(msgs, password)
+	require.NoError(t, err)
+	signed, ok := v.([]*types.SignedMessage)
+	require.True(t, ok)
+	require.Len(t, signed, 2)
+	for i, sm := range signed {
+		assert.Equal(t, *msgs[i], sm.Message)
+		require.NoError(t, crypto.Verify(&sm.Signature, from, sm.Message.Cid().Bytes()))
+	}
+
+	// the signed messages round trip through the file
+	out := filepath.Join(t.TempDir(), "signed.json")
+	require.NoError(t, writeMessagesFile(out, signed))
+	var read []*types.SignedMessage
+	data, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	require.NoError(t, decodeMessages(data, &read))
+	require.Len(t, read, 2)
+	assert.Equal(t, signed[1].Cid(), read[1].Cid())
+
+	_, err = signFile(msgs, "wrong password")
+	assert.Error(t, err)
+
+	// a message from an id address cannot be signed without the chain
+	idMsg := *msgs[0]
+	idMsg.From = types.RequireIDAddress(t, 100)
+	_, err = signFile(&idMsg, password)
+	assert.Error(t, err)
+}
@@ -39,6 +39,7 @@ var minerCmd = &cmds.Command{
 		"info":    minerInfoCmd,
 		"actor":   minerActorCmd,
 		"proving": minerProvingCmd,
+		"health":  minerHealthCmd,
 	},
 }
 
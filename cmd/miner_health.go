@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var minerHealthCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Check the deadlines, sectors and balances of a miner.",
+		ShortDescription: `
+Reports, for each deadline of the miner, the next challenge window and the faulty,
+recovering and soon expiring sectors, along with the fee debt and the balances needed
+for the upcoming PoSts, and warns about everything putting the power of the miner at risk.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("address", true, false, "Address of miner to check"),
+	},
+	Options: []cmds.Option{
+		cmds.UintOption("expiring-days", "count the sectors expiring within this number of days").WithDefault(uint(30)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		maddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		ctx := req.Context
+
+		blockDelay, err := blockDelay(env.(*node.Env).ConfigAPI)
+		if err != nil {
+			return err
+		}
+		if blockDelay == 0 {
+			return xerrors.New("block delay is not set")
+		}
+		days, _ := req.Options["expiring-days"].(uint)
+		expiryWindow := abi.ChainEpoch(uint64(days) * 24 * 60 * 60 / blockDelay)
+
+		h, err := env.(*node.Env).ChainAPI.StateMinerHealth(ctx, maddr, expiryWindow, types.EmptyTSK)
+		if err != nil {
+			return xerrors.Errorf("getting miner health: %w", err)
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("Miner:             %s\n", h.Miner)
+		writer.Printf("Current Epoch:     %d\n", h.CurrentEpoch)
+		writer.Printf("Fee Debt:          %s\n", types.FIL(h.FeeDebt))
+		writer.Printf("Available Balance: %s\n", types.FIL(h.AvailableBalance))
+		writer.Printf("Worker Balance:    %s (%s)\n", types.FIL(h.WorkerBalance), h.Worker)
+		writer.Printf("PoSt Cost:         %s per proving period\n", types.FIL(h.PoStCost))
+		writer.Println()
+
+		tw := tabwriter.NewWriter(buf, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "deadline\tnext open\tpartitions (proven)\tlive\tfaulty\trecovering\texpiring in %dd\tat risk\n", days)
+		for _, dh := range h.Deadlines {
+			var cur string
+			if dh.Index == h.Deadline.Index {
+				cur = "\t(current)"
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%d (%d)\t%d\t%d\t%d\t%d\t%d%s\n", dh.Index, EpochTime(h.CurrentEpoch, dh.Open, blockDelay),
+				dh.Partitions, dh.ProvenPartitions, dh.Live, dh.Faulty, dh.Recovering, dh.Expiring, dh.AtRisk, cur)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		writer.Println()
+		if len(h.Warnings) == 0 {
+			writer.Println("No warnings")
+		}
+		for _, w := range h.Warnings {
+			writer.Printf("WARNING: %s\n", w)
+		}
+
+		return re.Emit(buf)
+	},
+}
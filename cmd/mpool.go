@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	stdbig "math/big"
 
@@ -17,6 +19,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/cmd/tablewriter"
 	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/messagepool"
@@ -28,17 +31,19 @@ var mpoolCmd = &cmds.Command{
 		Tagline: "Manage message pool",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"pending":  mpoolPending,
-		"clear":    mpoolClear,
-		"sub":      mpoolSub,
-		"stat":     mpoolStat,
-		"replace":  mpoolReplaceCmd,
-		"find":     mpoolFindCmd,
-		"config":   mpoolConfig,
-		"gas-perf": mpoolGasPerfCmd,
-		"publish":  mpoolPublish,
-		"delete":   mpoolDeleteAddress,
-		"select":   mpoolSelect,
+		"pending":      mpoolPending,
+		"clear":        mpoolClear,
+		"sub":          mpoolSub,
+		"stat":         mpoolStat,
+		"replace":      mpoolReplaceCmd,
+		"find":         mpoolFindCmd,
+		"config":       mpoolConfig,
+		"gas-perf":     mpoolGasPerfCmd,
+		"publish":      mpoolPublish,
+		"delete":       mpoolDeleteAddress,
+		"select":       mpoolSelect,
+		"gas-estimate": mpoolGasEstimateCmd,
+		"push-file":    mpoolPushFileCmd,
 	},
 }
 
@@ -677,6 +682,100 @@ var mpoolConfig = &cmds.Command{
 	},
 }
 
+var mpoolGasEstimateCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Estimate the gas premium and fee cap for a message to be included on chain",
+		ShortDescription: `
+Estimates, from the blocks of the last tipsets, the lowest gas premium with which a message of
+--gas-limit gas should be included within each number of epochs of --targets, and the matching
+fee cap. Premiums and fee caps are in attoFIL per gas unit.
+
+With --history, the base fee, the gas limit over the gas target and the gas premium percentiles
+of the last tipsets are printed first.
+`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("gas-limit", "gas limit of the message").WithDefault(int64(10_000_000)),
+		cmds.StringOption("targets", "comma separated numbers of epochs to be included within").WithDefault("1,5,20"),
+		cmds.IntOption("history", "number of tipsets of fee history to print").WithDefault(0),
+		cmds.StringOption("percentiles", "comma separated percentiles of the premiums of the fee history").WithDefault("10,50,90"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := env.(*node.Env).MessagePoolAPI
+		buf := new(bytes.Buffer)
+
+		if history, _ := req.Options["history"].(int); history > 0 {
+			var percentiles []float64
+			for _, s := range strings.Split(req.Options["percentiles"].(string), ",") {
+				p, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+				if err != nil {
+					return xerrors.Errorf("parsing percentile %s: %w", s, err)
+				}
+				percentiles = append(percentiles, p)
+			}
+
+			fh, err := api.GasFeeHistory(req.Context, history, percentiles, types.EmptyTSK)
+			if err != nil {
+				return err
+			}
+
+			cols := []tablewriter.Column{tablewriter.Col("Epoch"), tablewriter.Col("BaseFee"), tablewriter.Col("GasLimitRatio")}
+			for _, p := range percentiles {
+				cols = append(cols, tablewriter.Col(fmt.Sprintf("P%v", p)))
+			}
+			tw := tablewriter.New(cols...)
+			for i := range fh.Premiums {
+				row := map[string]interface{}{
+					"Epoch":         fh.OldestEpoch + abi.ChainEpoch(i),
+					"BaseFee":       fh.BaseFee[i],
+					"GasLimitRatio": fmt.Sprintf("%.2f", fh.GasLimitRatio[i]),
+				}
+				for j, p := range percentiles {
+					row[fmt.Sprintf("P%v", p)] = fh.Premiums[i][j]
+				}
+				tw.Write(row)
+			}
+			if err := tw.Flush(buf); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(buf, "next base fee: %s\n\n", fh.BaseFee[len(fh.BaseFee)-1])
+		}
+
+		var targets []uint64
+		for _, s := range strings.Split(req.Options["targets"].(string), ",") {
+			target, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return xerrors.Errorf("parsing target %s: %w", s, err)
+			}
+			targets = append(targets, target)
+		}
+
+		gasLimit, _ := req.Options["gas-limit"].(int64)
+		estimates, err := api.GasEstimateInclusion(req.Context, gasLimit, targets, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Epochs"),
+			tablewriter.Col("GasPremium"),
+			tablewriter.Col("GasFeeCap"),
+			tablewriter.Col("Probability"))
+		for _, est := range estimates {
+			tw.Write(map[string]interface{}{
+				"Epochs":      est.Epochs,
+				"GasPremium":  est.GasPremium,
+				"GasFeeCap":   est.GasFeeCap,
+				"Probability": fmt.Sprintf("%.1f%%", est.Probability*100),
+			})
+		}
+		if err := tw.Flush(buf); err != nil {
+			return err
+		}
+		return re.Emit(buf)
+	},
+}
+
 var mpoolGasPerfCmd = &cmds.Command{
 	Helptext: cmds.HelpText{
 		Tagline: "gas-perf",
@@ -754,3 +853,35 @@ Check gas performance of messages in mempool
 		return nil
 	},
 }
+
+var mpoolPushFileCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Push the signed messages of a file to the message pool",
+		ShortDescription: `
+Pushes the messages signed by 'venus wallet sign-file', a message or an array of messages
+in json, in order. It stops at the first message rejected.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.FileArg("messages", true, false, "File containing the signed messages").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var msgs []*types.SignedMessage
+		if err := readMessagesArg(req, &msgs); err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		for i, sm := range msgs {
+			c, err := env.(*node.Env).MessagePoolAPI.MpoolPush(req.Context, sm)
+			if err != nil {
+				_ = re.Emit(buf)
+				return xerrors.Errorf("pushing message %d from %s with nonce %d, %d messages pushed: %v", i, sm.Message.From, sm.Message.Nonce, i, err)
+			}
+			writer.Println(c.String())
+		}
+
+		return re.Emit(buf)
+	},
+}
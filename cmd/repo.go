@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/howeyc/gopass"
+	"github.com/ipfs/go-cid"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/app/paths"
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/migration"
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+var repoCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Manage the repo of the node",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"convert": repoConvertCmd,
+		"backup":  repoBackupCmd,
+		"restore": repoRestoreCmd,
+	},
+}
+
+// repoCmdDaemon holds the repo commands run by the daemon, see requiresDaemon.
+var repoCmdDaemon = &cmds.Command{
+	Subcommands: map[string]*cmds.Command{
+		"convert": repoConvertCmd,
+		"backup":  repoBackupCmd,
+	},
+}
+
+var repoConvertCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Move the data of the repo to another datastore backend",
+		ShortDescription: `
+Copies the blockstore, and the chain, metadata, paych and wallet datastores, to the backend
+given by --to. The data in the former backend is left in place, remove it once the node runs on
+the new one.
+
+The running node copies its repo while it keeps syncing, the writes it makes meanwhile are
+copied too, and switches to the new backend when it is restarted. With --offline the repo of a
+stopped node is converted and switched at once.
+
+The car backend is a read-only car file of the blocks, such as an archived chain, the other
+datastores then stay in their current backend. A repo is only converted to it with --offline.
+`,
+	},
+	Extra: AdminExtra,
+	Options: []cmds.Option{
+		cmds.StringOption("to", "the datastore type to convert to, one of "+strings.Join(repo.BackendTypes(), ", ")),
+		cmds.StringOption("path", "where to keep the blockstore in the repo, defaults to a path of the backend"),
+		cmds.BoolOption("offline", "convert the repo of a stopped node"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		to, _ := req.Options["to"].(string)
+		if to == "" {
+			return xerrors.New("--to is required")
+		}
+		path, _ := req.Options["path"].(string)
+
+		var res *repo.ConvertResult
+		var err error
+		if offline, _ := req.Options["offline"].(bool); offline {
+			res, err = convertStoppedRepo(req, to, path)
+		} else {
+			res, err = env.(*node.Env).RepoAPI.Convert(req.Context, to, path)
+		}
+		if err != nil {
+			return err
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("converted the repo to %s: %d blocks and %d datastore entries copied\n", to, res.Blocks, res.Entries)
+		if offline, _ := req.Options["offline"].(bool); !offline {
+			writer.WriteStringln("restart the node to switch to the new backend")
+		}
+		if len(res.Leftovers) > 0 {
+			writer.WriteStringln("the former data can be removed once the node runs on the new backend:")
+			for _, p := range res.Leftovers {
+				writer.Printf("  %s\n", p)
+			}
+		}
+		return re.Emit(buf)
+	},
+}
+
+// convertStoppedRepo converts the repo of the request, which fails while the node is running.
+func convertStoppedRepo(req *cmds.Request, to, path string) (*repo.ConvertResult, error) {
+	repoDir, _ := req.Options[OptionRepoDir].(string)
+	repoDir, err := paths.GetRepoPath(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	if running, err := repo.IsLocked(repoDir); err != nil {
+		return nil, err
+	} else if running {
+		return nil, xerrors.New("the node is running, stop the daemon before converting the repo with --offline")
+	}
+
+	r, err := openStoppedRepo(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// a car file takes the head as its roots
+	var roots []cid.Cid
+	if head, err := r.ChainDatastore().Get(chain.HeadKey); err == nil {
+		var tsk types.TipSetKey
+		if err := tsk.UnmarshalCBOR(bytes.NewReader(head)); err == nil {
+			roots = tsk.Cids()
+		}
+	}
+	return r.Convert(req.Context, to, path, roots)
+}
+
+var repoBackupCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Back up the config, keys and metadata of the repo to a file",
+		ShortDescription: `
+Writes the config, the keystore and the chain, metadata, paych and wallet datastores of the
+repo to <file>, they hold the local messages, the payment channel vouchers and the keys of the
+wallet. Of the blocks, only the genesis is included, the chain is synced again from it after a
+restore. The backup is taken from the running node, or from the repo with --offline when the
+node is stopped.
+
+With --encrypt the backup is encrypted by the cli with a password prompted for, or read from
+--password-file, the password is not sent to the node. Restore the backup with
+'venus repo restore'.
+`,
+	},
+	Extra: AdminExtra,
+	Arguments: []cmds.Argument{
+		cmds.StringArg("file", true, false, "the file to write the backup to"),
+	},
+	Options: []cmds.Option{
+		cmds.BoolOption("encrypt", "encrypt the backup with a password prompted for"),
+		cmds.StringOption(passwordFileOption, "file holding the password to encrypt the backup with, instead of prompting for it"),
+		cmds.BoolOption("offline", "back up the repo of a stopped node"),
+	},
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		if _, err := os.Stat(req.Arguments[0]); !os.IsNotExist(err) {
+			return xerrors.Errorf("%s exists already", req.Arguments[0])
+		}
+		// the password is kept by the cli, which encrypts the backup, and is not sent to the
+		// daemon with the request
+		if _, ok := req.Context.Value(backupPasswordKey{}).([]byte); ok {
+			return nil
+		}
+		if encrypt, _ := req.Options["encrypt"].(bool); !encrypt {
+			return nil
+		}
+		pw, err := readPasswordFile(req)
+		if err != nil {
+			return err
+		}
+		if len(pw) == 0 {
+			pw1, err := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+			if err != nil {
+				return err
+			}
+			pw2, err := gopass.GetPasswdPrompt("Enter Password again:", true, os.Stdin, os.Stdout)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(pw1, pw2) {
+				return xerrors.New("the input passwords are inconsistent")
+			}
+			if len(pw1) == 0 {
+				return xerrors.New("the password is empty")
+			}
+			pw = pw1
+		}
+		req.Context = context.WithValue(req.Context, backupPasswordKey{}, pw)
+		return nil
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		backup := func(ctx context.Context, w io.Writer) error {
+			_, err := env.(*node.Env).RepoAPI.Backup(ctx, w)
+			return err
+		}
+		if offline, _ := req.Options["offline"].(bool); offline {
+			r, err := openStoppedRepo(req)
+			if err != nil {
+				return err
+			}
+			backup = func(ctx context.Context, w io.Writer) error {
+				defer r.Close() // nolint: errcheck
+				_, err := node.NewRepoAPI(r).Backup(ctx, w)
+				return err
+			}
+		}
+
+		// the plain backup is streamed to the cli, which encrypts it and writes it to the file,
+		// see PostRun
+		pr, pw := io.Pipe()
+		go func() {
+			_ = pw.CloseWithError(backup(req.Context, pw))
+		}()
+		return re.Emit(pr)
+	},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			v, err := res.Next()
+			if err != nil {
+				return err
+			}
+			r, ok := v.(io.Reader)
+			if !ok {
+				return xerrors.Errorf("unexpected backup of type %T", v)
+			}
+			if password, _ := res.Request().Context.Value(backupPasswordKey{}).([]byte); len(password) > 0 {
+				plain := r
+				pr, pw := io.Pipe()
+				go func() {
+					_ = pw.CloseWithError(repo.EncryptBackup(pw, plain, password))
+				}()
+				r = pr
+			}
+			path := res.Request().Arguments[0]
+			if err := writeBackupFile(path, r); err != nil {
+				return xerrors.Errorf("writing the backup: %w", err)
+			}
+			return printOneString(re, fmt.Sprintf("backup written to %s", path))
+		},
+	},
+}
+
+// backupPasswordKey is the key of the password of the backup in the context of the request
+// of the cli.
+type backupPasswordKey struct{}
+
+// writeBackupFile writes the backup read from `r` to `path`, which only exists once the whole
+// backup is written.
+func writeBackupFile(path string, r io.Reader) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+var repoRestoreCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Create a repo from a backup",
+		ShortDescription: `
+Creates the repo, at --repodir or the default repo path, from a backup written by 'venus repo
+backup'. The repo must not exist. A backup of an older repo version is migrated, a backup of a
+newer one needs a newer venus. The password of an encrypted backup is prompted for, or read
+from --password-file. The head of the chain is reset to the genesis, the chain is synced again
+when the node starts. The repo is removed when the restore fails.
+`,
+	},
+	Arguments: []cmds.Argument{
+		cmds.StringArg("file", true, false, "the backup to restore"),
+	},
+	Options: []cmds.Option{
+		cmds.StringOption(passwordFileOption, "file holding the password of an encrypted backup, instead of prompting for it"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		repoDir, _ := req.Options[OptionRepoDir].(string)
+		repoDir, err := paths.GetRepoPath(repoDir)
+		if err != nil {
+			return err
+		}
+		password, err := readPasswordFile(req)
+		if err != nil {
+			return err
+		}
+
+		restore := func(password []byte) (*repo.BackupResult, error) {
+			f, err := os.Open(req.Arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close() // nolint: errcheck
+			return repo.Restore(req.Context, f, repoDir, password)
+		}
+		res, err := restore(password)
+		if err == repo.ErrBackupPassword && len(password) == 0 {
+			pw, perr := gopass.GetPasswdPrompt("Password:", true, os.Stdin, os.Stdout)
+			if perr != nil {
+				return perr
+			}
+			res, err = restore(pw)
+		}
+		if err != nil {
+			return err
+		}
+		// a repo which is not fully restored would be taken up by the next restore or daemon
+		if err := migration.TryToMigrate(repoDir); err != nil {
+			_ = os.RemoveAll(repoDir)
+			return xerrors.Errorf("migrating the restored repo: %w", err)
+		}
+		if err := resetRestoredHead(repoDir); err != nil {
+			_ = os.RemoveAll(repoDir)
+			return xerrors.Errorf("resetting the head of the restored repo: %w", err)
+		}
+
+		buf := new(bytes.Buffer)
+		writer := NewSilentWriter(buf)
+		writer.Printf("restored the repo at %s: %d keys\n", repoDir, res.Keys)
+		names := make([]string, 0, len(res.Entries))
+		for name := range res.Entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			writer.Printf("  %s: %d entries\n", name, res.Entries[name])
+		}
+		writer.WriteStringln("the head is reset to the genesis, the chain is synced again when the node starts")
+		return re.Emit(buf)
+	},
+}
+
+// resetRestoredHead sets the head of the chain of the repo at `repoDir` to the genesis, the
+// blocks of the former head are not in the backup.
+func resetRestoredHead(repoDir string) error {
+	r, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+	if err != nil {
+		return err
+	}
+	defer r.Close() // nolint: errcheck
+	return chain.ResetHeadToGenesis(r)
+}
+
+// openStoppedRepo opens the repo of the request, which fails while the node is running.
+func openStoppedRepo(req *cmds.Request) (*repo.FSRepo, error) {
+	repoDir, _ := req.Options[OptionRepoDir].(string)
+	repoDir, err := paths.GetRepoPath(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := migration.TryToMigrate(repoDir); err != nil {
+		return nil, err
+	}
+	r, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+	if err != nil {
+		return nil, xerrors.Errorf("opening the repo, is the node stopped: %w", err)
+	}
+	return r, nil
+}
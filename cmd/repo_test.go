@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestRepoConvertOfRunningNode(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, repo.InitFSRepo(repoDir, repo.LatestVersion, config.NewDefaultConfig()))
+	// the node holds the repo open
+	r, err := repo.OpenFSRepo(repoDir, repo.LatestVersion)
+	require.NoError(t, err)
+	defer r.Close() // nolint: errcheck
+
+	req, err := cmds.NewRequest(ctx, []string{"repo", "convert"}, cmds.OptMap{
+		OptionRepoDir: repoDir,
+		"to":          "levelds",
+		"offline":     true,
+	}, nil, nil, RootCmd)
+	require.NoError(t, err)
+	_, err = callCmd(req, node.NewClientEnv(ctx))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stop the daemon")
+}
@@ -3,9 +3,11 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 
 	"github.com/filecoin-project/go-address"
@@ -19,6 +21,7 @@ import (
 	"github.com/filecoin-project/venus/app/node"
 	"github.com/filecoin-project/venus/app/submodule/apiface"
 	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/cmd/tablewriter"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin"
 	"github.com/filecoin-project/venus/pkg/types"
@@ -51,6 +54,8 @@ var stateCmd = &cmds.Command{
 		"miner-info":      stateMinerInfo,
 		"network-version": stateNtwkVersionCmd,
 		"list-actor":      stateListActorCmd,
+		"miners":          stateMinersCmd,
+		"supply":          stateSupplyCmd,
 	},
 }
 
@@ -588,6 +593,177 @@ var stateListActorCmd = &cmds.Command{
 	},
 }
 
+var stateMinersCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "List all miners with claimed power",
+		ShortDescription: `Prints power, sector counts, faults, locked funds and peer id of every miner in the
+power actor. Miners can be sorted by power, raw, sectors, faults, locked or address.`,
+	},
+	Options: []cmds.Option{
+		cmds.StringOption("sort", "Sort miners by power, raw, sectors, faults, locked or address").WithDefault("power"),
+		cmds.UintOption("limit", "Number of miners to show, 0 shows all").WithDefault(uint(0)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		sortBy, _ := req.Options["sort"].(string)
+		less, ok := minerSummaryOrders[sortBy]
+		if !ok {
+			return xerrors.Errorf("unknown sort order %s", sortBy)
+		}
+
+		miners, err := env.(*node.Env).ChainAPI.StateMinersSummary(req.Context, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+		sortMinerSummaries(miners, less)
+		if limit, _ := req.Options["limit"].(uint); limit > 0 && int(limit) < len(miners) {
+			miners = miners[:limit]
+		}
+
+		buf := new(bytes.Buffer)
+		tw := tablewriter.New(
+			tablewriter.Col("Miner"),
+			tablewriter.Col("QAPower"),
+			tablewriter.Col("RawPower"),
+			tablewriter.Col("MinPower"),
+			tablewriter.Col("SectorSize"),
+			tablewriter.Col("Live"),
+			tablewriter.Col("Active"),
+			tablewriter.Col("Faulty"),
+			tablewriter.Col("Locked"),
+			tablewriter.Col("Balance"),
+			tablewriter.Col("PeerID"))
+		for _, m := range miners {
+			row := map[string]interface{}{
+				"Miner":      m.Address,
+				"QAPower":    types.SizeStr(m.Power.QualityAdjPower),
+				"RawPower":   types.SizeStr(m.Power.RawBytePower),
+				"MinPower":   m.HasMinPower,
+				"SectorSize": types.SizeStr(big.NewInt(int64(m.SectorSize))),
+				"Live":       m.Sectors.Live,
+				"Active":     m.Sectors.Active,
+				"Faulty":     m.Sectors.Faulty,
+				"Locked":     types.FIL(m.LockedFunds.TotalLockedFunds()),
+				"Balance":    types.FIL(m.Balance),
+			}
+			if m.PeerID != nil {
+				row["PeerID"] = m.PeerID.String()
+			}
+			tw.Write(row)
+		}
+		if err := tw.Flush(buf); err != nil {
+			return err
+		}
+
+		return re.Emit(buf)
+	},
+}
+
+// minerSummaryOrders are the orders supported by `state miners --sort`, largest first except for address.
+var minerSummaryOrders = map[string]func(a, b *apitypes.MinerSummary) bool{
+	"power": func(a, b *apitypes.MinerSummary) bool {
+		return a.Power.QualityAdjPower.GreaterThan(b.Power.QualityAdjPower)
+	},
+	"raw": func(a, b *apitypes.MinerSummary) bool {
+		return a.Power.RawBytePower.GreaterThan(b.Power.RawBytePower)
+	},
+	"sectors": func(a, b *apitypes.MinerSummary) bool {
+		return a.Sectors.Live > b.Sectors.Live
+	},
+	"faults": func(a, b *apitypes.MinerSummary) bool {
+		return a.Sectors.Faulty > b.Sectors.Faulty
+	},
+	"locked": func(a, b *apitypes.MinerSummary) bool {
+		return a.LockedFunds.TotalLockedFunds().GreaterThan(b.LockedFunds.TotalLockedFunds())
+	},
+	"address": func(a, b *apitypes.MinerSummary) bool {
+		return apitypes.LessAddress(a.Address, b.Address)
+	},
+}
+
+// sortMinerSummaries sorts the miners by less, the miners less considers equal are ordered by address.
+func sortMinerSummaries(miners []*apitypes.MinerSummary, less func(a, b *apitypes.MinerSummary) bool) {
+	sort.Slice(miners, func(i, j int) bool {
+		if less(miners[i], miners[j]) {
+			return true
+		}
+		if less(miners[j], miners[i]) {
+			return false
+		}
+		return apitypes.LessAddress(miners[i].Address, miners[j].Address)
+	})
+}
+
+// supplyColumns are the columns of the csv of `state supply`, the amounts are in FIL.
+var supplyColumns = []string{"height", "vested", "mined", "burnt", "locked", "circulating", "reserve_disbursed",
+	"total_pledge", "market_locked", "miner_fee_debt", "base_fee", "base_fee_burn", "over_estimation_burn"}
+
+var stateSupplyCmd = &cmds.Command{
+	Helptext: cmds.HelpText{
+		Tagline: "Print the breakdown of the FIL supply over a range of epochs as csv",
+		ShortDescription: `Prints the vested, mined, burnt, locked, circulating and reserve disbursed FIL, the
+total pledge, the market locked funds, the fee debt of the miners and the base fee burn
+every --step epochs from --from to --to. A null round is reported at the height of the
+tipset before it. Summing the fee debts walks every miner, so each line is slow.`,
+	},
+	Options: []cmds.Option{
+		cmds.Int64Option("from", "First epoch, defaults to --to").WithDefault(int64(-1)),
+		cmds.Int64Option("to", "Last epoch, defaults to the head").WithDefault(int64(-1)),
+		cmds.Int64Option("step", "Number of epochs between two lines").WithDefault(int64(1)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		ctx := req.Context
+		api := env.(*node.Env).ChainAPI
+
+		head, err := api.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+		from, _ := req.Options["from"].(int64)
+		to, _ := req.Options["to"].(int64)
+		step, _ := req.Options["step"].(int64)
+		if to < 0 {
+			to = int64(head.Height())
+		}
+		if from < 0 {
+			from = to
+		}
+		if step < 1 || from > to || to > int64(head.Height()) {
+			return xerrors.Errorf("expected 0 <= from <= to <= %d and step > 0", head.Height())
+		}
+
+		buf := new(bytes.Buffer)
+		w := csv.NewWriter(buf)
+		if err := w.Write(supplyColumns); err != nil {
+			return err
+		}
+		for h := from; h <= to; h += step {
+			ts, err := api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(h), head.Key())
+			if err != nil {
+				return xerrors.Errorf("loading tipset at %d: %v", h, err)
+			}
+			t, err := api.StateTokenomics(ctx, ts.Key())
+			if err != nil {
+				return xerrors.Errorf("getting tokenomics at %d: %v", ts.Height(), err)
+			}
+
+			row := []string{strconv.FormatInt(int64(t.Height), 10)}
+			for _, amount := range []abi.TokenAmount{t.FilVested, t.FilMined, t.FilBurnt, t.FilLocked, t.FilCirculating,
+				t.FilReserveDisbursed, t.TotalPledge, t.MarketLocked, t.MinerFeeDebt, t.BaseFee, t.BaseFeeBurn, t.OverEstimationBurn} {
+				row = append(row, types.FIL(amount).Unitless())
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+		return re.Emit(buf)
+	},
+}
+
 func makeActorView(act *types.Actor, addr address.Address) *ActorView {
 	return &ActorView{
 		Address: addr.String(),
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/app/submodule/apitypes"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/power"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestSortMinerSummaries(t *testing.T) {
+	tf.UnitTest(t)
+
+	miner := func(id uint64, qa int64) *apitypes.MinerSummary {
+		addr, err := address.NewIDAddress(id)
+		require.NoError(t, err)
+		return &apitypes.MinerSummary{
+			Address: addr,
+			Power:   power.Claim{QualityAdjPower: big.NewInt(qa), RawBytePower: big.Zero()},
+		}
+	}
+	addresses := func(miners []*apitypes.MinerSummary) []string {
+		var out []string
+		for _, m := range miners {
+			out = append(out, m.Address.String())
+		}
+		return out
+	}
+
+	miners := []*apitypes.MinerSummary{miner(1002, 1), miner(999, 5), miner(1000, 1), miner(10000, 5), miner(1001, 0)}
+
+	// ties are ordered by ID, whatever order the api returned them in
+	sortMinerSummaries(miners, minerSummaryOrders["power"])
+	assert.Equal(t, []string{"t0999", "t010000", "t01000", "t01002", "t01001"}, addresses(miners))
+
+	sortMinerSummaries(miners, minerSummaryOrders["address"])
+	assert.Equal(t, []string{"t0999", "t01000", "t01001", "t01002", "t010000"}, addresses(miners))
+
+	// every miner ties
+	miners[0], miners[4] = miners[4], miners[0]
+	sortMinerSummaries(miners, minerSummaryOrders["raw"])
+	assert.Equal(t, []string{"t0999", "t01000", "t01001", "t01002", "t010000"}, addresses(miners))
+}
+
+func TestLessAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	id, err := address.NewIDAddress(5)
+	require.NoError(t, err)
+	actor, err := address.NewActorAddress([]byte("miner"))
+	require.NoError(t, err)
+
+	assert.True(t, apitypes.LessAddress(id, actor))
+	assert.False(t, apitypes.LessAddress(actor, id))
+	assert.False(t, apitypes.LessAddress(id, id))
+}
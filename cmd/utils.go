@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/venus/app/node"
+	"github.com/filecoin-project/venus/pkg/types"
 )
 
 // SilentWriter writes to a stream, stopping after the first error and discarding output until
@@ -154,6 +157,18 @@ func cidsFromSlice(args []string) ([]cid.Cid, error) {
 	return out, nil
 }
 
+// parseTipSetKey parses a comma separated list of block CIDs.
+func parseTipSetKey(s string) (types.TipSetKey, error) {
+	if s == "" {
+		return types.EmptyTSK, nil
+	}
+	cids, err := cidsFromSlice(strings.Split(s, ","))
+	if err != nil {
+		return types.EmptyTSK, err
+	}
+	return types.NewTipSetKey(cids...), nil
+}
+
 func EpochTime(curr, e abi.ChainEpoch, blockDelay uint64) string {
 	switch {
 	case curr > e:
@@ -194,3 +209,22 @@ func ReqContext(cctx context.Context) context.Context {
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 	return ctx
 }
+
+// passwordFileOption is the option of the commands reading a password from a file instead of
+// prompting for it, a password given on the command line would be kept in the shell history
+// and seen by the other users of the machine.
+const passwordFileOption = "password-file"
+
+// readPasswordFile returns the password in the file of the --password-file option, without
+// the trailing newline, or nil when the option is not given.
+func readPasswordFile(req *cmds.Request) ([]byte, error) {
+	path, _ := req.Options[passwordFileOption].(string)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the password file")
+	}
+	return bytes.TrimRight(data, "\r\n"), nil
+}
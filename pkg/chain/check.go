@@ -0,0 +1,295 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/chainsync/exchange"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// Kinds of problems reported by the Checker.
+const (
+	CheckMissingBlock    = "missing-block"
+	CheckBrokenLink      = "broken-link"
+	CheckBadMetadata     = "bad-metadata"
+	CheckMissingState    = "missing-state"
+	CheckMissingMessages = "missing-messages"
+	CheckMissingReceipts = "missing-receipts"
+	CheckStateMismatch   = "state-mismatch"
+)
+
+// CheckOptions controls how deep the Checker verifies the chain.
+type CheckOptions struct {
+	// Height is the lowest epoch checked, the walk stops once it is reached.
+	Height abi.ChainEpoch
+	// ReExecute runs every tipset again and compares the resulting state
+	// and receipts roots with the stored ones. This is slow.
+	ReExecute bool
+	// Repair refetches missing blocks and messages from peers and rewrites
+	// broken tipset metadata with the state committed to by the child header.
+	Repair bool
+}
+
+// CheckProblem is a single inconsistency found in the local store.
+type CheckProblem struct {
+	Height   abi.ChainEpoch
+	TipSet   types.TipSetKey
+	Kind     string
+	Detail   string
+	Repaired bool
+}
+
+// CheckReport summarizes a walk of the Checker.
+type CheckReport struct {
+	From     abi.ChainEpoch
+	To       abi.ChainEpoch
+	Checked  int
+	Problems []CheckProblem
+}
+
+// StateTransitioner re-executes a tipset on top of its parent state, see
+// consensus.Expected.RunStateTransition.
+type StateTransitioner interface {
+	RunStateTransition(ctx context.Context, ts *types.TipSet, parentStateRoot cid.Cid) (root cid.Cid, receipt cid.Cid, err error)
+}
+
+// ChainFetcher retrieves chain data from the network, see exchange.Client.
+type ChainFetcher interface {
+	GetBlocks(ctx context.Context, tsk types.TipSetKey, count int) ([]*types.TipSet, error)
+	GetChainMessages(ctx context.Context, tipsets []*types.TipSet) ([]*exchange.CompactedMessages, error)
+}
+
+// Checker verifies that the chain kept in the local store is complete and
+// consistent: headers link to their parents, tipset metadata decodes and
+// agrees with the children headers, messages and receipts AMTs load and,
+// optionally, that re-executing a tipset produces the stored state root.
+type Checker struct {
+	store        *Store
+	messageStore *MessageStore
+	processor    StateTransitioner
+	fetcher      ChainFetcher
+}
+
+// NewChecker creates a Checker. The processor is only needed to re-execute
+// tipsets and the fetcher only to repair, either of them may be nil.
+func NewChecker(store *Store, messageStore *MessageStore, processor StateTransitioner, fetcher ChainFetcher) *Checker {
+	return &Checker{
+		store:        store,
+		messageStore: messageStore,
+		processor:    processor,
+		fetcher:      fetcher,
+	}
+}
+
+// Check walks the chain backwards from `from` down to `opts.Height` and
+// reports every problem found. An error is only returned if the walk can
+// not continue, the report collected so far is returned along with it.
+func (c *Checker) Check(ctx context.Context, from *types.TipSet, opts CheckOptions) (*CheckReport, error) {
+	if opts.ReExecute && c.processor == nil {
+		return nil, xerrors.New("re-execution requested without a state processor")
+	}
+	if opts.Repair && c.fetcher == nil {
+		return nil, xerrors.New("repair requested without a chain fetcher")
+	}
+
+	report := &CheckReport{From: from.Height(), To: from.Height()}
+	var child *types.TipSet
+	cur := from
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		c.checkTipSet(ctx, report, cur, child, opts)
+		report.Checked++
+		report.To = cur.Height()
+		if cur.Height() <= opts.Height || cur.Height() == 0 {
+			return report, nil
+		}
+
+		parent, err := c.loadParent(ctx, report, cur, opts)
+		if err != nil {
+			return report, xerrors.Errorf("unable to continue below height %d: %w", cur.Height(), err)
+		}
+		child, cur = cur, parent
+	}
+}
+
+func (c *Checker) report(report *CheckReport, ts *types.TipSet, kind string, repaired bool, format string, args ...interface{}) {
+	problem := CheckProblem{
+		Height:   ts.Height(),
+		TipSet:   ts.Key(),
+		Kind:     kind,
+		Detail:   fmt.Sprintf(format, args...),
+		Repaired: repaired,
+	}
+	log.Warnf("chain check: %s at %d (%s): %s, repaired: %t", kind, problem.Height, problem.TipSet, problem.Detail, repaired)
+	report.Problems = append(report.Problems, problem)
+}
+
+// loadParent loads the parent tipset of `ts` and checks that it links
+// correctly, refetching missing headers when repairing.
+func (c *Checker) loadParent(ctx context.Context, report *CheckReport, ts *types.TipSet, opts CheckOptions) (*types.TipSet, error) {
+	parent, err := c.store.GetTipSet(ts.Parents())
+	if err != nil {
+		if !opts.Repair {
+			c.report(report, ts, CheckMissingBlock, false, "loading parent tipset %s: %s", ts.Parents(), err)
+			return nil, err
+		}
+
+		parent, err = c.fetchTipSet(ctx, ts.Parents())
+		c.report(report, ts, CheckMissingBlock, err == nil, "loading parent tipset %s", ts.Parents())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !ts.IsChildOf(parent) || parent.Height() >= ts.Height() {
+		c.report(report, ts, CheckBrokenLink, false, "parent %s at height %d is not a valid parent", parent.Key(), parent.Height())
+		return nil, xerrors.Errorf("broken link to parent %s", parent.Key())
+	}
+	return parent, nil
+}
+
+func (c *Checker) fetchTipSet(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	tipsets, err := c.fetcher.GetBlocks(ctx, tsk, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(tipsets) == 0 || !tipsets[0].Key().Equals(tsk) {
+		return nil, xerrors.Errorf("peers did not return tipset %s", tsk)
+	}
+	for _, blk := range tipsets[0].Blocks() {
+		if _, err := c.store.PutObject(ctx, blk); err != nil {
+			return nil, err
+		}
+	}
+	return tipsets[0], nil
+}
+
+// checkTipSet verifies the messages, metadata, receipts and state of a single
+// tipset. `child` is the tipset built on top of `ts`, nil for the first one.
+func (c *Checker) checkTipSet(ctx context.Context, report *CheckReport, ts, child *types.TipSet, opts CheckOptions) {
+	if !c.checkMessages(ctx, ts) {
+		repaired := false
+		if opts.Repair {
+			if err := c.refetchMessages(ctx, ts); err != nil {
+				log.Warnf("chain check: refetching messages of %s failed: %s", ts.Key(), err)
+			} else {
+				repaired = true
+			}
+		}
+		c.report(report, ts, CheckMissingMessages, repaired, "block messages do not load")
+	}
+
+	meta, err := c.store.LoadTipsetMetadata(ts)
+	if err != nil {
+		c.report(report, ts, CheckBadMetadata, c.repairMetadata(ctx, ts, child, opts), "%s", err)
+		return
+	}
+
+	// The state computed by `ts` is the parent state of its children.
+	if child != nil && (child.At(0).ParentStateRoot != meta.TipSetStateRoot || child.At(0).ParentMessageReceipts != meta.TipSetReceipts) {
+		c.report(report, ts, CheckBadMetadata, c.repairMetadata(ctx, ts, child, opts),
+			"stored state %s/receipts %s differ from child header %s/%s",
+			meta.TipSetStateRoot, meta.TipSetReceipts, child.At(0).ParentStateRoot, child.At(0).ParentMessageReceipts)
+	}
+
+	if has, err := c.store.Blockstore().Has(meta.TipSetStateRoot); err != nil || !has {
+		c.report(report, ts, CheckMissingState, false, "state root %s not found in blockstore", meta.TipSetStateRoot)
+	}
+
+	if _, err := c.messageStore.LoadReceipts(ctx, meta.TipSetReceipts); err != nil {
+		c.report(report, ts, CheckMissingReceipts, false, "loading receipts %s: %s", meta.TipSetReceipts, err)
+	}
+
+	if opts.ReExecute && ts.Height() > 0 {
+		root, receipts, err := c.processor.RunStateTransition(ctx, ts, ts.At(0).ParentStateRoot)
+		switch {
+		case err != nil || !root.Defined():
+			c.report(report, ts, CheckStateMismatch, false, "re-execution failed: %v", err)
+		case child != nil && (root != child.At(0).ParentStateRoot || receipts != child.At(0).ParentMessageReceipts):
+			// The local execution disagrees with the chain, which is never
+			// rewritten after it.
+			c.report(report, ts, CheckStateMismatch, false, "re-execution computed state %s/receipts %s, child header has %s/%s",
+				root, receipts, child.At(0).ParentStateRoot, child.At(0).ParentMessageReceipts)
+		case root != meta.TipSetStateRoot || receipts != meta.TipSetReceipts:
+			// Only the metadata confirmed by the header of a child is repaired.
+			repaired := false
+			if opts.Repair && child != nil {
+				repaired = c.putMetadata(ctx, ts, root, receipts)
+			}
+			c.report(report, ts, CheckStateMismatch, repaired, "re-execution computed state %s/receipts %s, stored %s/%s",
+				root, receipts, meta.TipSetStateRoot, meta.TipSetReceipts)
+		}
+	}
+}
+
+func (c *Checker) checkMessages(ctx context.Context, ts *types.TipSet) bool {
+	for _, blk := range ts.Blocks() {
+		if _, _, err := c.messageStore.LoadMetaMessages(ctx, blk.Messages); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// refetchMessages requests the messages of `ts` from peers and stores them,
+// checking they match the message roots of the headers.
+func (c *Checker) refetchMessages(ctx context.Context, ts *types.TipSet) error {
+	msgs, err := c.fetcher.GetChainMessages(ctx, []*types.TipSet{ts})
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return xerrors.Errorf("peers returned no messages for %s", ts.Key())
+	}
+
+	for bi, blk := range ts.Blocks() {
+		var bmsgs []*types.UnsignedMessage
+		for _, mi := range msgs[0].BlsIncludes[bi] {
+			bmsgs = append(bmsgs, msgs[0].Bls[mi])
+		}
+		var smsgs []*types.SignedMessage
+		for _, mi := range msgs[0].SecpkIncludes[bi] {
+			smsgs = append(smsgs, msgs[0].Secpk[mi])
+		}
+
+		mcid, err := c.messageStore.StoreMessages(ctx, smsgs, bmsgs)
+		if err != nil {
+			return err
+		}
+		if mcid != blk.Messages {
+			return xerrors.Errorf("fetched messages root %s does not match block %s", mcid, blk.Cid())
+		}
+	}
+	return nil
+}
+
+// repairMetadata rebuilds the metadata of `ts` from the header of its child.
+// Without a child nothing confirms a re-executed state, so the metadata of
+// the tipset the check starts from is never rewritten.
+func (c *Checker) repairMetadata(ctx context.Context, ts, child *types.TipSet, opts CheckOptions) bool {
+	if !opts.Repair || child == nil {
+		return false
+	}
+	return c.putMetadata(ctx, ts, child.At(0).ParentStateRoot, child.At(0).ParentMessageReceipts)
+}
+
+func (c *Checker) putMetadata(ctx context.Context, ts *types.TipSet, root, receipts cid.Cid) bool {
+	err := c.store.PutTipSetMetadata(ctx, &TipSetMetadata{
+		TipSet:          ts,
+		TipSetStateRoot: root,
+		TipSetReceipts:  receipts,
+	})
+	if err != nil {
+		log.Warnf("chain check: writing metadata of %s failed: %s", ts.Key(), err)
+		return false
+	}
+	return true
+}
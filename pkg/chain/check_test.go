@@ -0,0 +1,144 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/chainsync/exchange"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+type offlineFetcher struct{}
+
+func (offlineFetcher) GetBlocks(context.Context, types.TipSetKey, int) ([]*types.TipSet, error) {
+	return nil, xerrors.New("offline")
+}
+
+func (offlineFetcher) GetChainMessages(context.Context, []*types.TipSet) ([]*exchange.CompactedMessages, error) {
+	return nil, xerrors.New("offline")
+}
+
+func hasProblem(report *chain.CheckReport, tsk types.TipSetKey, kind string) (found bool, repaired bool) {
+	for _, p := range report.Problems {
+		if p.TipSet.Equals(tsk) && p.Kind == kind {
+			return true, p.Repaired
+		}
+	}
+	return false, false
+}
+
+func TestCheckerRepairsMetadataFromChild(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	ts1 := builder.AppendOn(builder.Genesis(), 1)
+	ts2 := builder.AppendOn(ts1, 1)
+
+	store := builder.Store()
+	require.NoError(t, store.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+		TipSet:          ts2,
+		TipSetStateRoot: builder.StateForKey(ts2.Key()),
+		TipSetReceipts:  ts2.At(0).ParentMessageReceipts,
+	}))
+
+	checker := chain.NewChecker(store, builder.Mstore(), nil, offlineFetcher{})
+
+	// ts1 has no metadata at all.
+	report, err := checker.Check(ctx, ts2, chain.CheckOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, ts2.Height(), report.From)
+	assert.Equal(t, builder.Genesis().Height(), report.To)
+
+	found, repaired := hasProblem(report, ts1.Key(), chain.CheckBadMetadata)
+	assert.True(t, found)
+	assert.False(t, repaired)
+	found, _ = hasProblem(report, ts2.Key(), chain.CheckBadMetadata)
+	assert.False(t, found)
+
+	// The metadata of ts1 is rebuilt from the header of ts2.
+	report, err = checker.Check(ctx, ts2, chain.CheckOptions{Height: ts1.Height(), Repair: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	found, repaired = hasProblem(report, ts1.Key(), chain.CheckBadMetadata)
+	assert.True(t, found)
+	assert.True(t, repaired)
+
+	meta, err := store.LoadTipsetMetadata(ts1)
+	require.NoError(t, err)
+	assert.Equal(t, ts2.At(0).ParentStateRoot, meta.TipSetStateRoot)
+	assert.Equal(t, ts2.At(0).ParentMessageReceipts, meta.TipSetReceipts)
+
+	report, err = checker.Check(ctx, ts2, chain.CheckOptions{Height: ts1.Height()})
+	require.NoError(t, err)
+	found, _ = hasProblem(report, ts1.Key(), chain.CheckBadMetadata)
+	assert.False(t, found)
+}
+
+func TestCheckerRequiresProcessorToReExecute(t *testing.T) {
+	tf.UnitTest(t)
+
+	builder := chain.NewBuilder(t, address.Undef)
+	checker := chain.NewChecker(builder.Store(), builder.Mstore(), nil, nil)
+
+	_, err := checker.Check(context.Background(), builder.Genesis(), chain.CheckOptions{ReExecute: true})
+	assert.Error(t, err)
+	_, err = checker.Check(context.Background(), builder.Genesis(), chain.CheckOptions{Repair: true})
+	assert.Error(t, err)
+}
+
+type fixedProcessor struct {
+	root, receipts cid.Cid
+}
+
+func (p fixedProcessor) RunStateTransition(context.Context, *types.TipSet, cid.Cid) (cid.Cid, cid.Cid, error) {
+	return p.root, p.receipts, nil
+}
+
+func TestCheckerRepairsOnlyStateConfirmedByChild(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	ts1 := builder.AppendOn(builder.Genesis(), 1)
+	ts2 := builder.AppendOn(ts1, 1)
+	store := builder.Store()
+	for _, ts := range []*types.TipSet{ts1, ts2} {
+		require.NoError(t, store.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+			TipSet:          ts,
+			TipSetStateRoot: builder.StateForKey(ts.Key()),
+			TipSetReceipts:  ts.At(0).ParentMessageReceipts,
+		}))
+	}
+	stored, err := store.LoadTipsetMetadata(ts1)
+	require.NoError(t, err)
+
+	// a local execution disagreeing with the header of ts2 is not persisted
+	wrong := fixedProcessor{root: types.CidFromString(t, "wrong-root"), receipts: ts2.At(0).ParentMessageReceipts}
+	checker := chain.NewChecker(store, builder.Mstore(), wrong, offlineFetcher{})
+	report, err := checker.Check(ctx, ts2, chain.CheckOptions{Height: ts1.Height(), ReExecute: true, Repair: true})
+	require.NoError(t, err)
+	found, repaired := hasProblem(report, ts1.Key(), chain.CheckStateMismatch)
+	assert.True(t, found)
+	assert.False(t, repaired)
+	meta, err := store.LoadTipsetMetadata(ts1)
+	require.NoError(t, err)
+	assert.Equal(t, stored.TipSetStateRoot, meta.TipSetStateRoot)
+
+	// nor is the state of the tipset the check starts from, no child confirms it
+	found, repaired = hasProblem(report, ts2.Key(), chain.CheckStateMismatch)
+	assert.True(t, found)
+	assert.False(t, repaired)
+	meta, err = store.LoadTipsetMetadata(ts2)
+	require.NoError(t, err)
+	assert.Equal(t, builder.StateForKey(ts2.Key()), meta.TipSetStateRoot)
+}
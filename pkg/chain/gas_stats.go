@@ -0,0 +1,351 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	"github.com/filecoin-project/venus/pkg/state/tree"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
+)
+
+// MaxGasStatsEpochs is the largest range of epochs the gas statistics are collected over, a week.
+const MaxGasStatsEpochs = 7 * 2880
+
+// GasStatsTopConsumers is the number of senders in GasStats.TopConsumers.
+const GasStatsTopConsumers = 20
+
+// unknownActor names the receivers whose code is not known, such as the actors deleted since.
+const unknownActor = "unknown"
+
+// GasUsage sums the gas and the fees of a set of executed messages. The fees are paid by
+// the senders: the base fee burnt for the gas used and for the over estimated gas limit,
+// and the tip of the miners.
+type GasUsage struct {
+	Messages int
+	// Failed is the number of messages which did not exit successfully.
+	Failed             int
+	GasLimit           int64
+	GasUsed            int64
+	BaseFeeBurn        abi.TokenAmount
+	OverEstimationBurn abi.TokenAmount
+	MinerTip           abi.TokenAmount
+}
+
+// TotalFees returns the fees paid by the senders of the messages.
+func (u *GasUsage) TotalFees() abi.TokenAmount {
+	return big.Sum(u.BaseFeeBurn, u.OverEstimationBurn, u.MinerTip)
+}
+
+func (u *GasUsage) add(msg *types.UnsignedMessage, receipt *types.MessageReceipt, out *gas.GasOutputs) {
+	if u.BaseFeeBurn.Int == nil {
+		u.BaseFeeBurn, u.OverEstimationBurn, u.MinerTip = big.Zero(), big.Zero(), big.Zero()
+	}
+	u.Messages++
+	if receipt.ExitCode != exitcode.Ok {
+		u.Failed++
+	}
+	u.GasLimit += msg.GasLimit
+	u.GasUsed += receipt.GasUsed
+	u.BaseFeeBurn = big.Add(u.BaseFeeBurn, out.BaseFeeBurn)
+	u.OverEstimationBurn = big.Add(u.OverEstimationBurn, out.OverEstimationBurn)
+	u.MinerTip = big.Add(u.MinerTip, out.MinerTip)
+}
+
+// ActorGasUsage is the gas usage of the messages sent to a type of actor, or to a method of it.
+type ActorGasUsage struct {
+	// Actor is the type of the receivers, such as storageminer, whatever their actors version.
+	Actor  string
+	Method string `json:",omitempty"`
+	GasUsage
+}
+
+// SenderGasUsage is the gas usage of the messages of a sender.
+type SenderGasUsage struct {
+	Sender address.Address
+	GasUsage
+}
+
+// BaseFeeSample is the base fee paid by the messages of the tipset at Height.
+type BaseFeeSample struct {
+	Height  abi.ChainEpoch
+	BaseFee abi.TokenAmount
+}
+
+// GasStats aggregates the gas used and the fees paid by the messages of a range of tipsets.
+type GasStats struct {
+	// From and To are the heights of the oldest and newest tipsets counted.
+	From    abi.ChainEpoch
+	To      abi.ChainEpoch
+	Tipsets int
+	Blocks  int
+
+	// Total sums the messages of the range. The miners paid MinerPenalty for including
+	// messages which could not pay the base fee.
+	Total        GasUsage
+	MinerPenalty abi.TokenAmount
+	// BlockFullness is the average over the blocks of the gas limit of their messages over
+	// the block gas limit, the messages included by several blocks count in each.
+	BlockFullness float64
+
+	// BaseFee is the base fee of each tipset, oldest first.
+	BaseFee []BaseFeeSample
+
+	// ByActor and ByMethod break the usage down by type of receiver and by its methods, the
+	// most gas used first.
+	ByActor  []ActorGasUsage
+	ByMethod []ActorGasUsage
+	// TopConsumers are the senders which paid the most fees, the most first.
+	TopConsumers []SenderGasUsage
+}
+
+// GasStatsCollector aggregates the messages of tipsets into GasStats.
+type GasStatsCollector struct {
+	fork *config.ForkUpgradeConfig
+
+	stats    GasStats
+	fullness float64
+	byActor  map[string]*ActorGasUsage
+	byMethod map[string]*ActorGasUsage
+	bySender map[address.Address]*SenderGasUsage
+}
+
+// NewGasStatsCollector returns a collector for the chain with the upgrades of `fork`.
+func NewGasStatsCollector(fork *config.ForkUpgradeConfig) *GasStatsCollector {
+	return &GasStatsCollector{
+		fork:     fork,
+		stats:    GasStats{MinerPenalty: big.Zero()},
+		byActor:  map[string]*ActorGasUsage{},
+		byMethod: map[string]*ActorGasUsage{},
+		bySender: map[address.Address]*SenderGasUsage{},
+	}
+}
+
+// AddTipSet counts the messages of `ts`, as returned by MessageStore.MessagesForTipset, with
+// the `receipts` of their execution. `blockGasLimits` holds the gas limit of the messages of
+// each block of `ts`, and `actorCode` returns the code of a receiver, or cid.Undef when it is
+// not known.
+func (c *GasStatsCollector) AddTipSet(ts *types.TipSet, msgs []types.ChainMsg, receipts []types.MessageReceipt, blockGasLimits []int64, actorCode func(address.Address) (cid.Cid, error)) error {
+	if len(msgs) != len(receipts) {
+		return fmt.Errorf("got %d messages but %d receipts for tipset %s", len(msgs), len(receipts), ts.Key())
+	}
+
+	// the messages of a tipset are executed with the base fee in its own headers
+	baseFee := ts.Blocks()[0].ParentBaseFee
+	if c.stats.Tipsets == 0 || ts.Height() < c.stats.From {
+		c.stats.From = ts.Height()
+	}
+	if c.stats.Tipsets == 0 || ts.Height() > c.stats.To {
+		c.stats.To = ts.Height()
+	}
+	c.stats.Tipsets++
+	c.stats.Blocks += len(blockGasLimits)
+	c.stats.BaseFee = append(c.stats.BaseFee, BaseFeeSample{Height: ts.Height(), BaseFee: baseFee})
+	for _, limit := range blockGasLimits {
+		c.fullness += float64(limit) / float64(constants.BlockGasLimit)
+	}
+
+	for i, m := range msgs {
+		msg := m.VMMessage()
+		receipt := &receipts[i]
+		code, err := actorCode(msg.To)
+		if err != nil {
+			return errors.Wrapf(err, "failed to look up the actor %s", msg.To)
+		}
+
+		out := gas.ComputeGasOutputs(receipt.GasUsed, msg.GasLimit, baseFee, msg.GasFeeCap, msg.GasPremium, c.chargesBaseFee(ts.Height(), msg, code, receipt.ExitCode))
+		c.stats.Total.add(msg, receipt, &out)
+		c.stats.MinerPenalty = big.Add(c.stats.MinerPenalty, out.MinerPenalty)
+
+		actor, method := actorMethodName(code, msg.Method)
+		byActor, ok := c.byActor[actor]
+		if !ok {
+			byActor = &ActorGasUsage{Actor: actor}
+			c.byActor[actor] = byActor
+		}
+		byActor.add(msg, receipt, &out)
+
+		byMethod, ok := c.byMethod[actor+"."+method]
+		if !ok {
+			byMethod = &ActorGasUsage{Actor: actor, Method: method}
+			c.byMethod[actor+"."+method] = byMethod
+		}
+		byMethod.add(msg, receipt, &out)
+
+		bySender, ok := c.bySender[msg.From]
+		if !ok {
+			bySender = &SenderGasUsage{Sender: msg.From}
+			c.bySender[msg.From] = bySender
+		}
+		bySender.add(msg, receipt, &out)
+	}
+	return nil
+}
+
+// chargesBaseFee tells whether the base fee of a message is burnt, like the vm which did not
+// burn it for the successful window posts up to network version 12.
+func (c *GasStatsCollector) chargesBaseFee(height abi.ChainEpoch, msg *types.UnsignedMessage, code cid.Cid, exit exitcode.ExitCode) bool {
+	return !(height > c.fork.UpgradeClausHeight && height <= c.fork.UpgradeHyperdriveHeight &&
+		exit == exitcode.Ok && msg.Method == miner.Methods.SubmitWindowedPoSt && builtin.IsStorageMinerActor(code))
+}
+
+// Result returns the statistics of the tipsets added.
+func (c *GasStatsCollector) Result() *GasStats {
+	stats := c.stats
+	if stats.Total.BaseFeeBurn.Int == nil {
+		stats.Total.BaseFeeBurn, stats.Total.OverEstimationBurn, stats.Total.MinerTip = big.Zero(), big.Zero(), big.Zero()
+	}
+	if stats.Blocks > 0 {
+		stats.BlockFullness = c.fullness / float64(stats.Blocks)
+	}
+	sort.Slice(stats.BaseFee, func(i, j int) bool {
+		return stats.BaseFee[i].Height < stats.BaseFee[j].Height
+	})
+
+	stats.ByActor = sortActorGasUsage(c.byActor)
+	stats.ByMethod = sortActorGasUsage(c.byMethod)
+
+	stats.TopConsumers = make([]SenderGasUsage, 0, len(c.bySender))
+	for _, u := range c.bySender {
+		stats.TopConsumers = append(stats.TopConsumers, *u)
+	}
+	sort.Slice(stats.TopConsumers, func(i, j int) bool {
+		fi, fj := stats.TopConsumers[i].TotalFees(), stats.TopConsumers[j].TotalFees()
+		if !fi.Equals(fj) {
+			return fi.GreaterThan(fj)
+		}
+		return stats.TopConsumers[i].Sender.String() < stats.TopConsumers[j].Sender.String()
+	})
+	if len(stats.TopConsumers) > GasStatsTopConsumers {
+		stats.TopConsumers = stats.TopConsumers[:GasStatsTopConsumers]
+	}
+	return &stats
+}
+
+func sortActorGasUsage(usages map[string]*ActorGasUsage) []ActorGasUsage {
+	out := make([]ActorGasUsage, 0, len(usages))
+	for _, u := range usages {
+		out = append(out, *u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].GasUsed != out[j].GasUsed {
+			return out[i].GasUsed > out[j].GasUsed
+		}
+		return out[i].Actor+"."+out[i].Method < out[j].Actor+"."+out[j].Method
+	})
+	return out
+}
+
+// actorMethodName returns the type of the actor of `code` without its version, and the name
+// of its method `num`.
+func actorMethodName(code cid.Cid, num abi.MethodNum) (string, string) {
+	if !code.Defined() || !builtin.IsBuiltinActor(code) {
+		return unknownActor, fmt.Sprintf("%d", num)
+	}
+	actor := builtin.ActorNameByCode(code)
+	actor = actor[strings.LastIndexByte(actor, '/')+1:]
+	if meta, ok := MethodsMap[code][num]; ok {
+		return actor, meta.Name
+	}
+	return actor, fmt.Sprintf("%d", num)
+}
+
+// CollectGasStats collects the gas statistics of the messages of the tipsets from the height
+// `from` to `to` of the chain of `head`. The messages of a tipset are executed by its child,
+// so `to` must be below the height of `head`.
+func CollectGasStats(ctx context.Context, store *Store, ms *MessageStore, head *types.TipSet, from, to abi.ChainEpoch) (*GasStats, error) {
+	if from < 0 || from > to {
+		return nil, fmt.Errorf("invalid range of epochs %d to %d", from, to)
+	}
+	if to >= head.Height() {
+		return nil, fmt.Errorf("the messages up to epoch %d are executed, the head is at %d", head.Height()-1, head.Height())
+	}
+	if to-from+1 > MaxGasStatsEpochs {
+		return nil, fmt.Errorf("the range of epochs cannot exceed %d", MaxGasStatsEpochs)
+	}
+
+	// the first tipset after `to` holds the receipts of the messages of the tipset at `to`
+	child, err := store.GetTipSetByHeight(ctx, head, to+1, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the tipset at %d", to+1)
+	}
+
+	collector := NewGasStatsCollector(ms.fkCfg)
+	codes := map[address.Address]cid.Cid{}
+	for child.Height() > from {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ts, err := store.GetTipSet(child.Parents())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the parent of tipset %s", child.Key())
+		}
+		if ts.Height() < from {
+			break
+		}
+
+		msgs, err := ms.MessagesForTipset(ts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the messages of tipset %s", ts.Key())
+		}
+		receipts, err := ms.LoadReceipts(ctx, child.Blocks()[0].ParentMessageReceipts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the receipts of tipset %s", ts.Key())
+		}
+		limits := make([]int64, 0, ts.Len())
+		for _, blk := range ts.Blocks() {
+			secp, bls, err := ms.LoadMetaMessages(ctx, blk.Messages)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to load the messages of block %s", blk.Cid())
+			}
+			var limit int64
+			for _, msg := range secp {
+				limit += msg.Message.GasLimit
+			}
+			for _, msg := range bls {
+				limit += msg.GasLimit
+			}
+			limits = append(limits, limit)
+		}
+
+		// the receivers are looked up in the state the messages were executed into, where the
+		// actors they created exist
+		st, err := tree.LoadState(ctx, store.stateAndBlockSource, child.Blocks()[0].ParentStateRoot)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the state of tipset %s", child.Key())
+		}
+		actorCode := func(addr address.Address) (cid.Cid, error) {
+			if code, ok := codes[addr]; ok {
+				return code, nil
+			}
+			act, found, err := st.GetActor(ctx, addr)
+			if err != nil {
+				return cid.Undef, err
+			}
+			if !found {
+				return cid.Undef, nil
+			}
+			codes[addr] = act.Code
+			return act.Code, nil
+		}
+
+		if err := collector.AddTipSet(ts, msgs, receipts, limits, actorCode); err != nil {
+			return nil, err
+		}
+		child = ts
+	}
+	return collector.Result(), nil
+}
@@ -0,0 +1,145 @@
+package chain_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	builtin5 "github.com/filecoin-project/specs-actors/v5/actors/builtin"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/crypto"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
+)
+
+func TestGasStatsCollector(t *testing.T) {
+	tf.UnitTest(t)
+
+	minerAddr := types.RequireIDAddress(t, 1000)
+	accountAddr := types.RequireIDAddress(t, 1001)
+	goneAddr := types.RequireIDAddress(t, 1002)
+	worker := types.RequireIDAddress(t, 2000)
+	client := types.RequireIDAddress(t, 2001)
+	codes := map[address.Address]cid.Cid{
+		minerAddr:   builtin5.StorageMinerActorCodeID,
+		accountAddr: builtin5.AccountActorCodeID,
+	}
+	actorCode := func(addr address.Address) (cid.Cid, error) {
+		return codes[addr], nil
+	}
+
+	post := func(height abi.ChainEpoch) *types.UnsignedMessage {
+		return &types.UnsignedMessage{From: worker, To: minerAddr, Method: miner.Methods.SubmitWindowedPoSt,
+			GasLimit: 1000, GasFeeCap: abi.NewTokenAmount(200), GasPremium: abi.NewTokenAmount(10), Nonce: uint64(height)}
+	}
+	send := &types.UnsignedMessage{From: client, To: accountAddr, Method: 0,
+		GasLimit: 1000, GasFeeCap: abi.NewTokenAmount(200), GasPremium: abi.NewTokenAmount(50)}
+	failed := &types.UnsignedMessage{From: worker, To: goneAddr, Method: 7,
+		GasLimit: 2000, GasFeeCap: abi.NewTokenAmount(200), GasPremium: abi.NewTokenAmount(10)}
+	ok := func(gasUsed int64) types.MessageReceipt {
+		return types.MessageReceipt{ExitCode: exitcode.Ok, GasUsed: gasUsed}
+	}
+
+	fork := &config.ForkUpgradeConfig{UpgradeClausHeight: 10, UpgradeHyperdriveHeight: 20}
+	collector := chain.NewGasStatsCollector(fork)
+
+	// the base fee of the window posts is not burnt between clause and hyperdrive
+	ts1 := mkGasStatsTipSet(t, 15, abi.NewTokenAmount(100), 2)
+	require.NoError(t, collector.AddTipSet(ts1,
+		[]types.ChainMsg{post(15), send, failed},
+		[]types.MessageReceipt{ok(800), ok(500), {ExitCode: exitcode.SysErrForbidden, GasUsed: 300}},
+		[]int64{2000, 3000}, actorCode))
+	ts2 := mkGasStatsTipSet(t, 25, abi.NewTokenAmount(120), 1)
+	require.NoError(t, collector.AddTipSet(ts2, []types.ChainMsg{post(25)}, []types.MessageReceipt{ok(800)}, []int64{1000}, actorCode))
+
+	assert.Error(t, collector.AddTipSet(ts2, []types.ChainMsg{post(25)}, nil, []int64{1000}, actorCode))
+
+	stats := collector.Result()
+	assert.Equal(t, abi.ChainEpoch(15), stats.From)
+	assert.Equal(t, abi.ChainEpoch(25), stats.To)
+	assert.Equal(t, 2, stats.Tipsets)
+	assert.Equal(t, 3, stats.Blocks)
+	assert.InDelta(t, 6000.0/3/float64(constants.BlockGasLimit), stats.BlockFullness, 1e-15)
+	assert.Equal(t, []chain.BaseFeeSample{
+		{Height: 15, BaseFee: abi.NewTokenAmount(100)},
+		{Height: 25, BaseFee: abi.NewTokenAmount(120)},
+	}, stats.BaseFee)
+
+	postBefore := gas.ComputeGasOutputs(800, 1000, abi.NewTokenAmount(100), abi.NewTokenAmount(200), abi.NewTokenAmount(10), false)
+	postAfter := gas.ComputeGasOutputs(800, 1000, abi.NewTokenAmount(120), abi.NewTokenAmount(200), abi.NewTokenAmount(10), true)
+	sendOut := gas.ComputeGasOutputs(500, 1000, abi.NewTokenAmount(100), abi.NewTokenAmount(200), abi.NewTokenAmount(50), true)
+	failedOut := gas.ComputeGasOutputs(300, 2000, abi.NewTokenAmount(100), abi.NewTokenAmount(200), abi.NewTokenAmount(10), true)
+	assert.True(t, postBefore.BaseFeeBurn.IsZero())
+	assert.Equal(t, big.NewInt(800*120), postAfter.BaseFeeBurn)
+
+	assert.Equal(t, 4, stats.Total.Messages)
+	assert.Equal(t, 1, stats.Total.Failed)
+	assert.Equal(t, int64(5000), stats.Total.GasLimit)
+	assert.Equal(t, int64(2400), stats.Total.GasUsed)
+	assert.Equal(t, big.Sum(postBefore.BaseFeeBurn, postAfter.BaseFeeBurn, sendOut.BaseFeeBurn, failedOut.BaseFeeBurn), stats.Total.BaseFeeBurn)
+	assert.Equal(t, big.Sum(postBefore.OverEstimationBurn, postAfter.OverEstimationBurn, sendOut.OverEstimationBurn, failedOut.OverEstimationBurn), stats.Total.OverEstimationBurn)
+	assert.Equal(t, big.Sum(postBefore.MinerTip, postAfter.MinerTip, sendOut.MinerTip, failedOut.MinerTip), stats.Total.MinerTip)
+
+	actors := map[string]int64{}
+	for _, u := range stats.ByActor {
+		actors[u.Actor] = u.GasUsed
+	}
+	assert.Equal(t, map[string]int64{"storageminer": 1600, "account": 500, "unknown": 300}, actors)
+	assert.Equal(t, "storageminer", stats.ByActor[0].Actor)
+
+	require.Len(t, stats.ByMethod, 3)
+	assert.Equal(t, "SubmitWindowedPoSt", stats.ByMethod[0].Method)
+	assert.Equal(t, 2, stats.ByMethod[0].Messages)
+	assert.Equal(t, big.Add(postBefore.BaseFeeBurn, postAfter.BaseFeeBurn), stats.ByMethod[0].BaseFeeBurn)
+	assert.Equal(t, "Send", stats.ByMethod[1].Method)
+	assert.Equal(t, "unknown", stats.ByMethod[2].Actor)
+	assert.Equal(t, "7", stats.ByMethod[2].Method)
+	assert.Equal(t, 1, stats.ByMethod[2].Failed)
+
+	require.Len(t, stats.TopConsumers, 2)
+	assert.Equal(t, worker, stats.TopConsumers[0].Sender)
+	assert.Equal(t, 3, stats.TopConsumers[0].Messages)
+	assert.Equal(t, client, stats.TopConsumers[1].Sender)
+	assert.Equal(t, big.Sum(sendOut.BaseFeeBurn, sendOut.OverEstimationBurn, sendOut.MinerTip), stats.TopConsumers[1].TotalFees())
+}
+
+func TestGasStatsCollectorEmpty(t *testing.T) {
+	tf.UnitTest(t)
+
+	stats := chain.NewGasStatsCollector(&config.ForkUpgradeConfig{}).Result()
+	assert.Equal(t, 0, stats.Tipsets)
+	assert.Equal(t, big.Zero(), stats.Total.TotalFees())
+	assert.Empty(t, stats.ByActor)
+	assert.Empty(t, stats.TopConsumers)
+}
+
+func mkGasStatsTipSet(t *testing.T, height abi.ChainEpoch, baseFee abi.TokenAmount, blocks int) *types.TipSet {
+	c := types.CidFromString(t, "gas-stats")
+	var blks []*types.BlockHeader
+	for i := 0; i < blocks; i++ {
+		blks = append(blks, &types.BlockHeader{
+			Miner:                 types.RequireIDAddress(t, 3000+i),
+			Ticket:                types.Ticket{VRFProof: []byte{byte(i)}},
+			ElectionProof:         &types.ElectionProof{VRFProof: []byte{byte(i)}},
+			Height:                height,
+			ParentWeight:          big.Zero(),
+			ParentStateRoot:       c,
+			ParentMessageReceipts: c,
+			Messages:              c,
+			ParentBaseFee:         baseFee,
+			BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+			BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+		})
+	}
+	return types.RequireNewTipSet(t, blks...)
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// ReadGenesisCid queries the provided datastore for the cid written at GenesisKey.
+func ReadGenesisCid(ds datastore.Datastore) (cid.Cid, error) {
+	bb, err := ds.Get(GenesisKey)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to read genesisKey")
+	}
+
+	var c cid.Cid
+	err = json.Unmarshal(bb, &c)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to cast genesisCid")
+	}
+	return c, nil
+}
+
+// ResetHeadToGenesis sets the head of the chain of `r` back to its genesis, the chain is then
+// synced again from the genesis. A restored repo holds the genesis but not the blocks of the
+// head it was backed up with, which the Store fails to load.
+func ResetHeadToGenesis(r repo.Repo) error {
+	genCid, err := ReadGenesisCid(r.ChainDatastore())
+	if err != nil {
+		return err
+	}
+	if has, err := r.Datastore().Has(genCid); err != nil {
+		return err
+	} else if !has {
+		return errors.Errorf("the genesis block %s is not in the blockstore", genCid)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := types.NewTipSetKey(genCid).MarshalCBOR(buf); err != nil {
+		return err
+	}
+	return r.ChainDatastore().Put(HeadKey, buf.Bytes())
+}
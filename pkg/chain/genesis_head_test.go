@@ -0,0 +1,58 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/chain"
+	"github.com/filecoin-project/venus/pkg/repo"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestRestoredChainLoads(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	genTS := builder.Genesis()
+	link1 := builder.AppendOn(genTS, 2)
+	link2 := builder.AppendOn(link1, 3)
+
+	mr := repo.NewInMemoryRepo()
+	genBytes, err := json.Marshal(genTS.At(0).Cid())
+	require.NoError(t, err)
+	require.NoError(t, mr.ChainDatastore().Put(chain.GenesisKey, genBytes))
+	cs := newChainStore(mr, genTS)
+	requirePutTestChain(ctx, t, cs, link2.Key(), builder, 3)
+	assertSetHead(t, cs, link2)
+	cs.Stop()
+
+	// only the blocks of the genesis are backed up
+	var buf bytes.Buffer
+	_, err = repo.Backup(ctx, mr, &buf, nil, []cid.Cid{genTS.At(0).Cid()})
+	require.NoError(t, err)
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	_, err = repo.Restore(ctx, &buf, repoPath, nil)
+	require.NoError(t, err)
+
+	r, err := repo.OpenFSRepo(repoPath, repo.LatestVersion)
+	require.NoError(t, err)
+	defer r.Close() // nolint: errcheck
+
+	// the blocks of the former head are not in the restored repo
+	err = newChainStore(r, genTS).Load(ctx)
+	assert.Contains(t, err.Error(), "error loading head tipset")
+
+	require.NoError(t, chain.ResetHeadToGenesis(r))
+	restored := newChainStore(r, genTS)
+	require.NoError(t, restored.Load(ctx))
+	assert.Equal(t, genTS.Key(), restored.GetHead().Key())
+}
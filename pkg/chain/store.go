@@ -29,6 +29,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/metrics/tracing"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/specactors/adt"
@@ -145,6 +146,24 @@ type Store struct {
 	reorgNotifeeCh chan ReorgNotifee
 
 	tsCache *lru.ARCCache
+
+	journal  journal.Journal
+	evtTypes [2]journal.EventType
+}
+
+const (
+	evtTypeHeadChange = iota
+	evtTypeReorg
+)
+
+// HeadChangeEvt is the journal event of a change of the head of the chain.
+type HeadChangeEvt struct {
+	From        types.TipSetKey
+	FromHeight  abi.ChainEpoch
+	To          types.TipSetKey
+	ToHeight    abi.ChainEpoch
+	RevertCount int
+	ApplyCount  int
 }
 
 // NewStore constructs a new default store.
@@ -153,7 +172,12 @@ func NewStore(ds repo.Datastore,
 	bsstore blockstore.Blockstore,
 	forkConfig *config.ForkUpgradeConfig,
 	genesisCid cid.Cid,
+	j journal.Journal,
 ) *Store {
+	if j == nil {
+		j = journal.NilJournal()
+	}
+
 	tsCache, _ := lru.NewARC(10000)
 	store := &Store{
 		stateAndBlockSource: cst,
@@ -165,6 +189,11 @@ func NewStore(ds repo.Datastore,
 		genesis:        genesisCid,
 		reorgNotifeeCh: make(chan ReorgNotifee),
 		tsCache:        tsCache,
+		journal:        j,
+		evtTypes: [...]journal.EventType{
+			evtTypeHeadChange: j.RegisterEventType("chain", "head_change"),
+			evtTypeReorg:      j.RegisterEventType("chain", "reorg"),
+		},
 	}
 	//todo cycle reference , may think a better idea
 	store.tipIndex = NewTipStateCache(store)
@@ -590,6 +619,8 @@ func (store *Store) reorgWorker(ctx context.Context) chan reorg {
 				notifees = append(notifees, n)
 
 			case r := <-out:
+				store.journalReorg(r)
+
 				var toremove map[int]struct{}
 				for i, hcf := range notifees {
 					err := hcf(r.old, r.new)
@@ -628,6 +659,34 @@ func (store *Store) reorgWorker(ctx context.Context) chan reorg {
 	return out
 }
 
+// journalReorg records the head change, and the reorg if tipsets were reverted.
+func (store *Store) journalReorg(r reorg) {
+	if len(r.new) == 0 {
+		return
+	}
+	supplier := func() interface{} {
+		from, to := r.new[0], r.new[0]
+		if len(r.old) > 0 {
+			from = r.old[0]
+		} else if parent, err := store.GetTipSet(to.Parents()); err == nil {
+			from = parent
+		}
+		return HeadChangeEvt{
+			From:        from.Key(),
+			FromHeight:  from.Height(),
+			To:          to.Key(),
+			ToHeight:    to.Height(),
+			RevertCount: len(r.old),
+			ApplyCount:  len(r.new),
+		}
+	}
+
+	store.journal.RecordEvent(store.evtTypes[evtTypeHeadChange], supplier)
+	if len(r.old) > 0 {
+		store.journal.RecordEvent(store.evtTypes[evtTypeReorg], supplier)
+	}
+}
+
 // SubHeadChanges returns channel with chain head updates.
 // First message is guaranteed to be of len == 1, and type == 'current'.
 // Then event in the message may be HCApply and HCRevert.
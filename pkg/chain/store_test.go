@@ -40,7 +40,7 @@ func newChainStore(r repo.Repo, genTS *types.TipSet) *CborBlockStore {
 	tempBlock := r.Datastore()
 	cborStore := cbor.NewCborStore(tempBlock)
 	return &CborBlockStore{
-		Store:     chain.NewStore(r.ChainDatastore(), cborStore, tempBlock, config.DefaultForkUpgradeParam, genTS.At(0).Cid()),
+		Store:     chain.NewStore(r.ChainDatastore(), cborStore, tempBlock, config.DefaultForkUpgradeParam, genTS.At(0).Cid(), nil),
 		cborStore: cborStore,
 	}
 }
@@ -304,9 +304,9 @@ func TestHead(t *testing.T) {
 	r := builder.Repo()
 	bs := builder.BlockStore()
 	cborStore := builder.Cstore()
-	cs := chain.NewStore(r.ChainDatastore(), cborStore, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid())
+	cs := chain.NewStore(r.ChainDatastore(), cborStore, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid(), nil)
 	cboreStore := &CborBlockStore{
-		Store: chain.NewStore(r.ChainDatastore(), cborStore, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid()),
+		Store: chain.NewStore(r.ChainDatastore(), cborStore, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid(), nil),
 	}
 	// Construct test chain data
 	link1 := builder.AppendOn(genTS, 2)
@@ -417,7 +417,7 @@ func TestLoadAndReboot(t *testing.T) {
 	requirePutBlocksToCborStore(t, cst, link4.ToSlice()...)
 
 	cboreStore := &CborBlockStore{
-		Store:     chain.NewStore(ds, cst, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid()),
+		Store:     chain.NewStore(ds, cst, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid(), nil),
 		cborStore: cst,
 	}
 	requirePutTestChain(ctx, t, cboreStore, link4.Key(), builder, 5)
@@ -427,7 +427,7 @@ func TestLoadAndReboot(t *testing.T) {
 	cboreStore.Stop()
 
 	// rebuild chain with same datastore and cborstore
-	rebootChain := chain.NewStore(ds, cst, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid())
+	rebootChain := chain.NewStore(ds, cst, bs, config.DefaultForkUpgradeParam, genTS.At(0).Cid(), nil)
 	rebootCbore := &CborBlockStore{
 		Store: rebootChain,
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/chainsync/syncer"
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/journal"
 )
 
 // BlockProposer allows callers to propose new blocks for inclusion in the chain.
@@ -42,13 +43,14 @@ func NewManager(fv syncer.StateProcessor,
 	bsstore blockstore.Blockstore,
 	exchangeClient exchange.Client,
 	c clock.Clock,
-	fork fork.IFork) (Manager, error) {
-	syncer, err := syncer.NewSyncer(fv, hv, cs, s, m, bsstore, exchangeClient, c, fork)
+	fork fork.IFork,
+	j journal.Journal) (Manager, error) {
+	syncer, err := syncer.NewSyncer(fv, hv, cs, s, m, bsstore, exchangeClient, c, fork, j)
 	if err != nil {
 		return Manager{}, err
 	}
 
-	dispatcher := dispatcher.NewDispatcher(syncer)
+	dispatcher := dispatcher.NewDispatcher(syncer, j)
 
 	return Manager{
 		syncer:     syncer,
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/filecoin-project/venus/pkg/chainsync/types"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/streadway/handy/atomic"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	logging "github.com/ipfs/go-log/v2"
 )
 
@@ -29,12 +31,15 @@ type dispatchSyncer interface {
 }
 
 // NewDispatcher creates a new syncing dispatcher with default queue sizes.
-func NewDispatcher(catchupSyncer dispatchSyncer) *Dispatcher {
-	return NewDispatcherWithSizes(catchupSyncer, DefaultWorkQueueSize, DefaultInQueueSize)
+func NewDispatcher(catchupSyncer dispatchSyncer, j journal.Journal) *Dispatcher {
+	return NewDispatcherWithSizes(catchupSyncer, DefaultWorkQueueSize, DefaultInQueueSize, j)
 }
 
 // NewDispatcherWithSizes creates a new syncing dispatcher.
-func NewDispatcherWithSizes(syncer dispatchSyncer, workQueueSize, inQueueSize int) *Dispatcher {
+func NewDispatcherWithSizes(syncer dispatchSyncer, workQueueSize, inQueueSize int, j journal.Journal) *Dispatcher {
+	if j == nil {
+		j = journal.NilJournal()
+	}
 	return &Dispatcher{
 		workTracker:     types.NewTargetTracker(workQueueSize),
 		syncer:          syncer,
@@ -43,9 +48,19 @@ func NewDispatcherWithSizes(syncer dispatchSyncer, workQueueSize, inQueueSize in
 		registeredCb:    func(t *types.Target, err error) {},
 		cancelControler: list.New(),
 		maxCount:        1,
+		journal:         j,
+		targetEvtType:   j.RegisterEventType("sync", "target"),
 	}
 }
 
+// SyncTargetEvt is the journal event of a finished target sync.
+type SyncTargetEvt struct {
+	Head     types2.TipSetKey
+	Height   abi.ChainEpoch
+	Duration time.Duration
+	Error    string
+}
+
 // cbMessage registers a user callback to be fired following every successful
 // sync.
 type cbMessage struct {
@@ -83,6 +98,9 @@ type Dispatcher struct {
 	lk              sync.Mutex
 	conCurrent      atomic.Int
 	maxCount        int64
+
+	journal       journal.Journal
+	targetEvtType journal.EventType
 }
 
 // SendOwnBlock handles chain info from a node's own mining system
@@ -194,11 +212,23 @@ func (d *Dispatcher) syncWorker(ctx context.Context) {
 						d.conCurrent.Add(1)
 
 						go func() {
+							start := time.Now()
 							err := d.syncer.HandleNewTipSet(ctx, syncTarget)
 							d.workTracker.Remove(syncTarget)
 							if err != nil {
 								log.Infof("failed sync of %v at %d  %s", syncTarget.Head.Key(), syncTarget.Head.Height(), err)
 							}
+							d.journal.RecordEvent(d.targetEvtType, func() interface{} {
+								evt := SyncTargetEvt{
+									Head:     syncTarget.Head.Key(),
+									Height:   syncTarget.Head.Height(),
+									Duration: time.Since(start),
+								}
+								if err != nil {
+									evt.Error = err.Error()
+								}
+								return evt
+							})
 							d.registeredCb(syncTarget, err)
 							d.conCurrent.Add(-1)
 						}()
@@ -36,7 +36,7 @@ func TestDispatchStartHappy(t *testing.T) {
 	s := &mockSyncer{
 		headsCalled: make([]*types.TipSet, 0),
 	}
-	testDispatch := dispatcher.NewDispatcher(s)
+	testDispatch := dispatcher.NewDispatcher(s, nil)
 
 	cis := []*types.ChainInfo{
 		// We need to put these in priority order to avoid a race.
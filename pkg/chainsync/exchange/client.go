@@ -10,17 +10,23 @@ import (
 	"time"
 
 	cborutil "github.com/filecoin-project/go-cbor-util"
+	blockadt "github.com/filecoin-project/specs-actors/actors/util/adt"
 	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
 	logging "github.com/ipfs/go-log"
 
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"go.opencensus.io/trace"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/venus/pkg/net"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
 )
 
 var exchangeClientLogger = logging.Logger("exchange.client")
@@ -103,8 +109,8 @@ func (c *client) doRequest(
 
 	// Try the request for each peer in the list,
 	// return on the first successful response.
-	// FIXME: Doing this serially isn't great, but fetching in parallel
-	//  may not be a good idea either. Think about this more.
+	// Large message requests are split and spread over several peers
+	// by `getChainMessagesParallel` before reaching this point.
 	globalTime := time.Now()
 	// Global time used to track what is the expected time we will need to get
 	// a response if a client fails us.
@@ -115,6 +121,10 @@ func (c *client) doRequest(
 		default:
 		}
 
+		if c.peerTracker.isBanned(peer) {
+			continue
+		}
+
 		// Send request, read response.
 		res, err := c.sendRequestToPeer(ctx, peer, req)
 		if err != nil {
@@ -126,7 +136,7 @@ func (c *client) doRequest(
 		}
 
 		// Process and validate response.
-		validRes, err := c.processResponse(req, res, tipsets)
+		validRes, err := c.processResponse(peer, req, res, tipsets)
 		if err != nil {
 			exchangeClientLogger.Warnf("processing peer %s response failed: %s", peer.String(), err)
 			continue
@@ -146,15 +156,20 @@ func (c *client) doRequest(
 // need.
 //
 // We are conflating in the single error returned both status and validation
-// errors. Peer penalization should happen here then, before returning, so
-// we can apply the correct penalties depending on the cause of the error.
-// FIXME: Add the `peer` as argument once we implement penalties.
-func (c *client) processResponse(req *Request, res *Response, tipsets []*types.TipSet) (*validatedResponse, error) {
-	err := res.statusToError()
+// errors. Status errors are not penalized, but a response that fails
+// validation scores the peer down (and eventually bans it) in the tracker.
+func (c *client) processResponse(peer peer.ID, req *Request, res *Response, tipsets []*types.TipSet) (_ *validatedResponse, err error) {
+	err = res.statusToError()
 	if err != nil {
 		return nil, xerrors.Errorf("status error: %s", err)
 	}
 
+	defer func() {
+		if err != nil {
+			c.peerTracker.logInvalidResponse(peer)
+		}
+	}()
+
 	options := parseOptions(req.Options)
 	if options.noOptionsSet() {
 		// Safety check: this shouldn't have been sent, and even if it did
@@ -229,6 +244,9 @@ func (c *client) processResponse(req *Request, res *Response, tipsets []*types.T
 			if err != nil {
 				return nil, err
 			}
+			if err := validateMessageRoots(res.Chain); err != nil {
+				return nil, err
+			}
 		} else {
 			// If we didn't request the headers they should have been provided
 			// by the caller.
@@ -248,12 +266,70 @@ func (c *client) processResponse(req *Request, res *Response, tipsets []*types.T
 			if err != nil {
 				return nil, err
 			}
+			if err := validateMessageRoots(chain); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return validRes, nil
 }
 
+// validateMessageRoots checks that the messages included by each block hash to
+// the `Messages` root of its header, valid indices alone do not prevent a peer
+// from returning other messages. The indices must have been validated first.
+func validateMessageRoots(chain []*BSTipSet) error {
+	for tipsetIdx, bst := range chain {
+		for blockIdx, blk := range bst.Blocks {
+			root, err := computeMsgMeta(bst.Messages, blockIdx)
+			if err != nil {
+				return xerrors.Errorf("computing the message root of block %s: %w", blk.Cid(), err)
+			}
+			if root != blk.Messages {
+				return xerrors.Errorf("messages of block %s at height (head - %d) do not match its header (%s != %s)",
+					blk.Cid(), tipsetIdx, root, blk.Messages)
+			}
+		}
+	}
+	return nil
+}
+
+// computeMsgMeta returns the root of the messages included by the block at
+// `blockIdx`, see chain.ComputeMsgMeta.
+func computeMsgMeta(msgs *CompactedMessages, blockIdx int) (cid.Cid, error) {
+	// block headers use adt0
+	store := blockadt.WrapStore(context.TODO(), cbor.NewCborStore(blockstoreutil.NewTemporary()))
+	bmArr := blockadt.MakeEmptyArray(store)
+	smArr := blockadt.MakeEmptyArray(store)
+
+	for i, mi := range msgs.BlsIncludes[blockIdx] {
+		c := cbg.CborCid(msgs.Bls[mi].Cid())
+		if err := bmArr.Set(uint64(i), &c); err != nil {
+			return cid.Undef, err
+		}
+	}
+	for i, mi := range msgs.SecpkIncludes[blockIdx] {
+		c := cbg.CborCid(msgs.Secpk[mi].Cid())
+		if err := smArr.Set(uint64(i), &c); err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	bmroot, err := bmArr.Root()
+	if err != nil {
+		return cid.Undef, err
+	}
+	smroot, err := smArr.Root()
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return store.Put(store.Context(), &types.TxMeta{
+		BLSRoot:  bmroot,
+		SecpRoot: smroot,
+	})
+}
+
 func (c *client) validateCompressedIndices(chain []*BSTipSet) error {
 	resLength := len(chain)
 	for tipsetIdx := 0; tipsetIdx < resLength; tipsetIdx++ {
@@ -307,7 +383,7 @@ func (c *client) GetBlocks(ctx context.Context, tsk types.TipSetKey, count int)
 		Options: Headers,
 	}
 
-	validRes, err := c.doRequest(ctx, req, nil, nil)
+	validRes, err := c.raceRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -315,6 +391,73 @@ func (c *client) GetBlocks(ctx context.Context, tsk types.TipSetKey, count int)
 	return validRes.tipsets, nil
 }
 
+// raceRequest sends a header request to `HeaderRacePeers` of the best peers
+// at the same time and returns the first valid response, cancelling the
+// others. A header request cannot be split like a message request, the head
+// of each window being only known once the previous one arrived, so it is
+// raced instead: a slow or lying peer does not stall the sync, and the
+// remaining peers are tried as the ones in flight fail.
+func (c *client) raceRequest(ctx context.Context, req *Request) (*validatedResponse, error) {
+	var peers []peer.ID
+	for _, p := range c.getShuffledPeers() {
+		if !c.peerTracker.isBanned(p) {
+			peers = append(peers, p)
+		}
+	}
+	if len(peers) == 0 {
+		return nil, xerrors.Errorf("no peers available")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		peer peer.ID
+		res  *validatedResponse
+		err  error
+	}
+	results := make(chan result, len(peers))
+	send := func(p peer.ID) {
+		res, err := c.sendRequestToPeer(ctx, p, req)
+		if err != nil {
+			results <- result{peer: p, err: err}
+			return
+		}
+		validRes, err := c.processResponse(p, req, res, nil)
+		results <- result{peer: p, res: validRes, err: err}
+	}
+
+	globalTime := time.Now()
+	next, inFlight := 0, 0
+	for ; next < len(peers) && next < HeaderRacePeers; next++ {
+		go send(peers[next])
+		inFlight++
+	}
+	for inFlight > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, xerrors.Errorf("context cancelled: %w", ctx.Err())
+		case r := <-results:
+			inFlight--
+			if r.err == nil {
+				c.peerTracker.logGlobalSuccess(time.Since(globalTime))
+				c.host.ConnManager().TagPeer(r.peer, "bsync", SuccessPeerTagValue)
+				return r.res, nil
+			}
+			if !xerrors.Is(r.err, network.ErrNoConn) {
+				exchangeClientLogger.Warnf("request to peer %s failed: %s", r.peer.String(), r.err)
+			}
+			if next < len(peers) {
+				go send(peers[next])
+				next++
+				inFlight++
+			}
+		}
+	}
+
+	return nil, xerrors.Errorf("doRequest failed for all peers")
+}
+
 // GetFullTipSet implements Client.GetFullTipSet(). Refer to the godocs there.
 func (c *client) GetFullTipSet(ctx context.Context, peers []peer.ID, tsk types.TipSetKey) (*types.FullTipSet, error) {
 	// TODO: round robin through these peers on error
@@ -349,6 +492,10 @@ func (c *client) GetChainMessages(ctx context.Context, tipsets []*types.TipSet)
 	}
 	defer span.End()
 
+	if length > ParallelChunkLength {
+		return c.getChainMessagesParallel(ctx, tipsets)
+	}
+
 	req := &Request{
 		Head:    head.Key().Cids(),
 		Length:  length,
@@ -363,6 +510,68 @@ func (c *client) GetChainMessages(ctx context.Context, tipsets []*types.TipSet)
 	return validRes.messages, nil
 }
 
+// getChainMessagesParallel splits the tipsets into chunks of at most
+// `ParallelChunkLength` and requests them concurrently, assigning each
+// chunk to one of the best `MaxParallelPeers` peers and falling back to
+// the rest of them on failure. Every response is validated against the
+// headers of its own chunk as it arrives.
+func (c *client) getChainMessagesParallel(ctx context.Context, tipsets []*types.TipSet) ([]*CompactedMessages, error) {
+	peers := c.getShuffledPeers()
+	if len(peers) == 0 {
+		return nil, xerrors.Errorf("no peers available")
+	}
+	if len(peers) > MaxParallelPeers {
+		peers = peers[:MaxParallelPeers]
+	}
+
+	messages := make([]*CompactedMessages, len(tipsets))
+	throttle := make(chan struct{}, len(peers))
+	eg, ctx := errgroup.WithContext(ctx)
+	for chunkIdx, start := 0, 0; start < len(tipsets); chunkIdx, start = chunkIdx+1, start+ParallelChunkLength {
+		end := start + ParallelChunkLength
+		if end > len(tipsets) {
+			end = len(tipsets)
+		}
+
+		// Rotate the peer list so each chunk starts with a different peer.
+		offset := chunkIdx % len(peers)
+		chunkPeers := append(append([]peer.ID{}, peers[offset:]...), peers[:offset]...)
+		chunk := tipsets[start:end]
+		out := messages[start:end]
+
+		throttle <- struct{}{}
+		eg.Go(func() error {
+			defer func() { <-throttle }()
+			return c.fetchMessageChunk(ctx, chunkPeers, chunk, out)
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// fetchMessageChunk fills `out` with the messages of `chunk`, issuing new
+// requests for the remainder when a peer only returns a partial response.
+func (c *client) fetchMessageChunk(ctx context.Context, peers []peer.ID, chunk []*types.TipSet, out []*CompactedMessages) error {
+	for fetched := 0; fetched < len(chunk); {
+		left := chunk[fetched:]
+		req := &Request{
+			Head:    left[0].Key().Cids(),
+			Length:  uint64(len(left)),
+			Options: Messages,
+		}
+
+		validRes, err := c.doRequest(ctx, req, peers, left)
+		if err != nil {
+			return xerrors.Errorf("fetching messages from height %d: %w", left[0].Height(), err)
+		}
+		fetched += copy(out[fetched:], validRes.messages)
+	}
+	return nil
+}
+
 // Send a request to a peer. Write request in the stream and read the
 // response back. We do not do any processing of the request/response
 // here.
@@ -416,6 +625,18 @@ func (c *client) sendRequestToPeer(ctx context.Context, peer peer.ID, req *Reque
 		go stream.Close() //nolint:errcheck
 	}()
 
+	// The response is read without the context, reset the stream to abort
+	// the read when the request is cancelled, e.g. after losing a race.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = stream.Reset()
+		case <-done:
+		}
+	}()
+
 	// Write request.
 	_ = stream.SetWriteDeadline(time.Now().Add(WriteReqDeadline))
 	if err := cborutil.WriteCborRPC(stream, req); err != nil {
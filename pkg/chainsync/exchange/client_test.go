@@ -0,0 +1,80 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	emptycid "github.com/filecoin-project/venus/pkg/testhelpers/empty_cid"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestValidateMessageRoots(t *testing.T) {
+	tf.UnitTest(t)
+
+	msgs := types.NewMsgs(3)
+	compacted := &CompactedMessages{
+		Bls:           msgs,
+		BlsIncludes:   [][]uint64{{0, 2}, {}},
+		SecpkIncludes: [][]uint64{{}, {}},
+	}
+	root, err := computeMsgMeta(compacted, 0)
+	require.NoError(t, err)
+	empty, err := computeMsgMeta(compacted, 1)
+	require.NoError(t, err)
+	assert.Equal(t, emptycid.EmptyTxMetaCID, empty)
+
+	blocks := []*types.BlockHeader{mkExchangeBlock(t, 1, root), mkExchangeBlock(t, 2, empty)}
+	chain := []*BSTipSet{{Blocks: blocks, Messages: compacted}}
+	assert.NoError(t, validateMessageRoots(chain))
+
+	// valid indices to other messages
+	compacted.BlsIncludes[0] = []uint64{0, 1}
+	assert.Error(t, validateMessageRoots(chain))
+	compacted.BlsIncludes[0] = []uint64{0, 2}
+	compacted.Bls[2] = types.NewMsgs(4)[3]
+	assert.Error(t, validateMessageRoots(chain))
+}
+
+func TestProcessResponsePenalizesWrongMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	bpt := &bsPeerTracker{peers: make(map[peer.ID]*peerStats)}
+	c := &client{peerTracker: bpt}
+	liar := peer.ID("liar")
+	bpt.addPeer(liar)
+
+	compacted := &CompactedMessages{Bls: types.NewMsgs(1), BlsIncludes: [][]uint64{{0}}, SecpkIncludes: [][]uint64{{}}}
+	ts, err := types.NewTipSet(mkExchangeBlock(t, 1, emptycid.EmptyTxMetaCID))
+	require.NoError(t, err)
+	req := &Request{Head: ts.Key().Cids(), Length: 1, Options: Messages}
+	res := &Response{Status: Ok, Chain: []*BSTipSet{{Messages: compacted}}}
+
+	for i := 0; i < PeerBanThreshold; i++ {
+		_, err := c.processResponse(liar, req, res, []*types.TipSet{ts})
+		assert.Error(t, err)
+	}
+	assert.True(t, bpt.isBanned(liar))
+}
+
+func mkExchangeBlock(t *testing.T, miner int, messages cid.Cid) *types.BlockHeader {
+	root := types.CidFromString(t, "state")
+	return &types.BlockHeader{
+		Miner:                 types.RequireIDAddress(t, miner),
+		Ticket:                types.Ticket{VRFProof: []byte{byte(miner)}},
+		ElectionProof:         &types.ElectionProof{VRFProof: []byte{byte(miner)}},
+		ParentWeight:          big.Zero(),
+		ParentStateRoot:       root,
+		ParentMessageReceipts: root,
+		Messages:              messages,
+		ParentBaseFee:         big.Zero(),
+		BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+		BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+	}
+}
@@ -18,6 +18,16 @@ type peerStats struct {
 	failures    int
 	firstSeen   time.Time
 	averageTime time.Duration
+
+	// invalid counts the responses that failed validation since the
+	// peer was last banned, bannedUntil is the time until which the peer
+	// is excluded from `prefSortedPeers`.
+	invalid     int
+	bannedUntil time.Time
+}
+
+func (ps *peerStats) isBanned(now time.Time) bool {
+	return now.Before(ps.bannedUntil)
 }
 
 type bsPeerTracker struct {
@@ -64,14 +74,30 @@ const (
 	// newPeerMul is how much better than average is the new peer assumed to be
 	// less than one to encourouge trying new peers
 	newPeerMul = 0.9
+
+	// invalidResponseMul is how many times the global average request time
+	// is added to the cost of a peer per invalid response it returned.
+	invalidResponseMul = 4
+)
+
+var (
+	// PeerBanThreshold is the number of invalid responses after which a peer
+	// is temporarily excluded from requests.
+	PeerBanThreshold = 3
+	// PeerBanDuration is how long a peer stays excluded once banned.
+	PeerBanDuration = 10 * time.Minute
 )
 
 func (bpt *bsPeerTracker) prefSortedPeers() []peer.ID {
 	// TODO: this could probably be cached, but as long as its not too many peers, fine for now
 	bpt.lk.Lock()
 	defer bpt.lk.Unlock()
+	now := time.Now()
 	out := make([]peer.ID, 0, len(bpt.peers))
-	for p := range bpt.peers {
+	for p, ps := range bpt.peers {
+		if ps.isBanned(now) {
+			continue
+		}
 		out = append(out, p)
 	}
 
@@ -93,6 +119,7 @@ func (bpt *bsPeerTracker) prefSortedPeers() []peer.ID {
 		} else {
 			costI = getPeerInitLat(out[i])
 		}
+		costI += float64(pi.invalid) * invalidResponseMul * float64(bpt.avgGlobalTime)
 
 		if pj.successes+pj.failures > 0 {
 			failRateJ := float64(pj.failures) / float64(pj.failures+pj.successes)
@@ -100,6 +127,7 @@ func (bpt *bsPeerTracker) prefSortedPeers() []peer.ID {
 		} else {
 			costJ = getPeerInitLat(out[j])
 		}
+		costJ += float64(pj.invalid) * invalidResponseMul * float64(bpt.avgGlobalTime)
 
 		return costI < costJ
 	})
@@ -172,6 +200,38 @@ func (bpt *bsPeerTracker) logFailure(p peer.ID, dur time.Duration, reqSize uint6
 	logTime(pi, dur/time.Duration(reqSize))
 }
 
+// logInvalidResponse scores down a peer that returned data which failed
+// validation, banning it for `PeerBanDuration` once it reaches
+// `PeerBanThreshold` invalid responses.
+func (bpt *bsPeerTracker) logInvalidResponse(p peer.ID) {
+	bpt.lk.Lock()
+	defer bpt.lk.Unlock()
+
+	var pi *peerStats
+	var ok bool
+	if pi, ok = bpt.peers[p]; !ok {
+		log.Warn("log invalid response called on peer not in tracker", "peerid", p.String())
+		return
+	}
+
+	pi.failures++
+	pi.invalid++
+	if pi.invalid >= PeerBanThreshold {
+		log.Warnf("banning peer %s for %s after %d invalid responses", p, PeerBanDuration, pi.invalid)
+		pi.bannedUntil = time.Now().Add(PeerBanDuration)
+		pi.invalid = 0
+	}
+}
+
+// isBanned returns whether the peer is currently excluded from requests.
+func (bpt *bsPeerTracker) isBanned(p peer.ID) bool {
+	bpt.lk.Lock()
+	defer bpt.lk.Unlock()
+
+	pi, ok := bpt.peers[p]
+	return ok && pi.isBanned(time.Now())
+}
+
 func (bpt *bsPeerTracker) removePeer(p peer.ID) {
 	bpt.lk.Lock()
 	defer bpt.lk.Unlock()
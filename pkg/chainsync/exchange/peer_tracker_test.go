@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestPeerTrackerBansInvalidPeers(t *testing.T) {
+	tf.UnitTest(t)
+
+	bpt := &bsPeerTracker{peers: make(map[peer.ID]*peerStats)}
+	good, bad := peer.ID("good"), peer.ID("bad")
+	bpt.addPeer(good)
+	bpt.addPeer(bad)
+	bpt.logGlobalSuccess(time.Second)
+
+	bpt.logInvalidResponse(bad)
+	assert.False(t, bpt.isBanned(bad))
+	assert.Equal(t, []peer.ID{good, bad}, bpt.prefSortedPeers())
+
+	for i := 1; i < PeerBanThreshold; i++ {
+		bpt.logInvalidResponse(bad)
+	}
+	assert.True(t, bpt.isBanned(bad))
+	assert.Equal(t, []peer.ID{good}, bpt.prefSortedPeers())
+
+	bpt.peers[bad].bannedUntil = time.Now().Add(-time.Second)
+	assert.False(t, bpt.isBanned(bad))
+	assert.Len(t, bpt.prefSortedPeers(), 2)
+}
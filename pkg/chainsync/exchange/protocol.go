@@ -40,6 +40,16 @@ const (
 	ReadResMinSpeed     = 50 << 10
 	ShufflePeersPrefix  = 16
 	WriteResDeadline    = 60 * time.Second
+
+	// ParallelChunkLength is the maximum number of tipsets requested from
+	// a single peer when a message request is spread over several peers.
+	ParallelChunkLength = 50
+	// MaxParallelPeers is the maximum number of peers queried concurrently
+	// for a single message request.
+	MaxParallelPeers = 4
+	// HeaderRacePeers is the number of peers a header request is sent to at
+	// the same time.
+	HeaderRacePeers = 2
 )
 
 // FIXME: Rename. Make private.
@@ -112,6 +122,9 @@ const (
 	GoAway        = 202
 	InternalError = 203
 	BadRequest    = 204
+	// The peer has run out of its serving budget for now, the request may
+	// be retried later.
+	Busy = 205
 )
 
 // Convert status to internal error.
@@ -124,7 +137,9 @@ func (res *Response) statusToError() error {
 	case NotFound:
 		return xerrors.Errorf("not found")
 	case GoAway:
-		return xerrors.Errorf("not handling 'go away' chainxchg responses yet")
+		return xerrors.Errorf("peer told us to go away: %s", res.ErrorMessage)
+	case Busy:
+		return xerrors.Errorf("peer is busy: %s", res.ErrorMessage)
 	case InternalError:
 		return xerrors.Errorf("block sync peer errored: %s", res.ErrorMessage)
 	case BadRequest:
@@ -14,7 +14,10 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p-core/host"
 	inet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
 
+	"github.com/filecoin-project/venus/pkg/metrics"
+	"github.com/filecoin-project/venus/pkg/net"
 	"github.com/filecoin-project/venus/pkg/types"
 )
 
@@ -31,23 +34,37 @@ type messageStore interface {
 	LoadSignedMessagesFromCids(cids []cid.Cid) ([]*types.SignedMessage, error)
 }
 
+var throttledReqCt = metrics.NewInt64Counter("exchange/throttled_requests", "Number of chain exchange requests refused because the peer exceeded its budget")
+var throttledPeersGauge = metrics.NewInt64Gauge("exchange/throttled_peers", "Number of peers whose chain exchange requests were refused within the last minute")
+
 // server implements exchange.Server. It services requests for the
 // libp2p ChainExchange protocol.
 type server struct {
 	cr chainReader
 	mr messageStore
 	h  host.Host
+
+	// headerLimiter and msgLimiter account the tipsets of headers and of
+	// messages served to each peer, nil means no limit.
+	headerLimiter *net.PeerLimiter
+	msgLimiter    *net.PeerLimiter
+	// throttled are the peers refused by the limiters, only their number is exported.
+	throttled *net.ThrottledPeers
 }
 
 var _ Server = (*server)(nil)
 
 // NewServer creates a new libp2p-based exchange.Server. It services requests
-// for the libp2p ChainExchange protocol.
-func NewServer(cr chainReader, mr messageStore, h host.Host) Server {
+// for the libp2p ChainExchange protocol, limiting the headers and messages
+// served per peer with the given limiters (which may be nil).
+func NewServer(cr chainReader, mr messageStore, h host.Host, headerLimiter, msgLimiter *net.PeerLimiter) Server {
 	return &server{
-		cr: cr,
-		mr: mr,
-		h:  h,
+		cr:            cr,
+		mr:            mr,
+		h:             h,
+		headerLimiter: headerLimiter,
+		msgLimiter:    msgLimiter,
+		throttled:     net.NewThrottledPeers(),
 	}
 }
 
@@ -70,13 +87,17 @@ func (s *server) handleStream(stream inet.Stream) {
 		exchangeServerLog.Warnf("failed to read block sync request: %s", err)
 		return
 	}
-	fmt.Println(stream.Conn().RemotePeer())
-	exchangeServerLog.Infow("block sync request", "start", req.Head, "len", req.Length)
+	from := stream.Conn().RemotePeer()
+	exchangeServerLog.Infow("block sync request", "peer", from, "start", req.Head, "len", req.Length)
 
-	resp, err := s.processRequest(ctx, &req)
-	if err != nil {
-		exchangeServerLog.Warn("failed to process request: ", err)
-		return
+	resp := s.throttle(ctx, from, &req)
+	if resp == nil {
+		var err error
+		resp, err = s.processRequest(ctx, &req)
+		if err != nil {
+			exchangeServerLog.Warn("failed to process request: ", err)
+			return
+		}
 	}
 
 	_ = stream.SetDeadline(time.Now().Add(WriteResDeadline))
@@ -89,6 +110,64 @@ func (s *server) handleStream(stream inet.Stream) {
 	_ = stream.SetDeadline(time.Time{})
 }
 
+// throttle charges the cost of the request to the peer budgets. It returns
+// nil if the request may be served, or the `GoAway`/`Busy` response to send
+// back otherwise. Invalid requests are left for `validateRequest` to reject.
+func (s *server) throttle(ctx context.Context, from peer.ID, req *Request) *Response {
+	if req.Length == 0 || req.Length > MaxRequestLength {
+		return nil
+	}
+	defer func() {
+		throttledPeersGauge.Set(ctx, int64(s.throttled.Count()))
+	}()
+
+	options := parseOptions(req.Options)
+	var headerCost, msgCost uint64
+	if options.IncludeHeaders {
+		headerCost = req.Length
+	}
+	if options.IncludeMessages {
+		msgCost = req.Length
+	}
+
+	if s.headerLimiter.Exceeds(from, headerCost) || s.msgLimiter.Exceeds(from, msgCost) {
+		throttledReqCt.Inc(ctx, 1)
+		s.throttled.Add(from)
+		exchangeServerLog.Infow("refusing request above the peer budget", "peer", from, "len", req.Length)
+		return &Response{
+			Status:       GoAway,
+			ErrorMessage: "request exceeds the serving budget per minute",
+		}
+	}
+
+	// Both budgets are checked before charging either. A concurrent request
+	// of the peer may still take the budget in between, the headers charged
+	// are then refunded.
+	busy := func(budget string, cost uint64) *Response {
+		throttledReqCt.Inc(ctx, 1)
+		s.throttled.Add(from)
+		exchangeServerLog.Infow("throttling peer", "peer", from, budget, cost)
+		return &Response{
+			Status:       Busy,
+			ErrorMessage: budget + " budget exhausted, retry later",
+		}
+	}
+	if !s.headerLimiter.Available(from, headerCost) {
+		return busy("headers", headerCost)
+	}
+	if !s.msgLimiter.Available(from, msgCost) {
+		return busy("messages", msgCost)
+	}
+	if !s.headerLimiter.Allow(from, headerCost) {
+		return busy("headers", headerCost)
+	}
+	if !s.msgLimiter.Allow(from, msgCost) {
+		s.headerLimiter.Refund(from, headerCost)
+		return busy("messages", msgCost)
+	}
+	return nil
+}
+
 // Validate and service the request. We return either a protocol
 // response or an internal error.
 func (s *server) processRequest(ctx context.Context, req *Request) (*Response, error) {
@@ -0,0 +1,55 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/net"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestServerThrottle(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	p, other := peer.ID("leecher"), peer.ID("other")
+	s := &server{
+		headerLimiter: net.NewPeerLimiter(int64(MaxRequestLength), nil),
+		msgLimiter:    net.NewPeerLimiter(100, nil),
+		throttled:     net.NewThrottledPeers(),
+	}
+
+	// a full length header request fits the header budget of a minute
+	assert.Nil(t, s.throttle(ctx, p, &Request{Length: MaxRequestLength, Options: Headers}))
+	res := s.throttle(ctx, p, &Request{Length: 1, Options: Headers})
+	require.NotNil(t, res)
+	assert.Equal(t, status(Busy), res.Status)
+
+	// a peer asking for more than its messages per minute is throttled
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, s.throttle(ctx, p, &Request{Length: 10, Options: Messages}))
+	}
+	res = s.throttle(ctx, p, &Request{Length: 10, Options: Messages})
+	require.NotNil(t, res)
+	assert.Equal(t, status(Busy), res.Status)
+
+	// a request longer than the messages budget is never served
+	res = s.throttle(ctx, other, &Request{Length: 101, Options: Messages})
+	require.NotNil(t, res)
+	assert.Equal(t, status(GoAway), res.Status)
+
+	// a request rejected by the messages budget does not consume headers
+	assert.Nil(t, s.throttle(ctx, other, &Request{Length: 100, Options: Messages}))
+	res = s.throttle(ctx, other, &Request{Length: 10, Options: Headers | Messages})
+	require.NotNil(t, res)
+	assert.Equal(t, status(Busy), res.Status)
+	assert.True(t, s.headerLimiter.Available(other, MaxRequestLength))
+
+	// both throttled peers are counted, not the ones served
+	assert.Nil(t, s.throttle(ctx, peer.ID("polite"), &Request{Length: 1, Options: Headers}))
+	assert.Equal(t, 2, s.throttled.Count())
+}
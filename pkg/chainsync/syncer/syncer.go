@@ -11,6 +11,7 @@ import (
 	syncTypes "github.com/filecoin-project/venus/pkg/chainsync/types"
 	cbor "github.com/ipfs/go-ipld-cbor"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	blockstore "github.com/ipfs/go-ipfs-blockstore"
@@ -25,6 +26,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/pkg/constants"
 	"github.com/filecoin-project/venus/pkg/fork"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/metrics"
 	"github.com/filecoin-project/venus/pkg/metrics/tracing"
 	"github.com/filecoin-project/venus/pkg/specactors/policy"
@@ -136,6 +138,30 @@ type Syncer struct {
 	checkPoint types.TipSetKey
 
 	fork fork.IFork
+
+	journal  journal.Journal
+	evtTypes [2]journal.EventType
+}
+
+const (
+	evtTypeBadTipSet = iota
+	evtTypeForkTooLong
+)
+
+// BadTipSetEvt is the journal event of a tipset failing validation.
+type BadTipSetEvt struct {
+	Key    types.TipSetKey
+	Height abi.ChainEpoch
+	Target types.TipSetKey
+	Error  string
+}
+
+// ForkTooLongEvt is the journal event of a fork rejected for being longer than the threshold.
+type ForkTooLongEvt struct {
+	Incoming       types.TipSetKey
+	IncomingHeight abi.ChainEpoch
+	Known          types.TipSetKey
+	KnownHeight    abi.ChainEpoch
 }
 
 // NewSyncer constructs a Syncer ready for use.  The chain reader must have a
@@ -148,7 +174,11 @@ func NewSyncer(fv StateProcessor,
 	bsstore blockstore.Blockstore,
 	exchangeClient exchange.Client,
 	c clock.Clock,
-	fork fork.IFork) (*Syncer, error) {
+	fork fork.IFork,
+	j journal.Journal) (*Syncer, error) {
+	if j == nil {
+		j = journal.NilJournal()
+	}
 	return &Syncer{
 		exchangeClient:  exchangeClient,
 		badTipSets:      syncTypes.NewBadTipSetCache(),
@@ -160,6 +190,11 @@ func NewSyncer(fv StateProcessor,
 		messageProvider: m,
 		clock:           c,
 		fork:            fork,
+		journal:         j,
+		evtTypes: [...]journal.EventType{
+			evtTypeBadTipSet:   j.RegisterEventType("sync", "bad_tipset"),
+			evtTypeForkTooLong: j.RegisterEventType("sync", "fork_too_long"),
+		},
 	}, nil
 }
 
@@ -320,12 +355,15 @@ func (syncer *Syncer) syncSegement(ctx context.Context, target *syncTypes.Target
 	errProcessChan <- nil //init
 	var wg sync.WaitGroup
 	//todo  write a pipline segment processor function
-	if err = rangeProcess(tipsets, func(segTipset []*types.TipSet) error {
+	// Messages are fetched a window of several segments at a time so the
+	// exchange client can spread the request over multiple peers, while
+	// the previous window is still being processed.
+	if err = rangeProcess(tipsets, maxFetchLen, func(fetchTipset []*types.TipSet) error {
 		// fetch messages
-		startTip := segTipset[0].Height()
-		emdTipset := segTipset[len(segTipset)-1].Height()
+		startTip := fetchTipset[0].Height()
+		emdTipset := fetchTipset[len(fetchTipset)-1].Height()
 		logSyncer.Infof("start to fetch message segement %d-%d", startTip, emdTipset)
-		_, err := syncer.fetchSegMessage(ctx, segTipset)
+		_, err := syncer.fetchSegMessage(ctx, fetchTipset)
 		if err != nil {
 			return err
 		}
@@ -337,23 +375,22 @@ func (syncer *Syncer) syncSegement(ctx context.Context, target *syncTypes.Target
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			logSyncer.Infof("start to process message segement %d-%d", startTip, emdTipset)
-			defer logSyncer.Infof("finish to process message segement %d-%d", startTip, emdTipset)
-			var processErr error
-			parent, processErr = syncer.processTipSetSegment(ctx, target, parent, segTipset)
-			if processErr != nil {
-				errProcessChan <- processErr
-				return
-			}
+			errProcessChan <- rangeProcess(fetchTipset, maxProcessLen, func(segTipset []*types.TipSet) error {
+				startTip := segTipset[0].Height()
+				emdTipset := segTipset[len(segTipset)-1].Height()
+				logSyncer.Infof("start to process message segement %d-%d", startTip, emdTipset)
+				defer logSyncer.Infof("finish to process message segement %d-%d", startTip, emdTipset)
+				var processErr error
+				parent, processErr = syncer.processTipSetSegment(ctx, target, parent, segTipset)
+				if processErr != nil {
+					return processErr
+				}
 
-			if !parent.Key().Equals(syncer.checkPoint) {
-				err := syncer.SetHead(ctx, parent)
-				if err != nil {
-					errProcessChan <- err
-					return
+				if !parent.Key().Equals(syncer.checkPoint) {
+					return syncer.SetHead(ctx, parent)
 				}
-			}
-			errProcessChan <- nil
+				return nil
+			})
 		}()
 		return nil
 	}); err != nil {
@@ -460,6 +497,14 @@ loop:
 		if xerrors.Is(err, ErrForkTooLong) {
 			// TODO: we're marking this block bad in the same way that we mark invalid blocks bad. Maybe distinguish?
 			log.Warn("adding forked chain to our bad tipset cache")
+			syncer.journal.RecordEvent(syncer.evtTypes[evtTypeForkTooLong], func() interface{} {
+				return ForkTooLongEvt{
+					Incoming:       targetTip.Key(),
+					IncomingHeight: targetTip.Height(),
+					Known:          knownTip.Key(),
+					KnownHeight:    knownTip.Height(),
+				}
+			})
 			/*		for _, b := range incoming.Blocks() {
 					syncer.bad.Add(b.Cid(), NewBadBlockReason(incoming.Cids(), "fork past finality"))
 				}*/
@@ -622,6 +667,14 @@ func (syncer *Syncer) processTipSetSegment(ctx context.Context, target *syncType
 			// there is no assumption that the running node's data is valid at all,
 			// so we don't really lose anything with this simplification.
 			syncer.badTipSets.AddChain(segTipset[i:])
+			syncer.journal.RecordEvent(syncer.evtTypes[evtTypeBadTipSet], func() interface{} {
+				return BadTipSetEvt{
+					Key:    ts.Key(),
+					Height: ts.Height(),
+					Target: target.Head.Key(),
+					Error:  err.Error(),
+				}
+			})
 			return nil, errors.Wrapf(err, "failed to sync tipset %s, number %d of %d in chain", ts.Key(), i, len(segTipset))
 		}
 		parent = ts
@@ -707,21 +760,25 @@ func zipTipSetAndMessages(bs blockstore.Blockstore, ts *types.TipSet, allbmsgs [
 
 const maxProcessLen = 32
 
-func rangeProcess(ts []*types.TipSet, cb func(ts []*types.TipSet) error) (err error) {
+// maxFetchLen is the number of tipsets whose messages are requested at once,
+// large enough for the exchange client to split it over several peers.
+const maxFetchLen = maxProcessLen * 4
+
+func rangeProcess(ts []*types.TipSet, segLen int, cb func(ts []*types.TipSet) error) (err error) {
 	for {
 		if len(ts) == 0 {
 			break
-		} else if len(ts) < maxProcessLen {
+		} else if len(ts) < segLen {
 			// break out if less than process len
 			err = cb(ts)
 			break
 		} else {
-			processTS := ts[0:maxProcessLen]
+			processTS := ts[0:segLen]
 			err = cb(processTS)
 			if err != nil {
 				break
 			}
-			ts = ts[maxProcessLen:]
+			ts = ts[segLen:]
 		}
 		logSyncer.Infof("Sync Process End,Remaining: %v, err: %v ...", len(ts), err)
 	}
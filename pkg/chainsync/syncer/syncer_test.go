@@ -310,7 +310,8 @@ func TestNoUncessesaryFetch(t *testing.T) {
 		builder.BlockStore(),
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		nil)
 	require.NoError(t, err)
 
 	target2 := &syncTypes.Target{
@@ -574,7 +575,8 @@ func setupWithValidator(ctx context.Context, t *testing.T, builder *chain.Builde
 		builder.BlockStore(),
 		builder,
 		clock.NewFake(time.Unix(1234567890, 0)),
-		fork.NewMockFork())
+		fork.NewMockFork(),
+		nil)
 	require.NoError(t, err)
 
 	return builder, syncer
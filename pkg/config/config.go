@@ -37,6 +37,8 @@ type Config struct {
 	Wallet        *WalletConfig        `json:"walletModule"`
 	SlashFilterDs *SlashFilterDsConfig `json:"slashFilter"`
 	RateLimitCfg  *RateLimitCfg        `json:"rateLimit"`
+	PeerRateLimit *PeerRateLimitConfig `json:"peerRateLimit"`
+	Journal       *JournalConfig       `json:"journal"`
 }
 
 // APIConfig holds all configuration options related to the api.
@@ -47,6 +49,19 @@ type APIConfig struct {
 	AccessControlAllowOrigin      []string `json:"accessControlAllowOrigin"`
 	AccessControlAllowCredentials bool     `json:"accessControlAllowCredentials"`
 	AccessControlAllowMethods     []string `json:"accessControlAllowMethods"`
+	// EnableDealIndex keeps an index of market deals by client and provider to speed up deal queries
+	EnableDealIndex bool `json:"enableDealIndex"`
+	// ReadyMaxHeadLag is how many epochs the head may lag behind the clock for /readyz to report the node ready
+	ReadyMaxHeadLag int64 `json:"readyMaxHeadLag"`
+	// TLSCertPath and TLSKeyPath are the pem files of the certificate and key serving the api over tls
+	TLSCertPath string `json:"tlsCertPath"`
+	TLSKeyPath  string `json:"tlsKeyPath"`
+	// TLSClientCAPath is a pem file of the authorities of the client certificates, when set the clients must present one
+	TLSClientCAPath string `json:"tlsClientCAPath"`
+	// UnixSocketPath is the path of a unix socket the api is also served on, none when empty
+	UnixSocketPath string `json:"unixSocketPath"`
+	// UnixSocketMode is the octal file mode of the unix socket
+	UnixSocketMode string `json:"unixSocketMode"`
 }
 
 type RateLimitCfg struct {
@@ -54,6 +69,19 @@ type RateLimitCfg struct {
 	User     string `json:"user"`
 	Pwd      string `json:"pwd"`
 	Enable   bool   `json:"enable"`
+
+	// InProcess enables the rate limiter built in the node, which needs neither redis nor venus-auth.
+	InProcess bool `json:"inProcess"`
+	// KeyBy is what the budgets of the in-process limiter are kept by, "token" or "ip".
+	KeyBy string `json:"keyBy"`
+	// TierRates are the budgets of a caller in cost units per second, by the highest permission
+	// of its token. A missing or non positive rate leaves the tier unlimited.
+	TierRates map[string]float64 `json:"tierRates"`
+	// MethodRates are the budgets of a caller for a single method in cost units per second, on
+	// top of its tier budget.
+	MethodRates map[string]float64 `json:"methodRates"`
+	// MethodCosts are the cost units consumed by a call of a method, 1 for the methods not listed.
+	MethodCosts map[string]float64 `json:"methodCosts"`
 }
 
 func newDefaultAPIConfig() *APIConfig {
@@ -66,14 +94,20 @@ func newDefaultAPIConfig() *APIConfig {
 			"https://127.0.0.1:8080",
 		},
 		AccessControlAllowMethods: []string{"GET", "POST", "PUT"},
+		ReadyMaxHeadLag:           10,
+		UnixSocketMode:            "0600",
 	}
 }
 
 // DatastoreConfig holds all the configuration options for the datastore.
 // TODO: use the advanced datastore configuration from ipfs
 type DatastoreConfig struct {
+	// Type is the backend of the blockstore, such as badgerds, levelds or car.
 	Type string `json:"type"`
 	Path string `json:"path"`
+	// MetadataType is the backend of the chain, metadata, paych and wallet datastores, the
+	// backend of the blockstore when empty.
+	MetadataType string `json:"metadataType,omitempty"`
 }
 
 // Validators hold the list of validation functions for each configuration
@@ -122,10 +156,32 @@ func newDefaultBootstrapConfig() *BootstrapConfig {
 
 // WalletConfig holds all configuration options related to the wallet.
 type WalletConfig struct {
-	DefaultAddress   address.Address  `json:"defaultAddress,omitempty"`
-	PassphraseConfig PassphraseConfig `json:"passphraseConfig,omitempty"`
-	RemoteEnable     bool             `json:"remoteEnable"`
-	RemoteBackend    string           `json:"remoteBackend"`
+	DefaultAddress   address.Address   `json:"defaultAddress,omitempty"`
+	PassphraseConfig PassphraseConfig  `json:"passphraseConfig,omitempty"`
+	RemoteEnable     bool              `json:"remoteEnable"`
+	RemoteBackend    string            `json:"remoteBackend"`
+	SignPolicy       *SignPolicyConfig `json:"signPolicy,omitempty"`
+}
+
+// SignPolicyConfig restricts what the local wallet signs, nothing is restricted when it is not set.
+type SignPolicyConfig struct {
+	// Default applies to the addresses without a rule of their own.
+	Default *SignRuleConfig `json:"default,omitempty"`
+	// Rules is keyed by the bls or secp256k1 addresses of the wallet, not their ID addresses.
+	Rules map[string]*SignRuleConfig `json:"rules,omitempty"`
+}
+
+// SignRuleConfig restricts what an address signs, an empty field does not restrict anything.
+// MsgTypes can only allow the "message", "block", "signedvoucher" and "dealproposal" types, whose
+// signed bytes are checked against the message, block header, voucher or proposal sent with them,
+// a rule allowing another type is rejected. Methods, AllowedTo, MaxValue and MaxDailyValue restrict chain messages, an address with
+// any of them set is not allowed to sign anything else.
+type SignRuleConfig struct {
+	MsgTypes      []string        `json:"msgTypes,omitempty"`
+	Methods       []abi.MethodNum `json:"methods,omitempty"`
+	AllowedTo     []string        `json:"allowedTo,omitempty"`
+	MaxValue      string          `json:"maxValue,omitempty"`
+	MaxDailyValue string          `json:"maxDailyValue,omitempty"`
 }
 
 type PassphraseConfig struct {
@@ -343,6 +399,70 @@ func newDefaultSlashFilterDsConfig() *SlashFilterDsConfig {
 func newRateLimitConfig() *RateLimitCfg {
 	return &RateLimitCfg{
 		Enable: false,
+
+		InProcess: false,
+		KeyBy:     "token",
+		TierRates: map[string]float64{
+			"read":  100,
+			"write": 100,
+			"sign":  100,
+			"admin": 0,
+		},
+		MethodRates: map[string]float64{},
+		MethodCosts: map[string]float64{
+			"StateMarketDeals": 50,
+			"StateListActors":  20,
+			"StateCall":        5,
+		},
+	}
+}
+
+// PeerRateLimitConfig holds the per-peer budgets of the chain exchange and
+// hello servers. A non positive budget disables the corresponding limit. A peer
+// may spend a whole budget at once, the exchange budgets must be at least the
+// maximum length of a request, the chain finality. The number of peers throttled
+// within the last minute is exported as a metric, the peers themselves are only
+// logged.
+type PeerRateLimitConfig struct {
+	// HeadersPerMinute is the number of tipset headers served to a peer per minute.
+	HeadersPerMinute int64 `json:"headersPerMinute"`
+	// MessagesPerMinute is the number of tipsets of messages served to a peer per minute.
+	MessagesPerMinute int64 `json:"messagesPerMinute"`
+	// HelloPerMinute is the number of hello messages processed from a peer per minute.
+	HelloPerMinute int64 `json:"helloPerMinute"`
+	// Whitelist is a list of trusted peer IDs which are never limited.
+	Whitelist []string `json:"whitelist"`
+}
+
+func newDefaultPeerRateLimitConfig() *PeerRateLimitConfig {
+	return &PeerRateLimitConfig{
+		HeadersPerMinute:  0,
+		MessagesPerMinute: 0,
+		HelloPerMinute:    0,
+		Whitelist:         []string{},
+	}
+}
+
+// JournalConfig holds the options of the event journal, which writes to rotating
+// files in the journal directory of the repo.
+type JournalConfig struct {
+	// DisabledEvents lists the events which are not journaled, as system:event.
+	// The VENUS_JOURNAL_DISABLED_EVENTS environment variable overrides it.
+	DisabledEvents []string `json:"disabledEvents"`
+	// MaxFileSize is the size in bytes after which the journal file is rolled.
+	MaxFileSize int64 `json:"maxFileSize"`
+	// MaxFiles is the number of journal files kept, 0 keeps them all.
+	MaxFiles int `json:"maxFiles"`
+	// ZapSink also writes the events to journal.json in the repo through zap.
+	ZapSink bool `json:"zapSink"`
+}
+
+func newDefaultJournalConfig() *JournalConfig {
+	return &JournalConfig{
+		DisabledEvents: []string{"mpool:add", "mpool:remove"},
+		MaxFileSize:    1 << 30,
+		MaxFiles:       10,
+		ZapSink:        false,
 	}
 }
 
@@ -360,6 +480,8 @@ func NewDefaultConfig() *Config {
 		Wallet:        newDefaultWalletConfig(),
 		SlashFilterDs: newDefaultSlashFilterDsConfig(),
 		RateLimitCfg:  newRateLimitConfig(),
+		PeerRateLimit: newDefaultPeerRateLimitConfig(),
+		Journal:       newDefaultJournalConfig(),
 	}
 }
 
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/filecoin-project/go-crypto"
+	"github.com/pkg/errors"
+)
+
+// HardenedKeyStart is the index of the first hardened child key in BIP32.
+const HardenedKeyStart uint32 = 0x80000000
+
+// secp256k1N is the order of the secp256k1 curve.
+var secp256k1N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+var errInvalidBIP32Key = errors.New("derived key is invalid, use the next index")
+
+// bip32Key is an extended private key as defined by BIP32.
+type bip32Key struct {
+	key       []byte
+	chainCode []byte
+}
+
+// bip32Master computes the master extended key of a seed.
+func bip32Master(seed []byte) (*bip32Key, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	_, _ = mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	k := new(big.Int).SetBytes(sum[:32])
+	if k.Sign() == 0 || k.Cmp(secp256k1N) >= 0 {
+		return nil, errInvalidBIP32Key
+	}
+	return &bip32Key{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// child derives the child private key at `index`, hardened if index >= HardenedKeyStart.
+func (k *bip32Key) child(index uint32) (*bip32Key, error) {
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0x0}, k.key...)
+	} else {
+		data = compressPublicKey(crypto.PublicKey(k.key))
+	}
+	data = append(data, make([]byte, 4)...)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	_, _ = mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(secp256k1N) >= 0 {
+		return nil, errInvalidBIP32Key
+	}
+	childKey := il.Add(il, new(big.Int).SetBytes(k.key))
+	childKey.Mod(childKey, secp256k1N)
+	if childKey.Sign() == 0 {
+		return nil, errInvalidBIP32Key
+	}
+
+	key := make([]byte, 32)
+	childKey.FillBytes(key)
+	return &bip32Key{key: key, chainCode: sum[32:]}, nil
+}
+
+// compressPublicKey converts an uncompressed secp256k1 public key, 0x04 || X || Y, to the
+// compressed 33 bytes form.
+func compressPublicKey(pub []byte) []byte {
+	out := make([]byte, 33)
+	out[0] = 0x02 + pub[64]&1
+	copy(out[1:], pub[1:33])
+	return out
+}
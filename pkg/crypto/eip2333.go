@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+)
+
+// bls12381R is the order of the BLS12-381 curve subgroups.
+var bls12381R, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// eip2333Master derives the master BLS secret key from a seed, see EIP-2333.
+func eip2333Master(seed []byte) (*big.Int, error) {
+	if len(seed) < 32 {
+		return nil, errors.New("seed must be at least 32 bytes")
+	}
+	return hkdfModR(seed)
+}
+
+// eip2333Child derives the child secret key at `index` of a parent secret key, see EIP-2333.
+func eip2333Child(parent *big.Int, index uint32) (*big.Int, error) {
+	salt := make([]byte, 4)
+	binary.BigEndian.PutUint32(salt, index)
+
+	ikm := make([]byte, 32)
+	parent.FillBytes(ikm)
+	notIkm := make([]byte, 32)
+	for i := range ikm {
+		notIkm[i] = ^ikm[i]
+	}
+
+	lamport0, err := ikmToLamportSK(ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	lamport1, err := ikmToLamportSK(notIkm, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	lamportPK := sha256.New()
+	for _, chunks := range [][][]byte{lamport0, lamport1} {
+		for _, chunk := range chunks {
+			sum := sha256.Sum256(chunk)
+			_, _ = lamportPK.Write(sum[:])
+		}
+	}
+	return hkdfModR(lamportPK.Sum(nil))
+}
+
+// ikmToLamportSK expands the key material into 255 chunks of 32 bytes.
+func ikmToLamportSK(ikm, salt []byte) ([][]byte, error) {
+	okm := make([]byte, 32*255)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, nil), okm); err != nil {
+		return nil, err
+	}
+
+	chunks := make([][]byte, 255)
+	for i := range chunks {
+		chunks[i] = okm[i*32 : (i+1)*32]
+	}
+	return chunks, nil
+}
+
+// hkdfModR derives a non zero secret key lower than the curve order from the key material.
+func hkdfModR(ikm []byte) (*big.Int, error) {
+	const l = 48
+
+	ikm = append(append([]byte{}, ikm...), 0)
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+
+		okm := make([]byte, l)
+		info := []byte{0, l}
+		if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, info), okm); err != nil {
+			return nil, err
+		}
+		sk.SetBytes(okm)
+		sk.Mod(sk, bls12381R)
+	}
+	return sk, nil
+}
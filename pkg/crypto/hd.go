@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// FilecoinCoinType is the SLIP-44 coin type of Filecoin, used in the derivation paths.
+const FilecoinCoinType = 461
+
+// blsPurpose is the purpose of BLS12-381 keys in EIP-2334 paths.
+const blsPurpose = 12381
+
+// NewMnemonic generates a new 24 words BIP39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicToSeed checks the mnemonic and returns its BIP39 seed.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mnemonic")
+	}
+	return seed, nil
+}
+
+// HDPath returns the derivation path of the key at `index`, m/44'/461'/0'/0/index for
+// secp256k1 keys (BIP44) and m/12381/461/0/index for BLS keys (EIP-2334).
+func HDPath(sigType SigType, index uint32) (string, error) {
+	switch sigType {
+	case SigTypeSecp256k1:
+		return fmt.Sprintf("m/44'/%d'/0'/0/%d", FilecoinCoinType, index), nil
+	case SigTypeBLS:
+		return fmt.Sprintf("m/%d/%d/0/%d", blsPurpose, FilecoinCoinType, index), nil
+	default:
+		return "", errors.Errorf("unsupported signature type %d", sigType)
+	}
+}
+
+// DeriveKey derives the key of `sigType` at `index` of the seed, see HDPath.
+func DeriveKey(sigType SigType, seed []byte, index uint32) (KeyInfo, error) {
+	if index >= HardenedKeyStart {
+		return KeyInfo{}, errors.Errorf("index %d out of range", index)
+	}
+
+	var k []byte
+	switch sigType {
+	case SigTypeSecp256k1:
+		key, err := deriveSecp(seed, []uint32{44 + HardenedKeyStart, FilecoinCoinType + HardenedKeyStart, HardenedKeyStart, 0, index})
+		if err != nil {
+			return KeyInfo{}, err
+		}
+		k = key
+	case SigTypeBLS:
+		key, err := deriveBLS(seed, []uint32{blsPurpose, FilecoinCoinType, 0, index})
+		if err != nil {
+			return KeyInfo{}, err
+		}
+		k = key
+	default:
+		return KeyInfo{}, errors.Errorf("unsupported signature type %d", sigType)
+	}
+
+	ki := &KeyInfo{
+		SigType: sigType,
+	}
+	ki.SetPrivateKey(k)
+	copy(k, make([]byte, len(k))) //wipe with zero bytes
+	return *ki, nil
+}
+
+func deriveSecp(seed []byte, path []uint32) ([]byte, error) {
+	key, err := bip32Master(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range path {
+		if key, err = key.child(i); err != nil {
+			return nil, err
+		}
+	}
+	return key.key, nil
+}
+
+func deriveBLS(seed []byte, path []uint32) ([]byte, error) {
+	sk, err := eip2333Master(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range path {
+		if sk, err = eip2333Child(sk, i); err != nil {
+			return nil, err
+		}
+	}
+	return blsPrivateKeyBytes(sk), nil
+}
+
+// blsPrivateKeyBytes serializes a BLS secret key the way filecoin does, as a 32 bytes little
+// endian integer.
+func blsPrivateKeyBytes(sk *big.Int) []byte {
+	k := make([]byte, 32)
+	sk.FillBytes(k)
+	for i, j := 0, len(k)-1; i < j; i, j = i+1, j-1 {
+		k[i], k[j] = k[j], k[i]
+	}
+	return k
+}
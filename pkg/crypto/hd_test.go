@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestBIP32Derivation(t *testing.T) {
+	tf.UnitTest(t)
+
+	// test vector 1 of BIP32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	key, err := bip32Master(seed)
+	require.NoError(t, err)
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(key.key))
+
+	key, err = key.child(HardenedKeyStart)
+	require.NoError(t, err)
+	assert.Equal(t, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea", hex.EncodeToString(key.key))
+
+	key, err = key.child(1)
+	require.NoError(t, err)
+	assert.Equal(t, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", hex.EncodeToString(key.key))
+}
+
+func TestEIP2333Derivation(t *testing.T) {
+	tf.UnitTest(t)
+
+	// test case 0 of EIP-2333
+	seed, _ := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	master, err := eip2333Master(seed)
+	require.NoError(t, err)
+	expected, _ := new(big.Int).SetString("6083874454709270928345386274498605044986640685124978867557563392430687146096", 10)
+	assert.Equal(t, 0, expected.Cmp(master))
+
+	child, err := eip2333Child(master, 0)
+	require.NoError(t, err)
+	expected, _ = new(big.Int).SetString("20397789859736650942317412262472558107875392172444076792671091975210932703118", 10)
+	assert.Equal(t, 0, expected.Cmp(child))
+}
+
+func TestHDPath(t *testing.T) {
+	tf.UnitTest(t)
+
+	path, err := HDPath(SigTypeSecp256k1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "m/44'/461'/0'/0/3", path)
+
+	path, err = HDPath(SigTypeBLS, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "m/12381/461/0/3", path)
+
+	_, err = DeriveKey(SigTypeSecp256k1, make([]byte, 64), HardenedKeyStart)
+	assert.Error(t, err)
+}
@@ -17,6 +17,7 @@ type Signature = crypto.Signature
 type SigType = crypto.SigType
 
 const (
+	SigTypeUnknown   = crypto.SigTypeUnknown
 	SigTypeSecp256k1 = crypto.SigTypeSecp256k1
 	SigTypeBLS       = crypto.SigTypeBLS
 )
@@ -30,6 +30,8 @@ const helloProtocolID = "/fil/hello/1.0.0"
 
 var genesisErrCt = metrics.NewInt64Counter("hello_genesis_error", "Number of errors encountered in hello protocol due to incorrect genesis block")
 var helloMsgErrCt = metrics.NewInt64Counter("hello_message_error", "Number of errors encountered in hello protocol due to malformed message")
+var helloThrottledCt = metrics.NewInt64Counter("hello_throttled", "Number of hello messages ignored because the peer exceeded its budget")
+var helloThrottledPeersGauge = metrics.NewInt64Gauge("hello_throttled_peers", "Number of peers whose hello messages were ignored within the last minute")
 
 // HelloMessage is the data structure of a single message in the hello protocol.
 type HelloMessage struct {
@@ -71,6 +73,12 @@ type HelloProtocolHandler struct {
 	exchange     exchange.Client
 	chainStore   *chain.Store
 	messageStore *chain.MessageStore
+
+	// limiter bounds the hello messages processed per peer, each of them
+	// may trigger a full tipset load. nil means no limit.
+	limiter *fnet.PeerLimiter
+	// throttled are the peers refused by the limiter, only their number is exported.
+	throttled *fnet.ThrottledPeers
 }
 
 type PeerDiscoveredCallback func(ci *types.ChainInfo)
@@ -86,6 +94,7 @@ func NewHelloProtocolHandler(h host.Host,
 	messageStore *chain.MessageStore,
 	gen cid.Cid,
 	helloTimeOut time.Duration,
+	limiter *fnet.PeerLimiter,
 ) *HelloProtocolHandler {
 	return &HelloProtocolHandler{
 		host:         h,
@@ -95,6 +104,8 @@ func NewHelloProtocolHandler(h host.Host,
 		chainStore:   chainStore,
 		messageStore: messageStore,
 		helloTimeOut: helloTimeOut,
+		limiter:      limiter,
+		throttled:    fnet.NewThrottledPeers(),
 	}
 }
 
@@ -145,6 +156,17 @@ func (h *HelloProtocolHandler) handleNewStream(s net.Stream) {
 		}
 	}()
 
+	allowed := h.limiter.Allow(from, 1)
+	if !allowed {
+		h.throttled.Add(from)
+	}
+	helloThrottledPeersGauge.Set(ctx, int64(h.throttled.Count()))
+	if !allowed {
+		helloThrottledCt.Inc(ctx, 1)
+		log.Debugf("ignoring hello from peer %s, budget exhausted", from)
+		return
+	}
+
 	protos, err := h.host.Peerstore().GetProtocols(s.Conn().RemotePeer())
 	if err != nil {
 		log.Warnf("got error from peerstore.GetProtocols: %s", err)
@@ -68,8 +68,8 @@ func TestHelloHandshake(t *testing.T) {
 	aPeerMgr, err := mockPeerMgr(ctx, t, a)
 	require.NoError(t, err)
 
-	discovery.NewHelloProtocolHandler(a, aPeerMgr, nil, store, mstore, genesisA.Blocks()[0].Cid(), time.Second*30).Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
-	discovery.NewHelloProtocolHandler(b, aPeerMgr, nil, store, mstore, genesisA.Blocks()[0].Cid(), time.Second*30).Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(a, aPeerMgr, nil, store, mstore, genesisA.Blocks()[0].Cid(), time.Second*30, nil).Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(b, aPeerMgr, nil, store, mstore, genesisA.Blocks()[0].Cid(), time.Second*30, nil).Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
 
 	msc1.On("HelloCallback", b.ID(), heavy2.Key()).Return()
 	msc2.On("HelloCallback", a.ID(), heavy1.Key()).Return()
@@ -130,8 +130,8 @@ func TestHelloBadGenesis(t *testing.T) {
 	peerMgr, err := mockPeerMgr(ctx, t, a)
 	require.NoError(t, err)
 
-	discovery.NewHelloProtocolHandler(a, peerMgr, nil, store, mstore, genesisA.Blocks()[0].Cid(), time.Second*30).Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
-	discovery.NewHelloProtocolHandler(b, peerMgr, nil, store, mstore, genesisB.Blocks()[0].Cid(), time.Second*30).Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(a, peerMgr, nil, store, mstore, genesisA.Blocks()[0].Cid(), time.Second*30, nil).Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(b, peerMgr, nil, store, mstore, genesisB.Blocks()[0].Cid(), time.Second*30, nil).Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
 
 	msc1.On("HelloCallback", mock.Anything, mock.Anything, mock.Anything).Return()
 	msc2.On("HelloCallback", mock.Anything, mock.Anything, mock.Anything).Return()
@@ -175,8 +175,8 @@ func TestHelloMultiBlock(t *testing.T) {
 	peerMgr, err := mockPeerMgr(ctx, t, a)
 	require.NoError(t, err)
 
-	discovery.NewHelloProtocolHandler(a, peerMgr, nil, store, mstore, genesisTipset.At(0).Cid(), time.Second*30).Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
-	discovery.NewHelloProtocolHandler(b, peerMgr, nil, store, mstore, genesisTipset.At(0).Cid(), time.Second*30).Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(a, peerMgr, nil, store, mstore, genesisTipset.At(0).Cid(), time.Second*30, nil).Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(b, peerMgr, nil, store, mstore, genesisTipset.At(0).Cid(), time.Second*30, nil).Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
 
 	msc1.On("HelloCallback", b.ID(), heavy2.Key()).Return()
 	msc2.On("HelloCallback", a.ID(), heavy1.Key()).Return()
@@ -24,12 +24,16 @@ type (
 
 type heightHandler struct {
 	confidence int
+	height     abi.ChainEpoch
 	called     bool
 
 	handle HeightHandler
 	revert RevertHandler
 }
 
+// GCConfidence is the depth past which the events no longer revert a tipset.
+const GCConfidence = 2 * constants.ForkLengthThreshold
+
 type Events struct {
 	api IEvent
 
@@ -44,7 +48,7 @@ type Events struct {
 }
 
 func NewEvents(ctx context.Context, api IEvent) *Events {
-	gcConfidence := 2 * constants.ForkLengthThreshold
+	gcConfidence := GCConfidence
 
 	tsc := newTSCache(gcConfidence, api)
 
@@ -177,7 +177,10 @@ func (e *hcEvents) handleReverts(ts *types.TipSet) {
 				continue // event wasn't apply()-ied yet
 			}
 
-			trigger := e.triggers[event.trigger]
+			trigger, ok := e.triggers[event.trigger]
+			if !ok {
+				continue // the trigger was removed
+			}
 
 			if err := trigger.revert(e.ctx, ts); err != nil {
 				log.Errorf("reverting chain trigger (@H %d, triggered @ %d) failed: %s", ts.Height(), triggerH, err)
@@ -235,8 +238,8 @@ func (e *hcEvents) applyWithConfidence(ts *types.TipSet, height abi.ChainEpoch)
 				continue
 			}
 
-			trigger := e.triggers[event.trigger]
-			if trigger.disabled {
+			trigger, ok := e.triggers[event.trigger]
+			if !ok || trigger.disabled {
 				continue
 			}
 
@@ -348,10 +351,50 @@ func (e *hcEvents) onHeadChanged(check CheckFunc, hnd EventHandler, rev RevertHa
 	return id, nil
 }
 
+// Stop listening for an event, the trigger and its matchers are dropped and its
+// queued events are no longer handled or reverted
+func (e *hcEvents) removeTrigger(id triggerID) {
+	e.lk.Lock()
+	defer e.lk.Unlock()
+
+	trigger, ok := e.triggers[id]
+	if !ok {
+		return
+	}
+	delete(e.triggers, id)
+
+	if touts, ok := e.timeouts[trigger.timeout]; ok {
+		delete(touts, id)
+		if len(touts) == 0 {
+			delete(e.timeouts, trigger.timeout)
+		}
+	}
+
+	e.messageEvents.lk.Lock()
+	delete(e.messageEvents.matchers, id)
+	e.messageEvents.lk.Unlock()
+
+	e.watcherEvents.lk.Lock()
+	delete(e.watcherEvents.matchers, id)
+	e.watcherEvents.lk.Unlock()
+}
+
 // headChangeAPI is used to allow the composed event APIs to call back to hcEvents
 // to listen for changes
 type headChangeAPI interface {
 	onHeadChanged(check CheckFunc, hnd EventHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch) (triggerID, error)
+	removeTrigger(id triggerID)
+}
+
+// removeWhenDone removes the trigger `id` once `ctx` is done, unless the events stop first.
+func removeWhenDone(ctx, eventsCtx context.Context, hcAPI headChangeAPI, id triggerID) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			hcAPI.removeTrigger(id)
+		case <-eventsCtx.Done():
+		}
+	}()
 }
 
 // watcherEvents watches for a state change
@@ -435,6 +478,22 @@ type StateMatchFunc func(oldTs, newTs *types.TipSet) (bool, StateChange, error)
 //   the state change is queued up until the confidence interval has elapsed (and
 //   `StateChangeHandler` is called)
 func (we *watcherEvents) StateChanged(check CheckFunc, scHnd StateChangeHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch, mf StateMatchFunc) error {
+	_, err := we.stateChanged(check, scHnd, rev, confidence, timeout, mf)
+	return err
+}
+
+// StateChangedUntil is StateChanged, with the handlers and the matcher removed once `ctx`
+// is done.
+func (we *watcherEvents) StateChangedUntil(ctx context.Context, check CheckFunc, scHnd StateChangeHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch, mf StateMatchFunc) error {
+	id, err := we.stateChanged(check, scHnd, rev, confidence, timeout, mf)
+	if err != nil {
+		return err
+	}
+	removeWhenDone(ctx, we.ctx, we.hcAPI, id)
+	return nil
+}
+
+func (we *watcherEvents) stateChanged(check CheckFunc, scHnd StateChangeHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch, mf StateMatchFunc) (triggerID, error) {
 	hnd := func(data eventData, prevTs, ts *types.TipSet, height abi.ChainEpoch) (bool, error) {
 		states, ok := data.(StateChange)
 		if data != nil && !ok {
@@ -446,14 +505,14 @@ func (we *watcherEvents) StateChanged(check CheckFunc, scHnd StateChangeHandler,
 
 	id, err := we.hcAPI.onHeadChanged(check, hnd, rev, confidence, timeout)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	we.lk.Lock()
 	defer we.lk.Unlock()
 	we.matchers[id] = mf
 
-	return nil
+	return id, nil
 }
 
 // messageEvents watches for message calls to actors
@@ -583,6 +642,21 @@ type MsgMatchFunc func(msg *types.UnsignedMessage) (matched bool, err error)
 //   message is queued up until the confidence interval has elapsed (and
 //   `MsgHandler` is called)
 func (me *messageEvents) Called(check CheckFunc, msgHnd MsgHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch, mf MsgMatchFunc) error {
+	_, err := me.called(check, msgHnd, rev, confidence, timeout, mf)
+	return err
+}
+
+// CalledUntil is Called, with the handlers and the matcher removed once `ctx` is done.
+func (me *messageEvents) CalledUntil(ctx context.Context, check CheckFunc, msgHnd MsgHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch, mf MsgMatchFunc) error {
+	id, err := me.called(check, msgHnd, rev, confidence, timeout, mf)
+	if err != nil {
+		return err
+	}
+	removeWhenDone(ctx, me.ctx, me.hcAPI, id)
+	return nil
+}
+
+func (me *messageEvents) called(check CheckFunc, msgHnd MsgHandler, rev RevertHandler, confidence int, timeout abi.ChainEpoch, mf MsgMatchFunc) (triggerID, error) {
 	hnd := func(data eventData, prevTs, ts *types.TipSet, height abi.ChainEpoch) (bool, error) {
 		msg, ok := data.(*types.UnsignedMessage)
 		if data != nil && !ok {
@@ -601,14 +675,14 @@ func (me *messageEvents) Called(check CheckFunc, msgHnd MsgHandler, rev RevertHa
 
 	id, err := me.hcAPI.onHeadChanged(check, hnd, rev, confidence, timeout)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	me.lk.Lock()
 	defer me.lk.Unlock()
 	me.matchers[id] = mf
 
-	return nil
+	return id, nil
 }
 
 // Convenience function for checking and matching messages
@@ -41,13 +41,18 @@ func (e *heightEvents) headChangeAt(rev, app []*types.TipSet) error {
 
 		revert := func(h abi.ChainEpoch, ts *types.TipSet) {
 			for _, tid := range e.htHeights[h] {
+				hnd, ok := e.heightTriggers[tid]
+				if !ok {
+					continue // removed while the lock was released
+				}
+
 				ctx, span := trace.StartSpan(ctx, "events.HeightRevert")
 
-				rev := e.heightTriggers[tid].revert
+				rev := hnd.revert
 				e.lk.Unlock()
 				err := rev(ctx, ts)
 				e.lk.Lock()
-				e.heightTriggers[tid].called = false
+				hnd.called = false
 
 				span.End()
 
@@ -89,7 +94,10 @@ func (e *heightEvents) headChangeAt(rev, app []*types.TipSet) error {
 
 		apply := func(h abi.ChainEpoch, ts *types.TipSet) error {
 			for _, tid := range e.htTriggerHeights[h] {
-				hnd := e.heightTriggers[tid]
+				hnd, ok := e.heightTriggers[tid]
+				if !ok {
+					continue // removed while the lock was released
+				}
 				if hnd.called {
 					return nil
 				}
@@ -149,12 +157,37 @@ func (e *heightEvents) headChangeAt(rev, app []*types.TipSet) error {
 //
 // ts passed to handlers is the tipset at the specified, or above, if lower tipsets were null
 func (e *heightEvents) ChainAt(hnd HeightHandler, rev RevertHandler, confidence int, h abi.ChainEpoch) error {
+	_, _, err := e.chainAt(hnd, rev, confidence, h)
+	return err
+}
+
+// ChainAtUntil is ChainAt, with the handlers removed once `ctx` is done. It returns false when
+// the height is final already, `hnd` is then called once and no handler is kept.
+func (e *heightEvents) ChainAtUntil(ctx context.Context, hnd HeightHandler, rev RevertHandler, confidence int, h abi.ChainEpoch) (bool, error) {
+	id, ok, err := e.chainAt(hnd, rev, confidence, h)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			e.removeHeightTrigger(id)
+		case <-e.ctx.Done():
+		}
+	}()
+	return true, nil
+}
+
+// chainAt returns the id of the trigger, `ok` is false if no trigger was registered as the
+// height is already final.
+func (e *heightEvents) chainAt(hnd HeightHandler, rev RevertHandler, confidence int, h abi.ChainEpoch) (id triggerID, ok bool, err error) {
 	e.lk.Lock() // Tricky locking, check your locks if you modify this function!
 
 	best, err := e.tsc.best()
 	if err != nil {
 		e.lk.Unlock()
-		return xerrors.Errorf("error getting best tipset: %w", err)
+		return 0, false, xerrors.Errorf("error getting best tipset: %w", err)
 	}
 
 	bestH := best.Height()
@@ -172,14 +205,14 @@ func (e *heightEvents) ChainAt(hnd HeightHandler, rev RevertHandler, confidence
 		span.End()
 
 		if err != nil {
-			return err
+			return 0, false, err
 		}
 
 		e.lk.Lock()
 		best, err = e.tsc.best()
 		if err != nil {
 			e.lk.Unlock()
-			return xerrors.Errorf("error getting best tipset: %w", err)
+			return 0, false, xerrors.Errorf("error getting best tipset: %w", err)
 		}
 		bestH = best.Height()
 	}
@@ -187,16 +220,17 @@ func (e *heightEvents) ChainAt(hnd HeightHandler, rev RevertHandler, confidence
 	defer e.lk.Unlock()
 
 	if bestH >= h+abi.ChainEpoch(confidence)+e.gcConfidence {
-		return nil
+		return 0, false, nil
 	}
 
 	triggerAt := h + abi.ChainEpoch(confidence)
 
-	id := e.ctr
+	id = e.ctr
 	e.ctr++
 
 	e.heightTriggers[id] = &heightHandler{
 		confidence: confidence,
+		height:     h,
 
 		handle: hnd,
 		revert: rev,
@@ -205,5 +239,36 @@ func (e *heightEvents) ChainAt(hnd HeightHandler, rev RevertHandler, confidence
 	e.htHeights[h] = append(e.htHeights[h], id)
 	e.htTriggerHeights[triggerAt] = append(e.htTriggerHeights[triggerAt], id)
 
-	return nil
+	return id, true, nil
+}
+
+// removeHeightTrigger drops the trigger `id`, its handlers are no longer called.
+func (e *heightEvents) removeHeightTrigger(id triggerID) {
+	e.lk.Lock()
+	defer e.lk.Unlock()
+
+	hnd, ok := e.heightTriggers[id]
+	if !ok {
+		return
+	}
+	delete(e.heightTriggers, id)
+
+	removeID(e.htHeights, hnd.height, id)
+	removeID(e.htTriggerHeights, hnd.height+abi.ChainEpoch(hnd.confidence), id)
+}
+
+// removeID removes `id` from the triggers at `h`, into a new slice as the head change
+// handlers may be ranging over the old one.
+func removeID(triggers map[abi.ChainEpoch][]triggerID, h abi.ChainEpoch, id triggerID) {
+	var left []triggerID
+	for _, tid := range triggers[h] {
+		if tid != id {
+			left = append(left, tid)
+		}
+	}
+	if len(left) == 0 {
+		delete(triggers, h)
+		return
+	}
+	triggers[h] = left
 }
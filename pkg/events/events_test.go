@@ -12,6 +12,7 @@ import (
 
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/multiformats/go-multihash"
@@ -1403,3 +1404,87 @@ func TestCalledMultiplePerEpoch(t *testing.T) {
 
 	fcs.advance(9, 1, nil)
 }
+
+func TestRemovedWhenDone(t *testing.T) {
+	tf.UnitTest(t)
+	fcs := &fakeCS{
+		t: t,
+		h: 1,
+
+		msgs:    map[cid.Cid]fakeMsg{},
+		blkMsgs: map[cid.Cid]cid.Cid{},
+		tsc:     newTSCache(2*constants.ForkLengthThreshold, nil),
+	}
+	require.NoError(t, fcs.tsc.add(fcs.makeTS(t, types.EmptyTSK, 1, dummyCid)))
+
+	events := NewEvents(context.Background(), fcs)
+
+	t0123, err := address.NewFromString("t0123")
+	require.NoError(t, err)
+
+	var called, changed, at bool
+	var matched int
+	check := func(ts *types.TipSet) (d bool, m bool, e error) {
+		return false, true, nil
+	}
+	rev := func(_ context.Context, ts *types.TipSet) error {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = events.CalledUntil(ctx, check, func(msg *types.UnsignedMessage, rec *types.MessageReceipt, ts *types.TipSet, curH abi.ChainEpoch) (bool, error) {
+		called = true
+		return true, nil
+	}, rev, 1, NoTimeout, matchAddrMethod(t0123, 5))
+	require.NoError(t, err)
+	err = events.StateChangedUntil(ctx, check, func(oldTs, newTs *types.TipSet, data StateChange, curH abi.ChainEpoch) (bool, error) {
+		changed = true
+		return true, nil
+	}, rev, 1, NoTimeout, func(oldTs, newTs *types.TipSet) (bool, StateChange, error) {
+		matched++
+		return true, testStateChange{from: "a", to: "b"}, nil
+	})
+	require.NoError(t, err)
+	watching, err := events.ChainAtUntil(ctx, func(_ context.Context, ts *types.TipSet, curH abi.ChainEpoch) error {
+		at = true
+		return nil
+	}, rev, 1, 5)
+	require.NoError(t, err)
+	require.True(t, watching)
+
+	// a state change of the tipset at H=2 is queued for confidence
+	fcs.advance(0, 1, nil) // H=2
+	require.Equal(t, 1, matched)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		events.hcEvents.lk.Lock()
+		defer events.hcEvents.lk.Unlock()
+		events.heightEvents.lk.Lock()
+		defer events.heightEvents.lk.Unlock()
+		return len(events.hcEvents.triggers) == 0 && len(events.heightEvents.heightTriggers) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	events.messageEvents.lk.RLock()
+	require.Empty(t, events.messageEvents.matchers)
+	events.messageEvents.lk.RUnlock()
+	events.watcherEvents.lk.RLock()
+	require.Empty(t, events.watcherEvents.matchers)
+	events.watcherEvents.lk.RUnlock()
+	require.Empty(t, events.heightEvents.htHeights)
+	require.Empty(t, events.heightEvents.htTriggerHeights)
+
+	// neither the queued change nor the new message and height reach the handlers
+	fcs.advance(0, 5, map[int]cid.Cid{
+		0: fcs.fakeMsgs(fakeMsg{
+			bmsgs: []*types.UnsignedMessage{
+				{To: t0123, From: t0123, Method: 5, Nonce: 1},
+			},
+		}),
+	}) // H=7
+	fcs.advance(2, 2, nil) // H=7
+	require.Equal(t, 1, matched)
+	require.False(t, called)
+	require.False(t, changed)
+	require.False(t, at)
+}
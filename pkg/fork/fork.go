@@ -111,6 +111,8 @@ type PreMigration struct {
 }
 
 type Upgrade struct {
+	// Name identifies the upgrade, it is the name of its height in the fork upgrade config.
+	Name      string
 	Height    abi.ChainEpoch
 	Network   network.Version
 	Expensive bool
@@ -141,53 +143,76 @@ func (ml migrationLogger) Log(level rt.LogLevel, msg string, args ...interface{}
 
 func defaultUpgradeSchedule(cf *ChainFork, upgradeHeight *config.ForkUpgradeConfig) UpgradeSchedule {
 	var us UpgradeSchedule
+	for _, u := range allUpgrades(cf, upgradeHeight) {
+		if u.Height < 0 {
+			// upgrade disabled
+			continue
+		}
+		us = append(us, u)
+	}
+	return us
+}
 
-	updates := []Upgrade{{
+// allUpgrades returns every known upgrade, including the disabled ones.
+func allUpgrades(cf *ChainFork, upgradeHeight *config.ForkUpgradeConfig) []Upgrade {
+	return []Upgrade{{
+		Name:      "breeze",
 		Height:    upgradeHeight.UpgradeBreezeHeight,
 		Network:   network.Version1,
 		Migration: cf.UpgradeFaucetBurnRecovery,
 	}, {
+		Name:      "smoke",
 		Height:    upgradeHeight.UpgradeSmokeHeight,
 		Network:   network.Version2,
 		Migration: nil,
 	}, {
+		Name:      "ignition",
 		Height:    upgradeHeight.UpgradeIgnitionHeight,
 		Network:   network.Version3,
 		Migration: cf.UpgradeIgnition,
 	}, {
+		Name:      "refuel",
 		Height:    upgradeHeight.UpgradeRefuelHeight,
 		Network:   network.Version3,
 		Migration: cf.UpgradeRefuel,
 	}, {
+		Name:      "assembly",
 		Height:    upgradeHeight.UpgradeAssemblyHeight,
 		Network:   network.Version4,
 		Expensive: true,
 		Migration: cf.UpgradeActorsV2,
 	}, {
+		Name:      "tape",
 		Height:    upgradeHeight.UpgradeTapeHeight,
 		Network:   network.Version5,
 		Migration: nil,
 	}, {
+		Name:      "liftoff",
 		Height:    upgradeHeight.UpgradeLiftoffHeight,
 		Network:   network.Version5,
 		Migration: cf.UpgradeLiftoff,
 	}, {
+		Name:      "kumquat",
 		Height:    upgradeHeight.UpgradeKumquatHeight,
 		Network:   network.Version6,
 		Migration: nil,
 	}, {
+		Name:      "calico",
 		Height:    upgradeHeight.UpgradeCalicoHeight,
 		Network:   network.Version7,
 		Migration: cf.UpgradeCalico,
 	}, {
+		Name:      "persian",
 		Height:    upgradeHeight.UpgradePersianHeight,
 		Network:   network.Version8,
 		Migration: nil,
 	}, {
+		Name:      "orange",
 		Height:    upgradeHeight.UpgradeOrangeHeight,
 		Network:   network.Version9,
 		Migration: nil,
 	}, {
+		Name:      "trust",
 		Height:    upgradeHeight.UpgradeTrustHeight,
 		Network:   network.Version10,
 		Migration: cf.UpgradeActorsV3,
@@ -204,10 +229,12 @@ func defaultUpgradeSchedule(cf *ChainFork, upgradeHeight *config.ForkUpgradeConf
 		}},
 		Expensive: true,
 	}, {
+		Name:      "norwegian",
 		Height:    upgradeHeight.UpgradeNorwegianHeight,
 		Network:   network.Version11,
 		Migration: nil,
 	}, {
+		Name:      "turbo",
 		Height:    upgradeHeight.UpgradeTurboHeight,
 		Network:   network.Version12,
 		Migration: cf.UpgradeActorsV4,
@@ -224,6 +251,7 @@ func defaultUpgradeSchedule(cf *ChainFork, upgradeHeight *config.ForkUpgradeConf
 		}},
 		Expensive: true,
 	}, {
+		Name:      "hyperdrive",
 		Height:    upgradeHeight.UpgradeHyperdriveHeight,
 		Network:   network.Version13,
 		Migration: cf.UpgradeActorsV5,
@@ -239,15 +267,6 @@ func defaultUpgradeSchedule(cf *ChainFork, upgradeHeight *config.ForkUpgradeConf
 			StopWithin:      5,
 		}},
 		Expensive: true}}
-
-	for _, u := range updates {
-		if u.Height < 0 {
-			// upgrade disabled
-			continue
-		}
-		us = append(us, u)
-	}
-	return us
 }
 
 func (us UpgradeSchedule) Validate() error {
@@ -352,6 +371,9 @@ type ChainFork struct {
 	// ErrExpensiveFork.
 	expensiveUpgrades map[abi.ChainEpoch]struct{}
 
+	// All the known upgrades by name, for the migration rehearsals.
+	upgrades map[string]Upgrade
+
 	// upgrade param
 	networkType int
 	forkUpgrade *config.ForkUpgradeConfig
@@ -407,6 +429,11 @@ func NewChainFork(ctx context.Context, cr chainReader, ipldstore cbor.IpldStore,
 	fork.stateMigrations = stateMigrations
 	fork.expensiveUpgrades = expensiveUpgrades
 
+	fork.upgrades = make(map[string]Upgrade)
+	for _, upgrade := range allUpgrades(fork, networkParams.ForkUpgradeParam) {
+		fork.upgrades[upgrade.Name] = upgrade
+	}
+
 	return fork, nil
 }
 
@@ -0,0 +1,180 @@
+package fork
+
+import (
+	"context"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/filecoin-project/specs-actors/v3/actors/migration/nv10"
+	"github.com/ipfs/go-cid"
+	blockstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/types"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+// memSamplePeriod is how often the heap is sampled while a migration rehearsal runs.
+const memSamplePeriod = time.Second
+
+// the runtime metrics read by memSampler, reading them does not stop the world.
+const (
+	heapAllocsMetric  = "/gc/heap/allocs:bytes"
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+)
+
+// MigrationResult is the outcome of a migration rehearsal, see RehearseMigration.
+type MigrationResult struct {
+	Upgrade string
+	Network network.Version
+	// Height is the epoch given to the migration, the height of the tipset.
+	Height  abi.ChainEpoch
+	OldRoot cid.Cid
+	NewRoot cid.Cid
+
+	// PreMigrationDuration is zero when the pre-migrations were not run.
+	PreMigrationDuration time.Duration
+	Duration             time.Duration
+
+	// AllocatedBytes is the number of bytes allocated on the heap by the process while the
+	// pre-migrations and the migration ran, and PeakHeapBytes the highest heap size sampled
+	// meanwhile.
+	AllocatedBytes uint64
+	PeakHeapBytes  uint64
+}
+
+// RehearseMigration applies the migration of the upgrade `name` to the parent state of `ts`,
+// as if the upgrade was at the height of `ts`, and reports how long it took and how much
+// memory it used. When `preMigrate` is set the pre-migrations of the upgrade fill the
+// migration cache first, otherwise the migration starts with an empty cache.
+//
+// The state is read from `bs` but the migrated state is written to a buffer in memory, which
+// is dropped with the result. The memory reported is that of the process, the rehearsal is
+// meant to run on its own, on the repo of a stopped node.
+func RehearseMigration(ctx context.Context, cr chainReader, bs blockstore.Blockstore, networkParams *config.NetworkParamsConfig, name string, ts *types.TipSet, preMigrate bool) (*MigrationResult, error) {
+	buf := blockstoreutil.NewTieredBstore(bs, blockstoreutil.NewTemporarySync())
+	c, err := NewChainFork(ctx, cr, cbor.NewCborStore(buf), buf, networkParams)
+	if err != nil {
+		return nil, err
+	}
+	return c.runMigration(ctx, name, ts, preMigrate)
+}
+
+// runMigration runs the migration of the upgrade `name` on the blockstore of the fork, see
+// RehearseMigration. The cache of the scheduled upgrade is not updated.
+func (c *ChainFork) runMigration(ctx context.Context, name string, ts *types.TipSet, preMigrate bool) (*MigrationResult, error) {
+	u, ok := c.upgrades[name]
+	if !ok {
+		return nil, xerrors.Errorf("unknown upgrade %s, expected one of %s", name, strings.Join(c.upgradeNames(), ", "))
+	}
+	if u.Migration == nil {
+		return nil, xerrors.Errorf("upgrade %s has no state migration", name)
+	}
+
+	res := &MigrationResult{
+		Upgrade: u.Name,
+		Network: u.Network,
+		Height:  ts.Height(),
+		OldRoot: ts.Blocks()[0].ParentStateRoot,
+	}
+
+	sampler := startMemSampler()
+	cache := nv10.NewMemMigrationCache()
+	if preMigrate {
+		start := time.Now()
+		for _, prem := range u.PreMigrations {
+			if err := prem.PreMigration(ctx, cache, res.OldRoot, res.Height, ts); err != nil {
+				sampler.stop()
+				return nil, xerrors.Errorf("pre-migration of upgrade %s failed: %v", name, err)
+			}
+		}
+		res.PreMigrationDuration = time.Since(start)
+	}
+
+	start := time.Now()
+	log.Warnw("STARTING migration rehearsal", "upgrade", name, "height", res.Height, "from", res.OldRoot)
+	newRoot, err := u.Migration(ctx, cache, res.OldRoot, res.Height, ts)
+	res.Duration = time.Since(start)
+	res.AllocatedBytes, res.PeakHeapBytes = sampler.stop()
+	if err != nil {
+		return nil, xerrors.Errorf("migration of upgrade %s failed: %v", name, err)
+	}
+	res.NewRoot = newRoot
+	log.Warnw("COMPLETED migration rehearsal", "upgrade", name, "from", res.OldRoot, "to", newRoot, "duration", res.Duration)
+
+	return res, nil
+}
+
+func (c *ChainFork) upgradeNames() []string {
+	var names []string
+	for name, u := range c.upgrades {
+		if u.Migration != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// memSampler samples the heap size until it is stopped.
+type memSampler struct {
+	startAlloc uint64
+	peak       uint64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func startMemSampler() *memSampler {
+	s := &memSampler{done: make(chan struct{})}
+	s.startAlloc = s.sample()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(memSamplePeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.sample()
+			}
+		}
+	}()
+	return s
+}
+
+// sample records the heap size and returns the number of bytes allocated so far, the metrics
+// the runtime does not support are read as zero.
+func (s *memSampler) sample() uint64 {
+	samples := []metrics.Sample{{Name: heapAllocsMetric}, {Name: heapObjectsMetric}}
+	metrics.Read(samples)
+	if heap := sampleUint64(samples[1]); heap > s.peak {
+		s.peak = heap
+	}
+	return sampleUint64(samples[0])
+}
+
+func sampleUint64(sample metrics.Sample) uint64 {
+	if sample.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample.Value.Uint64()
+}
+
+// stop stops the sampling and returns the number of bytes allocated since the start and
+// the peak heap size.
+func (s *memSampler) stop() (uint64, uint64) {
+	close(s.done)
+	s.wg.Wait()
+	return s.sample() - s.startAlloc, s.peak
+}
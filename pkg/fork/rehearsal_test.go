@@ -0,0 +1,100 @@
+package fork
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestRehearseMigration(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	oldRoot := types.CidFromString(t, "old-root")
+	cached := types.CidFromString(t, "cached")
+	ts := types.RequireNewTipSet(t, &types.BlockHeader{
+		Miner:                 types.RequireIDAddress(t, 1),
+		Ticket:                types.Ticket{VRFProof: []byte{1}},
+		ElectionProof:         &types.ElectionProof{VRFProof: []byte{1}},
+		Height:                100,
+		ParentWeight:          big.Zero(),
+		ParentStateRoot:       oldRoot,
+		ParentMessageReceipts: oldRoot,
+		Messages:              oldRoot,
+		ParentBaseFee:         big.Zero(),
+		BLSAggregate:          &crypto.Signature{Type: crypto.SigTypeBLS},
+		BlockSig:              &crypto.Signature{Type: crypto.SigTypeBLS},
+	})
+
+	// the migration returns the root cached by the pre-migration, if any
+	var scratch []byte
+	var migratedFrom cid.Cid
+	var migratedAt abi.ChainEpoch
+	fork := &ChainFork{upgrades: map[string]Upgrade{
+		"test": {
+			Name:    "test",
+			Network: network.Version10,
+			PreMigrations: []PreMigration{{
+				PreMigration: func(ctx context.Context, cache MigrationCache, root cid.Cid, height abi.ChainEpoch, ts *types.TipSet) error {
+					// a large allocation is counted by the runtime metrics right away
+					scratch = make([]byte, 1<<20)
+					return cache.Write("root", cached)
+				},
+			}},
+			Migration: func(ctx context.Context, cache MigrationCache, root cid.Cid, height abi.ChainEpoch, ts *types.TipSet) (cid.Cid, error) {
+				migratedFrom, migratedAt = root, height
+				found, c, err := cache.Read("root")
+				if err != nil || !found {
+					return types.CidFromString(t, "migrated"), err
+				}
+				return c, nil
+			},
+		},
+		"failing": {
+			Name: "failing",
+			Migration: func(context.Context, MigrationCache, cid.Cid, abi.ChainEpoch, *types.TipSet) (cid.Cid, error) {
+				return cid.Undef, xerrors.New("boom")
+			},
+		},
+		"no-migration": {Name: "no-migration"},
+	}}
+
+	res, err := fork.runMigration(ctx, "test", ts, false)
+	require.NoError(t, err)
+	assert.Equal(t, "test", res.Upgrade)
+	assert.Equal(t, network.Version10, res.Network)
+	assert.Equal(t, abi.ChainEpoch(100), res.Height)
+	assert.Equal(t, oldRoot, res.OldRoot)
+	assert.Equal(t, types.CidFromString(t, "migrated"), res.NewRoot)
+	assert.Zero(t, res.PreMigrationDuration)
+	assert.Equal(t, oldRoot, migratedFrom)
+	assert.Equal(t, abi.ChainEpoch(100), migratedAt)
+
+	t.Log("the pre-migrations fill the cache of the migration")
+	res, err = fork.runMigration(ctx, "test", ts, true)
+	require.NoError(t, err)
+	assert.Equal(t, cached, res.NewRoot)
+	assert.NotZero(t, res.PreMigrationDuration)
+	assert.NotZero(t, res.AllocatedBytes)
+	assert.NotZero(t, res.PeakHeapBytes)
+	assert.Len(t, scratch, 1<<20)
+
+	_, err = fork.runMigration(ctx, "failing", ts, false)
+	assert.Error(t, err)
+	_, err = fork.runMigration(ctx, "no-migration", ts, false)
+	assert.Error(t, err)
+	_, err = fork.runMigration(ctx, "unknown", ts, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failing, test")
+}
@@ -509,7 +509,7 @@ func MakeGenesisBlock(ctx context.Context, rep repo.Repo, bs blockstore.Blocksto
 	}
 
 	// temp chainstore
-	cs := chain.NewStore(rep.ChainDatastore(), cbor.NewCborStore(bs), bs, para, cid.Undef)
+	cs := chain.NewStore(rep.ChainDatastore(), cbor.NewCborStore(bs), bs, para, cid.Undef, nil)
 
 	// Verify PreSealed Data
 	stateroot, err = VerifyPreSealedData(ctx, cs, stateroot, template, keyIDs, para)
@@ -0,0 +1,34 @@
+package journal
+
+import (
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/venus/pkg/config"
+)
+
+// envDisabledEvents is the environment variable through which disabled
+// journal events can be customized.
+const envDisabledEvents = "VENUS_JOURNAL_DISABLED_EVENTS"
+
+func EnvDisabledEvents() DisabledEvents {
+	if env, ok := os.LookupEnv(envDisabledEvents); ok {
+		if ret, err := ParseDisabledEvents(env); err == nil {
+			return ret
+		}
+	}
+	// fallback if env variable is not set, or if it failed to parse.
+	return DefaultDisabledEvents
+}
+
+// configDisabledEvents returns the events disabled by the environment variable if
+// it is set, by the config otherwise.
+func configDisabledEvents(cfg *config.JournalConfig) (DisabledEvents, error) {
+	if _, ok := os.LookupEnv(envDisabledEvents); ok || cfg.DisabledEvents == nil {
+		return EnvDisabledEvents(), nil
+	}
+	if len(cfg.DisabledEvents) == 0 {
+		return DisabledEvents{}, nil
+	}
+	return ParseDisabledEvents(strings.Join(cfg.DisabledEvents, ","))
+}
@@ -0,0 +1,205 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+)
+
+const (
+	journalFilePrefix = "venus-journal-"
+	journalFileSuffix = ".ndjson"
+	// journalFileTime is the layout of the time a journal file was opened at, in its name.
+	// Times are formatted in UTC so that the names sort chronologically.
+	journalFileTime = "20060102T150405.000000000Z"
+
+	// DefaultMaxFileSize is the size after which a journal file is rolled when none is configured.
+	DefaultMaxFileSize = 1 << 30
+)
+
+// FileSink writes the events as ndjson to files in a directory. The file is rolled
+// once it reaches its size limit, and the oldest files are removed so that at most
+// `maxFiles` are kept.
+type FileSink struct {
+	dir       string
+	sizeLimit int64
+	maxFiles  int
+
+	fi    *os.File
+	fSize int64
+}
+
+var _ Sink = (*FileSink)(nil)
+var _ Querier = (*FileSink)(nil)
+
+// OpenFileSink opens a file sink writing to `dir`. A non positive `sizeLimit`
+// defaults to DefaultMaxFileSize, a non positive `maxFiles` keeps all the files.
+func OpenFileSink(dir string, sizeLimit int64, maxFiles int) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to mk directory %s for file journal: %w", dir, err)
+	}
+	if sizeLimit <= 0 {
+		sizeLimit = DefaultMaxFileSize
+	}
+
+	f := &FileSink{
+		dir:       dir,
+		sizeLimit: sizeLimit,
+		maxFiles:  maxFiles,
+	}
+	if err := f.rollJournalFile(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends the event to the current journal file.
+func (f *FileSink) Write(evt *Event) error {
+	b, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	n, err := f.fi.Write(append(b, '\n'))
+	if err != nil {
+		return err
+	}
+
+	f.fSize += int64(n)
+
+	if f.fSize >= f.sizeLimit {
+		return f.rollJournalFile()
+	}
+
+	return nil
+}
+
+// Close closes the current journal file.
+func (f *FileSink) Close() error {
+	return f.fi.Close()
+}
+
+func (f *FileSink) rollJournalFile() error {
+	if f.fi != nil {
+		_ = f.fi.Close()
+	}
+
+	name := journalFilePrefix + constants.Clock.Now().UTC().Format(journalFileTime) + journalFileSuffix
+	nfi, err := os.OpenFile(filepath.Join(f.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to open journal file: %w", err)
+	}
+
+	f.fi = nfi
+	f.fSize = 0
+
+	if f.maxFiles > 0 {
+		files, err := journalFiles(f.dir)
+		if err != nil {
+			return err
+		}
+		for len(files) > f.maxFiles {
+			if err := os.Remove(filepath.Join(f.dir, files[0].name)); err != nil {
+				log.Warnf("failed to remove old journal file %s: %s", files[0].name, err)
+			}
+			files = files[1:]
+		}
+	}
+	return nil
+}
+
+// Query reads the events back from the journal files of the directory.
+func (f *FileSink) Query(system, event string, since time.Time) ([]*Event, error) {
+	return QueryDir(f.dir, system, event, since)
+}
+
+type journalFile struct {
+	name   string
+	opened time.Time
+}
+
+// journalFiles lists the journal files of `dir`, oldest first.
+func journalFiles(dir string) ([]journalFile, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []journalFile
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, journalFilePrefix) || !strings.HasSuffix(name, journalFileSuffix) {
+			continue
+		}
+		opened, err := time.Parse(journalFileTime, strings.TrimSuffix(strings.TrimPrefix(name, journalFilePrefix), journalFileSuffix))
+		if err != nil {
+			continue
+		}
+		files = append(files, journalFile{name: name, opened: opened})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].opened.Before(files[j].opened)
+	})
+	return files, nil
+}
+
+// QueryDir reads back the events of the journal files in `dir`, see Journal.Query.
+func QueryDir(dir string, system, event string, since time.Time) ([]*Event, error) {
+	files, err := journalFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Event
+	for i, file := range files {
+		// the events of a file were all recorded before the next file was opened
+		if i+1 < len(files) && !files[i+1].opened.After(since) {
+			continue
+		}
+
+		out, err = queryFile(filepath.Join(dir, file.name), system, event, since, out)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) >= MaxQueryEvents {
+			return out[:MaxQueryEvents], nil
+		}
+	}
+	return out, nil
+}
+
+func queryFile(path string, system, event string, since time.Time, out []*Event) ([]*Event, error) {
+	fi, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close() // nolint: errcheck
+
+	scanner := bufio.NewScanner(fi)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	for scanner.Scan() {
+		evt := &Event{}
+		if err := json.Unmarshal(scanner.Bytes(), evt); err != nil {
+			// the last line may be partially written
+			log.Debugf("skip malformed journal entry in %s: %s", path, err)
+			continue
+		}
+		if (system != "" && evt.System != system) || (event != "" && evt.Event != event) || evt.Timestamp.Before(since) {
+			continue
+		}
+		out = append(out, evt)
+		if len(out) >= MaxQueryEvents {
+			break
+		}
+	}
+	return out, scanner.Err()
+}
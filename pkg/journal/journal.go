@@ -1,14 +1,259 @@
 package journal
 
-// Writer defines an interface for recording events and their metadata
-type Writer interface {
-	// Write records an operation and its metadata to a Journal accepting variadic key-value
-	// pairs.
-	Write(event string, kvs ...interface{})
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	logging "github.com/ipfs/go-log"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/repo"
+)
+
+var log = logging.Logger("journal")
+
+var (
+	// DefaultDisabledEvents lists the journal events disabled by
+	// default, usually because they are considered noisy.
+	DefaultDisabledEvents = DisabledEvents{
+		EventType{System: "mpool", Event: "add"},
+		EventType{System: "mpool", Event: "remove"},
+	}
+)
+
+// DisabledEvents is the set of event types whose journaling is suppressed.
+type DisabledEvents []EventType
+
+// ParseDisabledEvents parses a string of the form: "system1:event1,system1:event2[,...]"
+// into a DisabledEvents object, returning an error if the string failed to parse.
+//
+// It sanitizes strings via strings.TrimSpace.
+func ParseDisabledEvents(s string) (DisabledEvents, error) {
+	s = strings.TrimSpace(s) // sanitize
+	evts := strings.Split(s, ",")
+	ret := make(DisabledEvents, 0, len(evts))
+	for _, evt := range evts {
+		evt = strings.TrimSpace(evt) // sanitize
+		s := strings.Split(evt, ":")
+		if len(s) != 2 {
+			return nil, fmt.Errorf("invalid event type: %s", s)
+		}
+		ret = append(ret, EventType{System: s[0], Event: s[1]})
+	}
+	return ret, nil
+}
+
+// EventType represents the signature of an event.
+type EventType struct {
+	System string
+	Event  string
+
+	// enabled stores whether this event type is enabled.
+	enabled bool
+
+	// safe is a sentinel marker that's set to true if this EventType was
+	// constructed correctly (via Journal#RegisterEventType).
+	safe bool
+}
+
+func (et EventType) String() string {
+	return et.System + ":" + et.Event
+}
+
+// Enabled returns whether this event type is enabled in the journaling
+// subsystem. Users are advised to check this before actually attempting to
+// add a journal entry, as it helps bypass object construction for events that
+// would be discarded anyway.
+//
+// All event types are enabled by default, and specific event types can only
+// be disabled at Journal construction time.
+func (et EventType) Enabled() bool {
+	return et.safe && et.enabled
 }
 
-// Journal defines an interface for creating Journals with a topic.
+// Journal represents an audit trail of system actions.
+//
+// Every entry is tagged with a timestamp, a system name, and an event name.
+// The supplied data can be any type, as long as it is JSON serializable,
+// including structs, map[string]interface{}, or primitive types.
+//
+// For cleanliness and type safety, we recommend to use typed events. See the
+// *Evt struct types in this package for more info.
 type Journal interface {
-	// Topic returns a Writer that records events for a topic.
-	Topic(topic string) Writer
+	EventTypeRegistry
+
+	// RecordEvent records this event to the journal, if and only if the
+	// EventType is enabled. If so, it calls the supplier function to obtain
+	// the payload to record.
+	//
+	// Implementations MUST recover from panics raised by the supplier function.
+	RecordEvent(evtType EventType, supplier func() interface{})
+
+	// Query reads back the events of `system` recorded at or after `since`, oldest
+	// first. Empty `system` or `event` match all the systems or events. At most
+	// MaxQueryEvents are returned, the following ones can be read with a later `since`.
+	Query(system, event string, since time.Time) ([]*Event, error)
+
+	// Close closes this journal for further writing.
+	Close() error
+}
+
+// Event represents a journal entry.
+//
+// See godocs on Journal for more information.
+type Event struct {
+	EventType
+
+	Timestamp time.Time
+	Data      interface{}
+}
+
+// MaxQueryEvents is the maximum number of events returned by a query.
+const MaxQueryEvents = 10000
+
+// Sink is an output of the journal.
+type Sink interface {
+	// Write outputs an event, it is never called concurrently.
+	Write(evt *Event) error
+	Close() error
+}
+
+// Querier is a sink the events can be read back from.
+type Querier interface {
+	Query(system, event string, since time.Time) ([]*Event, error)
+}
+
+// sinkJournal is a journal writing the recorded events to its sinks from a
+// single goroutine.
+type sinkJournal struct {
+	EventTypeRegistry
+
+	sinks []Sink
+
+	incoming chan *Event
+
+	closing chan struct{}
+	closed  chan struct{}
+}
+
+// NewJournal returns a journal writing the events which are not disabled to `sinks`.
+func NewJournal(disabled DisabledEvents, sinks ...Sink) Journal {
+	j := &sinkJournal{
+		EventTypeRegistry: NewEventTypeRegistry(disabled),
+		sinks:             sinks,
+		incoming:          make(chan *Event, 32),
+		closing:           make(chan struct{}),
+		closed:            make(chan struct{}),
+	}
+
+	go j.runLoop()
+
+	return j
+}
+
+func (j *sinkJournal) RecordEvent(evtType EventType, supplier func() interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warnf("recovered from panic while recording journal event; type=%s, err=%v", evtType, r)
+		}
+	}()
+
+	if !evtType.Enabled() {
+		return
+	}
+
+	je := &Event{
+		EventType: evtType,
+		Timestamp: constants.Clock.Now(),
+		Data:      supplier(),
+	}
+	select {
+	case j.incoming <- je:
+	case <-j.closing:
+		log.Warnw("journal closed but tried to log event", "event", je)
+	}
+}
+
+func (j *sinkJournal) Query(system, event string, since time.Time) ([]*Event, error) {
+	for _, sink := range j.sinks {
+		if q, ok := sink.(Querier); ok {
+			return q.Query(system, event, since)
+		}
+	}
+	return nil, fmt.Errorf("the journal has no sink to query events from")
+}
+
+func (j *sinkJournal) Close() error {
+	close(j.closing)
+	<-j.closed
+	return nil
+}
+
+func (j *sinkJournal) runLoop() {
+	defer close(j.closed)
+
+	write := func(je *Event) {
+		for _, sink := range j.sinks {
+			if err := sink.Write(je); err != nil {
+				log.Errorw("failed to write out journal event", "event", je, "err", err)
+			}
+		}
+	}
+
+	for {
+		select {
+		case je := <-j.incoming:
+			write(je)
+		case <-j.closing:
+			// write the events recorded before closing
+			for len(j.incoming) > 0 {
+				write(<-j.incoming)
+			}
+			for _, sink := range j.sinks {
+				if err := sink.Close(); err != nil {
+					log.Errorw("failed to close journal sink", "err", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// Open opens the journal of the repo configured by its journal config. The events
+// are written to rotating files in the journal directory of the repo and, if
+// enabled, to the journal path of the repo through zap.
+func Open(lr repo.Repo) (Journal, error) {
+	cfg := lr.Config().Journal
+	if cfg == nil {
+		cfg = config.NewDefaultConfig().Journal
+	}
+
+	disabled, err := configDisabledEvents(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := lr.Path()
+	if err != nil {
+		return nil, err
+	}
+	files, err := OpenFileSink(filepath.Join(path, "journal"), cfg.MaxFileSize, cfg.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+	sinks := []Sink{files}
+
+	if cfg.ZapSink {
+		zs, err := NewZapSink(lr.JournalPath())
+		if err != nil {
+			_ = files.Close()
+			return nil, err
+		}
+		sinks = append(sinks, zs)
+	}
+
+	return NewJournal(disabled, sinks...), nil
 }
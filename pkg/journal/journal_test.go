@@ -1,37 +1,87 @@
 package journal
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
-	"github.com/filecoin-project/venus/pkg/clock"
 	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
 )
 
-func TestSimpleInMemoryJournal(t *testing.T) {
+type testEvt struct {
+	Height int64
+}
+
+func TestFileJournalQuery(t *testing.T) {
 	tf.UnitTest(t)
 
-	mj := NewInMemoryJournal(t, clock.NewFake(time.Unix(1234567890, 0)))
-	topicJ := mj.Topic("testing")
-	topicJ.Write("event1", "foo", "bar")
+	dir, err := ioutil.TempDir("", "journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
 
-	memoryWriter, ok := topicJ.(*MemoryWriter)
-	assert.True(t, ok)
-	assert.Equal(t, 1, len(memoryWriter.journal.topics["testing"]))
-	assert.Equal(t, "testing", memoryWriter.topic)
+	files, err := OpenFileSink(dir, 0, 0)
+	require.NoError(t, err)
+	j := NewJournal(DisabledEvents{{System: "chain", Event: "disabled"}}, files)
 
-	topicJ.Write("event2", "number", 42)
-	assert.Equal(t, 2, len(memoryWriter.journal.topics["testing"]))
+	start := time.Now()
+	reorg := j.RegisterEventType("chain", "reorg")
+	failed := j.RegisterEventType("sync", "failed")
+	disabled := j.RegisterEventType("chain", "disabled")
+	for i := int64(0); i < 3; i++ {
+		j.RecordEvent(reorg, func() interface{} { return testEvt{Height: i} })
+		j.RecordEvent(failed, func() interface{} { return testEvt{Height: i} })
+		j.RecordEvent(disabled, func() interface{} { return testEvt{Height: i} })
+	}
+	j.RecordEvent(reorg, func() interface{} { panic("recovered") })
+	require.NoError(t, j.Close())
 
-	obj := struct {
-		Name string
-		Arg  int
-	}{"bob",
-		42,
+	// the journal files are read directly as the journal is closed
+	evts, err := QueryDir(dir, "chain", "", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evts, 3)
+	for i, evt := range evts {
+		assert.Equal(t, "reorg", evt.Event)
+		assert.Equal(t, float64(i), evt.Data.(map[string]interface{})["Height"])
 	}
-	topicJ.Write("event3", "object", obj, "name", "bob", "age", 42)
-	assert.Equal(t, 3, len(memoryWriter.journal.topics["testing"]))
 
+	evts, err = QueryDir(dir, "", "failed", start)
+	require.NoError(t, err)
+	assert.Len(t, evts, 3)
+
+	evts, err = QueryDir(dir, "", "", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, evts, 0)
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	tf.UnitTest(t)
+
+	dir, err := ioutil.TempDir("", "journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	// every event rolls the file
+	files, err := OpenFileSink(dir, 1, 3)
+	require.NoError(t, err)
+
+	evtType := NewEventTypeRegistry(nil).RegisterEventType("chain", "reorg")
+	for i := int64(0); i < 5; i++ {
+		require.NoError(t, files.Write(&Event{EventType: evtType, Timestamp: time.Now(), Data: testEvt{Height: i}}))
+	}
+	require.NoError(t, files.Close())
+
+	names, err := journalFiles(dir)
+	require.NoError(t, err)
+	assert.Len(t, names, 3)
+
+	// the file opened by the last write is empty, the two before hold the last events
+	evts, err := files.Query("", "", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, evts, 2)
+	assert.Equal(t, float64(3), evts[0].Data.(map[string]interface{})["Height"])
+	assert.Equal(t, float64(4), evts[1].Data.(map[string]interface{})["Height"])
 }
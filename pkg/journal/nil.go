@@ -1,5 +1,7 @@
 package journal
 
+import "time"
+
 type nilJournal struct{}
 
 // nilj is a singleton nil journal.
@@ -13,4 +15,6 @@ func (n *nilJournal) RegisterEventType(_, _ string) EventType { return EventType
 
 func (n *nilJournal) RecordEvent(_ EventType, _ func() interface{}) {}
 
+func (n *nilJournal) Query(_, _ string, _ time.Time) ([]*Event, error) { return nil, nil }
+
 func (n *nilJournal) Close() error { return nil }
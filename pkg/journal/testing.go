@@ -2,61 +2,58 @@ package journal
 
 import (
 	"sync"
-	"testing"
 	"time"
-
-	"github.com/filecoin-project/venus/pkg/clock"
 )
 
-// NewInMemoryJournal returns a journal backed by an in-memory map.
-func NewInMemoryJournal(t *testing.T, clk clock.Clock) Journal {
+// NewMemoryJournal returns a journal keeping the recorded events in memory, with
+// no disabled event.
+func NewMemoryJournal() *MemoryJournal {
 	return &MemoryJournal{
-		t:      t,
-		clock:  clk,
-		topics: make(map[string][]entry),
+		EventTypeRegistry: NewEventTypeRegistry(nil),
 	}
 }
 
 // MemoryJournal represents a journal held in memory.
 type MemoryJournal struct {
-	t        *testing.T
-	clock    clock.Clock
-	topicsMu sync.Mutex
-	topics   map[string][]entry
+	EventTypeRegistry
+
+	lk     sync.Mutex
+	events []*Event
 }
 
-// Topic returns a Writer with the provided `topic`.
-func (mj *MemoryJournal) Topic(topic string) Writer {
-	mr := &MemoryWriter{
-		topic:   topic,
-		journal: mj,
+var _ Journal = (*MemoryJournal)(nil)
+
+// RecordEvent records the event synchronously.
+func (mj *MemoryJournal) RecordEvent(evtType EventType, supplier func() interface{}) {
+	if !evtType.Enabled() {
+		return
 	}
-	return mr
-}
 
-type entry struct {
-	time  time.Time
-	event string
-	kvs   []interface{}
+	mj.lk.Lock()
+	defer mj.lk.Unlock()
+	mj.events = append(mj.events, &Event{
+		EventType: evtType,
+		Timestamp: time.Now(),
+		Data:      supplier(),
+	})
 }
 
-// MemoryWriter writes journal entires in memory.
-type MemoryWriter struct {
-	topic   string
-	journal *MemoryJournal
+// Query returns the recorded events matching the filter.
+func (mj *MemoryJournal) Query(system, event string, since time.Time) ([]*Event, error) {
+	mj.lk.Lock()
+	defer mj.lk.Unlock()
+
+	var out []*Event
+	for _, evt := range mj.events {
+		if (system != "" && evt.System != system) || (event != "" && evt.Event != event) || evt.Timestamp.Before(since) {
+			continue
+		}
+		out = append(out, evt)
+	}
+	return out, nil
 }
 
-// Write records an operation and its metadata to a Journal accepting variadic key-value
-// pairs.
-func (mw *MemoryWriter) Write(event string, kvs ...interface{}) {
-	if len(kvs)%2 != 0 {
-		mw.journal.t.Fatalf("journal write call has odd number of key values pairs: %d event: %s topic: %s", len(kvs), event, mw.topic)
-	}
-	mw.journal.topicsMu.Lock()
-	mw.journal.topics[mw.topic] = append(mw.journal.topics[mw.topic], entry{
-		event: event,
-		time:  mw.journal.clock.Now(),
-		kvs:   kvs,
-	})
-	mw.journal.topicsMu.Unlock()
+// Close does nothing.
+func (mj *MemoryJournal) Close() error {
+	return nil
 }
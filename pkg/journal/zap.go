@@ -5,16 +5,16 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// NewZapJournal returns a Journal backed by a zap logger. ZapJournal writes entries as ndjson to
+// NewZapSink returns a Sink backed by a zap logger. ZapSink writes entries as ndjson to
 // file at `filepath`.
-func NewZapJournal(filepath string) (Journal, error) {
+func NewZapSink(filepath string) (Sink, error) {
 	zapCfg := zap.NewProductionConfig()
 	zapCfg.Encoding = "json"
 	zapCfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	zapCfg.EncoderConfig.LevelKey = ""
 	zapCfg.EncoderConfig.CallerKey = ""
 	zapCfg.EncoderConfig.MessageKey = "_event"
-	zapCfg.EncoderConfig.NameKey = "_topic"
+	zapCfg.EncoderConfig.NameKey = "_system"
 	zapCfg.OutputPaths = []string{filepath}
 	zapCfg.ErrorOutputPaths = []string{"stderr"}
 
@@ -23,30 +23,22 @@ func NewZapJournal(filepath string) (Journal, error) {
 		return nil, err
 	}
 
-	return &ZapJournal{global}, nil
+	return &ZapSink{global}, nil
 }
 
-// ZapJournal implemented the Journal interface.
-type ZapJournal struct {
+// ZapSink implements the Sink interface.
+type ZapSink struct {
 	logger *zap.Logger
 }
 
-// Topic returns a Writer that records events for a topic.
-func (zj *ZapJournal) Topic(topic string) Writer {
-	return &ZapWriter{
-		logger: zj.logger.Sugar().Named(topic),
-		topic:  topic,
-	}
-}
-
-// ZapWriter implements the Journal Write interface and is backed by a zap logger.
-type ZapWriter struct {
-	logger *zap.SugaredLogger
-	topic  string
+// Write records the event, named by its system.
+func (zs *ZapSink) Write(evt *Event) error {
+	zs.logger.Sugar().Named(evt.System).Infow(evt.Event, "timestamp", evt.Timestamp, "data", evt.Data)
+	return nil
 }
 
-// Write records an operation and its metadata to a Journal accepting variadic key-value
-// pairs.
-func (zw *ZapWriter) Write(event string, kvs ...interface{}) {
-	zw.logger.Infow(event, kvs...)
+// Close flushes the logger.
+func (zs *ZapSink) Close() error {
+	_ = zs.logger.Sync()
+	return nil
 }
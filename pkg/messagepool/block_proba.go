@@ -100,3 +100,14 @@ func (mp *MessagePool) blockProbabilities(tq float64) []float64 {
 	}
 	return out
 }
+
+// epochInclusionProbability returns the probability that a message is included in an epoch,
+// given the probability `blockProb` that a single block includes it: the epoch has no block
+// with the probability of a null round, otherwise at least one of its blocks must include it.
+func epochInclusionProbability(blockProb float64) float64 {
+	var p float64
+	for winners, pCase := range noWinnersProb() {
+		p += pCase * (1 - math.Pow(1-blockProb, float64(winners)))
+	}
+	return p
+}
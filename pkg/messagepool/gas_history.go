@@ -0,0 +1,270 @@
+package messagepool
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// MaxFeeHistoryBlocks is the maximum number of tipsets of a fee history.
+const MaxFeeHistoryBlocks = 1024
+
+// inclusionHistory is the number of tipsets the inclusion estimates are based on.
+const inclusionHistory = 40
+
+// inclusionConfidence is the probability of inclusion the estimates aim for.
+const inclusionConfidence = 0.9
+
+// FeeHistory reports the base fee and the gas premiums of the messages of a range of
+// tipsets, oldest first.
+type FeeHistory struct {
+	OldestEpoch abi.ChainEpoch
+	// BaseFee holds the base fee paid by the messages of each tipset, followed by the base
+	// fee of the messages of the next tipset, so it has one more entry than the other fields.
+	BaseFee []abi.TokenAmount
+	// GasLimitRatio is the gas limit of the messages of each tipset over its gas target.
+	GasLimitRatio []float64
+	// Premiums holds the requested percentiles of the gas premiums of the messages of each
+	// tipset, weighted by gas limit. They are zero when the tipset has no message.
+	Premiums [][]abi.TokenAmount
+}
+
+// InclusionEstimate is a gas premium and a fee cap with which a message should be included
+// on chain within Epochs epochs with the given Probability.
+type InclusionEstimate struct {
+	Epochs      uint64
+	GasPremium  abi.TokenAmount
+	GasFeeCap   abi.TokenAmount
+	Probability float64
+}
+
+// GasFeeHistory returns the fee history of the `blocks` non null tipsets ending at `tsk`,
+// the head when `tsk` is empty, like the eth_feeHistory of EIP-1559. The percentiles are
+// between 0 and 100.
+func (mp *MessagePool) GasFeeHistory(ctx context.Context, blocks int, percentiles []float64, tsk types.TipSetKey) (*FeeHistory, error) {
+	if blocks < 1 || blocks > MaxFeeHistoryBlocks {
+		return nil, xerrors.Errorf("block count must be between 1 and %d", MaxFeeHistoryBlocks)
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 || (i > 0 && p < percentiles[i-1]) {
+			return nil, xerrors.Errorf("percentiles must be increasing and between 0 and 100")
+		}
+	}
+
+	ts, err := mp.loadTipSetOrHead(tsk)
+	if err != nil {
+		return nil, err
+	}
+	nextBaseFee, err := mp.api.ChainComputeBaseFee(ctx, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing next base fee: %w", err)
+	}
+
+	// walked from the newest tipset
+	var baseFees []abi.TokenAmount
+	var ratios []float64
+	var premiums [][]abi.TokenAmount
+	oldest := ts.Height()
+	err = mp.walkBack(ts, blocks, func(ts *types.TipSet) error {
+		meta, err := mp.PriceCache.GetTSGasStats(mp.api, ts)
+		if err != nil {
+			return err
+		}
+
+		var limit int64
+		for _, m := range meta {
+			limit += m.Limit
+		}
+		target := constants.BlockGasTarget * int64(len(ts.Blocks()))
+
+		oldest = ts.Height()
+		baseFees = append(baseFees, ts.Blocks()[0].ParentBaseFee)
+		ratios = append(ratios, float64(limit)/float64(target))
+		premiums = append(premiums, premiumPercentiles(meta, percentiles))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(baseFees)
+	history := &FeeHistory{
+		OldestEpoch:   oldest,
+		BaseFee:       make([]abi.TokenAmount, n+1),
+		GasLimitRatio: make([]float64, n),
+		Premiums:      make([][]abi.TokenAmount, n),
+	}
+	for i := 0; i < n; i++ {
+		history.BaseFee[i] = baseFees[n-1-i]
+		history.GasLimitRatio[i] = ratios[n-1-i]
+		history.Premiums[i] = premiums[n-1-i]
+	}
+	history.BaseFee[n] = nextBaseFee
+
+	return history, nil
+}
+
+// GasEstimateInclusion estimates, for each number of epochs of `targets`, the lowest gas
+// premium with which a message of `gasLimit` gas would be included within these epochs,
+// and the matching fee cap.
+//
+// The blocks of the last tipsets tell the probability that a block includes the message: it
+// is included when the block had room left for it, or when its premium beats the lowest
+// premium the block included. The number of blocks of an epoch follows the distribution of
+// the winners of the election, see epochInclusionProbability.
+func (mp *MessagePool) GasEstimateInclusion(ctx context.Context, gasLimit int64, targets []uint64, tsk types.TipSetKey) ([]InclusionEstimate, error) {
+	ts, err := mp.loadTipSetOrHead(tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []blockGasStats
+	err = mp.walkBack(ts, inclusionHistory, func(ts *types.TipSet) error {
+		for _, blk := range ts.Blocks() {
+			bls, secp, err := mp.api.MessagesForBlock(blk)
+			if err != nil {
+				return xerrors.Errorf("loading messages of block %s: %w", blk.Cid(), err)
+			}
+			s := blockGasStats{minPremium: big.Zero()}
+			add := func(msg *types.UnsignedMessage) {
+				if s.limit == 0 || msg.GasPremium.LessThan(s.minPremium) {
+					s.minPremium = msg.GasPremium
+				}
+				s.limit += msg.GasLimit
+			}
+			for _, msg := range bls {
+				add(msg)
+			}
+			for _, msg := range secp {
+				add(&msg.Message)
+			}
+			stats = append(stats, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the candidates are the premiums beating the lowest premium of a block
+	candidates := []abi.TokenAmount{big.NewInt(MinGasPremium)}
+	for _, s := range stats {
+		if p := big.Add(s.minPremium, big.NewInt(1)); p.GreaterThan(candidates[0]) {
+			candidates = append(candidates, p)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].LessThan(candidates[j])
+	})
+
+	out := make([]InclusionEstimate, 0, len(targets))
+	for _, epochs := range targets {
+		if epochs == 0 {
+			return nil, xerrors.Errorf("target must be at least one epoch")
+		}
+
+		var est InclusionEstimate
+		for _, premium := range candidates {
+			p := 1 - math.Pow(1-epochInclusionProbability(blockInclusionProbability(stats, premium, gasLimit)), float64(epochs))
+			est = InclusionEstimate{Epochs: epochs, GasPremium: premium, Probability: p}
+			if p >= inclusionConfidence {
+				break
+			}
+		}
+
+		est.GasFeeCap, err = mp.GasEstimateFeeCap(ctx, &types.UnsignedMessage{GasPremium: est.GasPremium}, int64(epochs), tsk)
+		if err != nil {
+			return nil, xerrors.Errorf("estimating fee cap: %w", err)
+		}
+		out = append(out, est)
+	}
+
+	return out, nil
+}
+
+type blockGasStats struct {
+	limit      int64
+	minPremium abi.TokenAmount
+}
+
+// blockInclusionProbability is the share of the blocks which would have included a message
+// of `gasLimit` gas paying `premium`.
+func blockInclusionProbability(stats []blockGasStats, premium abi.TokenAmount, gasLimit int64) float64 {
+	if len(stats) == 0 {
+		return 1
+	}
+	var included int
+	for _, s := range stats {
+		if s.limit+gasLimit <= constants.BlockGasLimit || premium.GreaterThan(s.minPremium) {
+			included++
+		}
+	}
+	return float64(included) / float64(len(stats))
+}
+
+// premiumPercentiles returns the percentiles of the premiums of `prices` weighted by gas limit.
+func premiumPercentiles(prices []GasMeta, percentiles []float64) []abi.TokenAmount {
+	out := make([]abi.TokenAmount, len(percentiles))
+	if len(prices) == 0 {
+		for i := range out {
+			out[i] = big.Zero()
+		}
+		return out
+	}
+
+	// the prices are shared with the cache
+	sorted := make([]GasMeta, len(prices))
+	copy(sorted, prices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Price.LessThan(sorted[j].Price)
+	})
+
+	var total int64
+	for _, m := range sorted {
+		total += m.Limit
+	}
+
+	for i, p := range percentiles {
+		threshold := int64(float64(total) * p / 100)
+		out[i] = sorted[len(sorted)-1].Price
+		var cumulated int64
+		for _, m := range sorted {
+			cumulated += m.Limit
+			if cumulated >= threshold {
+				out[i] = m.Price
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (mp *MessagePool) loadTipSetOrHead(tsk types.TipSetKey) (*types.TipSet, error) {
+	if tsk.IsEmpty() {
+		return mp.api.ChainHead()
+	}
+	return mp.api.LoadTipSet(tsk)
+}
+
+// walkBack calls `cb` on `ts` and its ancestors, up to `n` tipsets or the genesis excluded.
+func (mp *MessagePool) walkBack(ts *types.TipSet, n int, cb func(*types.TipSet) error) error {
+	for i := 0; i < n && ts.Height() > 0; i++ {
+		if err := cb(ts); err != nil {
+			return err
+		}
+
+		pts, err := mp.api.LoadTipSet(ts.Parents())
+		if err != nil {
+			return xerrors.Errorf("loading parent of tipset %s: %w", ts.Key(), err)
+		}
+		ts = pts
+	}
+	return nil
+}
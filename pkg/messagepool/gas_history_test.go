@@ -0,0 +1,50 @@
+package messagepool
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus/pkg/constants"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestPremiumPercentiles(t *testing.T) {
+	tf.UnitTest(t)
+
+	prices := []GasMeta{
+		{Price: big.NewInt(300), Limit: 100},
+		{Price: big.NewInt(100), Limit: 700},
+		{Price: big.NewInt(200), Limit: 200},
+	}
+	out := premiumPercentiles(prices, []float64{0, 50, 70, 80, 100})
+	assert.Equal(t, []abi.TokenAmount{big.NewInt(100), big.NewInt(100), big.NewInt(100), big.NewInt(200), big.NewInt(300)}, out)
+	// the prices are left untouched
+	assert.Equal(t, big.NewInt(300), prices[0].Price)
+
+	out = premiumPercentiles(nil, []float64{50})
+	assert.Equal(t, []abi.TokenAmount{big.Zero()}, out)
+}
+
+func TestInclusionProbability(t *testing.T) {
+	tf.UnitTest(t)
+
+	stats := []blockGasStats{
+		{limit: constants.BlockGasLimit, minPremium: big.NewInt(200)},
+		{limit: constants.BlockGasLimit / 2, minPremium: big.NewInt(100)},
+	}
+	assert.Equal(t, 0.5, blockInclusionProbability(stats, big.NewInt(150), 1000))
+	assert.Equal(t, 1.0, blockInclusionProbability(stats, big.NewInt(250), 1000))
+	assert.Equal(t, 0.0, blockInclusionProbability(stats, big.NewInt(50), constants.BlockGasLimit))
+
+	// only null rounds prevent the inclusion of a message every block accepts
+	var notNull float64
+	for _, p := range noWinnersProb()[1:] {
+		notNull += p
+	}
+	assert.InDelta(t, notNull, epochInclusionProbability(1), 1e-9)
+	assert.Equal(t, 0.0, epochInclusionProbability(0))
+	assert.True(t, epochInclusionProbability(0.5) < epochInclusionProbability(0.6))
+}
@@ -32,7 +32,7 @@ import (
 
 	"github.com/filecoin-project/venus/pkg/chain"
 	"github.com/filecoin-project/venus/pkg/constants"
-	"github.com/filecoin-project/venus/pkg/messagepool/journal"
+	"github.com/filecoin-project/venus/pkg/journal"
 	"github.com/filecoin-project/venus/pkg/net/msgsub"
 	"github.com/filecoin-project/venus/pkg/repo"
 	"github.com/filecoin-project/venus/pkg/types"
@@ -639,9 +639,10 @@ func (mp *MessagePool) PublishMsg(smsg *types.SignedMessage) error {
 	return nil
 }
 
+// Close stops the message pool, the journal is shared with the node and is closed by it.
 func (mp *MessagePool) Close() error {
 	close(mp.closer)
-	return mp.journal.Close()
+	return nil
 }
 
 func (mp *MessagePool) Prune() {
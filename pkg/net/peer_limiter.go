@@ -0,0 +1,128 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	"github.com/filecoin-project/venus/pkg/util/tokenbucket"
+)
+
+// PeerLimiter is a token-bucket rate limiter keyed by peer. Each peer gets a
+// budget of `perMinute` units which refills continuously, a request consumes
+// as many units as its cost. Whitelisted peers are never limited.
+type PeerLimiter struct {
+	capacity  float64
+	perSecond float64
+	buckets   *tokenbucket.Buckets
+	whitelist map[peer.ID]struct{}
+
+	now func() time.Time
+}
+
+// NewPeerLimiter creates a limiter allowing each peer `perMinute` units of
+// cost per minute. A peer may spend its whole budget at once but no more, so a
+// request costing more than `perMinute` is never served. A non positive
+// `perMinute` disables the limit.
+func NewPeerLimiter(perMinute int64, whitelist []peer.ID) *PeerLimiter {
+	wl := make(map[peer.ID]struct{}, len(whitelist))
+	for _, p := range whitelist {
+		wl[p] = struct{}{}
+	}
+	return &PeerLimiter{
+		capacity:  float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+		buckets:   tokenbucket.New(),
+		whitelist: wl,
+		now:       time.Now,
+	}
+}
+
+// Allow consumes `cost` units from the budget of peer `p` and returns whether
+// the request may be served. A rejected request consumes nothing.
+func (l *PeerLimiter) Allow(p peer.ID, cost uint64) bool {
+	if l.disabled(p) {
+		return true
+	}
+	_, ok := l.buckets.Take(l.now(), float64(cost), l.budget(p))
+	return ok
+}
+
+// Available returns whether peer `p` has `cost` units left, without consuming
+// them. Used to check several limiters before charging any of them.
+func (l *PeerLimiter) Available(p peer.ID, cost uint64) bool {
+	if l.disabled(p) {
+		return true
+	}
+	return l.buckets.Available(l.now(), float64(cost), l.budget(p))
+}
+
+// Refund gives back `cost` units consumed by Allow for a request which was
+// finally not served.
+func (l *PeerLimiter) Refund(p peer.ID, cost uint64) {
+	if l.disabled(p) {
+		return
+	}
+	l.buckets.Refund(l.now(), float64(cost), l.budget(p))
+}
+
+// Exceeds returns whether `cost` is larger than the whole budget of a peer,
+// that is, a request that could never be served no matter how long the peer
+// waits.
+func (l *PeerLimiter) Exceeds(p peer.ID, cost uint64) bool {
+	if l.disabled(p) {
+		return false
+	}
+	return float64(cost) > l.capacity
+}
+
+func (l *PeerLimiter) disabled(p peer.ID) bool {
+	if l == nil || l.perSecond <= 0 {
+		return true
+	}
+	_, ok := l.whitelist[p]
+	return ok
+}
+
+func (l *PeerLimiter) budget(p peer.ID) tokenbucket.Budget {
+	return tokenbucket.Budget{Key: string(p), Rate: l.perSecond, Capacity: l.capacity}
+}
+
+// throttledWindow is how long a throttled peer is counted after it was last throttled.
+const throttledWindow = time.Minute
+
+// ThrottledPeers counts the peers throttled within the last minute. The count is the
+// bounded signal exported as a metric, the throttled peers themselves are only logged.
+type ThrottledPeers struct {
+	lk    sync.Mutex
+	peers map[peer.ID]time.Time
+
+	now func() time.Time
+}
+
+// NewThrottledPeers creates an empty ThrottledPeers.
+func NewThrottledPeers() *ThrottledPeers {
+	return &ThrottledPeers{peers: make(map[peer.ID]time.Time), now: time.Now}
+}
+
+// Add records that peer `p` was throttled.
+func (t *ThrottledPeers) Add(p peer.ID) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+	t.peers[p] = t.now()
+}
+
+// Count returns the number of peers throttled within the last minute, and forgets the
+// peers throttled before.
+func (t *ThrottledPeers) Count() int {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+	since := t.now().Add(-throttledWindow)
+	for p, last := range t.peers {
+		if last.Before(since) {
+			delete(t.peers, p)
+		}
+	}
+	return len(t.peers)
+}
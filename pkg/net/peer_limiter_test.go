@@ -0,0 +1,108 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestPeerLimiter(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Now()
+	trusted, leecher := peer.ID("trusted"), peer.ID("leecher")
+	l := NewPeerLimiter(60, []peer.ID{trusted})
+	l.now = func() time.Time { return now }
+
+	assert.True(t, l.Allow(leecher, 50))
+	assert.False(t, l.Available(leecher, 20))
+	assert.False(t, l.Allow(leecher, 20))
+	assert.True(t, l.Allow(leecher, 10))
+
+	// one unit per second is refilled
+	now = now.Add(20 * time.Second)
+	assert.True(t, l.Available(leecher, 20))
+	assert.True(t, l.Allow(leecher, 20))
+	assert.False(t, l.Allow(leecher, 1))
+	l.Refund(leecher, 5)
+	assert.True(t, l.Allow(leecher, 5))
+
+	assert.True(t, l.Exceeds(leecher, 61))
+	assert.False(t, l.Exceeds(trusted, 61))
+	assert.True(t, l.Allow(trusted, 1000))
+}
+
+func TestPeerLimiterThrottlesOverRate(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Now()
+	p := peer.ID("p")
+	l := NewPeerLimiter(900, nil)
+	l.now = func() time.Time { return now }
+
+	// the budget of a minute can be spent at once, but not more
+	assert.False(t, l.Exceeds(p, 900))
+	assert.True(t, l.Exceeds(p, 901))
+	assert.True(t, l.Allow(p, 900))
+	assert.False(t, l.Allow(p, 1))
+
+	// a peer asking faster than the rate only gets what was refilled
+	served := uint64(0)
+	for i := 0; i < 60; i++ {
+		now = now.Add(time.Second)
+		for l.Allow(p, 5) {
+			served += 5
+		}
+	}
+	assert.Equal(t, uint64(900), served)
+}
+
+func TestPeerLimiterEvictsIdlePeers(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Now()
+	l := NewPeerLimiter(60, nil)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		l.Allow(peer.ID(rune('a'+i)), 1)
+	}
+	assert.Equal(t, 10, l.buckets.Len())
+
+	now = now.Add(10*time.Minute + time.Second)
+	l.Allow(peer.ID("z"), 1)
+	assert.Equal(t, 1, l.buckets.Len())
+}
+
+func TestPeerLimiterDisabled(t *testing.T) {
+	tf.UnitTest(t)
+
+	var nilLimiter *PeerLimiter
+	assert.True(t, nilLimiter.Allow(peer.ID("p"), 1<<20))
+	assert.True(t, NewPeerLimiter(0, nil).Allow(peer.ID("p"), 1<<20))
+}
+
+func TestThrottledPeers(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Now()
+	tp := NewThrottledPeers()
+	tp.now = func() time.Time { return now }
+
+	tp.Add(peer.ID("a"))
+	tp.Add(peer.ID("b"))
+	tp.Add(peer.ID("a"))
+	assert.Equal(t, 2, tp.Count())
+
+	// the peers are counted for a minute after they were last throttled
+	now = now.Add(40 * time.Second)
+	tp.Add(peer.ID("a"))
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, 1, tp.Count())
+	now = now.Add(time.Minute)
+	assert.Equal(t, 0, tp.Count())
+}
@@ -50,7 +50,8 @@ func (o *pcAPI) WalletHas(ctx context.Context, addr address.Address) (bool, erro
 	return o.walletAPI.WalletHas(ctx, addr)
 }
 func (o *pcAPI) WalletSign(ctx context.Context, k address.Address, msg []byte) (*crypto.Signature, error) {
-	return o.walletAPI.WalletSign(ctx, k, msg, wallet.MsgMeta{Type: wallet.MTSignedVoucher})
+	// the signing bytes of a voucher are its encoding, they are sent for the sign policy to check
+	return o.walletAPI.WalletSign(ctx, k, msg, wallet.MsgMeta{Type: wallet.MTSignedVoucher, Extra: msg})
 }
 func (o *pcAPI) StateNetworkVersion(ctx context.Context, ts types.TipSetKey) (network.Version, error) {
 	return o.chainInfoAPI.StateNetworkVersion(ctx, ts)
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/jwtauth"
+	"github.com/filecoin-project/venus/pkg/metrics"
+	"github.com/filecoin-project/venus/pkg/util/tokenbucket"
+)
+
+var limitLog = logging.Logger("api-ratelimit")
+
+// the callers are only logged, a tag of the metric would let any client grow its cardinality
+var apiCallsLimited = metrics.NewInt64Counter("api/rate_limited", "Number of api calls rejected by the in-process rate limiter", metrics.MethodKey)
+
+// burstSeconds is how many seconds of budget a caller may spend at once.
+const burstSeconds = 10
+
+// ErrCodeRateLimited is the json-rpc error code of the calls rejected by the limiter, in the
+// range of the codes left to the implementations, as "limit exceeded".
+const ErrCodeRateLimited = -32005
+
+// tiers are the permissions from the highest, the tier of a caller is the highest
+// permission of its token.
+var tiers = []auth.Permission{"admin", "sign", "write", "read"}
+
+// ErrRateLimited is returned for the calls exceeding the budget of their caller.
+type ErrRateLimited struct {
+	Method     string
+	RetryAfter time.Duration
+}
+
+func (e *ErrRateLimited) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("rate limit exceeded: %s costs more than the whole budget", e.Method)
+	}
+	return fmt.Sprintf("rate limit exceeded for %s, retry in %s", e.Method, e.RetryAfter.Round(time.Millisecond))
+}
+
+// APILimiter is a token-bucket rate limiter of the api calls, kept in the node itself. Each
+// caller, known by its token or its ip, has the budget of the tier of its token, and some
+// methods have a budget of their own. A call consumes its cost from every budget it falls
+// under, and is rejected, consuming nothing, when one of them is short.
+type APILimiter struct {
+	keyByIP     bool
+	tierRates   map[auth.Permission]float64
+	methodRates map[string]float64
+	costs       map[string]float64
+
+	buckets *tokenbucket.Buckets
+
+	now func() time.Time
+}
+
+// NewAPILimiter creates the limiter configured by `cfg`.
+func NewAPILimiter(cfg *config.RateLimitCfg) *APILimiter {
+	l := &APILimiter{
+		keyByIP:     cfg.KeyBy == "ip",
+		tierRates:   make(map[auth.Permission]float64, len(cfg.TierRates)),
+		methodRates: cfg.MethodRates,
+		costs:       cfg.MethodCosts,
+		buckets:     tokenbucket.New(),
+		now:         time.Now,
+	}
+	for tier, rate := range cfg.TierRates {
+		l.tierRates[auth.Permission(tier)] = rate
+	}
+	return l
+}
+
+// Allow consumes the cost of a call of `method` by the caller of `ctx`, it returns an
+// *ErrRateLimited when the call exceeds one of the budgets of the caller. The calls already
+// let through by the handler of the limiter are not charged again.
+func (l *APILimiter) Allow(ctx context.Context, method string) error {
+	if checked, _ := ctx.Value(checkedKey{}).(bool); checked {
+		return nil
+	}
+
+	caller := l.caller(ctx)
+	cost, ok := l.costs[method]
+	if !ok {
+		cost = 1
+	}
+
+	var budgets []tokenbucket.Budget
+	if rate := l.tierRates[tier(ctx)]; rate > 0 {
+		budgets = append(budgets, tokenbucket.Budget{Key: caller, Rate: rate, Capacity: rate * burstSeconds})
+	}
+	if rate := l.methodRates[method]; rate > 0 {
+		budgets = append(budgets, tokenbucket.Budget{Key: caller + "/" + method, Rate: rate, Capacity: rate * burstSeconds})
+	}
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	if wait, ok := l.buckets.Take(l.now(), cost, budgets...); !ok {
+		tagged, _ := tag.New(ctx, tag.Upsert(metrics.MethodKey, method))
+		apiCallsLimited.Inc(tagged, 1)
+		limitLog.Infow("api call rate limited", "method", method, "caller", caller, "retryAfter", wait)
+		return &ErrRateLimited{Method: method, RetryAfter: wait}
+	}
+	return nil
+}
+
+// caller returns the key of the budgets of the caller of `ctx`: the account of its token,
+// or its ip when the limiter is keyed by ip or the account is unknown.
+func (l *APILimiter) caller(ctx context.Context) string {
+	var vfc jwtauth.ValueFromCtx
+	if !l.keyByIP {
+		if name, ok := vfc.AccFromCtx(ctx); ok && name != "" {
+			return "token:" + name
+		}
+	}
+	if host, ok := vfc.HostFromCtx(ctx); ok && host != "" {
+		if ip, _, err := net.SplitHostPort(host); err == nil {
+			host = ip
+		}
+		return "ip:" + host
+	}
+	return "unknown"
+}
+
+func tier(ctx context.Context) auth.Permission {
+	for _, p := range tiers {
+		if auth.HasPerm(ctx, nil, p) {
+			return p
+		}
+	}
+	return ""
+}
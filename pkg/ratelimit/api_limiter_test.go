@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/metrics"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func newTestLimiter(now *time.Time) *APILimiter {
+	l := NewAPILimiter(&config.RateLimitCfg{
+		TierRates:   map[string]float64{"read": 1, "admin": 0},
+		MethodRates: map[string]float64{"MpoolPush": 0.1},
+		MethodCosts: map[string]float64{"StateMarketDeals": 5, "StateCompute": 100},
+	})
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestAPILimiterTiers(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	read := auth.WithPerm(context.Background(), []auth.Permission{"read"})
+	admin := auth.WithPerm(context.Background(), []auth.Permission{"read", "write", "sign", "admin"})
+
+	// a burst of 10 seconds of budget
+	for i := 0; i < 10; i++ {
+		require.NoError(t, l.Allow(read, "ChainHead"))
+	}
+	err := l.Allow(read, "ChainHead")
+	require.Error(t, err)
+	assert.Equal(t, time.Second, err.(*ErrRateLimited).RetryAfter)
+
+	// the admin tier is not limited
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.Allow(admin, "ChainHead"))
+	}
+
+	now = now.Add(time.Second)
+	assert.NoError(t, l.Allow(read, "ChainHead"))
+	assert.Error(t, l.Allow(read, "ChainHead"))
+}
+
+func TestAPILimiterCostsAndMethods(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	read := auth.WithPerm(context.Background(), []auth.Permission{"read"})
+
+	require.NoError(t, l.Allow(read, "StateMarketDeals"))
+	require.NoError(t, l.Allow(read, "StateMarketDeals"))
+	// a rejected call consumes nothing
+	assert.Error(t, l.Allow(read, "StateMarketDeals"))
+	now = now.Add(5 * time.Second)
+	assert.NoError(t, l.Allow(read, "StateMarketDeals"))
+
+	// a call costing more than the whole budget is never served
+	err := l.Allow(read, "StateCompute")
+	require.Error(t, err)
+	assert.Equal(t, time.Duration(0), err.(*ErrRateLimited).RetryAfter)
+
+	// the budget of a method comes on top of the tier budget
+	now = now.Add(time.Hour)
+	require.NoError(t, l.Allow(read, "MpoolPush"))
+	err = l.Allow(read, "MpoolPush")
+	require.Error(t, err)
+	assert.Equal(t, 10*time.Second, err.(*ErrRateLimited).RetryAfter)
+	assert.NoError(t, l.Allow(read, "ChainHead"))
+}
+
+func TestAPILimiterMetricTags(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	read := auth.WithPerm(context.Background(), []auth.Permission{"read"})
+	require.Error(t, l.Allow(read, "StateCompute"))
+
+	rows, err := view.RetrieveData("api/rate_limited")
+	require.NoError(t, err)
+	found := false
+	for _, row := range rows {
+		tags := make(map[tag.Key]string, len(row.Tags))
+		for _, tg := range row.Tags {
+			tags[tg.Key] = tg.Value
+		}
+		if tags[metrics.MethodKey] == "StateCompute" && len(tags) == 1 {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
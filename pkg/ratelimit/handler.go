@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/gorilla/websocket"
+)
+
+// checkedKey marks the contexts of the calls already let through by the handler.
+type checkedKey struct{}
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   rpcError        `json:"error"`
+}
+
+// Handler checks the json-rpc calls posted over http before `next` serves them, and answers
+// the rejected ones with a 429 and an error of code ErrCodeRateLimited, telling when to retry.
+// The calls over websocket are left to Allow, through the api proxy.
+func (l *APILimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, jsonrpc.DEFAULT_MAX_REQUEST_SIZE))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// the server reads the request again, including what is left over the limit
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			// let the server answer the malformed requests
+			next.ServeHTTP(w, r)
+			return
+		}
+		method := req.Method
+		if i := strings.LastIndex(method, "."); i >= 0 {
+			method = method[i+1:]
+		}
+
+		err = l.Allow(r.Context(), method)
+		if err == nil {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), checkedKey{}, true)))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if rerr, ok := err.(*ErrRateLimited); ok && rerr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rerr.RetryAfter.Seconds()))))
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		if req.ID == nil {
+			// a notification gets no response
+			return
+		}
+		_ = json.NewEncoder(w).Encode(rpcResponse{
+			Jsonrpc: "2.0",
+			ID:      req.ID,
+			Error:   rpcError{Code: ErrCodeRateLimited, Message: err.Error()},
+		})
+	})
+}
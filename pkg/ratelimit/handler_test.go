@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestAPILimiterHandler(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	l := newTestLimiter(&now)
+	served := 0
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		// the calls let through are not charged again by the api proxy
+		assert.NoError(t, l.Allow(r.Context(), "StateCompute"))
+	}))
+	post := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/rpc/v1", strings.NewReader(body))
+		r = r.WithContext(auth.WithPerm(context.Background(), []auth.Permission{"read"}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, post(`{"jsonrpc":"2.0","id":1,"method":"Filecoin.StateMarketDeals"}`).Code)
+	}
+	assert.Equal(t, 2, served)
+
+	w := post(`{"jsonrpc":"2.0","id":3,"method":"Filecoin.StateMarketDeals"}`)
+	assert.Equal(t, 2, served)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	var res struct {
+		ID    int64 `json:"id"`
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, int64(3), res.ID)
+	assert.Equal(t, ErrCodeRateLimited, res.Error.Code)
+	assert.Contains(t, res.Error.Message, "StateMarketDeals")
+
+	// a notification gets no response
+	w = post(`{"jsonrpc":"2.0","method":"Filecoin.StateMarketDeals"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+}
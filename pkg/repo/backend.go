@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	badgerds "github.com/ipfs/go-ds-badger2"
+	levelds "github.com/ipfs/go-ds-leveldb"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+// ClosableBlockstore is a blockstore backed by files, which must be closed.
+type ClosableBlockstore interface {
+	blockstoreutil.Blockstore
+	io.Closer
+}
+
+// Backend is a storage engine a repo can keep its data in, it is chosen by the type of the
+// datastore config.
+type Backend struct {
+	// DefaultPath is where the blockstore is kept in the repo when it is converted to the backend.
+	DefaultPath string
+	// OpenBlockstore opens the blockstore at `path`.
+	OpenBlockstore func(path string) (ClosableBlockstore, error)
+	// OpenDatastore opens the key-value datastore at `path`, it is nil for the backends which
+	// can only hold blocks, such as the read-only ones.
+	OpenDatastore func(path string) (Datastore, error)
+	// ReadOnly is set for the backends which cannot be written to.
+	ReadOnly bool
+}
+
+var backends = map[string]*Backend{}
+
+// RegisterBackend makes the backend `b` available as the datastore type `typ`.
+func RegisterBackend(typ string, b *Backend) {
+	if _, ok := backends[typ]; ok {
+		panic("datastore backend registered twice: " + typ)
+	}
+	backends[typ] = b
+}
+
+// LookupBackend returns the backend of the datastore type `typ`.
+func LookupBackend(typ string) (*Backend, error) {
+	b, ok := backends[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown datastore type %s, expected one of %v", typ, BackendTypes())
+	}
+	return b, nil
+}
+
+// BackendTypes returns the registered datastore types.
+func BackendTypes() []string {
+	types := make([]string, 0, len(backends))
+	for typ := range backends {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// metadataBackendType returns the datastore type of the chain, metadata, paych, wallet and
+// staging datastores, they are kept in the backend of the blockstore unless configured otherwise.
+func metadataBackendType(cfg *config.DatastoreConfig) string {
+	if cfg.MetadataType != "" {
+		return cfg.MetadataType
+	}
+	return cfg.Type
+}
+
+func init() {
+	RegisterBackend("badgerds", &Backend{
+		DefaultPath: "badger",
+		OpenBlockstore: func(path string) (ClosableBlockstore, error) {
+			opts, err := blockstoreutil.BadgerBlockstoreOptions(path, false)
+			if err != nil {
+				return nil, err
+			}
+			opts.Prefix = bstore.BlockPrefix.String()
+			return blockstoreutil.Open(opts)
+		},
+		OpenDatastore: func(path string) (Datastore, error) {
+			return badgerds.NewDatastore(path, badgerOptions())
+		},
+	})
+
+	// leveldb is a pure go engine, it does not have the memory spikes of badger at the cost
+	// of a lower write throughput.
+	RegisterBackend("levelds", &Backend{
+		DefaultPath: "leveldb",
+		OpenBlockstore: func(path string) (ClosableBlockstore, error) {
+			ds, err := levelds.NewDatastore(path, nil)
+			if err != nil {
+				return nil, err
+			}
+			return &datastoreBlockstore{Blockstore: blockstoreutil.NewBlockstore(ds), ds: ds}, nil
+		},
+		OpenDatastore: func(path string) (Datastore, error) {
+			return levelds.NewDatastore(path, nil)
+		},
+	})
+
+	// a car file, such as a chain export, served read-only for archival.
+	RegisterBackend("car", &Backend{
+		DefaultPath: "chain.car",
+		OpenBlockstore: func(path string) (ClosableBlockstore, error) {
+			return blockstoreutil.OpenCarBlockstore(path)
+		},
+		ReadOnly: true,
+	})
+}
+
+// datastoreBlockstore is a blockstore over a datastore, closing it closes the datastore.
+type datastoreBlockstore struct {
+	blockstoreutil.Blockstore
+	ds Datastore
+}
+
+func (bs *datastoreBlockstore) Close() error {
+	return bs.ds.Close()
+}
+
+// openBackendDatastore opens the datastore `prefix` of the repo at `repoPath` in the
+// metadata backend of `cfg`.
+func openBackendDatastore(repoPath, prefix string, cfg *config.DatastoreConfig) (Datastore, error) {
+	typ := metadataBackendType(cfg)
+	b, err := LookupBackend(typ)
+	if err != nil {
+		return nil, err
+	}
+	if b.OpenDatastore == nil {
+		return nil, fmt.Errorf("datastore type %s can only hold blocks, set datastore.metadataType", typ)
+	}
+	return b.OpenDatastore(filepath.Join(repoPath, prefix))
+}
@@ -0,0 +1,634 @@
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/mitchellh/go-homedir"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+// backupMagic starts every backup archive.
+var backupMagic = []byte("venus-repo-backup\n")
+
+// backupFormatVersion is the version of the layout of the archives written by Backup, the
+// version 2 holds the blocks of the genesis.
+const backupFormatVersion = 2
+
+// the scrypt parameters deriving the keys of an encrypted backup, backupScryptN is lowered
+// by the tests.
+var backupScryptN = 1 << 18
+
+const (
+	backupScryptR = 8
+	backupScryptP = 1
+)
+
+// the kinds of the records of a backup.
+const (
+	recordEnd byte = iota
+	recordConfig
+	recordKey
+	recordEntry
+	recordBlock
+)
+
+// ErrBackupPassword is returned when a backup cannot be restored with the given password.
+var ErrBackupPassword = errors.New("the backup is encrypted, a valid password is required")
+
+// backupHeader describes a backup archive, it is written in the clear after the magic.
+type backupHeader struct {
+	FormatVersion int
+	RepoVersion   uint
+	CreatedAt     time.Time
+	// Encryption is set when the records are encrypted.
+	Encryption *backupEncryption `json:",omitempty"`
+}
+
+// backupEncryption are the parameters of an encrypted backup. The records are encrypted with
+// aes-256-ctr and the archive is authenticated with hmac-sha256, both keyed by scrypt.
+type backupEncryption struct {
+	ScryptN int
+	ScryptR int
+	ScryptP int
+	Salt    []byte
+	IV      []byte
+	// Check is a mac of backupMagic, it tells a wrong password before the records are read.
+	Check []byte
+}
+
+func (e *backupEncryption) keys(password []byte) (encKey []byte, macKey []byte, err error) {
+	dk, err := scrypt.Key(password, e.Salt, e.ScryptN, e.ScryptR, e.ScryptP, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dk[:32], dk[32:], nil
+}
+
+func backupCheck(macKey []byte) []byte {
+	mac := hmac.New(sha256.New, macKey)
+	_, _ = mac.Write(backupMagic)
+	return mac.Sum(nil)
+}
+
+// backupDatastores returns the datastores of `r` included in a backup, by name.
+func backupDatastores(r Repo) map[string]Datastore {
+	return map[string]Datastore{
+		chainDatastorePrefix:  r.ChainDatastore(),
+		metaDatastorePrefix:   r.MetaDatastore(),
+		paychDatastorePrefix:  r.PaychDatastore(),
+		walletDatastorePrefix: r.WalletDatastore(),
+	}
+}
+
+// BackupResult reports what a backup holds.
+type BackupResult struct {
+	Keys    int
+	Entries map[string]int
+	Blocks  int
+}
+
+// Backup writes the config, the keystore and the chain, metadata, paych and wallet datastores
+// of `r` to `w`, with the blocks of the DAGs of `roots`, such as the genesis, which cannot be
+// synced again. The rest of the blockstore is not included. It can run while the node is using
+// the repo, the datastores are read from snapshots taken at the same point. The records are
+// encrypted with `password` when it is not empty.
+func Backup(ctx context.Context, r Repo, w io.Writer, password []byte, roots []cid.Cid) (*BackupResult, error) {
+	bw, err := newBackupWriter(w, backupHeader{
+		FormatVersion: backupFormatVersion,
+		RepoVersion:   r.Version(),
+		CreatedAt:     time.Now().UTC(),
+	}, password)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := json.Marshal(r.Config())
+	if err != nil {
+		return nil, err
+	}
+	if err := bw.record(recordConfig, cfg); err != nil {
+		return nil, err
+	}
+
+	res := &BackupResult{Entries: map[string]int{}}
+	names, err := r.Keystore().List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the keystore")
+	}
+	for _, name := range names {
+		key, err := r.Keystore().Get(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get key %s", name)
+		}
+		if err := bw.record(recordKey, []byte(name), key); err != nil {
+			return nil, err
+		}
+		res.Keys++
+	}
+
+	snapshots, err := snapshotDatastores(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, results := range snapshots {
+			_ = results.Close()
+		}
+	}()
+	for name, results := range snapshots {
+		n, err := backupDatastore(ctx, bw, name, results)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to back up the %s datastore", name)
+		}
+		res.Entries[name] = n
+	}
+
+	if res.Blocks, err = backupBlocks(ctx, bw, r.Datastore(), roots); err != nil {
+		return nil, errors.Wrap(err, "failed to back up the blocks")
+	}
+	return res, bw.close()
+}
+
+// snapshotDatastores queries the datastores of the backup, the queries read snapshots of the
+// datastores taken when they are made. The writes of the repo are held meanwhile, when it can
+// hold them, so that the snapshots agree with each other.
+func snapshotDatastores(r Repo) (map[string]query.Results, error) {
+	if h, ok := r.(interface{ holdWrites() func() }); ok {
+		release := h.holdWrites()
+		defer release()
+	}
+
+	snapshots := map[string]query.Results{}
+	for name, ds := range backupDatastores(r) {
+		results, err := ds.Query(query.Query{})
+		if err != nil {
+			for _, results := range snapshots {
+				_ = results.Close()
+			}
+			return nil, errors.Wrapf(err, "failed to query the %s datastore", name)
+		}
+		snapshots[name] = results
+	}
+	return snapshots, nil
+}
+
+func backupDatastore(ctx context.Context, w *backupWriter, name string, results query.Results) (int, error) {
+	var n int
+	for res := range results.Next() {
+		if res.Error != nil {
+			return 0, res.Error
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if err := w.record(recordEntry, []byte(name), []byte(res.Key), res.Value); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// backupBlocks writes the blocks of the DAGs of `roots` in `bs`, the links to blocks which
+// are not in `bs` are skipped.
+func backupBlocks(ctx context.Context, w *backupWriter, bs blockstoreutil.Blockstore, roots []cid.Cid) (int, error) {
+	seen := cid.NewSet()
+	queue := append([]cid.Cid(nil), roots...)
+	var n int
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		c := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if !seen.Visit(c) || c.Prefix().MhType == mh.IDENTITY {
+			continue
+		}
+
+		blk, err := bs.Get(c)
+		if err == blockstoreutil.ErrNotFound {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		if c.Prefix().Codec == cid.DagCBOR {
+			if err := cbg.ScanForLinks(bytes.NewReader(blk.RawData()), func(link cid.Cid) {
+				queue = append(queue, link)
+			}); err != nil {
+				return 0, errors.Wrapf(err, "failed to scan the links of %s", c)
+			}
+		}
+		if err := w.record(recordBlock, c.Bytes(), blk.RawData()); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// EncryptBackup writes the plain backup read from `rd` to `w`, encrypted with `password`.
+func EncryptBackup(w io.Writer, rd io.Reader, password []byte) error {
+	src, err := openBackup(rd, nil)
+	if err != nil {
+		return err
+	}
+	dst, err := newBackupWriter(w, src.header, password)
+	if err != nil {
+		return err
+	}
+	for {
+		kind, fields, err := src.record()
+		if err != nil {
+			return err
+		}
+		if kind == recordEnd {
+			if err := src.verify(); err != nil {
+				return err
+			}
+			return dst.close()
+		}
+		if err := dst.record(kind, fields...); err != nil {
+			return err
+		}
+	}
+}
+
+// backupWriter writes the records of a backup archive.
+type backupWriter struct {
+	bw   *bufio.Writer
+	h    hash.Hash
+	body io.Writer
+}
+
+// newBackupWriter starts an archive with `header` on `w`, the records are encrypted with
+// `password` when it is not empty.
+func newBackupWriter(w io.Writer, header backupHeader, password []byte) (*backupWriter, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(backupMagic); err != nil {
+		return nil, err
+	}
+
+	// the hash covers the header and the records as written, a checksum of a plain backup
+	// and the mac of an encrypted one
+	var h hash.Hash = sha256.New()
+	var body io.Writer = io.MultiWriter(bw, h)
+	headerOut := body
+	header.Encryption = nil
+	if len(password) > 0 {
+		enc := &backupEncryption{
+			ScryptN: backupScryptN,
+			ScryptR: backupScryptR,
+			ScryptP: backupScryptP,
+			Salt:    make([]byte, 32),
+			IV:      make([]byte, aes.BlockSize),
+		}
+		if _, err := io.ReadFull(rand.Reader, enc.Salt); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(rand.Reader, enc.IV); err != nil {
+			return nil, err
+		}
+		encKey, macKey, err := enc.keys(password)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(encKey)
+		if err != nil {
+			return nil, err
+		}
+		enc.Check = backupCheck(macKey)
+		header.Encryption = enc
+		h = hmac.New(sha256.New, macKey)
+		headerOut = io.MultiWriter(bw, h)
+		body = cipher.StreamWriter{S: cipher.NewCTR(block, enc.IV), W: headerOut}
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBytes(headerOut, headerBytes); err != nil {
+		return nil, err
+	}
+	return &backupWriter{bw: bw, h: h, body: body}, nil
+}
+
+func (w *backupWriter) record(kind byte, fields ...[]byte) error {
+	return writeRecord(w.body, kind, fields...)
+}
+
+// close ends the archive, with its checksum or its mac.
+func (w *backupWriter) close() error {
+	if err := writeRecord(w.body, recordEnd); err != nil {
+		return err
+	}
+	if _, err := w.bw.Write(w.h.Sum(nil)); err != nil {
+		return err
+	}
+	return w.bw.Flush()
+}
+
+// backupReader reads the records of a backup archive.
+type backupReader struct {
+	header backupHeader
+	br     *bufio.Reader
+	h      hash.Hash
+	body   io.Reader
+}
+
+// openBackup reads the header of the archive read from `rd`, which is decrypted with
+// `password` when it is encrypted.
+func openBackup(rd io.Reader, password []byte) (*backupReader, error) {
+	br := bufio.NewReader(rd)
+	magic := make([]byte, len(backupMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, backupMagic) {
+		return nil, errors.New("not a backup of a venus repo")
+	}
+
+	var h hash.Hash = sha256.New()
+	var headerIn io.Reader = io.TeeReader(br, h)
+	headerBytes, err := readBytes(headerIn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the backup header")
+	}
+	var header backupHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the backup header")
+	}
+	if header.FormatVersion != backupFormatVersion {
+		return nil, fmt.Errorf("unsupported backup format %d", header.FormatVersion)
+	}
+
+	body := headerIn
+	if enc := header.Encryption; enc != nil {
+		if len(password) == 0 {
+			return nil, ErrBackupPassword
+		}
+		encKey, macKey, err := enc.keys(password)
+		if err != nil {
+			return nil, err
+		}
+		if !hmac.Equal(enc.Check, backupCheck(macKey)) {
+			return nil, ErrBackupPassword
+		}
+		block, err := aes.NewCipher(encKey)
+		if err != nil {
+			return nil, err
+		}
+		// the mac covers the header as well
+		h = hmac.New(sha256.New, macKey)
+		if err := writeBytes(h, headerBytes); err != nil {
+			return nil, err
+		}
+		body = cipher.StreamReader{S: cipher.NewCTR(block, enc.IV), R: io.TeeReader(br, h)}
+	}
+	return &backupReader{header: header, br: br, h: h, body: body}, nil
+}
+
+func (r *backupReader) record() (byte, [][]byte, error) {
+	return readRecord(r.body)
+}
+
+// verify checks the checksum or the mac of the archive, once its end record is read.
+func (r *backupReader) verify() error {
+	sum := make([]byte, r.h.Size())
+	if _, err := io.ReadFull(r.br, sum); err != nil {
+		return errors.Wrap(err, "failed to read the backup")
+	}
+	if !hmac.Equal(sum, r.h.Sum(nil)) {
+		return errors.New("the backup is corrupted")
+	}
+	return nil
+}
+
+// Restore creates a repo at `repoPath` from the backup read from `rd`, decrypting it with
+// `password` when it is encrypted. The repo keeps the version of the backup, which must not
+// be newer than LatestVersion, and is to be migrated when opened. The blockstore of the repo
+// only holds the blocks of the backup, a read-only blockstore backend is replaced by the
+// backend of the other datastores. The head of the chain is left as it was backed up, see
+// chain.ResetHeadToGenesis. Nothing is left at `repoPath` when the restore fails.
+func Restore(ctx context.Context, rd io.Reader, repoPath string, password []byte) (*BackupResult, error) {
+	repoPath, err := homedir.Expand(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if exists, err := fileExists(repoPath); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, errors.Errorf("repo at %s, file exists", repoPath)
+	}
+
+	src, err := openBackup(rd, password)
+	if err != nil {
+		return nil, err
+	}
+	if src.header.RepoVersion > LatestVersion {
+		return nil, fmt.Errorf("the backup is of repo version %d, this binary handles up to version %d. Update binary to latest release", src.header.RepoVersion, LatestVersion)
+	}
+
+	res, err := restoreRecords(ctx, src, repoPath)
+	if err == nil {
+		err = src.verify()
+	}
+	if err != nil {
+		_ = os.RemoveAll(repoPath)
+		return nil, err
+	}
+	return res, nil
+}
+
+func restoreRecords(ctx context.Context, src *backupReader, repoPath string) (*BackupResult, error) {
+	kind, fields, err := src.record()
+	if err != nil {
+		return nil, err
+	}
+	if kind != recordConfig {
+		return nil, errors.New("the backup does not start with the config")
+	}
+	cfg := config.NewDefaultConfig()
+	if err := json.Unmarshal(fields[0], cfg); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the config")
+	}
+	if b, err := LookupBackend(cfg.Datastore.Type); err == nil && b.ReadOnly {
+		cfg.Datastore.Type = metadataBackendType(cfg.Datastore)
+		cfg.Datastore.MetadataType = ""
+		if b, err := LookupBackend(cfg.Datastore.Type); err == nil {
+			cfg.Datastore.Path = b.DefaultPath
+		}
+	}
+
+	version := src.header.RepoVersion
+	if err := InitFSRepo(repoPath, version, cfg); err != nil {
+		return nil, err
+	}
+	r, err := OpenFSRepo(repoPath, version)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close() // nolint: errcheck
+
+	res := &BackupResult{Entries: map[string]int{}}
+	stores := backupDatastores(r)
+	batches := map[string]datastore.Batch{}
+	var blks []blocks.Block
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		kind, fields, err := src.record()
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case recordEnd:
+			for name, batch := range batches {
+				if err := batch.Commit(); err != nil {
+					return nil, errors.Wrapf(err, "failed to restore the %s datastore", name)
+				}
+			}
+			if err := r.Datastore().PutMany(blks); err != nil {
+				return nil, errors.Wrap(err, "failed to restore the blocks")
+			}
+			return res, nil
+		case recordKey:
+			if len(fields) != 2 {
+				return nil, errors.New("malformed key record")
+			}
+			if err := r.Keystore().Put(string(fields[0]), fields[1]); err != nil {
+				return nil, errors.Wrapf(err, "failed to restore key %s", fields[0])
+			}
+			res.Keys++
+		case recordEntry:
+			if len(fields) != 3 {
+				return nil, errors.New("malformed datastore record")
+			}
+			name := string(fields[0])
+			ds, ok := stores[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown datastore %s in the backup", name)
+			}
+			batch, ok := batches[name]
+			if !ok {
+				if batch, err = ds.Batch(); err != nil {
+					return nil, err
+				}
+				batches[name] = batch
+			}
+			if err := batch.Put(datastore.NewKey(string(fields[1])), fields[2]); err != nil {
+				return nil, err
+			}
+			res.Entries[name]++
+			if res.Entries[name]%convertBatchSize == 0 {
+				if err := batch.Commit(); err != nil {
+					return nil, errors.Wrapf(err, "failed to restore the %s datastore", name)
+				}
+				delete(batches, name)
+			}
+		case recordBlock:
+			if len(fields) != 2 {
+				return nil, errors.New("malformed block record")
+			}
+			c, err := cid.Cast(fields[0])
+			if err != nil {
+				return nil, errors.Wrap(err, "malformed block record")
+			}
+			blk, err := blocks.NewBlockWithCid(fields[1], c)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to restore block %s", c)
+			}
+			blks = append(blks, blk)
+			res.Blocks++
+			if len(blks) == convertBatchSize {
+				if err := r.Datastore().PutMany(blks); err != nil {
+					return nil, errors.Wrap(err, "failed to restore the blocks")
+				}
+				blks = blks[:0]
+			}
+		default:
+			return nil, fmt.Errorf("unknown record kind %d in the backup", kind)
+		}
+	}
+}
+
+// maxBackupField bounds the fields read from a backup, a larger length means a corrupted
+// archive.
+const maxBackupField = 1 << 26
+
+// a record is its kind, the number of its fields and the fields, each prefixed by its length.
+func writeRecord(w io.Writer, kind byte, fields ...[]byte) error {
+	if _, err := w.Write([]byte{kind, byte(len(fields))}); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := writeBytes(w, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRecord(r io.Reader) (byte, [][]byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, nil, errors.Wrap(err, "failed to read the backup")
+	}
+	fields := make([][]byte, head[1])
+	for i := range fields {
+		f, err := readBytes(r)
+		if err != nil {
+			return 0, nil, errors.Wrap(err, "failed to read the backup")
+		}
+		fields[i] = f
+	}
+	return head[0], fields, nil
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(b)))
+	if _, err := w.Write(l[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readBytes(r io.Reader) ([]byte, error) {
+	var l [4]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(l[:])
+	if n > maxBackupField {
+		return nil, fmt.Errorf("field of %d bytes is too large", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
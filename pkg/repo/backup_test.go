@@ -0,0 +1,217 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestBackupRestore(t *testing.T) {
+	tf.UnitTest(t)
+	backupScryptN = 1 << 10
+
+	ctx := context.Background()
+	mr := NewInMemoryRepo()
+	mr.C.API.APIAddress = "/ip4/127.0.0.1/tcp/1234"
+	mr.C.Datastore = &config.DatastoreConfig{Type: "levelds", Path: "leveldb"}
+	require.NoError(t, mr.Keystore().Put("self", []byte("libp2p key")))
+	require.NoError(t, mr.WalletDatastore().Put(ds.NewKey("/wallet/key"), []byte("wallet")))
+	require.NoError(t, mr.PaychDatastore().Put(ds.NewKey("/paych/voucher"), []byte("voucher")))
+	require.NoError(t, mr.MetaDatastore().Put(ds.NewKey("/mpool/local"), []byte("msg")))
+	require.NoError(t, mr.ChainDatastore().Put(ds.NewKey("/head"), []byte("head")))
+
+	// the root links to a block in the blockstore and to one which is not
+	leaf := blocks.NewBlock([]byte("leaf"))
+	missing := blocks.NewBlock([]byte("missing"))
+	root, err := cbor.WrapObject(map[string]cid.Cid{"leaf": leaf.Cid(), "missing": missing.Cid()}, mh.SHA2_256, -1)
+	require.NoError(t, err)
+	other := blocks.NewBlock([]byte("other"))
+	require.NoError(t, mr.Datastore().PutMany([]blocks.Block{leaf, root, other}))
+
+	container, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer RequireRemoveAll(t, container)
+
+	for _, password := range []string{"", "secret"} {
+		var buf bytes.Buffer
+		res, err := Backup(ctx, mr, &buf, []byte(password), []cid.Cid{root.Cid()})
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Keys)
+		assert.Equal(t, map[string]int{"wallet": 1, "paych": 1, "metadata": 1, "chain": 1}, res.Entries)
+		assert.Equal(t, 2, res.Blocks)
+
+		repoPath := path.Join(container, "repo"+password)
+		res, err = Restore(ctx, bytes.NewReader(buf.Bytes()), repoPath, []byte(password))
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Keys)
+		assert.Equal(t, 4, len(res.Entries))
+		assert.Equal(t, 2, res.Blocks)
+
+		r, err := OpenFSRepo(repoPath, LatestVersion)
+		require.NoError(t, err)
+		assert.Equal(t, mr.C.API.APIAddress, r.Config().API.APIAddress)
+		assert.Equal(t, mr.C.Datastore, r.Config().Datastore)
+		key, err := r.Keystore().Get("self")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("libp2p key"), key)
+		val, err := r.PaychDatastore().Get(ds.NewKey("/paych/voucher"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("voucher"), val)
+		val, err = r.WalletDatastore().Get(ds.NewKey("/wallet/key"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("wallet"), val)
+		for _, blk := range []blocks.Block{root, leaf} {
+			got, err := r.Datastore().Get(blk.Cid())
+			require.NoError(t, err)
+			assert.Equal(t, blk.RawData(), got.RawData())
+		}
+		has, err := r.Datastore().Has(other.Cid())
+		require.NoError(t, err)
+		assert.False(t, has)
+		require.NoError(t, r.Close())
+
+		// a repo is never restored over another one
+		_, err = Restore(ctx, bytes.NewReader(buf.Bytes()), repoPath, []byte(password))
+		assert.Error(t, err)
+	}
+}
+
+func TestRestoreFailures(t *testing.T) {
+	tf.UnitTest(t)
+	backupScryptN = 1 << 10
+
+	ctx := context.Background()
+	mr := NewInMemoryRepo()
+	mr.C.Datastore = &config.DatastoreConfig{Type: "levelds", Path: "leveldb"}
+	require.NoError(t, mr.PaychDatastore().Put(ds.NewKey("/paych/voucher"), []byte("voucher")))
+
+	container, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer RequireRemoveAll(t, container)
+	repoPath := path.Join(container, "repo")
+
+	var buf bytes.Buffer
+	_, err = Backup(ctx, mr, &buf, []byte("secret"), nil)
+	require.NoError(t, err)
+	encrypted := buf.Bytes()
+
+	t.Run("the password is checked", func(t *testing.T) {
+		_, err := Restore(ctx, bytes.NewReader(encrypted), repoPath, nil)
+		assert.Equal(t, ErrBackupPassword, err)
+		_, err = Restore(ctx, bytes.NewReader(encrypted), repoPath, []byte("wrong"))
+		assert.Equal(t, ErrBackupPassword, err)
+		assertNotExist(t, repoPath)
+	})
+
+	t.Run("a corrupted backup is not restored", func(t *testing.T) {
+		corrupted := append([]byte{}, encrypted...)
+		corrupted[len(corrupted)-40] ^= 1
+		_, err := Restore(ctx, bytes.NewReader(corrupted), repoPath, []byte("secret"))
+		assert.Error(t, err)
+		assertNotExist(t, repoPath)
+
+		_, err = Restore(ctx, bytes.NewReader(encrypted[:len(encrypted)-10]), repoPath, []byte("secret"))
+		assert.Error(t, err)
+		assertNotExist(t, repoPath)
+	})
+
+	t.Run("a backup of a newer repo is refused", func(t *testing.T) {
+		mr.version = LatestVersion + 1
+		defer func() { mr.version = LatestVersion }()
+		var buf bytes.Buffer
+		_, err := Backup(ctx, mr, &buf, nil, nil)
+		require.NoError(t, err)
+		_, err = Restore(ctx, &buf, repoPath, nil)
+		assert.Contains(t, err.Error(), "Update binary to latest release")
+		assertNotExist(t, repoPath)
+	})
+
+	t.Run("not a backup", func(t *testing.T) {
+		cfg, err := json.Marshal(mr.Config())
+		require.NoError(t, err)
+		_, err = Restore(ctx, bytes.NewReader(cfg), repoPath, nil)
+		assert.EqualError(t, err, "not a backup of a venus repo")
+	})
+}
+
+func TestEncryptBackup(t *testing.T) {
+	tf.UnitTest(t)
+	backupScryptN = 1 << 10
+
+	ctx := context.Background()
+	mr := NewInMemoryRepo()
+	mr.C.Datastore = &config.DatastoreConfig{Type: "levelds", Path: "leveldb"}
+	require.NoError(t, mr.PaychDatastore().Put(ds.NewKey("/paych/voucher"), []byte("voucher")))
+
+	container, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer RequireRemoveAll(t, container)
+	repoPath := path.Join(container, "repo")
+
+	var plain bytes.Buffer
+	_, err = Backup(ctx, mr, &plain, nil, nil)
+	require.NoError(t, err)
+
+	t.Run("a corrupted backup is not encrypted", func(t *testing.T) {
+		corrupted := append([]byte{}, plain.Bytes()...)
+		corrupted[len(corrupted)-40] ^= 1
+		assert.Error(t, EncryptBackup(ioutil.Discard, bytes.NewReader(corrupted), []byte("secret")))
+	})
+
+	var encrypted bytes.Buffer
+	require.NoError(t, EncryptBackup(&encrypted, bytes.NewReader(plain.Bytes()), []byte("secret")))
+	_, err = Restore(ctx, bytes.NewReader(encrypted.Bytes()), repoPath, nil)
+	assert.Equal(t, ErrBackupPassword, err)
+
+	res, err := Restore(ctx, bytes.NewReader(encrypted.Bytes()), repoPath, []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"paych": 1}, res.Entries)
+}
+
+func TestBackupHoldsWrites(t *testing.T) {
+	tf.UnitTest(t)
+
+	container, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer RequireRemoveAll(t, container)
+	repoPath := path.Join(container, "repo")
+	require.NoError(t, InitFSRepo(repoPath, LatestVersion, config.NewDefaultConfig()))
+	r, err := OpenFSRepo(repoPath, LatestVersion)
+	require.NoError(t, err)
+	defer r.Close() // nolint: errcheck
+
+	// the writes wait while the snapshots of the datastores are taken
+	release := r.holdWrites()
+	done := make(chan error)
+	go func() {
+		done <- r.PaychDatastore().Put(ds.NewKey("/paych/voucher"), []byte("voucher"))
+	}()
+	select {
+	case <-done:
+		t.Fatal("the write was not held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	release()
+	require.NoError(t, <-done)
+}
+
+func assertNotExist(t *testing.T, path string) {
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,525 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+// convertBatchSize is how many blocks or entries are written at once while converting.
+const convertBatchSize = 1024
+
+// convertingSuffix marks the datastores being written by a conversion.
+const convertingSuffix = ".converting"
+
+// convertPendingFile holds the datastore config of the copies of a conversion, the repo
+// switches to them when it is opened next. It is kept until the switch is done, so that a switch
+// which is interrupted is resumed.
+const convertPendingFile = "convert.pending"
+
+// ConvertResult reports a conversion of the backend of a repo.
+type ConvertResult struct {
+	// Blocks is the number of blocks copied, zero when the blockstore kept its backend.
+	Blocks int
+	// Entries is the number of entries of the other datastores copied.
+	Entries int
+	// Leftovers are the paths of the data in the former backends, which can be removed once
+	// the node runs on the new one.
+	Leftovers []string
+}
+
+// Convert copies the data of the repo to the datastore backend `to` and switches the repo to
+// it, the repo must not be used by a running node and is closed when Convert returns. The
+// blockstore is copied to `path` in the repo, the default path of the backend when empty; a
+// read-only backend is written as a car file with `roots` as its roots, and the other
+// datastores then keep their backend. The data in the former backends is left in place.
+func (r *FSRepo) Convert(ctx context.Context, to, path string, roots []cid.Cid) (*ConvertResult, error) {
+	res, target, err := r.copyTo(ctx, to, path, roots)
+	if cerr := r.closeStores(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err == nil {
+		err = r.writePending(target)
+	}
+	if err == nil {
+		err = r.finishConversion()
+	}
+	if cerr := r.lockfile.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ConvertOnline copies the data of the repo of a running node to the datastore backend `to`,
+// with the blockstore at `path` in the repo, the default path of the backend when empty. The
+// writes of the node are mirrored to the copies from the time they are made, and the repo
+// switches to them the next time it is opened, once the node is restarted. The repo cannot be
+// converted online to a read-only backend. The data in the former backends is left in place.
+func (r *FSRepo) ConvertOnline(ctx context.Context, to, path string) (res *ConvertResult, err error) {
+	r.convertLk.Lock()
+	defer r.convertLk.Unlock()
+
+	c, err := r.newConversion(to, path)
+	if err != nil {
+		return nil, err
+	}
+	if c.backend.ReadOnly {
+		return nil, fmt.Errorf("the %s backend is read-only, a running node cannot be converted to it, stop the daemon first", to)
+	}
+	if _, err := os.Stat(filepath.Join(r.path, convertPendingFile)); !os.IsNotExist(err) || r.mirror.active() {
+		return nil, errors.New("the repo is converted already, restart the node to switch to the new backend")
+	}
+
+	res = &ConvertResult{}
+	r.mirror.start(func(err error) {
+		log.Errorf("the conversion of the repo is dropped, the node keeps its backend: failed to mirror a write: %v", err)
+		r.dropConversion(c)
+	})
+	defer func() {
+		if err != nil {
+			r.dropConversion(c)
+		}
+	}()
+
+	if c.copyBlocks {
+		dst, err := c.backend.OpenBlockstore(c.blocksPath)
+		if err != nil {
+			return nil, err
+		}
+		// the blocks put from now on are mirrored, those put before are copied
+		r.mirror.mirrorBlocks(dst)
+		if res.Blocks, err = copyBlocks(ctx, r.ds, dst); err != nil {
+			return nil, errors.Wrap(err, "failed to convert the blockstore")
+		}
+	}
+
+	if c.copyMeta {
+		dsts := map[string]Datastore{}
+		for prefix := range r.metadataStores() {
+			path := filepath.Join(r.path, prefix+convertingSuffix)
+			if err := os.RemoveAll(path); err != nil {
+				return nil, err
+			}
+			dst, err := openBackendDatastore(r.path, prefix+convertingSuffix, c.target)
+			if err != nil {
+				for _, ds := range dsts {
+					_ = ds.Close()
+				}
+				return nil, err
+			}
+			dsts[prefix] = dst
+		}
+		// the writes are mirrored from now on, the entries copied do not overwrite those written
+		// meanwhile
+		r.mirror.mirrorDatastores(dsts)
+		for prefix, src := range r.metadataStores() {
+			dst := dsts[prefix]
+			n, err := copyEntries(ctx, src, dst, func(entries []query.Entry) error {
+				return r.mirror.copyEntries(prefix, dst, entries)
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to convert the %s datastore", prefix)
+			}
+			res.Entries += n
+		}
+		r.mirror.copied()
+	}
+
+	if err := r.writePending(c.target); err != nil {
+		return nil, err
+	}
+	if err := r.mirror.failed(); err != nil {
+		return nil, errors.Wrap(err, "failed to mirror the writes to the converted datastores")
+	}
+	res.Leftovers = r.leftovers(c)
+	return res, nil
+}
+
+// dropConversion stops mirroring the writes to the copies of the online conversion `c` and
+// removes them, the node keeps its backend.
+func (r *FSRepo) dropConversion(c *conversion) {
+	if err := r.mirror.close(); err != nil {
+		log.Warnf("closing the converted datastores: %v", err)
+	}
+	if err := os.Remove(filepath.Join(r.path, convertPendingFile)); err != nil && !os.IsNotExist(err) {
+		log.Errorf("removing %s, remove it before restarting the node: %v", convertPendingFile, err)
+	}
+	var paths []string
+	if c.copyBlocks {
+		paths = append(paths, c.blocksPath)
+	}
+	if c.copyMeta {
+		for prefix := range r.metadataStores() {
+			paths = append(paths, filepath.Join(r.path, prefix+convertingSuffix))
+		}
+	}
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			log.Warnf("removing the converted datastore %s: %v", path, err)
+		}
+	}
+}
+
+// writePending records the datastore config of the copies, the repo switches to them when it
+// is opened next.
+func (r *FSRepo) writePending(target *config.DatastoreConfig) error {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	pending := filepath.Join(r.path, convertPendingFile)
+	if err := ioutil.WriteFile(pending+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(pending+".tmp", pending)
+}
+
+// finishConversion switches the repo, whose stores are not open, to the copies recorded by
+// writePending.
+func (r *FSRepo) finishConversion() error {
+	pending := filepath.Join(r.path, convertPendingFile)
+	data, err := ioutil.ReadFile(pending)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var target config.DatastoreConfig
+	if err := json.Unmarshal(data, &target); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", pending)
+	}
+	c := &conversion{
+		copyBlocks: target.Type != r.Config().Datastore.Type || target.Path != r.Config().Datastore.Path,
+		copyMeta:   metadataBackendType(&target) != metadataBackendType(r.Config().Datastore),
+	}
+	leftovers := r.leftovers(c)
+	if err := r.switchTo(&target); err != nil {
+		return err
+	}
+	if err := os.Remove(pending); err != nil {
+		return err
+	}
+	log.Infof("the repo is switched to the %s backend, the former data can be removed: %s", target.Type, strings.Join(leftovers, ", "))
+	return nil
+}
+
+// conversion is where the data of the repo is copied to by a conversion.
+type conversion struct {
+	backend *Backend
+	// target is the datastore config of the copies
+	target     *config.DatastoreConfig
+	blocksPath string
+	// copyBlocks and copyMeta are set when the blockstore, and the other datastores, are not
+	// kept in their target backend already
+	copyBlocks bool
+	copyMeta   bool
+}
+
+// newConversion returns the conversion of the repo to the backend `to`, with its blockstore
+// at `path`.
+func (r *FSRepo) newConversion(to, path string) (*conversion, error) {
+	b, err := LookupBackend(to)
+	if err != nil {
+		return nil, err
+	}
+
+	from := r.Config().Datastore
+	target := *from
+	target.Type = to
+	target.Path = path
+	if target.Path == "" {
+		target.Path = b.DefaultPath
+	}
+	if b.ReadOnly {
+		target.MetadataType = metadataBackendType(from)
+	} else {
+		target.MetadataType = ""
+	}
+
+	c := &conversion{
+		backend:    b,
+		target:     &target,
+		blocksPath: filepath.Join(r.path, target.Path),
+		copyBlocks: target.Type != from.Type || target.Path != from.Path,
+		copyMeta:   metadataBackendType(&target) != metadataBackendType(from),
+	}
+	if !c.copyBlocks && !c.copyMeta {
+		return nil, fmt.Errorf("the repo is kept in %s already", to)
+	}
+	if c.copyBlocks {
+		if c.blocksPath == filepath.Join(r.path, from.Path) {
+			return nil, fmt.Errorf("the blockstore is at %s already, choose another path", from.Path)
+		}
+		if _, err := os.Stat(c.blocksPath); !os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s exists already", c.blocksPath)
+		}
+	}
+	// the switch moves the datastores aside to where the data of a former conversion may be left
+	if c.copyMeta {
+		fromMeta := metadataBackendType(from)
+		for prefix := range r.metadataStores() {
+			old := filepath.Join(r.path, prefix+"."+fromMeta)
+			if _, err := os.Stat(old); !os.IsNotExist(err) {
+				return nil, fmt.Errorf("%s exists already, remove the data of the former conversion first", old)
+			}
+		}
+	}
+	return c, nil
+}
+
+// leftovers returns the paths the data of the former backends is left at once the repo is
+// switched to the copies.
+func (r *FSRepo) leftovers(c *conversion) []string {
+	var paths []string
+	if c.copyBlocks {
+		paths = append(paths, filepath.Join(r.path, r.Config().Datastore.Path))
+	}
+	if c.copyMeta {
+		fromMeta := metadataBackendType(r.Config().Datastore)
+		for prefix := range r.metadataStores() {
+			paths = append(paths, filepath.Join(r.path, prefix+"."+fromMeta))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// copyTo copies the data of the repo to the backend `to`, it returns the datastore config of
+// the copies.
+func (r *FSRepo) copyTo(ctx context.Context, to, path string, roots []cid.Cid) (*ConvertResult, *config.DatastoreConfig, error) {
+	c, err := r.newConversion(to, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := &ConvertResult{}
+	if c.copyBlocks {
+		if res.Blocks, err = r.convertBlockstore(ctx, c.backend, c.blocksPath, roots); err != nil {
+			_ = os.RemoveAll(c.blocksPath)
+			return nil, nil, errors.Wrap(err, "failed to convert the blockstore")
+		}
+	}
+
+	if c.copyMeta {
+		for prefix, src := range r.metadataStores() {
+			n, err := convertDatastore(ctx, src, r.path, prefix+convertingSuffix, c.target)
+			if err != nil {
+				if c.copyBlocks {
+					_ = os.RemoveAll(c.blocksPath)
+				}
+				return nil, nil, errors.Wrapf(err, "failed to convert the %s datastore", prefix)
+			}
+			res.Entries += n
+		}
+	}
+	res.Leftovers = r.leftovers(c)
+	return res, c.target, nil
+}
+
+// switchTo makes the closed repo use the copies made by copyTo or ConvertOnline. Each step is
+// skipped when it is done already, so that a switch which was interrupted is resumed, and the
+// config is written last.
+func (r *FSRepo) switchTo(target *config.DatastoreConfig) error {
+	fromMeta := metadataBackendType(r.Config().Datastore)
+	if metadataBackendType(target) != fromMeta {
+		for prefix := range r.metadataStores() {
+			if err := r.switchDatastore(prefix, fromMeta); err != nil {
+				return errors.Wrapf(err, "failed to switch the %s datastore", prefix)
+			}
+		}
+	}
+
+	cfg := *r.Config()
+	cfg.Datastore = target
+	if err := r.ReplaceConfig(&cfg); err != nil {
+		return errors.Wrap(err, "failed to write the config, the data is converted but the datastore config must be updated by hand")
+	}
+	return nil
+}
+
+// switchDatastore moves the datastore `prefix` aside, to `prefix.<fromMeta>`, and its copy in
+// its place.
+func (r *FSRepo) switchDatastore(prefix, fromMeta string) error {
+	cur := filepath.Join(r.path, prefix)
+	old := filepath.Join(r.path, prefix+"."+fromMeta)
+	converting := filepath.Join(r.path, prefix+convertingSuffix)
+
+	hasCur, err := fileExists(cur)
+	if err != nil {
+		return err
+	}
+	hasOld, err := fileExists(old)
+	if err != nil {
+		return err
+	}
+	hasConverting, err := fileExists(converting)
+	if err != nil {
+		return err
+	}
+
+	if !hasConverting {
+		if hasCur && hasOld {
+			// switched already
+			return nil
+		}
+		return fmt.Errorf("the copy %s is missing", converting)
+	}
+	if hasCur {
+		if hasOld {
+			return fmt.Errorf("%s exists already", old)
+		}
+		if err := os.Rename(cur, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(converting, cur)
+}
+
+// metadataStores returns the datastores of the repo kept in its metadata backend, by prefix.
+func (r *FSRepo) metadataStores() map[string]Datastore {
+	return map[string]Datastore{
+		chainDatastorePrefix:   r.chainDs,
+		metaDatastorePrefix:    r.metaDs,
+		paychDatastorePrefix:   r.paychDs,
+		walletDatastorePrefix:  r.walletDs,
+		stagingDatastorePrefix: r.stagingDs,
+	}
+}
+
+func (r *FSRepo) convertBlockstore(ctx context.Context, b *Backend, path string, roots []cid.Cid) (int, error) {
+	if b.ReadOnly {
+		var n int
+		counted := &countingBlockstore{Blockstore: r.ds, n: &n}
+		err := blockstoreutil.WriteCarFile(ctx, counted, roots, path)
+		return n, err
+	}
+
+	dst, err := b.OpenBlockstore(path)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close() // nolint: errcheck
+	return copyBlocks(ctx, r.ds, dst)
+}
+
+// copyBlocks copies the blocks of `src` to `dst`.
+func copyBlocks(ctx context.Context, src, dst blockstoreutil.Blockstore) (int, error) {
+	keys, err := src.AllKeysChan(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	batch := make([]blocks.Block, 0, convertBatchSize)
+	for k := range keys {
+		blk, err := src.Get(k)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get block %s", k)
+		}
+		batch = append(batch, blk)
+		if len(batch) == convertBatchSize {
+			if err := dst.PutMany(batch); err != nil {
+				return 0, err
+			}
+			n += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := dst.PutMany(batch); err != nil {
+		return 0, err
+	}
+	return n + len(batch), nil
+}
+
+// convertDatastore copies the entries of `src` to the datastore `prefix` of the repo at
+// `repoPath`, in the metadata backend of `cfg`.
+func convertDatastore(ctx context.Context, src Datastore, repoPath, prefix string, cfg *config.DatastoreConfig) (int, error) {
+	if err := os.RemoveAll(filepath.Join(repoPath, prefix)); err != nil {
+		return 0, err
+	}
+	dst, err := openBackendDatastore(repoPath, prefix, cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close() // nolint: errcheck
+	return copyDatastore(ctx, src, dst)
+}
+
+// copyDatastore copies the entries of `src` to `dst`.
+func copyDatastore(ctx context.Context, src, dst Datastore) (int, error) {
+	return copyEntries(ctx, src, dst, func(entries []query.Entry) error {
+		batch, err := dst.Batch()
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := batch.Put(datastore.NewKey(e.Key), e.Value); err != nil {
+				return err
+			}
+		}
+		return batch.Commit()
+	})
+}
+
+// copyEntries reads the entries of `src` and passes them to `write` in batches, which writes
+// them to `dst`.
+func copyEntries(ctx context.Context, src, dst Datastore, write func([]query.Entry) error) (int, error) {
+	results, err := src.Query(query.Query{})
+	if err != nil {
+		return 0, err
+	}
+	defer results.Close() // nolint: errcheck
+
+	var n int
+	entries := make([]query.Entry, 0, convertBatchSize)
+	for res := range results.Next() {
+		if res.Error != nil {
+			return 0, res.Error
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		entries = append(entries, res.Entry)
+		n++
+		if len(entries) == convertBatchSize {
+			if err := write(entries); err != nil {
+				return 0, err
+			}
+			entries = entries[:0]
+		}
+	}
+	if err := write(entries); err != nil {
+		return 0, err
+	}
+	return n, dst.Sync(datastore.NewKey("/"))
+}
+
+// countingBlockstore counts the blocks read from it.
+type countingBlockstore struct {
+	blockstoreutil.Blockstore
+	n *int
+}
+
+func (bs *countingBlockstore) Get(k cid.Cid) (blocks.Block, error) {
+	*bs.n++
+	return bs.Blockstore.Get(k)
+}
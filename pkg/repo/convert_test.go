@@ -0,0 +1,193 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+func TestFSRepoConvert(t *testing.T) {
+	tf.UnitTest(t)
+
+	container, err := ioutil.TempDir("", "convert")
+	require.NoError(t, err)
+	defer RequireRemoveAll(t, container)
+
+	repoPath := path.Join(container, "repo")
+	require.NoError(t, InitFSRepo(repoPath, 42, config.NewDefaultConfig()))
+
+	r, err := OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	blk := blocks.NewBlock([]byte("beep"))
+	require.NoError(t, r.Datastore().Put(blk))
+	require.NoError(t, r.ChainDatastore().Put(ds.NewKey("beep"), []byte("boop")))
+
+	// badger to leveldb, every datastore is copied
+	res, err := r.Convert(context.Background(), "levelds", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Blocks)
+	assert.Equal(t, 1, res.Entries)
+	assert.Contains(t, res.Leftovers, filepath.Join(repoPath, "badger"))
+	assert.Contains(t, res.Leftovers, filepath.Join(repoPath, "chain.badgerds"))
+
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	assert.Equal(t, &config.DatastoreConfig{Type: "levelds", Path: "leveldb"}, r.Config().Datastore)
+	has, err := r.Datastore().Has(blk.Cid())
+	require.NoError(t, err)
+	assert.True(t, has)
+	val, err := r.ChainDatastore().Get(ds.NewKey("beep"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("boop"), val)
+
+	_, err = r.Convert(context.Background(), "levelds", "", nil)
+	assert.Error(t, err)
+
+	// leveldb to a read-only car file, the other datastores stay in leveldb
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	_, err = r.Convert(context.Background(), "car", "", []cid.Cid{blk.Cid()})
+	require.NoError(t, err)
+
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	assert.Equal(t, &config.DatastoreConfig{Type: "car", Path: "chain.car", MetadataType: "levelds"}, r.Config().Datastore)
+	got, err := r.Datastore().Get(blk.Cid())
+	require.NoError(t, err)
+	assert.Equal(t, blk.RawData(), got.RawData())
+	assert.Equal(t, blockstoreutil.ErrReadOnlyBlockstore, r.Datastore().Put(blocks.NewBlock([]byte("boop"))))
+	assert.Equal(t, []cid.Cid{blk.Cid()}, r.Datastore().(*blockstoreutil.CarBlockstore).Roots())
+	val, err = r.ChainDatastore().Get(ds.NewKey("beep"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("boop"), val)
+	require.NoError(t, r.Close())
+}
+
+func TestFSRepoConvertOnline(t *testing.T) {
+	tf.UnitTest(t)
+
+	repoPath := path.Join(t.TempDir(), "repo")
+	require.NoError(t, InitFSRepo(repoPath, 42, config.NewDefaultConfig()))
+
+	r, err := OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	before := blocks.NewBlock([]byte("before"))
+	require.NoError(t, r.Datastore().Put(before))
+	require.NoError(t, r.ChainDatastore().Put(ds.NewKey("before"), []byte("before")))
+	require.NoError(t, r.ChainDatastore().Put(ds.NewKey("deleted"), []byte("deleted")))
+	// a batch started before the conversion is committed after it
+	batch, err := r.MetaDatastore().Batch()
+	require.NoError(t, err)
+	require.NoError(t, batch.Put(ds.NewKey("batched"), []byte("batched")))
+
+	_, err = r.ConvertOnline(context.Background(), "car", "")
+	assert.Error(t, err)
+
+	res, err := r.ConvertOnline(context.Background(), "levelds", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Blocks)
+	assert.Equal(t, 2, res.Entries)
+	assert.Contains(t, res.Leftovers, filepath.Join(repoPath, "badger"))
+	assert.Contains(t, res.Leftovers, filepath.Join(repoPath, "chain.badgerds"))
+	_, err = r.ConvertOnline(context.Background(), "levelds", "other")
+	assert.Error(t, err)
+
+	// the node keeps writing to its stores, the writes are mirrored to the copies
+	assert.Equal(t, "badgerds", r.Config().Datastore.Type)
+	after := blocks.NewBlock([]byte("after"))
+	require.NoError(t, r.Datastore().Put(after))
+	require.NoError(t, r.ChainDatastore().Put(ds.NewKey("after"), []byte("after")))
+	require.NoError(t, r.ChainDatastore().Delete(ds.NewKey("deleted")))
+	require.NoError(t, batch.Commit())
+	require.NoError(t, r.Close())
+
+	// the repo switches to the copies when it is opened again
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	assert.Equal(t, &config.DatastoreConfig{Type: "levelds", Path: "leveldb"}, r.Config().Datastore)
+	for _, blk := range []blocks.Block{before, after} {
+		has, err := r.Datastore().Has(blk.Cid())
+		require.NoError(t, err)
+		assert.True(t, has)
+	}
+	for k, d := range map[string]Datastore{"before": r.ChainDatastore(), "after": r.ChainDatastore(), "batched": r.MetaDatastore()} {
+		val, err := d.Get(ds.NewKey(k))
+		require.NoError(t, err)
+		assert.Equal(t, []byte(k), val)
+	}
+	_, err = r.ChainDatastore().Get(ds.NewKey("deleted"))
+	assert.Equal(t, ds.ErrNotFound, err)
+	require.NoError(t, r.Close())
+}
+
+func TestFSRepoConvertResumesSwitch(t *testing.T) {
+	tf.UnitTest(t)
+
+	repoPath := path.Join(t.TempDir(), "repo")
+	require.NoError(t, InitFSRepo(repoPath, 42, config.NewDefaultConfig()))
+
+	r, err := OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	require.NoError(t, r.ChainDatastore().Put(ds.NewKey("beep"), []byte("boop")))
+	_, err = r.ConvertOnline(context.Background(), "levelds", "")
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	// the switch is interrupted once the chain datastore is switched
+	chainPath := filepath.Join(repoPath, chainDatastorePrefix)
+	require.NoError(t, os.Rename(chainPath, chainPath+".badgerds"))
+	require.NoError(t, os.Rename(chainPath+convertingSuffix, chainPath))
+
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	assert.Equal(t, &config.DatastoreConfig{Type: "levelds", Path: "leveldb"}, r.Config().Datastore)
+	val, err := r.ChainDatastore().Get(ds.NewKey("beep"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("boop"), val)
+	_, err = os.Stat(filepath.Join(repoPath, convertPendingFile))
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, r.Close())
+}
+
+func TestFSRepoConvertOnlineDropped(t *testing.T) {
+	tf.UnitTest(t)
+
+	repoPath := path.Join(t.TempDir(), "repo")
+	require.NoError(t, InitFSRepo(repoPath, 42, config.NewDefaultConfig()))
+
+	r, err := OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	_, err = r.ConvertOnline(context.Background(), "levelds", "")
+	require.NoError(t, err)
+
+	// a write the copies missed drops the conversion and its copies
+	r.mirror.fail(errors.New("boom"))
+	for _, p := range []string{convertPendingFile, "leveldb", chainDatastorePrefix + convertingSuffix} {
+		_, err = os.Stat(filepath.Join(repoPath, p))
+		assert.True(t, os.IsNotExist(err), p)
+	}
+
+	// the repo can be converted again
+	_, err = r.ConvertOnline(context.Background(), "levelds", "")
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	r, err = OpenFSRepo(repoPath, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "levelds", r.Config().Datastore.Type)
+	require.NoError(t, r.Close())
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/filecoin-project/venus/pkg/repo/fskeystore"
 
 	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
-	bstore "github.com/ipfs/go-ipfs-blockstore"
 
 	"github.com/filecoin-project/go-multistore"
 	badgerds "github.com/ipfs/go-ds-badger2"
@@ -41,6 +40,7 @@ const (
 	chainDatastorePrefix   = "chain"
 	metaDatastorePrefix    = "metadata"
 	paychDatastorePrefix   = "paych"
+	stagingDatastorePrefix = "staging"
 	snapshotStorePrefix    = "snapshots"
 	snapshotFilenamePrefix = "snapshot"
 	dataTransfer           = "data-transfer"
@@ -58,7 +58,7 @@ type FSRepo struct {
 	lk  sync.RWMutex
 	cfg *config.Config
 
-	ds        *blockstoreutil.BadgerBlockstore
+	ds        ClosableBlockstore
 	stagingDs Datastore
 	mds       *multistore.MultiStore
 	keystore  fskeystore.Keystore
@@ -67,6 +67,12 @@ type FSRepo struct {
 	metaDs    Datastore
 	//marketDs  Datastore
 	paychDs Datastore
+	// writes holds the writes to the datastores in a backup
+	writes writeHold
+	// mirror copies the writes to the stores to the copies of an online conversion
+	mirror writeMirror
+	// convertLk is held by an online conversion
+	convertLk sync.Mutex
 	// lockfile is the file system lock to prevent others from opening the same repo.
 	lockfile io.Closer
 }
@@ -230,6 +236,10 @@ func (r *FSRepo) loadFromDisk() error {
 		return errors.Wrap(err, "failed to load config file")
 	}
 
+	if err := r.finishConversion(); err != nil {
+		return errors.Wrap(err, "failed to switch to the converted datastores")
+	}
+
 	if err := r.openDatastore(); err != nil {
 		return errors.Wrap(err, "failed to open datastore")
 	}
@@ -346,6 +356,24 @@ func (r *FSRepo) Keystore() fskeystore.Keystore {
 
 // Close closes the repo.
 func (r *FSRepo) Close() error {
+	if err := r.closeStores(); err != nil {
+		return err
+	}
+
+	if err := r.removeAPIFile(); err != nil {
+		return errors.Wrap(err, "error removing API file")
+	}
+
+	return r.lockfile.Close()
+}
+
+// holdWrites holds the writes to the chain, metadata, paych, wallet and staging datastores
+// until the returned func is called.
+func (r *FSRepo) holdWrites() func() {
+	return r.writes.hold()
+}
+
+func (r *FSRepo) closeStores() error {
 	if err := r.ds.Close(); err != nil {
 		return errors.Wrap(err, "failed to close datastore")
 	}
@@ -374,15 +402,14 @@ func (r *FSRepo) Close() error {
 		return errors.Wrap(err, "failed to close paych datastore")
 	}
 
+	if err := r.mirror.close(); err != nil {
+		return errors.Wrap(err, "failed to close the converted datastores")
+	}
+
 	/*if err := r.marketDs.Close(); err != nil {
 		return errors.Wrap(err, "failed to close market datastore")
 	}*/
-
-	if err := r.removeAPIFile(); err != nil {
-		return errors.Wrap(err, "error removing API file")
-	}
-
-	return r.lockfile.Close()
+	return nil
 }
 
 func (r *FSRepo) removeFile(path string) error {
@@ -430,21 +457,19 @@ func (r *FSRepo) readVersion() (uint, error) {
 }
 
 func (r *FSRepo) openDatastore() error {
-	switch r.cfg.Datastore.Type {
-	case "badgerds":
-		path := filepath.Join(r.path, r.cfg.Datastore.Path)
-		opts, err := blockstoreutil.BadgerBlockstoreOptions(path, false)
-		if err != nil {
-			return err
-		}
-		opts.Prefix = bstore.BlockPrefix.String()
-		ds, err := blockstoreutil.Open(opts)
-		if err != nil {
-			return err
-		}
+	b, err := LookupBackend(r.cfg.Datastore.Type)
+	if err != nil {
+		return err
+	}
+	ds, err := b.OpenBlockstore(filepath.Join(r.path, r.cfg.Datastore.Path))
+	if err != nil {
+		return err
+	}
+	// nothing is written to a read-only blockstore
+	if b.ReadOnly {
 		r.ds = ds
-	default:
-		return fmt.Errorf("unknown datastore type in config: %s", r.cfg.Datastore.Type)
+	} else {
+		r.ds = r.mirror.wrapBlockstore(ds)
 	}
 
 	return nil
@@ -464,32 +489,32 @@ func (r *FSRepo) openKeystore() error {
 }
 
 func (r *FSRepo) openChainDatastore() error {
-	ds, err := badgerds.NewDatastore(filepath.Join(r.path, chainDatastorePrefix), badgerOptions())
+	ds, err := openBackendDatastore(r.path, chainDatastorePrefix, r.cfg.Datastore)
 	if err != nil {
 		return err
 	}
 
-	r.chainDs = ds
+	r.chainDs = r.writes.wrap(r.mirror.wrap(chainDatastorePrefix, ds))
 
 	return nil
 }
 
 func (r *FSRepo) openMetaDatastore() error {
-	ds, err := badgerds.NewDatastore(filepath.Join(r.path, metaDatastorePrefix), badgerOptions())
+	ds, err := openBackendDatastore(r.path, metaDatastorePrefix, r.cfg.Datastore)
 	if err != nil {
 		return err
 	}
 
-	r.metaDs = ds
+	r.metaDs = r.writes.wrap(r.mirror.wrap(metaDatastorePrefix, ds))
 
 	return nil
 }
 func (r *FSRepo) openPaychDataStore() error {
-	var err error
-	r.paychDs, err = badgerds.NewDatastore(filepath.Join(r.path, paychDatastorePrefix), badgerOptions())
+	ds, err := openBackendDatastore(r.path, paychDatastorePrefix, r.cfg.Datastore)
 	if err != nil {
 		return err
 	}
+	r.paychDs = r.writes.wrap(r.mirror.wrap(paychDatastorePrefix, ds))
 	return nil
 }
 
@@ -502,23 +527,22 @@ func (r *FSRepo) openPaychDataStore() error {
 	return nil
 }*/
 func (r *FSRepo) openWalletDatastore() error {
-	// TODO: read wallet datastore info from config, use that to open it up
-	ds, err := badgerds.NewDatastore(filepath.Join(r.path, walletDatastorePrefix), badgerOptions())
+	ds, err := openBackendDatastore(r.path, walletDatastorePrefix, r.cfg.Datastore)
 	if err != nil {
 		return err
 	}
 
-	r.walletDs = ds
+	r.walletDs = r.writes.wrap(r.mirror.wrap(walletDatastorePrefix, ds))
 
 	return nil
 }
 
 func (r *FSRepo) openMultiStore() error {
-	var err error
-	r.stagingDs, err = badgerds.NewDatastore(filepath.Join(r.path, "/staging"), badgerOptions())
+	ds, err := openBackendDatastore(r.path, stagingDatastorePrefix, r.cfg.Datastore)
 	if err != nil {
 		return err
 	}
+	r.stagingDs = r.writes.wrap(r.mirror.wrap(stagingDatastorePrefix, ds))
 
 	mds, err := multistore.NewMultiDstore(r.stagingDs)
 	if err != nil {
@@ -667,6 +691,11 @@ func (r *FSRepo) JournalPath() string {
 	return fmt.Sprintf("%s/journal.json", r.path)
 }
 
+// IsLocked returns whether the repo at `repoPath` is opened by a running node.
+func IsLocked(repoPath string) (bool, error) {
+	return lockfile.Locked(repoPath, lockFile)
+}
+
 // APIAddrFromRepoPath returns the api addr from the filecoin repo
 func APIAddrFromRepoPath(repoPath string) (string, error) {
 	repoPath, err := homedir.Expand(repoPath)
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"sync"
+
+	"github.com/ipfs/go-datastore"
+)
+
+// writeHold holds the writes to the datastores it wraps, a backup holds them while it takes
+// its snapshots of the datastores so that they are all taken at the same point.
+type writeHold struct {
+	lk sync.RWMutex
+}
+
+// wrap returns `ds` with its writes going through the hold.
+func (h *writeHold) wrap(ds Datastore) Datastore {
+	return &heldDatastore{Datastore: ds, hold: h}
+}
+
+// hold holds the writes until the returned func is called, the writes in progress are
+// completed first.
+func (h *writeHold) hold() func() {
+	h.lk.Lock()
+	return h.lk.Unlock
+}
+
+type heldDatastore struct {
+	Datastore
+	hold *writeHold
+}
+
+func (d *heldDatastore) Put(key datastore.Key, value []byte) error {
+	d.hold.lk.RLock()
+	defer d.hold.lk.RUnlock()
+	return d.Datastore.Put(key, value)
+}
+
+func (d *heldDatastore) Delete(key datastore.Key) error {
+	d.hold.lk.RLock()
+	defer d.hold.lk.RUnlock()
+	return d.Datastore.Delete(key)
+}
+
+func (d *heldDatastore) Batch() (datastore.Batch, error) {
+	b, err := d.Datastore.Batch()
+	if err != nil {
+		return nil, err
+	}
+	return &heldBatch{Batch: b, hold: d.hold}, nil
+}
+
+type heldBatch struct {
+	datastore.Batch
+	hold *writeHold
+}
+
+func (b *heldBatch) Commit() error {
+	b.hold.lk.RLock()
+	defer b.hold.lk.RUnlock()
+	return b.Batch.Commit()
+}
@@ -0,0 +1,339 @@
+package repo
+
+import (
+	"sync"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+
+	"github.com/filecoin-project/venus/pkg/util/blockstoreutil"
+)
+
+// writeMirror copies the writes to the stores it wraps to the copies of an online conversion,
+// so that the copies stay up to date until the node stops and the repo switches to them, see
+// FSRepo.ConvertOnline. It copies nothing until the copies are set.
+type writeMirror struct {
+	// lk is held to write to the copies, and to set them
+	lk     sync.RWMutex
+	blocks ClosableBlockstore
+	stores map[string]Datastore
+
+	// copyLk is held to write to the copies of the datastores while their entries are copied,
+	// written holds the keys written to them meanwhile, by prefix, which the copy must not
+	// overwrite with their former values
+	copyLk  sync.Mutex
+	written map[string]map[string]struct{}
+
+	errLk sync.Mutex
+	err   error
+	// onFail is called once when a write to the copies fails, the copies are then out of date
+	onFail func(error)
+}
+
+// start prepares the mirror for a conversion, `onFail` is called if a write to its copies
+// fails.
+func (m *writeMirror) start(onFail func(error)) {
+	m.errLk.Lock()
+	defer m.errLk.Unlock()
+	m.err = nil
+	m.onFail = onFail
+}
+
+// wrapBlockstore returns `bs` with its writes mirrored.
+func (m *writeMirror) wrapBlockstore(bs ClosableBlockstore) ClosableBlockstore {
+	return &mirroredBlockstore{ClosableBlockstore: bs, mirror: m}
+}
+
+// wrap returns the datastore `prefix` of the repo with its writes mirrored.
+func (m *writeMirror) wrap(prefix string, ds Datastore) Datastore {
+	return &mirroredDatastore{Datastore: ds, mirror: m, prefix: prefix}
+}
+
+// active returns whether the writes are being mirrored.
+func (m *writeMirror) active() bool {
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+	return m.blocks != nil || m.stores != nil
+}
+
+// mirrorBlocks mirrors the writes of the blockstore to `bs`.
+func (m *writeMirror) mirrorBlocks(bs ClosableBlockstore) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.blocks = bs
+}
+
+// mirrorDatastores mirrors the writes of the datastores to `stores`, by prefix, and tracks the
+// keys written until copied is called.
+func (m *writeMirror) mirrorDatastores(stores map[string]Datastore) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.stores = stores
+
+	m.copyLk.Lock()
+	defer m.copyLk.Unlock()
+	m.written = make(map[string]map[string]struct{}, len(stores))
+	for prefix := range stores {
+		m.written[prefix] = map[string]struct{}{}
+	}
+}
+
+// copyEntries writes the entries copied from the datastore `prefix` to its copy `dst`, but
+// those written by the node since the writes are mirrored, which are newer.
+func (m *writeMirror) copyEntries(prefix string, dst Datastore, entries []query.Entry) error {
+	m.copyLk.Lock()
+	defer m.copyLk.Unlock()
+	written := m.written[prefix]
+	batch, err := dst.Batch()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		k := datastore.NewKey(e.Key)
+		if _, ok := written[k.String()]; ok {
+			continue
+		}
+		if err := batch.Put(k, e.Value); err != nil {
+			return err
+		}
+	}
+	return batch.Commit()
+}
+
+// copied stops tracking the keys written, once the entries of the datastores are copied.
+func (m *writeMirror) copied() {
+	m.copyLk.Lock()
+	defer m.copyLk.Unlock()
+	m.written = nil
+}
+
+// failed returns the error of the first write to the copies which failed.
+func (m *writeMirror) failed() error {
+	m.errLk.Lock()
+	defer m.errLk.Unlock()
+	return m.err
+}
+
+// fail records the error of a write to the copies, it is called without holding lk as onFail
+// may close the mirror.
+func (m *writeMirror) fail(err error) {
+	m.errLk.Lock()
+	first := m.err == nil
+	if first {
+		m.err = err
+	}
+	onFail := m.onFail
+	m.errLk.Unlock()
+	if first && onFail != nil {
+		onFail(err)
+	}
+}
+
+// toBlockstore applies `write` to the copy of the blockstore, if it is mirrored.
+func (m *writeMirror) toBlockstore(write func(blockstoreutil.Blockstore) error) {
+	err := func() error {
+		m.lk.RLock()
+		defer m.lk.RUnlock()
+		if m.blocks == nil {
+			return nil
+		}
+		return write(m.blocks)
+	}()
+	if err != nil {
+		m.fail(err)
+	}
+}
+
+// toDatastore applies `write`, of the keys `keys`, to the copy of the datastore `prefix`, if
+// it is mirrored.
+func (m *writeMirror) toDatastore(prefix string, keys []datastore.Key, write func(Datastore) error) {
+	err := func() error {
+		m.lk.RLock()
+		defer m.lk.RUnlock()
+		dst, ok := m.stores[prefix]
+		if !ok {
+			return nil
+		}
+
+		m.copyLk.Lock()
+		if written := m.written[prefix]; written != nil {
+			defer m.copyLk.Unlock()
+			for _, k := range keys {
+				written[k.String()] = struct{}{}
+			}
+			return write(dst)
+		}
+		m.copyLk.Unlock()
+		return write(dst)
+	}()
+	if err != nil {
+		m.fail(err)
+	}
+}
+
+// close stops the mirroring and closes the copies, once the writes to them are done.
+func (m *writeMirror) close() error {
+	m.lk.Lock()
+	bs, stores := m.blocks, m.stores
+	m.blocks, m.stores = nil, nil
+	m.lk.Unlock()
+	m.copied()
+
+	var err error
+	if bs != nil {
+		err = bs.Close()
+	}
+	for _, ds := range stores {
+		if cerr := ds.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+type mirroredBlockstore struct {
+	ClosableBlockstore
+	mirror *writeMirror
+}
+
+func (bs *mirroredBlockstore) Put(blk blocks.Block) error {
+	if err := bs.ClosableBlockstore.Put(blk); err != nil {
+		return err
+	}
+	bs.mirror.toBlockstore(func(dst blockstoreutil.Blockstore) error {
+		return dst.Put(blk)
+	})
+	return nil
+}
+
+func (bs *mirroredBlockstore) PutMany(blks []blocks.Block) error {
+	if err := bs.ClosableBlockstore.PutMany(blks); err != nil {
+		return err
+	}
+	bs.mirror.toBlockstore(func(dst blockstoreutil.Blockstore) error {
+		return dst.PutMany(blks)
+	})
+	return nil
+}
+
+func (bs *mirroredBlockstore) DeleteBlock(c cid.Cid) error {
+	if err := bs.ClosableBlockstore.DeleteBlock(c); err != nil {
+		return err
+	}
+	bs.mirror.toBlockstore(func(dst blockstoreutil.Blockstore) error {
+		return dst.DeleteBlock(c)
+	})
+	return nil
+}
+
+// View keeps the zero-copy reads of the blockstores which have them.
+func (bs *mirroredBlockstore) View(c cid.Cid, fn func([]byte) error) error {
+	if v, ok := bs.ClosableBlockstore.(blockstoreutil.Viewer); ok {
+		return v.View(c, fn)
+	}
+	blk, err := bs.ClosableBlockstore.Get(c)
+	if err != nil {
+		return err
+	}
+	return fn(blk.RawData())
+}
+
+type mirroredDatastore struct {
+	Datastore
+	mirror *writeMirror
+	prefix string
+}
+
+func (d *mirroredDatastore) Put(key datastore.Key, value []byte) error {
+	if err := d.Datastore.Put(key, value); err != nil {
+		return err
+	}
+	d.mirror.toDatastore(d.prefix, []datastore.Key{key}, func(dst Datastore) error {
+		return dst.Put(key, value)
+	})
+	return nil
+}
+
+func (d *mirroredDatastore) Delete(key datastore.Key) error {
+	if err := d.Datastore.Delete(key); err != nil {
+		return err
+	}
+	d.mirror.toDatastore(d.prefix, []datastore.Key{key}, func(dst Datastore) error {
+		return dst.Delete(key)
+	})
+	return nil
+}
+
+func (d *mirroredDatastore) Batch() (datastore.Batch, error) {
+	b, err := d.Datastore.Batch()
+	if err != nil {
+		return nil, err
+	}
+	return &mirroredBatch{Batch: b, ds: d}, nil
+}
+
+// mirroredBatch keeps its writes to mirror them when it is committed, the copies may be set
+// after the batch is started.
+type mirroredBatch struct {
+	datastore.Batch
+	ds  *mirroredDatastore
+	ops []batchOp
+}
+
+type batchOp struct {
+	key    datastore.Key
+	value  []byte
+	delete bool
+}
+
+func (b *mirroredBatch) Put(key datastore.Key, value []byte) error {
+	if err := b.Batch.Put(key, value); err != nil {
+		return err
+	}
+	b.ops = append(b.ops, batchOp{key: key, value: value})
+	return nil
+}
+
+func (b *mirroredBatch) Delete(key datastore.Key) error {
+	if err := b.Batch.Delete(key); err != nil {
+		return err
+	}
+	b.ops = append(b.ops, batchOp{key: key, delete: true})
+	return nil
+}
+
+func (b *mirroredBatch) Commit() error {
+	if err := b.Batch.Commit(); err != nil {
+		return err
+	}
+	ops := b.ops
+	b.ops = nil
+	keys := make([]datastore.Key, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+	b.ds.mirror.toDatastore(b.ds.prefix, keys, func(dst Datastore) error {
+		return replayBatch(dst, ops)
+	})
+	return nil
+}
+
+func replayBatch(ds Datastore, ops []batchOp) error {
+	batch, err := ds.Batch()
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if op.delete {
+			err = batch.Delete(op.key)
+		} else {
+			err = batch.Put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Commit()
+}
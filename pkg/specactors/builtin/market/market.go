@@ -155,6 +155,8 @@ type DealStates interface {
 
 type DealProposals interface {
 	ForEach(cb func(id abi.DealID, dp DealProposal) error) error
+	// ForEachFrom calls cb on the proposals whose id is not lower than start, without walking the lower ones.
+	ForEachFrom(start abi.DealID, cb func(id abi.DealID, dp DealProposal) error) error
 	Get(id abi.DealID) (*DealProposal, bool, error)
 
 	array() adt.Array
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/specactors/adt"
 	"github.com/filecoin-project/venus/pkg/types"
 
+	amt0 "github.com/filecoin-project/go-amt-ipld/v2"
 	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
 	adt0 "github.com/filecoin-project/specs-actors/actors/util/adt"
 )
@@ -98,7 +99,7 @@ func (s *state0) Proposals() (DealProposals, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dealProposals0{proposalArray}, nil
+	return &dealProposals0{Array: proposalArray, store: s.store, root: s.State.Proposals}, nil
 }
 
 func (s *state0) EscrowTable() (BalanceTable, error) {
@@ -187,6 +188,8 @@ func fromV0DealState(v0 market0.DealState) DealState {
 
 type dealProposals0 struct {
 	adt.Array
+	store adt.Store
+	root  cid.Cid
 }
 
 func (s *dealProposals0) Get(dealID abi.DealID) (*DealProposal, bool, error) {
@@ -209,6 +212,20 @@ func (s *dealProposals0) ForEach(cb func(dealID abi.DealID, dp DealProposal) err
 	})
 }
 
+func (s *dealProposals0) ForEachFrom(start abi.DealID, cb func(dealID abi.DealID, dp DealProposal) error) error {
+	root, err := amt0.LoadAMT(s.store.Context(), s.store, s.root)
+	if err != nil {
+		return err
+	}
+	return root.ForEachAt(s.store.Context(), uint64(start), func(idx uint64, val *cbg.Deferred) error {
+		dp, err := s.decode(val)
+		if err != nil {
+			return err
+		}
+		return cb(abi.DealID(idx), *dp)
+	})
+}
+
 func (s *dealProposals0) decode(val *cbg.Deferred) (*DealProposal, error) {
 	var dp0 market0.DealProposal
 	if err := dp0.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/specactors/adt"
 	"github.com/filecoin-project/venus/pkg/types"
 
+	amt2 "github.com/filecoin-project/go-amt-ipld/v2"
 	market2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/market"
 	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
 )
@@ -98,7 +99,7 @@ func (s *state2) Proposals() (DealProposals, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dealProposals2{proposalArray}, nil
+	return &dealProposals2{Array: proposalArray, store: s.store, root: s.State.Proposals}, nil
 }
 
 func (s *state2) EscrowTable() (BalanceTable, error) {
@@ -187,6 +188,8 @@ func fromV2DealState(v2 market2.DealState) DealState {
 
 type dealProposals2 struct {
 	adt.Array
+	store adt.Store
+	root  cid.Cid
 }
 
 func (s *dealProposals2) Get(dealID abi.DealID) (*DealProposal, bool, error) {
@@ -209,6 +212,20 @@ func (s *dealProposals2) ForEach(cb func(dealID abi.DealID, dp DealProposal) err
 	})
 }
 
+func (s *dealProposals2) ForEachFrom(start abi.DealID, cb func(dealID abi.DealID, dp DealProposal) error) error {
+	root, err := amt2.LoadAMT(s.store.Context(), s.store, s.root)
+	if err != nil {
+		return err
+	}
+	return root.ForEachAt(s.store.Context(), uint64(start), func(idx uint64, val *cbg.Deferred) error {
+		dp, err := s.decode(val)
+		if err != nil {
+			return err
+		}
+		return cb(abi.DealID(idx), *dp)
+	})
+}
+
 func (s *dealProposals2) decode(val *cbg.Deferred) (*DealProposal, error) {
 	var dp2 market2.DealProposal
 	if err := dp2.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
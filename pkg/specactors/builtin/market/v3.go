@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/specactors/adt"
 	"github.com/filecoin-project/venus/pkg/types"
 
+	amt3 "github.com/filecoin-project/go-amt-ipld/v3"
 	market3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
 	adt3 "github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
@@ -93,7 +94,7 @@ func (s *state3) Proposals() (DealProposals, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dealProposals3{proposalArray}, nil
+	return &dealProposals3{Array: proposalArray, store: s.store, root: s.State.Proposals}, nil
 }
 
 func (s *state3) EscrowTable() (BalanceTable, error) {
@@ -182,6 +183,8 @@ func fromV3DealState(v3 market3.DealState) DealState {
 
 type dealProposals3 struct {
 	adt.Array
+	store adt.Store
+	root  cid.Cid
 }
 
 func (s *dealProposals3) Get(dealID abi.DealID) (*DealProposal, bool, error) {
@@ -204,6 +207,20 @@ func (s *dealProposals3) ForEach(cb func(dealID abi.DealID, dp DealProposal) err
 	})
 }
 
+func (s *dealProposals3) ForEachFrom(start abi.DealID, cb func(dealID abi.DealID, dp DealProposal) error) error {
+	root, err := amt3.LoadAMT(s.store.Context(), s.store, s.root, amt3.UseTreeBitWidth(market3.ProposalsAmtBitwidth))
+	if err != nil {
+		return err
+	}
+	return root.ForEachAt(s.store.Context(), uint64(start), func(idx uint64, val *cbg.Deferred) error {
+		dp, err := s.decode(val)
+		if err != nil {
+			return err
+		}
+		return cb(abi.DealID(idx), *dp)
+	})
+}
+
 func (s *dealProposals3) decode(val *cbg.Deferred) (*DealProposal, error) {
 	var dp3 market3.DealProposal
 	if err := dp3.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/specactors/adt"
 	"github.com/filecoin-project/venus/pkg/types"
 
+	amt4 "github.com/filecoin-project/go-amt-ipld/v3"
 	market4 "github.com/filecoin-project/specs-actors/v4/actors/builtin/market"
 	adt4 "github.com/filecoin-project/specs-actors/v4/actors/util/adt"
 )
@@ -93,7 +94,7 @@ func (s *state4) Proposals() (DealProposals, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dealProposals4{proposalArray}, nil
+	return &dealProposals4{Array: proposalArray, store: s.store, root: s.State.Proposals}, nil
 }
 
 func (s *state4) EscrowTable() (BalanceTable, error) {
@@ -182,6 +183,8 @@ func fromV4DealState(v4 market4.DealState) DealState {
 
 type dealProposals4 struct {
 	adt.Array
+	store adt.Store
+	root  cid.Cid
 }
 
 func (s *dealProposals4) Get(dealID abi.DealID) (*DealProposal, bool, error) {
@@ -204,6 +207,20 @@ func (s *dealProposals4) ForEach(cb func(dealID abi.DealID, dp DealProposal) err
 	})
 }
 
+func (s *dealProposals4) ForEachFrom(start abi.DealID, cb func(dealID abi.DealID, dp DealProposal) error) error {
+	root, err := amt4.LoadAMT(s.store.Context(), s.store, s.root, amt4.UseTreeBitWidth(market4.ProposalsAmtBitwidth))
+	if err != nil {
+		return err
+	}
+	return root.ForEachAt(s.store.Context(), uint64(start), func(idx uint64, val *cbg.Deferred) error {
+		dp, err := s.decode(val)
+		if err != nil {
+			return err
+		}
+		return cb(abi.DealID(idx), *dp)
+	})
+}
+
 func (s *dealProposals4) decode(val *cbg.Deferred) (*DealProposal, error) {
 	var dp4 market4.DealProposal
 	if err := dp4.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/venus/pkg/specactors/adt"
 	"github.com/filecoin-project/venus/pkg/types"
 
+	amt5 "github.com/filecoin-project/go-amt-ipld/v3"
 	market5 "github.com/filecoin-project/specs-actors/v5/actors/builtin/market"
 	adt5 "github.com/filecoin-project/specs-actors/v5/actors/util/adt"
 )
@@ -93,7 +94,7 @@ func (s *state5) Proposals() (DealProposals, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dealProposals5{proposalArray}, nil
+	return &dealProposals5{Array: proposalArray, store: s.store, root: s.State.Proposals}, nil
 }
 
 func (s *state5) EscrowTable() (BalanceTable, error) {
@@ -182,6 +183,8 @@ func fromV5DealState(v5 market5.DealState) DealState {
 
 type dealProposals5 struct {
 	adt.Array
+	store adt.Store
+	root  cid.Cid
 }
 
 func (s *dealProposals5) Get(dealID abi.DealID) (*DealProposal, bool, error) {
@@ -204,6 +207,20 @@ func (s *dealProposals5) ForEach(cb func(dealID abi.DealID, dp DealProposal) err
 	})
 }
 
+func (s *dealProposals5) ForEachFrom(start abi.DealID, cb func(dealID abi.DealID, dp DealProposal) error) error {
+	root, err := amt5.LoadAMT(s.store.Context(), s.store, s.root, amt5.UseTreeBitWidth(market5.ProposalsAmtBitwidth))
+	if err != nil {
+		return err
+	}
+	return root.ForEachAt(s.store.Context(), uint64(start), func(idx uint64, val *cbg.Deferred) error {
+		dp, err := s.decode(val)
+		if err != nil {
+			return err
+		}
+		return cb(abi.DealID(idx), *dp)
+	})
+}
+
 func (s *dealProposals5) decode(val *cbg.Deferred) (*DealProposal, error) {
 	var dp5 market5.DealProposal
 	if err := dp5.UnmarshalCBOR(bytes.NewReader(val.Raw)); err != nil {
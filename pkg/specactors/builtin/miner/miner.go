@@ -199,6 +199,9 @@ type Partition interface {
 	RecoveringSectors() (bitfield.BitField, error)
 	LiveSectors() (bitfield.BitField, error)
 	ActiveSectors() (bitfield.BitField, error)
+	// ExpiringSectors returns the sectors of the expiration queue of the partition, expiring
+	// on time or early, scheduled before the epoch `before`.
+	ExpiringSectors(before abi.ChainEpoch) (bitfield.BitField, error)
 }
 
 type SectorOnChainInfo struct {
@@ -460,6 +460,29 @@ func (p *partition2) RecoveringSectors() (bitfield.BitField, error) {
 	return p.Partition.Recoveries, nil
 }
 
+func (p *partition2) ExpiringSectors(before abi.ChainEpoch) (bitfield.BitField, error) {
+	q, err := adt2.AsArray(p.store, p.Partition.ExpirationsEpochs)
+	if err != nil {
+		return bitfield.BitField{}, err
+	}
+
+	// the queue is keyed by epoch, it is walked until `before`
+	stopErr := errors.New("stop")
+	var sets []bitfield.BitField
+	var exp miner2.ExpirationSet
+	err = q.ForEach(&exp, func(epoch int64) error {
+		if abi.ChainEpoch(epoch) >= before {
+			return stopErr
+		}
+		sets = append(sets, exp.OnTimeSectors, exp.EarlySectors)
+		return nil
+	})
+	if err != nil && err != stopErr {
+		return bitfield.BitField{}, err
+	}
+	return bitfield.MultiMerge(sets...)
+}
+
 func fromV2SectorOnChainInfo(v2 miner2.SectorOnChainInfo) SectorOnChainInfo {
 
 	return SectorOnChainInfo{
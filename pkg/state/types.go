@@ -1,7 +1,9 @@
 package state
 
 import (
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
 	"github.com/filecoin-project/venus/pkg/specactors/builtin/miner"
@@ -23,6 +25,60 @@ type MarketDeal struct {
 	State    market.DealState
 }
 
+// MarketDealWithID is a deal of the storage market along with its id.
+type MarketDealWithID struct {
+	ID abi.DealID
+	MarketDeal
+}
+
+// MarketDealFilter selects deals of the storage market, a nil field matches every deal.
+// Client and Provider must be ID addresses, as stored in the deal proposals.
+type MarketDealFilter struct {
+	Client   *address.Address
+	Provider *address.Address
+	Verified *bool
+	// ActiveAt matches deals which are in a proven sector, not expired and not slashed at the epoch.
+	ActiveAt *abi.ChainEpoch
+	PieceCID *cid.Cid
+}
+
+// Match returns whether the deal satisfies every field of the filter.
+func (f *MarketDealFilter) Match(proposal *market.DealProposal, state *market.DealState) bool {
+	if f == nil {
+		return true
+	}
+	if f.Client != nil && proposal.Client != *f.Client {
+		return false
+	}
+	if f.Provider != nil && proposal.Provider != *f.Provider {
+		return false
+	}
+	if f.Verified != nil && proposal.VerifiedDeal != *f.Verified {
+		return false
+	}
+	if f.PieceCID != nil && !proposal.PieceCID.Equals(*f.PieceCID) {
+		return false
+	}
+	if f.ActiveAt != nil {
+		at := *f.ActiveAt
+		if state.SectorStartEpoch < 0 || state.SectorStartEpoch > at || proposal.EndEpoch <= at {
+			return false
+		}
+		if state.SlashEpoch >= 0 && state.SlashEpoch <= at {
+			return false
+		}
+	}
+	return true
+}
+
+// MarketDealsPage is a page of deals returned by a deal query. When More is set, querying
+// again from the Next cursor returns the following page.
+type MarketDealsPage struct {
+	Deals []MarketDealWithID
+	Next  abi.DealID
+	More  bool
+}
+
 type CirculatingSupply struct {
 	FilVested      abi.TokenAmount
 	FilMined       abi.TokenAmount
@@ -0,0 +1,57 @@
+package state_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/state"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestMarketDealFilterMatch(t *testing.T) {
+	tf.UnitTest(t)
+
+	client, err := address.NewIDAddress(100)
+	assert.NoError(t, err)
+	provider, err := address.NewIDAddress(1000)
+	assert.NoError(t, err)
+	other, err := address.NewIDAddress(101)
+	assert.NoError(t, err)
+	piece := types.CidFromString(t, "piece")
+	verified := true
+
+	proposal := &market.DealProposal{
+		PieceCID:     piece,
+		VerifiedDeal: true,
+		Client:       client,
+		Provider:     provider,
+		StartEpoch:   100,
+		EndEpoch:     200,
+	}
+	dealState := &market.DealState{SectorStartEpoch: 90, LastUpdatedEpoch: -1, SlashEpoch: -1}
+
+	epoch := func(e abi.ChainEpoch) *abi.ChainEpoch { return &e }
+
+	var nilFilter *state.MarketDealFilter
+	assert.True(t, nilFilter.Match(proposal, dealState))
+	assert.True(t, (&state.MarketDealFilter{}).Match(proposal, dealState))
+	assert.True(t, (&state.MarketDealFilter{Client: &client, Provider: &provider, Verified: &verified, PieceCID: &piece}).Match(proposal, dealState))
+	assert.False(t, (&state.MarketDealFilter{Client: &other}).Match(proposal, dealState))
+	assert.False(t, (&state.MarketDealFilter{Provider: &client}).Match(proposal, dealState))
+
+	notVerified := false
+	assert.False(t, (&state.MarketDealFilter{Verified: &notVerified}).Match(proposal, dealState))
+
+	assert.True(t, (&state.MarketDealFilter{ActiveAt: epoch(90)}).Match(proposal, dealState))
+	assert.False(t, (&state.MarketDealFilter{ActiveAt: epoch(89)}).Match(proposal, dealState))
+	assert.False(t, (&state.MarketDealFilter{ActiveAt: epoch(200)}).Match(proposal, dealState))
+
+	slashed := &market.DealState{SectorStartEpoch: 90, LastUpdatedEpoch: 150, SlashEpoch: 150}
+	assert.True(t, (&state.MarketDealFilter{ActiveAt: epoch(149)}).Match(proposal, slashed))
+	assert.False(t, (&state.MarketDealFilter{ActiveAt: epoch(150)}).Match(proposal, slashed))
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"sort"
 	"strconv"
 
 	"github.com/filecoin-project/go-bitfield"
@@ -375,6 +376,83 @@ func (v *View) MarketDealStatesForEach(ctx context.Context, f func(id abi.DealID
 	return nil
 }
 
+// MaxMarketDealsQueryLimit is the largest number of deals returned by a single deal query.
+const MaxMarketDealsQueryLimit = 10000
+
+// MarketDealsQuery returns, in ascending id order, up to `limit` deals matching the filter whose id
+// is not lower than `cursor`. Published deals which are not activated yet are returned with an
+// empty deal state, like StateMarketDeals does. If `candidates` is not nil, only those deal ids,
+// sorted ascending, are looked up instead of walking the deal proposals.
+func (v *View) MarketDealsQuery(ctx context.Context, filter *MarketDealFilter, candidates []abi.DealID, cursor abi.DealID, limit int) (*MarketDealsPage, error) {
+	if limit <= 0 || limit > MaxMarketDealsQueryLimit {
+		limit = MaxMarketDealsQueryLimit
+	}
+
+	marketState, err := v.loadMarketState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	proposals, err := marketState.Proposals()
+	if err != nil {
+		return nil, err
+	}
+	states, err := marketState.States()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MarketDealsPage{}
+	visit := func(id abi.DealID, proposal market.DealProposal) error {
+		ds, found, err := states.Get(id)
+		if err != nil {
+			return xerrors.Errorf("failed to get state for deal %d: %v", id, err)
+		} else if !found {
+			ds = market.EmptyDealState()
+		}
+		if !filter.Match(&proposal, ds) {
+			return nil
+		}
+
+		if len(page.Deals) == limit {
+			page.Next, page.More = id, true
+			return errStopIteration
+		}
+		page.Deals = append(page.Deals, MarketDealWithID{
+			ID:         id,
+			MarketDeal: MarketDeal{Proposal: proposal, State: *ds},
+		})
+		return nil
+	}
+
+	if candidates == nil {
+		err = proposals.ForEachFrom(cursor, visit)
+	} else {
+		err = marketDealProposalsForIDs(proposals, candidates, cursor, visit)
+	}
+	if err != nil && !page.More {
+		return nil, err
+	}
+	return page, nil
+}
+
+var errStopIteration = xerrors.New("stop iteration")
+
+func marketDealProposalsForIDs(proposals market.DealProposals, ids []abi.DealID, cursor abi.DealID, f func(id abi.DealID, proposal market.DealProposal) error) error {
+	from := sort.Search(len(ids), func(i int) bool { return ids[i] >= cursor })
+	for _, id := range ids[from:] {
+		proposal, found, err := proposals.Get(id)
+		if err != nil {
+			return xerrors.Errorf("failed to get proposal for deal %d: %v", id, err)
+		} else if !found {
+			continue
+		}
+		if err := f(id, *proposal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // StateDealProviderCollateralBounds returns the min and max collateral a storage provider
 // can issue. It takes the deal size and verified status as parameters.
 func (v *View) MarketDealProviderCollateralBounds(ctx context.Context, size abi.PaddedPieceSize, verified bool, height abi.ChainEpoch) (DealCollateralBounds, error) {
@@ -647,6 +725,78 @@ func (v *View) StateMinerPower(ctx context.Context, maddr addr.Address, tsk type
 	return mpow, tpow, minpow, nil
 }
 
+// StateMinerPowerAll returns the claims of every miner in the power actor along with the total power,
+// walking the claims table only once.
+func (v *View) StateMinerPowerAll(ctx context.Context) (map[addr.Address]power.Claim, power.Claim, error) {
+	pas, err := v.loadPowerActor(ctx)
+	if err != nil {
+		return nil, power.Claim{}, xerrors.Errorf("failed to load power actor state: %v", err)
+	}
+
+	tpow, err := pas.TotalPower()
+	if err != nil {
+		return nil, power.Claim{}, err
+	}
+
+	claims := make(map[addr.Address]power.Claim)
+	if err := pas.ForEachClaim(func(maddr addr.Address, claim power.Claim) error {
+		claims[maddr] = claim
+		return nil
+	}); err != nil {
+		return nil, power.Claim{}, err
+	}
+
+	return claims, tpow, nil
+}
+
+// StateLockedFunds returns the pledge collateral locked in the power actor and the funds locked in the market actor.
+func (v *View) StateLockedFunds(ctx context.Context) (abi.TokenAmount, abi.TokenAmount, error) {
+	pas, err := v.loadPowerActor(ctx)
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to load power actor state: %v", err)
+	}
+	pledge, err := pas.TotalLocked()
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to get total pledge: %v", err)
+	}
+
+	mas, err := v.loadMarketState(ctx)
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to load market actor state: %v", err)
+	}
+	marketLocked, err := mas.TotalLocked()
+	if err != nil {
+		return big.Zero(), big.Zero(), xerrors.Errorf("failed to get market locked funds: %v", err)
+	}
+
+	return pledge, marketLocked, nil
+}
+
+// StateTotalFeeDebt returns the sum of the fee debts of the miners with a claim in the power actor.
+func (v *View) StateTotalFeeDebt(ctx context.Context) (abi.TokenAmount, error) {
+	pas, err := v.loadPowerActor(ctx)
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("failed to load power actor state: %v", err)
+	}
+
+	total := big.Zero()
+	if err := pas.ForEachClaim(func(maddr addr.Address, _ power.Claim) error {
+		mas, err := v.loadMinerState(ctx, maddr)
+		if err != nil {
+			return xerrors.Errorf("failed to load miner %s state: %v", maddr, err)
+		}
+		debt, err := mas.FeeDebt()
+		if err != nil {
+			return xerrors.Errorf("failed to get fee debt of miner %s: %v", maddr, err)
+		}
+		total = big.Add(total, debt)
+		return nil
+	}); err != nil {
+		return big.Zero(), err
+	}
+	return total, nil
+}
+
 // StateMarketDeals returns information about every deal in the Storage Market
 func (v *View) StateMarketDeals(ctx context.Context, tsk types.TipSetKey) (map[string]MarketDeal, error) {
 	out := map[string]MarketDeal{}
@@ -0,0 +1,153 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
+	adt0 "github.com/filecoin-project/specs-actors/actors/util/adt"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/repo"
+	"github.com/filecoin-project/venus/pkg/specactors/adt"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/market"
+	"github.com/filecoin-project/venus/pkg/state"
+	"github.com/filecoin-project/venus/pkg/state/tree"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestMarketDealsQuery(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	client := requireIDAddress(t, 100)
+	other := requireIDAddress(t, 101)
+	provider := requireIDAddress(t, 1000)
+
+	// deal 2 is published but not activated yet and deal 3 expired and was deleted
+	proposals := map[abi.DealID]market0.DealProposal{
+		0: testProposal(t, client, provider),
+		1: testProposal(t, other, provider),
+		2: testProposal(t, client, provider),
+		4: testProposal(t, client, provider),
+		5: testProposal(t, other, provider),
+	}
+	states := map[abi.DealID]market0.DealState{
+		0: {SectorStartEpoch: 10, LastUpdatedEpoch: -1, SlashEpoch: -1},
+		1: {SectorStartEpoch: 10, LastUpdatedEpoch: -1, SlashEpoch: -1},
+		4: {SectorStartEpoch: 20, LastUpdatedEpoch: -1, SlashEpoch: -1},
+		5: {SectorStartEpoch: 20, LastUpdatedEpoch: -1, SlashEpoch: -1},
+	}
+	view := newMarketView(ctx, t, proposals, states)
+
+	ids := func(page *state.MarketDealsPage) []abi.DealID {
+		out := []abi.DealID{}
+		for _, deal := range page.Deals {
+			out = append(out, deal.ID)
+		}
+		return out
+	}
+
+	page, err := view.MarketDealsQuery(ctx, nil, nil, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.DealID{0, 1}, ids(page))
+	assert.True(t, page.More)
+	assert.Equal(t, abi.DealID(2), page.Next)
+
+	page, err = view.MarketDealsQuery(ctx, nil, nil, page.Next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.DealID{2, 4}, ids(page))
+	assert.Equal(t, *market.EmptyDealState(), page.Deals[0].State)
+	assert.Equal(t, abi.ChainEpoch(20), page.Deals[1].State.SectorStartEpoch)
+	assert.True(t, page.More)
+	assert.Equal(t, abi.DealID(5), page.Next)
+
+	page, err = view.MarketDealsQuery(ctx, nil, nil, page.Next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.DealID{5}, ids(page))
+	assert.False(t, page.More)
+
+	filter := &state.MarketDealFilter{Client: &client}
+	page, err = view.MarketDealsQuery(ctx, filter, nil, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.DealID{2, 4}, ids(page))
+
+	// the unactivated deal is not active at any epoch
+	active := abi.ChainEpoch(15)
+	page, err = view.MarketDealsQuery(ctx, &state.MarketDealFilter{Client: &client, ActiveAt: &active}, nil, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.DealID{0}, ids(page))
+
+	// candidates below the cursor or without a proposal are skipped
+	page, err = view.MarketDealsQuery(ctx, filter, []abi.DealID{0, 2, 3, 4}, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.DealID{2}, ids(page))
+	assert.True(t, page.More)
+	assert.Equal(t, abi.DealID(4), page.Next)
+}
+
+func requireIDAddress(t *testing.T, id uint64) address.Address {
+	addr, err := address.NewIDAddress(id)
+	require.NoError(t, err)
+	return addr
+}
+
+func testProposal(t *testing.T, client, provider address.Address) market0.DealProposal {
+	return market0.DealProposal{
+		PieceCID:             types.CidFromString(t, "piece"),
+		PieceSize:            abi.PaddedPieceSize(2048),
+		Client:               client,
+		Provider:             provider,
+		StartEpoch:           10,
+		EndEpoch:             100,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+}
+
+// newMarketView returns a view of a state tree holding only a storage market actor with the deals.
+func newMarketView(ctx context.Context, t *testing.T, proposals map[abi.DealID]market0.DealProposal, states map[abi.DealID]market0.DealState) *state.View {
+	cst := cbor.NewCborStore(repo.NewInMemoryRepo().Datastore())
+	store := adt.WrapStore(ctx, cst)
+
+	emptyArray, err := adt0.MakeEmptyArray(store).Root()
+	require.NoError(t, err)
+	emptyMap, err := adt0.MakeEmptyMap(store).Root()
+	require.NoError(t, err)
+	st := market0.ConstructState(emptyArray, emptyMap, emptyMap)
+
+	proposalArray, err := adt0.AsArray(store, st.Proposals)
+	require.NoError(t, err)
+	for id := range proposals {
+		proposal := proposals[id]
+		require.NoError(t, proposalArray.Set(uint64(id), &proposal))
+	}
+	st.Proposals, err = proposalArray.Root()
+	require.NoError(t, err)
+
+	stateArray, err := adt0.AsArray(store, st.States)
+	require.NoError(t, err)
+	for id := range states {
+		dealState := states[id]
+		require.NoError(t, stateArray.Set(uint64(id), &dealState))
+	}
+	st.States, err = stateArray.Root()
+	require.NoError(t, err)
+
+	head, err := cst.Put(ctx, st)
+	require.NoError(t, err)
+	stateTree, err := tree.NewState(cst, tree.StateTreeVersion0)
+	require.NoError(t, err)
+	require.NoError(t, stateTree.SetActor(ctx, market.Address, &types.Actor{Code: builtin0.StorageMarketActorCodeID, Head: head, Balance: big.Zero()}))
+	root, err := stateTree.Flush(ctx)
+	require.NoError(t, err)
+	return state.NewView(cst, root)
+}
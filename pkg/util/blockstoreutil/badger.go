@@ -397,8 +397,9 @@ func (b *BadgerBlockstore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, err
 				return // closing, yield.
 			}
 			k := iter.Item().Key()
-			// need to convert to key.Key using key.KeyFromDsKey.
-			bk, err := dshelp.BinaryFromDsKey(datastore.RawKey(string(k)))
+			// need to convert to key.Key using key.KeyFromDsKey, once the prefix is removed.
+			dsKey := b.keyTransform.InvertKey(datastore.RawKey(string(k)))
+			bk, err := dshelp.BinaryFromDsKey(dsKey)
 			if err != nil {
 				log.Warnf("error parsing key from binary: %s", err)
 				continue
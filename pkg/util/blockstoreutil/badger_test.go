@@ -0,0 +1,39 @@
+package blockstoreutil
+
+import (
+	"context"
+	"testing"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestBadgerAllKeysChanWithPrefix(t *testing.T) {
+	tf.UnitTest(t)
+
+	for _, prefix := range []string{"", "/blocks"} {
+		opts, err := BadgerBlockstoreOptions(t.TempDir(), false)
+		require.NoError(t, err)
+		opts.Prefix = prefix
+		bs, err := Open(opts)
+		require.NoError(t, err)
+
+		blks := []blocks.Block{blocks.NewBlock([]byte("foo")), blocks.NewBlock([]byte("bar"))}
+		require.NoError(t, bs.PutMany(blks))
+
+		keys, err := bs.AllKeysChan(context.Background())
+		require.NoError(t, err)
+		var listed []blocks.Block
+		for k := range keys {
+			// the keys listed are those of the blocks, whatever the prefix
+			blk, err := bs.Get(k)
+			require.NoError(t, err, "prefix %q", prefix)
+			listed = append(listed, blk)
+		}
+		assert.Len(t, listed, len(blks), "prefix %q", prefix)
+		require.NoError(t, bs.Close())
+	}
+}
@@ -0,0 +1,214 @@
+package blockstoreutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	car "github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+)
+
+// ErrReadOnlyBlockstore is returned by the writes to a read-only blockstore.
+var ErrReadOnlyBlockstore = fmt.Errorf("blockstore is read-only")
+
+// carEntry is where the data of a block is in the car file.
+type carEntry struct {
+	cid    cid.Cid
+	offset int64
+	size   int
+}
+
+// CarBlockstore is a read-only blockstore serving the blocks of a car file, such as a chain
+// export kept for archival. The file is indexed in memory when opened, the data of the
+// blocks is read from the file on demand.
+type CarBlockstore struct {
+	f     *os.File
+	roots []cid.Cid
+	// index is keyed by the multihash of the blocks, like the other blockstores
+	index map[string]carEntry
+	// keys are the cids in the order of the file
+	keys []cid.Cid
+}
+
+var _ Blockstore = (*CarBlockstore)(nil)
+
+// OpenCarBlockstore opens the car file at `path` and indexes its blocks.
+func OpenCarBlockstore(path string) (*CarBlockstore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bs, err := indexCar(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("indexing car file %s: %w", path, err)
+	}
+	return bs, nil
+}
+
+func indexCar(f *os.File) (*CarBlockstore, error) {
+	br := bufio.NewReaderSize(f, 1<<20)
+	h, offset, err := car.ReadHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if h.Version != 1 {
+		return nil, fmt.Errorf("unsupported car version %d", h.Version)
+	}
+
+	bs := &CarBlockstore{f: f, roots: h.Roots, index: map[string]carEntry{}}
+	for {
+		c, l, data, err := carutil.ReadNode(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key := string(c.Hash())
+		if _, ok := bs.index[key]; !ok {
+			bs.index[key] = carEntry{cid: c, offset: int64(offset + l - uint64(len(data))), size: len(data)}
+			bs.keys = append(bs.keys, c)
+		}
+		offset += l
+	}
+	return bs, nil
+}
+
+// Roots returns the roots of the car file.
+func (bs *CarBlockstore) Roots() []cid.Cid {
+	return bs.roots
+}
+
+// Close closes the car file.
+func (bs *CarBlockstore) Close() error {
+	return bs.f.Close()
+}
+
+func (bs *CarBlockstore) read(k cid.Cid) ([]byte, error) {
+	e, ok := bs.index[string(k.Hash())]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	data := make([]byte, e.size)
+	if _, err := bs.f.ReadAt(data, e.offset); err != nil {
+		return nil, fmt.Errorf("reading block %s from car file: %w", k, err)
+	}
+	return data, nil
+}
+
+func (bs *CarBlockstore) Has(k cid.Cid) (bool, error) {
+	_, ok := bs.index[string(k.Hash())]
+	return ok, nil
+}
+
+func (bs *CarBlockstore) View(k cid.Cid, callback func([]byte) error) error {
+	data, err := bs.read(k)
+	if err != nil {
+		return err
+	}
+	return callback(data)
+}
+
+func (bs *CarBlockstore) Get(k cid.Cid) (blocks.Block, error) {
+	if !k.Defined() {
+		return nil, ErrNotFound
+	}
+	data, err := bs.read(k)
+	if err != nil {
+		return nil, err
+	}
+	return blocks.NewBlockWithCid(data, k)
+}
+
+// GetSize returns the size of the block `k`.
+func (bs *CarBlockstore) GetSize(k cid.Cid) (int, error) {
+	e, ok := bs.index[string(k.Hash())]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return e.size, nil
+}
+
+// Put fails, the blockstore is read-only.
+func (bs *CarBlockstore) Put(blocks.Block) error {
+	return ErrReadOnlyBlockstore
+}
+
+// PutMany fails, the blockstore is read-only.
+func (bs *CarBlockstore) PutMany([]blocks.Block) error {
+	return ErrReadOnlyBlockstore
+}
+
+// DeleteBlock fails, the blockstore is read-only.
+func (bs *CarBlockstore) DeleteBlock(cid.Cid) error {
+	return ErrReadOnlyBlockstore
+}
+
+// AllKeysChan returns the cids of the blocks in the order of the car file.
+func (bs *CarBlockstore) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	ch := make(chan cid.Cid)
+	go func() {
+		defer close(ch)
+		for _, k := range bs.keys {
+			select {
+			case ch <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// HashOnRead is a no-op, the blocks are hashed when the file is indexed.
+func (bs *CarBlockstore) HashOnRead(bool) {}
+
+// WriteCarFile writes the blocks of `bs` to a car file at `path`, with `roots` as its roots.
+// The blockstores key the blocks by multihash, so the cids of the blocks in the file may be
+// raw cids, CarBlockstore looks them up by multihash as well.
+func WriteCarFile(ctx context.Context, bs Blockstore, roots []cid.Cid, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(f, 1<<20)
+
+	if err := writeCar(ctx, bs, roots, w); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeCar(ctx context.Context, bs Blockstore, roots []cid.Cid, w io.Writer) error {
+	if err := car.WriteHeader(&car.CarHeader{Roots: roots, Version: 1}, w); err != nil {
+		return err
+	}
+
+	keys, err := bs.AllKeysChan(ctx)
+	if err != nil {
+		return err
+	}
+	for k := range keys {
+		blk, err := bs.Get(k)
+		if err != nil {
+			return fmt.Errorf("getting block %s: %w", k, err)
+		}
+		if err := carutil.LdWrite(w, k.Bytes(), blk.RawData()); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,144 @@
+// Package tokenbucket implements the token buckets shared by the rate limiters of the node.
+package tokenbucket
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// gcPeriod is how often the buckets which refilled are dropped, so that the buckets do
+// not grow with every key ever seen.
+const gcPeriod = 10 * time.Minute
+
+// Budget is the bucket under `Key`, holding up to `Capacity` tokens and refilled with
+// `Rate` tokens per second. A budget with a non positive rate is not limited.
+type Budget struct {
+	Key      string
+	Rate     float64
+	Capacity float64
+}
+
+// Buckets are token buckets keyed by string. A bucket starts full, and is dropped once it
+// is full again, which is the same as starting over.
+type Buckets struct {
+	lk      sync.Mutex
+	buckets map[string]*bucket
+	lastGC  time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	last     time.Time
+	rate     float64
+	capacity float64
+}
+
+// New creates empty buckets.
+func New() *Buckets {
+	return &Buckets{buckets: make(map[string]*bucket)}
+}
+
+// Take consumes `cost` tokens from every budget at `now`, or nothing when one of them is
+// short. When it does not, it returns how long to wait for the budgets to refill, which is
+// zero when `cost` is larger than the capacity of a budget and the call can never be served.
+func (bs *Buckets) Take(now time.Time, cost float64, budgets ...Budget) (time.Duration, bool) {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+
+	bs.gc(now)
+
+	var wait time.Duration
+	short := false
+	taken := make([]*bucket, 0, len(budgets))
+	for _, bg := range budgets {
+		if bg.Rate <= 0 {
+			continue
+		}
+		if cost > bg.Capacity {
+			return 0, false
+		}
+		b := bs.refill(now, bg)
+		if b.tokens < cost {
+			short = true
+			if w := time.Duration((cost - b.tokens) / bg.Rate * float64(time.Second)); w > wait {
+				wait = w
+			}
+		}
+		taken = append(taken, b)
+	}
+	if short {
+		if wait <= 0 {
+			wait = time.Nanosecond
+		}
+		return wait, false
+	}
+
+	for _, b := range taken {
+		b.tokens -= cost
+	}
+	return 0, true
+}
+
+// Available returns whether every budget holds `cost` tokens at `now`, without consuming
+// them.
+func (bs *Buckets) Available(now time.Time, cost float64, budgets ...Budget) bool {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+
+	for _, bg := range budgets {
+		if bg.Rate > 0 && bs.refill(now, bg).tokens < cost {
+			return false
+		}
+	}
+	return true
+}
+
+// Refund gives back `cost` tokens taken from the budgets.
+func (bs *Buckets) Refund(now time.Time, cost float64, budgets ...Budget) {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+
+	for _, bg := range budgets {
+		if bg.Rate <= 0 {
+			continue
+		}
+		b := bs.refill(now, bg)
+		b.tokens = math.Min(bg.Capacity, b.tokens+cost)
+	}
+}
+
+// Len returns the number of buckets kept.
+func (bs *Buckets) Len() int {
+	bs.lk.Lock()
+	defer bs.lk.Unlock()
+
+	return len(bs.buckets)
+}
+
+// refill returns the bucket of `bg` refilled up to `now`, the lock must be held.
+func (bs *Buckets) refill(now time.Time, bg Budget) *bucket {
+	b, ok := bs.buckets[bg.Key]
+	if !ok {
+		b = &bucket{tokens: bg.Capacity, last: now}
+		bs.buckets[bg.Key] = b
+	} else {
+		b.tokens = math.Min(bg.Capacity, b.tokens+now.Sub(b.last).Seconds()*bg.Rate)
+		b.last = now
+	}
+	b.rate, b.capacity = bg.Rate, bg.Capacity
+	return b
+}
+
+// gc drops the buckets which refilled, the lock must be held.
+func (bs *Buckets) gc(now time.Time) {
+	if now.Sub(bs.lastGC) < gcPeriod {
+		return
+	}
+	bs.lastGC = now
+	for k, b := range bs.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.capacity {
+			delete(bs.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package tokenbucket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestBuckets(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	bs := New()
+	client := Budget{Key: "client", Rate: 1, Capacity: 10}
+	method := Budget{Key: "client/method", Rate: 0.1, Capacity: 2}
+
+	_, ok := bs.Take(now, 10, client)
+	assert.True(t, ok)
+	wait, ok := bs.Take(now, 2, client)
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, wait)
+
+	// a rejected take consumes nothing from any budget
+	now = now.Add(5 * time.Second)
+	_, ok = bs.Take(now, 2, client, method)
+	assert.True(t, ok)
+	wait, ok = bs.Take(now, 2, client, method)
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, wait)
+	assert.True(t, bs.Available(now, 3, client))
+	assert.False(t, bs.Available(now, 1, method))
+
+	bs.Refund(now, 100, method)
+	assert.True(t, bs.Available(now, 2, method))
+
+	// a cost larger than the capacity is never served, unlimited budgets are skipped
+	wait, ok = bs.Take(now, 11, client)
+	assert.False(t, ok)
+	assert.Zero(t, wait)
+	_, ok = bs.Take(now, 1000, Budget{Key: "unlimited"})
+	assert.True(t, ok)
+}
+
+func TestBucketsDropRefilledBuckets(t *testing.T) {
+	tf.UnitTest(t)
+
+	now := time.Unix(0, 0)
+	bs := New()
+	fast := Budget{Key: "fast", Rate: 1, Capacity: 60}
+	slow := Budget{Key: "slow", Rate: 1, Capacity: 3600}
+
+	bs.Take(now, 60, fast)
+	bs.Take(now, 3600, slow)
+	assert.Equal(t, 2, bs.Len())
+
+	// only the bucket which refilled is dropped
+	now = now.Add(gcPeriod)
+	bs.Take(now, 1, Budget{Key: "other", Rate: 1, Capacity: 1})
+	assert.Equal(t, 2, bs.Len())
+	_, ok := bs.Take(now, 3600, slow)
+	assert.False(t, ok)
+}
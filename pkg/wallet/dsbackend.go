@@ -1,7 +1,9 @@
 package wallet
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/json"
 	"reflect"
 	"strings"
 	"sync"
@@ -29,6 +31,10 @@ const (
 var ErrInvalidPassword = errors.New("password matching failed")
 var ErrRepeatPassword = errors.New("set password more than once")
 
+// trashPrefix is the datastore namespace of the deleted keys, they are kept encrypted so
+// that they can be recovered with RecoverKey.
+var trashPrefix = ds.NewKey("/_trash")
+
 // DSBackendType is the reflect type of the DSBackend.
 var DSBackendType = reflect.TypeOf(&DSBackend{})
 
@@ -47,6 +53,9 @@ type DSBackend struct {
 	unLocked map[address.Address]*crypto.KeyInfo
 
 	state int
+
+	// hdLk serializes the updates of the hd seed
+	hdLk sync.Mutex
 }
 
 var _ Backend = (*DSBackend)(nil)
@@ -67,6 +76,9 @@ func NewDSBackend(ds repo.Datastore, passphraseCfg config.PassphraseConfig, pass
 
 	addrCache := make(map[address.Address]struct{}, len(list))
 	for _, el := range list {
+		if isReservedKey(el.Key) {
+			continue
+		}
 		parsedAddr, err := address.NewFromString(strings.Trim(el.Key, "/"))
 		if err != nil {
 			return nil, errors.Wrapf(err, "trying to restore invalid address: %s", el.Key)
@@ -166,6 +178,10 @@ func (backend *DSBackend) putKeyInfo(ki *crypto.KeyInfo) error {
 		KeyInfo: ki,
 	}
 
+	// the key is encrypted under the lock so that the password can't change before it is stored
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
 	var keyJSON []byte
 	err = backend.UsePassword(func(password []byte) error {
 		var err error
@@ -176,13 +192,11 @@ func (backend *DSBackend) putKeyInfo(ki *crypto.KeyInfo) error {
 		return err
 	}
 
-	backend.lk.Lock()
 	if err := backend.ds.Put(ds.NewKey(key.Address.String()), keyJSON); err != nil {
 		return errors.Wrapf(err, "failed to store new address: %s", key.Address.String())
 	}
 	backend.cache[addr] = struct{}{}
 	backend.unLocked[addr] = ki
-	backend.lk.Unlock()
 
 	return nil
 }
@@ -290,13 +304,14 @@ func (backend *DSBackend) UnLockWallet(password []byte) error {
 	}
 
 	for _, addr := range backend.Addresses() {
-		ki, err := backend.GetKeyInfoPassphrase(addr, password)
+		// not GetKeyInfoPassphrase, it wipes the password after the first key
+		key, err := backend.getKey(addr, password)
 		if err != nil {
 			return err
 		}
 
 		backend.lk.Lock()
-		backend.unLocked[addr] = ki
+		backend.unLocked[addr] = key.KeyInfo
 		backend.lk.Unlock()
 	}
 	backend.state = Unlock
@@ -311,12 +326,12 @@ func (backend *DSBackend) SetPassword(password []byte) error {
 	}
 
 	for _, addr := range backend.Addresses() {
-		ki, err := backend.GetKeyInfoPassphrase(addr, password)
+		key, err := backend.getKey(addr, password)
 		if err != nil {
 			return err
 		}
 		backend.lk.Lock()
-		backend.unLocked[addr] = ki
+		backend.unLocked[addr] = key.KeyInfo
 		backend.lk.Unlock()
 	}
 	if backend.state == undetermined {
@@ -363,3 +378,219 @@ func (backend *DSBackend) cleanPassword() {
 	defer backend.lk.Unlock()
 	backend.password = nil
 }
+
+// isReservedKey returns whether the datastore key belongs to a namespace of the backend
+// instead of being an address.
+func isReservedKey(key string) bool {
+	for _, prefix := range []ds.Key{hdPrefix, trashPrefix} {
+		if strings.HasPrefix(key, prefix.String()+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteKey removes the key of `addr` from the backend. The encrypted key is moved to the
+// trash namespace in the same batch, it can be recovered with RecoverKey.
+func (backend *DSBackend) DeleteKey(addr address.Address) error {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.state == Lock {
+		return ErrWalletLocked
+	}
+
+	if _, ok := backend.cache[addr]; !ok {
+		return errors.New("backend does not contain address")
+	}
+
+	key := ds.NewKey(addr.String())
+	b, err := backend.ds.Get(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch private key from backend")
+	}
+
+	batch, err := backend.ds.Batch()
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(trashPrefix.ChildString(addr.String()), b); err != nil {
+		return err
+	}
+	if err := batch.Delete(key); err != nil {
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return errors.Wrapf(err, "failed to delete address: %s", addr)
+	}
+
+	delete(backend.cache, addr)
+	delete(backend.unLocked, addr)
+	return nil
+}
+
+// RecoverKey moves the key of a deleted address back from the trash namespace.
+func (backend *DSBackend) RecoverKey(addr address.Address) error {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.state == Lock {
+		return ErrWalletLocked
+	}
+
+	trashKey := trashPrefix.ChildString(addr.String())
+	b, err := backend.ds.Get(trashKey)
+	if err == ds.ErrNotFound {
+		return errors.Errorf("%s is not a deleted address", addr)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to fetch deleted key from backend")
+	}
+
+	var key *Key
+	err = backend.UsePassword(func(password []byte) error {
+		var err error
+		key, err = decryptKey(b, password)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	batch, err := backend.ds.Batch()
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(ds.NewKey(addr.String()), b); err != nil {
+		return err
+	}
+	if err := batch.Delete(trashKey); err != nil {
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return errors.Wrapf(err, "failed to recover address: %s", addr)
+	}
+
+	backend.cache[addr] = struct{}{}
+	backend.unLocked[addr] = key.KeyInfo
+	return nil
+}
+
+// ChangePassword re-encrypts every key of the backend, including the deleted ones and the
+// hd seed, with `newPassword`.
+func (backend *DSBackend) ChangePassword(oldPassword, newPassword []byte) error {
+	if len(newPassword) == 0 {
+		return errors.New("the new password is empty")
+	}
+
+	// the keys can't be stored between the re-encryption and the change of the password
+	backend.hdLk.Lock()
+	defer backend.hdLk.Unlock()
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if err := backend.checkPassword(oldPassword); err != nil {
+		return err
+	}
+	if err := backend.reencrypt(oldPassword, newPassword, backend.PassphraseConf); err != nil {
+		return err
+	}
+
+	pw := make([]byte, len(newPassword))
+	copy(pw, newPassword)
+	backend.password = memguard.NewEnclave(pw)
+	return nil
+}
+
+// Reencrypt re-encrypts every key of the backend, including the deleted ones and the hd
+// seed, with the scrypt parameters of `conf`. The following keys are also encrypted with them.
+func (backend *DSBackend) Reencrypt(password []byte, conf config.PassphraseConfig) error {
+	backend.hdLk.Lock()
+	defer backend.hdLk.Unlock()
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if err := backend.checkPassword(password); err != nil {
+		return err
+	}
+	if err := backend.reencrypt(password, password, conf); err != nil {
+		return err
+	}
+
+	backend.PassphraseConf = conf
+	return nil
+}
+
+// checkPassword must be called with the lock held.
+func (backend *DSBackend) checkPassword(password []byte) error {
+	if backend.state == Lock {
+		return ErrWalletLocked
+	}
+	if backend.password == nil {
+		return errors.New("the wallet has no password")
+	}
+	return backend.UsePassword(func(current []byte) error {
+		if !bytes.Equal(current, password) {
+			return ErrInvalidPassword
+		}
+		return nil
+	})
+}
+
+// reencrypt rewrites all the entries of the datastore in a single batch, so that a failure
+// never leaves keys encrypted with different passwords. It must be called with both locks held.
+func (backend *DSBackend) reencrypt(oldPassword, newPassword []byte, conf config.PassphraseConfig) error {
+	result, err := backend.ds.Query(dsq.Query{})
+	if err != nil {
+		return errors.Wrap(err, "failed to query datastore")
+	}
+	entries, err := result.Rest()
+	if err != nil {
+		return errors.Wrap(err, "failed to read query results")
+	}
+
+	batch, err := backend.ds.Batch()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var b []byte
+		if entry.Key == hdSeedKey.String() {
+			b, err = reencryptHDSeed(entry.Value, oldPassword, newPassword, conf)
+		} else {
+			b, err = reencryptKey(entry.Value, oldPassword, newPassword, conf)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to re-encrypt %s", entry.Key)
+		}
+		if err := batch.Put(ds.NewKey(entry.Key), b); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(batch.Commit(), "failed to store re-encrypted keys")
+}
+
+func reencryptKey(b, oldPassword, newPassword []byte, conf config.PassphraseConfig) ([]byte, error) {
+	key, err := decryptKey(b, oldPassword)
+	if err != nil {
+		return nil, err
+	}
+	return encryptKey(key, newPassword, conf.ScryptN, conf.ScryptP)
+}
+
+func reencryptHDSeed(b, oldPassword, newPassword []byte, conf config.PassphraseConfig) ([]byte, error) {
+	hs := &hdSeed{}
+	if err := json.Unmarshal(b, hs); err != nil {
+		return nil, err
+	}
+	seed, err := decryptData(hs.Crypto, oldPassword)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(seed)
+
+	if hs.Crypto, err = encryptData(seed, newPassword, conf.ScryptN, conf.ScryptP); err != nil {
+		return nil, err
+	}
+	return json.Marshal(hs)
+}
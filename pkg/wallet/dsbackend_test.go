@@ -141,6 +141,120 @@ func TestDSBackendParallel(t *testing.T) {
 	assert.Len(t, fs.Addresses(), 10)
 }
 
+func TestDSBackendDeleteAndRecover(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	fs, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+
+	addr, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	require.NoError(t, fs.DeleteKey(addr))
+	assert.False(t, fs.HasAddress(addr))
+	assert.Error(t, fs.DeleteKey(addr))
+
+	t.Log("deleted keys are not loaded by a new backend")
+	fs2, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+	assert.False(t, fs2.HasAddress(addr))
+
+	require.NoError(t, fs2.RecoverKey(addr))
+	assert.True(t, fs2.HasAddress(addr))
+	_, err = fs2.SignBytes([]byte("data"), addr)
+	assert.NoError(t, err)
+	assert.Error(t, fs2.RecoverKey(addr))
+}
+
+func TestDSBackendChangePassword(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	fs, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+
+	addr, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	deleted, err := fs.NewAddress(address.BLS)
+	require.NoError(t, err)
+	require.NoError(t, fs.DeleteKey(deleted))
+	_, err = fs.NewHDSeed()
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrInvalidPassword, fs.ChangePassword([]byte("wrong-password"), []byte("new-password")))
+	require.NoError(t, fs.ChangePassword([]byte("test-password"), []byte("new-password")))
+
+	t.Log("the keys created after the change use the new password")
+	hdAddr, err := fs.NewHDAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	_, err = NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	assert.Error(t, err)
+
+	fs2, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("new-password"))
+	require.NoError(t, err)
+	assert.True(t, fs2.HasAddress(addr))
+	assert.True(t, fs2.HasAddress(hdAddr.Address))
+	require.NoError(t, fs2.RecoverKey(deleted))
+
+	t.Log("re-encrypting with new scrypt parameters keeps the password")
+	conf := config.PassphraseConfig{ScryptN: 1 << 14, ScryptP: 1}
+	require.NoError(t, fs2.Reencrypt([]byte("new-password"), conf))
+	assert.Equal(t, conf, fs2.PassphraseConf)
+
+	fs3, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("new-password"))
+	require.NoError(t, err)
+	assert.Len(t, fs3.Addresses(), 3)
+	_, err = fs3.DeriveAddress(address.SECP256K1, 0)
+	assert.NoError(t, err)
+}
+
+func TestDSBackendChangePasswordConcurrently(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	fs, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+	deleted, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := fs.NewAddress(address.SECP256K1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, fs.DeleteKey(deleted))
+	}()
+	require.NoError(t, fs.ChangePassword([]byte("test-password"), []byte("new-password")))
+	wg.Wait()
+
+	t.Log("every key, including the ones stored during the change, is encrypted with the new password")
+	fs2, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("new-password"))
+	require.NoError(t, err)
+	assert.Len(t, fs2.Addresses(), 8)
+	require.NoError(t, fs2.RecoverKey(deleted))
+}
+
 func BenchmarkDSBackendSimple(b *testing.B) {
 	ds := datastore.NewMapDatastore()
 	defer func() {
@@ -0,0 +1,218 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/crypto"
+)
+
+var (
+	ErrHDSeedExists = errors.New("wallet already has a hd seed")
+	ErrNoHDSeed     = errors.New("wallet has no hd seed, create one with `wallet new --hd` or `wallet restore`")
+	ErrWalletLocked = errors.New("wallet is locked")
+)
+
+// hdPrefix is the datastore namespace of the hd wallet, it is not an address so the
+// backend skips it when listing keys.
+var hdPrefix = ds.NewKey("/_hd")
+
+var hdSeedKey = hdPrefix.ChildString("seed")
+
+// HDAddress is an address derived from the hd seed of the wallet.
+type HDAddress struct {
+	Address address.Address
+	Index   uint32
+	Path    string
+	// Mnemonic is only set when the seed was created along with the address, it is
+	// never returned again.
+	Mnemonic string `json:",omitempty"`
+}
+
+// hdSeed is the encrypted BIP39 seed along with the next index to derive for every
+// signature type.
+type hdSeed struct {
+	Crypto    CryptoJSON                `json:"crypto"`
+	NextIndex map[crypto.SigType]uint32 `json:"nextIndex"`
+	Version   int                       `json:"version"`
+}
+
+// HasHDSeed returns whether the backend holds a seed to derive addresses from.
+func (backend *DSBackend) HasHDSeed() bool {
+	has, err := backend.ds.Has(hdSeedKey)
+	return err == nil && has
+}
+
+// NewHDSeed generates a new mnemonic and stores its encrypted seed. The mnemonic is
+// returned to be written down, it can not be recovered from the wallet.
+func (backend *DSBackend) NewHDSeed() (string, error) {
+	mnemonic, err := crypto.NewMnemonic()
+	if err != nil {
+		return "", err
+	}
+	if err := backend.RestoreHDSeed(mnemonic); err != nil {
+		return "", err
+	}
+	return mnemonic, nil
+}
+
+// RestoreHDSeed stores the encrypted seed of an existing mnemonic, the addresses derived
+// from it can then be recreated with DeriveAddress.
+func (backend *DSBackend) RestoreHDSeed(mnemonic string) error {
+	backend.hdLk.Lock()
+	defer backend.hdLk.Unlock()
+
+	if backend.state == Lock {
+		return ErrWalletLocked
+	}
+	if backend.HasHDSeed() {
+		return ErrHDSeedExists
+	}
+
+	seed, err := crypto.MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return err
+	}
+	defer wipe(seed)
+
+	var cryptoJSON CryptoJSON
+	err = backend.UsePassword(func(password []byte) error {
+		var err error
+		cryptoJSON, err = encryptData(seed, password, backend.PassphraseConf.ScryptN, backend.PassphraseConf.ScryptP)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return backend.putHDSeed(&hdSeed{
+		Crypto:    cryptoJSON,
+		NextIndex: make(map[crypto.SigType]uint32),
+		Version:   version,
+	})
+}
+
+// NewHDAddress derives the key following the last one derived for `protocol`, stores it
+// and returns its address.
+func (backend *DSBackend) NewHDAddress(protocol address.Protocol) (*HDAddress, error) {
+	backend.hdLk.Lock()
+	defer backend.hdLk.Unlock()
+
+	hs, err := backend.getHDSeed()
+	if err != nil {
+		return nil, err
+	}
+	sigType, err := protocolToSigType(protocol)
+	if err != nil {
+		return nil, err
+	}
+	return backend.deriveAddress(hs, sigType, hs.NextIndex[sigType])
+}
+
+// DeriveAddress derives the key of `protocol` at `index`, stores it and returns its address.
+func (backend *DSBackend) DeriveAddress(protocol address.Protocol, index uint32) (*HDAddress, error) {
+	backend.hdLk.Lock()
+	defer backend.hdLk.Unlock()
+
+	hs, err := backend.getHDSeed()
+	if err != nil {
+		return nil, err
+	}
+	sigType, err := protocolToSigType(protocol)
+	if err != nil {
+		return nil, err
+	}
+	return backend.deriveAddress(hs, sigType, index)
+}
+
+func (backend *DSBackend) deriveAddress(hs *hdSeed, sigType crypto.SigType, index uint32) (*HDAddress, error) {
+	if backend.state == Lock {
+		return nil, ErrWalletLocked
+	}
+
+	var seed []byte
+	err := backend.UsePassword(func(password []byte) error {
+		var err error
+		seed, err = decryptData(hs.Crypto, password)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(seed)
+
+	ki, err := crypto.DeriveKey(sigType, seed, index)
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.putKeyInfo(&ki); err != nil {
+		return nil, err
+	}
+
+	if index >= hs.NextIndex[sigType] {
+		hs.NextIndex[sigType] = index + 1
+		if err := backend.putHDSeed(hs); err != nil {
+			return nil, err
+		}
+	}
+
+	addr, err := ki.Address()
+	if err != nil {
+		return nil, err
+	}
+	path, err := crypto.HDPath(sigType, index)
+	if err != nil {
+		return nil, err
+	}
+	return &HDAddress{Address: addr, Index: index, Path: path}, nil
+}
+
+func (backend *DSBackend) getHDSeed() (*hdSeed, error) {
+	b, err := backend.ds.Get(hdSeedKey)
+	if err == ds.ErrNotFound {
+		return nil, ErrNoHDSeed
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch hd seed from backend")
+	}
+
+	hs := &hdSeed{}
+	if err := json.Unmarshal(b, hs); err != nil {
+		return nil, err
+	}
+	if hs.Version != version {
+		return nil, fmt.Errorf("version not supported: %v", hs.Version)
+	}
+	if hs.NextIndex == nil {
+		hs.NextIndex = make(map[crypto.SigType]uint32)
+	}
+	return hs, nil
+}
+
+func (backend *DSBackend) putHDSeed(hs *hdSeed) error {
+	b, err := json.Marshal(hs)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(backend.ds.Put(hdSeedKey, b), "failed to store hd seed")
+}
+
+func protocolToSigType(protocol address.Protocol) (crypto.SigType, error) {
+	switch protocol {
+	case address.BLS:
+		return crypto.SigTypeBLS, nil
+	case address.SECP256K1:
+		return crypto.SigTypeSecp256k1, nil
+	default:
+		return crypto.SigTypeUnknown, errors.Errorf("Unknown address protocol %d", protocol)
+	}
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
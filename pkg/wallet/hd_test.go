@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	_ "github.com/filecoin-project/venus/pkg/crypto/bls"
+	_ "github.com/filecoin-project/venus/pkg/crypto/secp"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+)
+
+func TestDSBackendHDAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	fs, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+
+	_, err = fs.NewHDAddress(address.SECP256K1)
+	assert.Equal(t, ErrNoHDSeed, err)
+
+	mnemonic, err := fs.NewHDSeed()
+	require.NoError(t, err)
+	assert.Equal(t, ErrHDSeedExists, fs.RestoreHDSeed(mnemonic))
+
+	secp0, err := fs.NewHDAddress(address.SECP256K1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), secp0.Index)
+	secp1, err := fs.NewHDAddress(address.SECP256K1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), secp1.Index)
+	bls0, err := fs.NewHDAddress(address.BLS)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), bls0.Index)
+
+	for _, a := range []*HDAddress{secp0, secp1, bls0} {
+		assert.True(t, fs.HasAddress(a.Address))
+	}
+
+	t.Log("the seed is not listed as an address when loading fresh in a new backend")
+	fs2, err := NewDSBackend(ds, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+	assert.Len(t, fs2.Addresses(), 3)
+	secp2, err := fs2.NewHDAddress(address.SECP256K1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), secp2.Index)
+
+	t.Log("restoring the mnemonic in another wallet derives the same addresses")
+	ds3 := datastore.NewMapDatastore()
+	fs3, err := NewDSBackend(ds3, config.TestPassphraseConfig(), []byte("test-password"))
+	require.NoError(t, err)
+	require.NoError(t, fs3.RestoreHDSeed(mnemonic))
+
+	for _, a := range []*HDAddress{secp0, secp1, secp2, bls0} {
+		restored, err := fs3.DeriveAddress(a.Address.Protocol(), a.Index)
+		require.NoError(t, err)
+		assert.Equal(t, a.Address, restored.Address)
+		assert.Equal(t, a.Path, restored.Path)
+	}
+}
@@ -0,0 +1,309 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+// PolicyViolationKind is the rule of the sign policy a request was denied by.
+type PolicyViolationKind string
+
+const (
+	ViolationMsgType    = PolicyViolationKind("msgType")
+	ViolationMessage    = PolicyViolationKind("message")
+	ViolationMethod     = PolicyViolationKind("method")
+	ViolationRecipient  = PolicyViolationKind("recipient")
+	ViolationValue      = PolicyViolationKind("maxValue")
+	ViolationDailyValue = PolicyViolationKind("maxDailyValue")
+)
+
+// PolicyViolationError is returned when the sign policy denies a request.
+type PolicyViolationError struct {
+	Address address.Address
+	MsgType MsgType
+	Kind    PolicyViolationKind
+	Reason  string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("sign policy of %s denies %s: %s", e.Address, e.MsgType, e.Reason)
+}
+
+// IsPolicyViolation returns whether err was returned because of the sign policy.
+func IsPolicyViolation(err error) bool {
+	var pe *PolicyViolationError
+	return errors.As(err, &pe)
+}
+
+// checkedMsgTypes are the types whose signed bytes are checked against the MsgMeta.Extra sent
+// with them, the only types a rule can allow.
+var checkedMsgTypes = map[MsgType]struct{}{
+	MTChainMsg:      {},
+	MTBlock:         {},
+	MTSignedVoucher: {},
+	MTDealProposal:  {},
+}
+
+// signRule is the parsed form of config.SignRuleConfig.
+type signRule struct {
+	msgTypes      map[MsgType]struct{}
+	methods       map[abi.MethodNum]struct{}
+	allowedTo     map[address.Address]struct{}
+	maxValue      *big.Int
+	maxDailyValue *big.Int
+}
+
+// needMessage returns whether the rule needs to look into the signed message.
+func (r *signRule) needMessage() bool {
+	return r.methods != nil || r.allowedTo != nil || r.maxValue != nil || r.maxDailyValue != nil
+}
+
+type dailySpend struct {
+	day   int64
+	spent big.Int
+}
+
+// SignPolicy decides what the addresses of the wallet are allowed to sign, based on
+// the type of the request and, for chain messages, on the message itself.
+// The daily spends are kept in memory, they are reset when the node restarts.
+type SignPolicy struct {
+	lk sync.Mutex
+
+	def   *signRule
+	rules map[address.Address]*signRule
+	spent map[address.Address]*dailySpend
+
+	now func() time.Time
+}
+
+// NewSignPolicy parses the sign policy in the config.
+func NewSignPolicy(cfg *config.SignPolicyConfig) (*SignPolicy, error) {
+	policy := &SignPolicy{
+		rules: make(map[address.Address]*signRule),
+		spent: make(map[address.Address]*dailySpend),
+		now:   time.Now,
+	}
+	if cfg == nil {
+		return policy, nil
+	}
+
+	var err error
+	if cfg.Default != nil {
+		if policy.def, err = parseSignRule(cfg.Default); err != nil {
+			return nil, errors.Wrap(err, "invalid default sign rule")
+		}
+	}
+	for addrStr, ruleCfg := range cfg.Rules {
+		addr, err := address.NewFromString(addrStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid address %s in sign policy", addrStr)
+		}
+		// the wallet signs with the key address, a rule keyed by any other address would never apply
+		if addr.Protocol() != address.BLS && addr.Protocol() != address.SECP256K1 {
+			return nil, errors.Errorf("invalid address %s in sign policy, rules must be keyed by bls or secp256k1 addresses", addrStr)
+		}
+		if ruleCfg == nil {
+			continue
+		}
+		if policy.rules[addr], err = parseSignRule(ruleCfg); err != nil {
+			return nil, errors.Wrapf(err, "invalid sign rule of %s", addrStr)
+		}
+	}
+	return policy, nil
+}
+
+func parseSignRule(cfg *config.SignRuleConfig) (*signRule, error) {
+	rule := &signRule{}
+	if len(cfg.MsgTypes) != 0 {
+		rule.msgTypes = make(map[MsgType]struct{}, len(cfg.MsgTypes))
+		for _, t := range cfg.MsgTypes {
+			if _, ok := checkedMsgTypes[MsgType(t)]; !ok {
+				return nil, errors.Errorf("message type %s can not be allowed, its signed bytes can not be checked", t)
+			}
+			rule.msgTypes[MsgType(t)] = struct{}{}
+		}
+	}
+	if len(cfg.Methods) != 0 {
+		rule.methods = make(map[abi.MethodNum]struct{}, len(cfg.Methods))
+		for _, m := range cfg.Methods {
+			rule.methods[m] = struct{}{}
+		}
+	}
+	if len(cfg.AllowedTo) != 0 {
+		rule.allowedTo = make(map[address.Address]struct{}, len(cfg.AllowedTo))
+		for _, s := range cfg.AllowedTo {
+			to, err := address.NewFromString(s)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid recipient %s", s)
+			}
+			rule.allowedTo[to] = struct{}{}
+		}
+	}
+	if len(cfg.MaxValue) != 0 {
+		v, err := types.ParseFIL(cfg.MaxValue)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid max value")
+		}
+		rule.maxValue = &big.Int{Int: v.Int}
+	}
+	if len(cfg.MaxDailyValue) != 0 {
+		v, err := types.ParseFIL(cfg.MaxDailyValue)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid max daily value")
+		}
+		rule.maxDailyValue = &big.Int{Int: v.Int}
+	}
+	return rule, nil
+}
+
+func (policy *SignPolicy) ruleOf(addr address.Address) *signRule {
+	if rule, ok := policy.rules[addr]; ok {
+		return rule
+	}
+	return policy.def
+}
+
+// Authorize checks that `addr` is allowed to sign `toSign`. When it is, the returned
+// function must be called once the signing is done, with whether it succeeded, so that
+// the value of the message is only counted in the daily spends when it was signed.
+func (policy *SignPolicy) Authorize(addr address.Address, toSign []byte, meta MsgMeta) (func(signed bool), error) {
+	policy.lk.Lock()
+	defer policy.lk.Unlock()
+
+	done, err := policy.authorize(addr, toSign, meta)
+	if err != nil {
+		walletLog.Warnw("sign request denied", "address", addr, "type", meta.Type, "reason", err)
+		return nil, err
+	}
+	walletLog.Infow("sign request allowed", "address", addr, "type", meta.Type)
+	return done, nil
+}
+
+func (policy *SignPolicy) authorize(addr address.Address, toSign []byte, meta MsgMeta) (func(signed bool), error) {
+	noop := func(bool) {}
+	rule := policy.ruleOf(addr)
+	if rule == nil {
+		return noop, nil
+	}
+
+	deny := func(kind PolicyViolationKind, format string, args ...interface{}) error {
+		return &PolicyViolationError{Address: addr, MsgType: meta.Type, Kind: kind, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if rule.msgTypes != nil {
+		if _, ok := rule.msgTypes[meta.Type]; !ok {
+			return nil, deny(ViolationMsgType, "message type is not allowed")
+		}
+	} else if !rule.needMessage() {
+		return noop, nil
+	}
+	// the restrictions on messages can't be checked on other types, which could be used to sign a message
+	if rule.needMessage() && meta.Type != MTChainMsg {
+		return nil, deny(ViolationMsgType, "only chain messages are allowed by a rule restricting messages")
+	}
+
+	// the type sent with the request is only trusted once the signed bytes are checked against
+	// what it carries, the types which can't be checked are denied
+	var msg *types.UnsignedMessage
+	switch meta.Type {
+	case MTChainMsg:
+		var err error
+		if msg, err = types.DecodeMessage(meta.Extra); err != nil {
+			return nil, deny(ViolationMessage, "message can not be decoded: %v", err)
+		}
+		if !bytes.Equal(msg.Cid().Bytes(), toSign) {
+			return nil, deny(ViolationMessage, "signed bytes do not match the message")
+		}
+	case MTBlock:
+		blk, err := types.DecodeBlock(meta.Extra)
+		if err != nil {
+			return nil, deny(ViolationMessage, "block header can not be decoded: %v", err)
+		}
+		if !bytes.Equal(blk.SignatureData(), toSign) {
+			return nil, deny(ViolationMessage, "signed bytes do not match the block header")
+		}
+	case MTSignedVoucher:
+		var sv paych.SignedVoucher
+		if err := sv.UnmarshalCBOR(bytes.NewReader(meta.Extra)); err != nil {
+			return nil, deny(ViolationMessage, "voucher can not be decoded: %v", err)
+		}
+		vb, err := sv.SigningBytes()
+		if err != nil {
+			return nil, deny(ViolationMessage, "voucher can not be encoded: %v", err)
+		}
+		if !bytes.Equal(vb, toSign) {
+			return nil, deny(ViolationMessage, "signed bytes do not match the voucher")
+		}
+	case MTDealProposal:
+		var proposal market0.DealProposal
+		if err := proposal.UnmarshalCBOR(bytes.NewReader(meta.Extra)); err != nil {
+			return nil, deny(ViolationMessage, "deal proposal can not be decoded: %v", err)
+		}
+		buf := new(bytes.Buffer)
+		if err := proposal.MarshalCBOR(buf); err != nil {
+			return nil, deny(ViolationMessage, "deal proposal can not be encoded: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), toSign) {
+			return nil, deny(ViolationMessage, "signed bytes do not match the deal proposal")
+		}
+	default:
+		return nil, deny(ViolationMsgType, "signed bytes of the message type can not be checked")
+	}
+	if !rule.needMessage() {
+		return noop, nil
+	}
+
+	if rule.methods != nil {
+		if _, ok := rule.methods[msg.Method]; !ok {
+			return nil, deny(ViolationMethod, "method %d is not allowed", msg.Method)
+		}
+	}
+	if rule.allowedTo != nil {
+		if _, ok := rule.allowedTo[msg.To]; !ok {
+			return nil, deny(ViolationRecipient, "recipient %s is not allowed", msg.To)
+		}
+	}
+	if rule.maxValue != nil && msg.Value.GreaterThan(*rule.maxValue) {
+		return nil, deny(ViolationValue, "value %s is over the limit of %s", types.FIL(msg.Value), types.FIL(*rule.maxValue))
+	}
+	if rule.maxDailyValue == nil {
+		return noop, nil
+	}
+
+	day := policy.now().UTC().Unix() / int64(24*time.Hour/time.Second)
+	spend, ok := policy.spent[addr]
+	if !ok || spend.day != day {
+		spend = &dailySpend{day: day, spent: big.Zero()}
+		policy.spent[addr] = spend
+	}
+	total := big.Add(spend.spent, msg.Value)
+	if total.GreaterThan(*rule.maxDailyValue) {
+		return nil, deny(ViolationDailyValue, "value %s would bring the spends of the day to %s, over the limit of %s",
+			types.FIL(msg.Value), types.FIL(total), types.FIL(*rule.maxDailyValue))
+	}
+
+	// reserve the value until the signing is done so concurrent requests can't exceed the limit
+	spend.spent = total
+	return func(signed bool) {
+		if signed {
+			return
+		}
+		policy.lk.Lock()
+		defer policy.lk.Unlock()
+		if spend.day == day {
+			spend.spent = big.Sub(spend.spent, msg.Value)
+		}
+	}, nil
+}
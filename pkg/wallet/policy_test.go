@@ -0,0 +1,191 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/venus/pkg/config"
+	"github.com/filecoin-project/venus/pkg/specactors/builtin/paych"
+	emptycid "github.com/filecoin-project/venus/pkg/testhelpers/empty_cid"
+	tf "github.com/filecoin-project/venus/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/venus/pkg/types"
+)
+
+func TestSignPolicy(t *testing.T) {
+	tf.UnitTest(t)
+
+	owner, _ := address.NewSecp256k1Address([]byte("owner"))
+	worker, _ := address.NewSecp256k1Address([]byte("worker"))
+	spender, _ := address.NewSecp256k1Address([]byte("spender"))
+	allowed, _ := address.NewIDAddress(200)
+	other, _ := address.NewIDAddress(201)
+	anyone, _ := address.NewSecp256k1Address([]byte("anyone"))
+
+	policy, err := NewSignPolicy(&config.SignPolicyConfig{
+		Default: &config.SignRuleConfig{
+			MsgTypes: []string{string(MTBlock), string(MTSignedVoucher)},
+		},
+		Rules: map[string]*config.SignRuleConfig{
+			owner.String(): {
+				MsgTypes:      []string{string(MTChainMsg)},
+				Methods:       []abi.MethodNum{0},
+				AllowedTo:     []string{allowed.String()},
+				MaxValue:      "10",
+				MaxDailyValue: "15",
+			},
+			spender.String(): {
+				MaxValue: "10",
+			},
+			anyone.String(): {},
+		},
+	})
+	require.NoError(t, err)
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy.now = func() time.Time { return now }
+
+	chainMsgFrom := func(from, to address.Address, value string, method abi.MethodNum) ([]byte, MsgMeta) {
+		msg := types.NewUnsignedMessage(from, to, 0, abi.TokenAmount(types.MustParseFIL(value)), method, nil)
+		blk, err := msg.ToStorageBlock()
+		require.NoError(t, err)
+		return blk.Cid().Bytes(), MsgMeta{Type: MTChainMsg, Extra: blk.RawData()}
+	}
+	chainMsg := func(to address.Address, value string, method abi.MethodNum) ([]byte, MsgMeta) {
+		return chainMsgFrom(owner, to, value, method)
+	}
+	kindOf := func(err error) PolicyViolationKind {
+		require.True(t, IsPolicyViolation(err), err)
+		return err.(*PolicyViolationError).Kind
+	}
+
+	header := &types.BlockHeader{
+		Miner:                 allowed,
+		ParentWeight:          big.Zero(),
+		Messages:              emptycid.EmptyTxMetaCID,
+		ParentStateRoot:       emptycid.EmptyMessagesCID,
+		ParentMessageReceipts: emptycid.EmptyReceiptsCID,
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	}
+	blockSigning := header.SignatureData()
+
+	t.Log("the default rule applies to the addresses without a rule")
+	_, err = policy.Authorize(worker, blockSigning, MsgMeta{Type: MTBlock, Extra: blockSigning})
+	assert.NoError(t, err)
+	_, err = policy.Authorize(worker, []byte("proposal"), MsgMeta{Type: MTDealProposal})
+	assert.Equal(t, ViolationMsgType, kindOf(err))
+
+	t.Log("the allowed types are checked against the signed bytes")
+	toSign, meta := chainMsgFrom(worker, other, "100", 0)
+	_, err = policy.Authorize(worker, toSign, MsgMeta{Type: MTBlock, Extra: blockSigning})
+	assert.Equal(t, ViolationMessage, kindOf(err))
+	_, err = policy.Authorize(worker, toSign, MsgMeta{Type: MTBlock, Extra: meta.Extra})
+	assert.Equal(t, ViolationMessage, kindOf(err))
+	_, err = policy.Authorize(worker, blockSigning, MsgMeta{Type: MTBlock})
+	assert.Equal(t, ViolationMessage, kindOf(err))
+	voucher := &paych.SignedVoucher{ChannelAddr: allowed, Lane: 1, Nonce: 1, Amount: big.NewInt(10)}
+	voucherSigning, err := voucher.SigningBytes()
+	require.NoError(t, err)
+	_, err = policy.Authorize(worker, voucherSigning, MsgMeta{Type: MTSignedVoucher, Extra: voucherSigning})
+	assert.NoError(t, err)
+	_, err = policy.Authorize(worker, toSign, MsgMeta{Type: MTSignedVoucher, Extra: voucherSigning})
+	assert.Equal(t, ViolationMessage, kindOf(err))
+	_, err = policy.Authorize(worker, voucherSigning, MsgMeta{Type: MTSignedVoucher})
+	assert.Equal(t, ViolationMessage, kindOf(err))
+
+	t.Log("an empty rule allows anything")
+	_, err = policy.Authorize(anyone, toSign, MsgMeta{Type: MTSignedVoucher})
+	assert.NoError(t, err)
+
+	t.Log("messages are checked against the rule of the address")
+	toSign, meta = chainMsg(allowed, "5", 0)
+	done, err := policy.Authorize(owner, toSign, meta)
+	require.NoError(t, err)
+	done(true)
+
+	toSign, meta = chainMsg(other, "1", 0)
+	_, err = policy.Authorize(owner, toSign, meta)
+	assert.Equal(t, ViolationRecipient, kindOf(err))
+
+	toSign, meta = chainMsg(allowed, "1", 2)
+	_, err = policy.Authorize(owner, toSign, meta)
+	assert.Equal(t, ViolationMethod, kindOf(err))
+
+	toSign, meta = chainMsg(allowed, "11", 0)
+	_, err = policy.Authorize(owner, toSign, meta)
+	assert.Equal(t, ViolationValue, kindOf(err))
+
+	t.Log("the signed bytes must match the message")
+	_, meta = chainMsg(allowed, "1", 0)
+	_, err = policy.Authorize(owner, []byte("something else"), meta)
+	assert.Equal(t, ViolationMessage, kindOf(err))
+
+	t.Log("the value of failed signings is not counted in the daily spends")
+	toSign, meta = chainMsg(allowed, "10", 0)
+	done, err = policy.Authorize(owner, toSign, meta)
+	require.NoError(t, err)
+	done(false)
+
+	toSign, meta = chainMsg(allowed, "8", 0)
+	done, err = policy.Authorize(owner, toSign, meta)
+	require.NoError(t, err)
+	done(true)
+
+	toSign, meta = chainMsg(allowed, "3", 0)
+	_, err = policy.Authorize(owner, toSign, meta)
+	assert.Equal(t, ViolationDailyValue, kindOf(err))
+
+	t.Log("the daily spends are reset the next day")
+	now = now.Add(24 * time.Hour)
+	_, err = policy.Authorize(owner, toSign, meta)
+	assert.NoError(t, err)
+
+	t.Log("a rule restricting messages can't be bypassed by signing a message as another type")
+	toSign, meta = chainMsgFrom(spender, other, "100", 0)
+	_, err = policy.Authorize(spender, toSign, meta)
+	assert.Equal(t, ViolationValue, kindOf(err))
+	for _, msgType := range []MsgType{MTUnknown, MTDealProposal, MTBlock} {
+		_, err = policy.Authorize(spender, toSign, MsgMeta{Type: msgType, Extra: meta.Extra})
+		assert.Equal(t, ViolationMsgType, kindOf(err), msgType)
+		_, err = policy.Authorize(spender, toSign, MsgMeta{Type: msgType})
+		assert.Equal(t, ViolationMsgType, kindOf(err), msgType)
+	}
+}
+
+func TestSignPolicyRejectsUncheckedMsgTypes(t *testing.T) {
+	tf.UnitTest(t)
+
+	for _, msgType := range []MsgType{MTUnknown, MTStorageAsk, MTClientDeal} {
+		_, err := NewSignPolicy(&config.SignPolicyConfig{
+			Default: &config.SignRuleConfig{MsgTypes: []string{string(msgType)}},
+		})
+		assert.Error(t, err, msgType)
+	}
+	_, err := NewSignPolicy(&config.SignPolicyConfig{
+		Default: &config.SignRuleConfig{MsgTypes: []string{string(MTChainMsg), string(MTBlock), string(MTSignedVoucher), string(MTDealProposal)}},
+	})
+	assert.NoError(t, err)
+}
+
+func TestSignPolicyRejectsRulesOnIDAddresses(t *testing.T) {
+	tf.UnitTest(t)
+
+	id, _ := address.NewIDAddress(100)
+	actor, _ := address.NewActorAddress([]byte("multisig"))
+	for _, addr := range []address.Address{id, actor} {
+		_, err := NewSignPolicy(&config.SignPolicyConfig{
+			Rules: map[string]*config.SignRuleConfig{addr.String(): {MaxValue: "10"}},
+		})
+		assert.Error(t, err, addr)
+	}
+
+	bls, _ := address.NewBLSAddress(make([]byte, address.BlsPublicKeyBytes))
+	_, err := NewSignPolicy(&config.SignPolicyConfig{
+		Rules: map[string]*config.SignRuleConfig{bls.String(): {MaxValue: "10"}},
+	})
+	assert.NoError(t, err)
+}
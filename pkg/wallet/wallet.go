@@ -11,6 +11,7 @@ import (
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/venus/pkg/config"
 	"github.com/filecoin-project/venus/pkg/crypto"
 )
 
@@ -38,6 +39,8 @@ type Wallet struct {
 	lk sync.Mutex
 
 	backends map[reflect.Type][]Backend
+
+	policy *SignPolicy
 }
 
 // New constructs a new wallet, that manages addresses in all the
@@ -212,7 +215,28 @@ func (w *Wallet) WalletSign(addr address.Address, msg []byte, meta MsgMeta) (*cr
 		return nil, errors.Errorf("signing using key '%s': %v", addr.String(), ErrKeyInfoNotFound)
 	}
 
-	return ki.SignBytes(msg, addr)
+	w.lk.Lock()
+	policy := w.policy
+	w.lk.Unlock()
+	if policy == nil {
+		return ki.SignBytes(msg, addr)
+	}
+
+	done, err := policy.Authorize(addr, msg, meta)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ki.SignBytes(msg, addr)
+	done(err == nil)
+	return sig, err
+}
+
+//SetSignPolicy set the policy checked before signing with WalletSign, nil removes it
+func (w *Wallet) SetSignPolicy(policy *SignPolicy) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	w.policy = policy
 }
 
 //DSBacked return the first wallet backend
@@ -274,3 +298,80 @@ func (w *Wallet) WalletState() int {
 	}
 	return backend.WalletState()
 }
+
+// NewHDAddress derives a new address from the hd seed of the local wallet, the seed is
+// created first if the wallet has none, in which case its mnemonic is returned along.
+func (w *Wallet) NewHDAddress(p address.Protocol) (*HDAddress, error) {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return nil, err
+	}
+
+	var mnemonic string
+	if !backend.HasHDSeed() {
+		if mnemonic, err = backend.NewHDSeed(); err != nil {
+			return nil, err
+		}
+	}
+
+	addr, err := backend.NewHDAddress(p)
+	if err != nil {
+		return nil, err
+	}
+	addr.Mnemonic = mnemonic
+	return addr, nil
+}
+
+// RestoreHDSeed sets the hd seed of the local wallet from a mnemonic
+func (w *Wallet) RestoreHDSeed(mnemonic string) error {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return err
+	}
+	return backend.RestoreHDSeed(mnemonic)
+}
+
+// DeriveAddress derives the address at `index` from the hd seed of the local wallet
+func (w *Wallet) DeriveAddress(p address.Protocol, index uint32) (*HDAddress, error) {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return nil, err
+	}
+	return backend.DeriveAddress(p, index)
+}
+
+// DeleteAddress moves the key of `addr` to the trash of the local wallet
+func (w *Wallet) DeleteAddress(addr address.Address) error {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return err
+	}
+	return backend.DeleteKey(addr)
+}
+
+// UndeleteAddress recovers the key of a deleted address from the trash of the local wallet
+func (w *Wallet) UndeleteAddress(addr address.Address) error {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return err
+	}
+	return backend.RecoverKey(addr)
+}
+
+// ChangePassword re-encrypts the local wallet with a new password
+func (w *Wallet) ChangePassword(oldPassword, newPassword []byte) error {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return err
+	}
+	return backend.ChangePassword(oldPassword, newPassword)
+}
+
+// Reencrypt re-encrypts the local wallet with new scrypt parameters
+func (w *Wallet) Reencrypt(password []byte, conf config.PassphraseConfig) error {
+	backend, err := w.DSBacked()
+	if err != nil {
+		return err
+	}
+	return backend.Reencrypt(password, conf)
+}
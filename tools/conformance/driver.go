@@ -91,7 +91,7 @@ func (d *Driver) ExecuteTipset(bs blockstore.Blockstore, chainDs ds.Batching, pr
 	mainNetParams := networks.Mainnet()
 	node.SetNetParams(&mainNetParams.Network)
 	//chainstore
-	chainStore := chain.NewStore(chainDs, ipldStore, bs, mainNetParams.Network.ForkUpgradeParam, cid.Undef) //load genesis from car
+	chainStore := chain.NewStore(chainDs, ipldStore, bs, mainNetParams.Network.ForkUpgradeParam, cid.Undef, nil) //load genesis from car
 
 	//drand
 	/*genBlk, err := chainStore.GetGenesisBlock(context.TODO())
@@ -251,7 +251,7 @@ func (d *Driver) ExecuteMessage(bs blockstore.Blockstore, params ExecuteMessageP
 	ipldStore := cbor.NewCborStore(bs)
 	chainDs := ds.NewMapDatastore() //just mock one
 	//chainstore
-	chainStore := chain.NewStore(chainDs, ipldStore, bs, mainNetParams.Network.ForkUpgradeParam, cid.Undef) //load genesis from car
+	chainStore := chain.NewStore(chainDs, ipldStore, bs, mainNetParams.Network.ForkUpgradeParam, cid.Undef, nil) //load genesis from car
 
 	//drand
 	/*	genBlk, err := chainStore.GetGenesisBlock(context.TODO())
@@ -34,6 +34,10 @@ var defaultPerm = []string{"perm", "read"}
 
 var regRule, _ = regexp.Compile(rulePattern)
 
+// regPermTag matches the permission tags, like perm:admin, which some methods carry after
+// their declaration instead of a rule. They are not part of the doc of the method.
+var regPermTag = regexp.MustCompile(`^perm:\w+$`)
+
 func parseRule(comment string) (*funcrule.Rule, map[string][]string) {
 	rule := new(funcrule.Rule)
 	match := regRule.FindStringSubmatch(comment)
@@ -106,6 +110,13 @@ func main() {
 	if err := generate("./app/submodule", "apiface", "client", "./app/client/full.go"); err != nil {
 		fmt.Println("error: ", err)
 	}
+	docVersions := map[string][]string{
+		"v0": {"apiface", "apiface/v0api"},
+		"v1": {"apiface"},
+	}
+	if err := generateDocs("./app/submodule", docVersions, "openrpc", "./app/openrpc/docs_gen.go"); err != nil {
+		fmt.Println("error: ", err)
+	}
 }
 
 func typeName(e ast.Expr, pkg string) (string, error) {
@@ -296,9 +307,14 @@ func generate(rootPath string, pkg, outpkg, outfile string) error {
 
 				}
 
-				// try to parse tag info
+				// try to parse tag info, the rule may follow the doc comment of the method
 				if len(filteredComments) > 0 {
 					cmt := filteredComments[0].List[0].Text
+					for _, c := range filteredComments[0].List {
+						if regRule.MatchString(c.Text) {
+							cmt = c.Text
+						}
+					}
 					rule, tags := parseRule(cmt)
 					info.Methods[mname].Tags[rkPerm] = tags[rkPerm]
 					// remove ignore method
@@ -318,7 +334,7 @@ func generate(rootPath string, pkg, outpkg, outfile string) error {
 	if err != nil {
 		return err
 	}
-	err = doTemplate(w, m, `// Code generated by github.com/filecoin-project/tools/gen/api. DO NOT EDIT.
+	err = doTemplate(w, m, `// Code generated by github.com/filecoin-project/venus/tools/gen/api. DO NOT EDIT.
 
 package {{.OutPkg}}
 
@@ -349,3 +365,122 @@ func doTemplate(w io.Writer, info interface{}, templ string) error {
 
 	return t.Execute(w, info)
 }
+
+// generateDocs writes the doc comments and the parameter names of the methods of the api
+// interfaces, which the OpenRPC documents are built with. They are keyed by version, then by
+// interface and method, as the v0 api replaces some interfaces of the v1 api with its own.
+func generateDocs(rootPath string, versions map[string][]string, outpkg, outfile string) error {
+	apiDir, err := filepath.Abs(rootPath)
+	if err != nil {
+		return err
+	}
+	outfile, err = filepath.Abs(outfile)
+	if err != nil {
+		return err
+	}
+
+	type meta struct {
+		OutPkg   string
+		Versions map[string]map[string]*methodDoc
+	}
+	m := &meta{OutPkg: outpkg, Versions: map[string]map[string]*methodDoc{}}
+
+	for version, pkgs := range versions {
+		docs := map[string]*methodDoc{}
+		// the interfaces of the later packages replace those of the same name
+		for _, pkg := range pkgs {
+			pkgDocs, err := interfaceDocs(filepath.Join(apiDir, pkg))
+			if err != nil {
+				return err
+			}
+			for iface, methods := range pkgDocs {
+				for k := range docs {
+					if strings.HasPrefix(k, iface+".") {
+						delete(docs, k)
+					}
+				}
+				for name, doc := range methods {
+					docs[iface+"."+name] = doc
+				}
+			}
+		}
+		m.Versions[version] = docs
+	}
+
+	w, err := os.OpenFile(outfile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer w.Close() // nolint: errcheck
+
+	return doTemplate(w, m, `// Code generated by github.com/filecoin-project/venus/tools/gen/api. DO NOT EDIT.
+
+package {{.OutPkg}}
+
+var methodDocs = map[string]map[string]methodDoc{
+{{range $version, $docs := .Versions}}	"{{$version}}": {
+{{range $name, $doc := $docs}}		"{{$name}}": {Doc: {{$doc.Doc}}, Params: []string{ {{- $doc.Params -}} }},
+{{end}}	},
+{{end}}}
+`)
+}
+
+type methodDoc struct {
+	Doc    string
+	Params string
+}
+
+// interfaceDocs returns the docs of the methods of the interfaces of the package in `dir`,
+// by interface and method.
+func interfaceDocs(dir string) (map[string]map[string]*methodDoc, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := pkgs[filepath.Base(dir)]
+	if !ok {
+		return nil, fmt.Errorf("no package %s in %s", filepath.Base(dir), dir)
+	}
+	visitor := &Visitor{make(map[string]map[string]*methodMeta), map[string][]string{}}
+	ast.Walk(visitor, pkg)
+
+	docs := map[string]map[string]*methodDoc{}
+	for _, f := range pkg.Files {
+		cmap := ast.NewCommentMap(fset, f, f.Comments)
+		for iface, methods := range visitor.Methods {
+			if docs[iface] == nil {
+				docs[iface] = map[string]*methodDoc{}
+			}
+			for mname, node := range methods {
+				comments := cmap.Filter(node.node).Comments()
+				if len(comments) == 0 && docs[iface][mname] != nil {
+					continue
+				}
+
+				var doc []string
+				for _, cg := range comments {
+					for _, c := range cg.List {
+						if line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//")); line != "" && !regRule.MatchString(line) && !regPermTag.MatchString(line) {
+							doc = append(doc, line)
+						}
+					}
+				}
+				var params []string
+				for _, param := range node.ftype.Params.List {
+					if len(param.Names) == 0 {
+						params = append(params, strconv.Quote(fmt.Sprintf("p%d", len(params))))
+					}
+					for _, n := range param.Names {
+						params = append(params, strconv.Quote(n.Name))
+					}
+				}
+				docs[iface][mname] = &methodDoc{
+					Doc:    strconv.Quote(strings.Join(doc, " ")),
+					Params: strings.Join(params, ", "),
+				}
+			}
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/filecoin-project/venus/app/client"
+	"github.com/filecoin-project/venus/app/client/v0api"
+	"github.com/filecoin-project/venus/app/openrpc"
+)
+
+// writes the OpenRPC documents of the v0 and v1 apis to documentation/openrpc
+func main() {
+	docs := map[string]interface{}{
+		"v0": &v0api.FullNodeStruct{},
+		"v1": &client.FullNodeStruct{},
+	}
+	outDir := "./documentation/openrpc"
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Println("error: ", err)
+		os.Exit(1)
+	}
+	for version, api := range docs {
+		data, err := json.MarshalIndent(openrpc.NewDocument(api, version), "", "  ")
+		if err != nil {
+			fmt.Println("error: ", err)
+			os.Exit(1)
+		}
+		if err := ioutil.WriteFile(filepath.Join(outDir, version+".json"), append(data, '\n'), 0644); err != nil {
+			fmt.Println("error: ", err)
+			os.Exit(1)
+		}
+	}
+}
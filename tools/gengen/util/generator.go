@@ -77,7 +77,7 @@ func NewGenesisGenerator(bs blockstore.Blockstore) *GenesisGenerator {
 
 	chainDs := ds.NewMapDatastore() //just mock one
 	//chainstore
-	chainStore := chain.NewStore(chainDs, cst, bs, config.DefaultForkUpgradeParam, cid.Undef) //load genesis from car
+	chainStore := chain.NewStore(chainDs, cst, bs, config.DefaultForkUpgradeParam, cid.Undef, nil) //load genesis from car
 	chainFork, err := fork.NewChainFork(context.TODO(), chainStore, cst, bs, config.NewDefaultConfig().NetworkParams)
 	if err != nil {
 		panic(xerrors.Errorf("create chain fork error %v", err))